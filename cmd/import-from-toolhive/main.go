@@ -10,20 +10,27 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
 	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
 	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/readme"
+	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
 var (
-	sourceURL  string
-	sourceFile string
-	outputDir  string
-	verbose    bool
-	dryRun     bool
+	sourceURL     string
+	sourceFile    string
+	outputDir     string
+	verbose       bool
+	dryRun        bool
+	summaryOnly   bool
+	readmeWorkers int
+	merge         bool
 )
 
 var rootCmd = &cobra.Command{
@@ -32,8 +39,17 @@ var rootCmd = &cobra.Command{
 	Long: `Import the existing ToolHive registry.json and convert it to the modular YAML format.
 Each registry entry will be converted to its own directory with a spec.yaml file.
 
+With --merge, entries that already exist are updated in place: only the fields
+set in registry.json are changed, and local additions such as examples, license
+and comments are kept, as is the entry's README. New entries are created as usual.
+
 This tool is specifically for importing from ToolHive's format. For migrating to
 upstream MCP Registry format, use the 'migrate' command (future).`,
+	Example: `  # Import the upstream registry, overwriting existing entries
+  import-from-toolhive
+
+  # Re-import from a local file, keeping local edits to existing entries
+  import-from-toolhive --file registry.json --merge`,
 	RunE: runImport,
 }
 
@@ -45,6 +61,12 @@ func init() {
 	rootCmd.Flags().StringVarP(&outputDir, "output", "o", "registry", "Output directory for YAML files")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be created without actually creating files")
+	rootCmd.Flags().BoolVar(&summaryOnly, "summary-only", false,
+		"Only print the final created/updated/skipped/failed counts (per-entry lines are still shown with --verbose)")
+	rootCmd.Flags().IntVar(&readmeWorkers, "readme-workers", runtime.NumCPU(),
+		"Number of READMEs to generate concurrently once the spec files are written")
+	rootCmd.Flags().BoolVar(&merge, "merge", false,
+		"Update only the imported fields of existing entries, keeping local edits and comments")
 }
 
 func main() {
@@ -65,15 +87,23 @@ func runImport(_ *cobra.Command, _ []string) error {
 		return err
 	}
 
-	fmt.Printf("Found %d registry entries to import\n", len(registry.Servers))
+	links, err := parseServerLinks(registryData)
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if !summaryOnly {
+		fmt.Fprintf(out, "Found %d registry entries to import\n", len(registry.Servers))
 
-	if dryRun {
-		fmt.Println("\nDry run mode - no files will be created")
-		fmt.Println("\nWould create the following structure:")
+		if dryRun {
+			fmt.Fprintln(out, "\nDry run mode - no files will be created")
+			fmt.Fprintln(out, "\nWould create the following structure:")
+		}
 	}
 
-	successCount := processRegistryEntries(registry)
-	printImportSummary(successCount, len(registry.Servers))
+	stats := processRegistryEntries(out, registry, links)
+	printImportSummary(out, stats, len(registry.Servers))
 
 	return nil
 }
@@ -125,19 +155,104 @@ func parseRegistry(registryData []byte) (*toolhiveRegistry.Registry, error) {
 	return &registry, nil
 }
 
-func processRegistryEntries(registry *toolhiveRegistry.Registry) int {
+// serverLinks holds the link fields of a server that the toolhive types don't carry
+type serverLinks struct {
+	Homepage         string `json:"homepage,omitempty" yaml:"homepage,omitempty"`
+	DocumentationURL string `json:"documentation_url,omitempty" yaml:"documentation_url,omitempty"`
+}
+
+// importedServer is a server as written to spec.yaml, including its links
+type importedServer struct {
+	*toolhiveRegistry.ImageMetadata `yaml:",inline"`
+	serverLinks                     `yaml:",inline"`
+}
+
+func parseServerLinks(registryData []byte) (map[string]serverLinks, error) {
+	var raw struct {
+		Servers map[string]serverLinks `json:"servers"`
+	}
+	if err := json.Unmarshal(registryData, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse registry JSON: %w", err)
+	}
+	return raw.Servers, nil
+}
+
+// specHeaderEnd marks the end of the generated header comment in imported spec files
+const specHeaderEnd = "# ---\n"
+
+// importOutcome describes what importing an entry did to its spec file
+type importOutcome int
+
+const (
+	// outcomeCreated means the spec file didn't exist before
+	outcomeCreated importOutcome = iota
+	// outcomeUpdated means the spec file existed with different content
+	outcomeUpdated
+	// outcomeSkipped means the spec file already had the imported content
+	outcomeSkipped
+)
+
+// importStats counts the outcome of every entry in an import
+type importStats struct {
+	created int
+	updated int
+	skipped int
+	failed  int
+}
+
+// imported returns the number of entries that were imported successfully, including unchanged ones
+func (s importStats) imported() int {
+	return s.created + s.updated + s.skipped
+}
+
+// showEntryLines reports whether per-entry lines should be printed
+func showEntryLines() bool {
+	if verbose {
+		return true
+	}
+	return dryRun && !summaryOnly
+}
+
+func processRegistryEntries(
+	out io.Writer, registry *toolhiveRegistry.Registry, links map[string]serverLinks,
+) importStats {
 	names := getSortedServerNames(registry)
 
-	successCount := 0
+	var stats importStats
+	var readmes []readmeJob
 	for _, name := range names {
 		server := registry.Servers[name]
-		if err := importEntry(name, server, outputDir, dryRun); err != nil {
-			log.Printf("Warning: Failed to import %s: %v", name, err)
+		outcome, readme, err := importEntry(out, name, server, links[name], outputDir, dryRun, merge)
+		if err != nil {
+			if !summaryOnly || verbose {
+				log.Printf("Warning: Failed to import %s: %v", name, err)
+			}
+			stats.failed++
 			continue
 		}
-		successCount++
+		if readme != nil {
+			readmes = append(readmes, *readme)
+		}
+
+		switch outcome {
+		case outcomeCreated:
+			stats.created++
+		case outcomeUpdated:
+			stats.updated++
+		case outcomeSkipped:
+			stats.skipped++
+		}
 	}
-	return successCount
+
+	// READMEs are generated once every spec file is written, and their errors are
+	// logged in name order regardless of which worker finished first
+	for i, err := range writeReadmes(readmes, readmeWorkers) {
+		if err != nil && verbose {
+			log.Printf("Warning: Failed to write README for %s: %v", readmes[i].name, err)
+		}
+	}
+
+	return stats
 }
 
 func getSortedServerNames(registry *toolhiveRegistry.Registry) []string {
@@ -156,35 +271,46 @@ func getSortedServerNames(registry *toolhiveRegistry.Registry) []string {
 	return names
 }
 
-func printImportSummary(successCount, totalCount int) {
+func printImportSummary(out io.Writer, stats importStats, totalCount int) {
+	counts := fmt.Sprintf("created: %d, updated: %d, skipped: %d, failed: %d",
+		stats.created, stats.updated, stats.skipped, stats.failed)
+
+	if summaryOnly {
+		if dryRun {
+			fmt.Fprintf(out, "Would import %d/%d entries (%s)\n", stats.imported(), totalCount, counts)
+		} else {
+			fmt.Fprintf(out, "Imported %d/%d entries to %s (%s)\n", stats.imported(), totalCount, outputDir, counts)
+		}
+		return
+	}
+
 	if !dryRun {
-		fmt.Printf("\n✓ Successfully imported %d/%d entries to %s\n", successCount, totalCount, outputDir)
-		fmt.Println("\nNext steps:")
-		fmt.Println("  1. Review the imported entries in the registry/ directory")
-		fmt.Println("  2. Run 'registry-builder validate' to validate all entries")
-		fmt.Println("  3. Run 'registry-builder build' to generate the registry.json")
+		fmt.Fprintf(out, "\n✓ Successfully imported %d/%d entries to %s\n", stats.imported(), totalCount, outputDir)
+		fmt.Fprintf(out, "  %s\n", counts)
+		fmt.Fprintln(out, "\nNext steps:")
+		fmt.Fprintln(out, "  1. Review the imported entries in the registry/ directory")
+		fmt.Fprintln(out, "  2. Run 'registry-builder validate' to validate all entries")
+		fmt.Fprintln(out, "  3. Run 'registry-builder build' to generate the registry.json")
 	} else {
-		fmt.Printf("\n✓ Would import %d/%d entries\n", successCount, totalCount)
+		fmt.Fprintf(out, "\n✓ Would import %d/%d entries\n", stats.imported(), totalCount)
+		fmt.Fprintf(out, "  %s\n", counts)
 	}
 }
 
-func importEntry(name string, server *toolhiveRegistry.ImageMetadata, outputDir string, dryRun bool) error {
+// importEntry writes the spec file of an entry. It returns the README to generate for the
+// entry, if it needs one, rather than writing it so READMEs can be generated concurrently.
+// With merge, an existing spec file is merged with the imported fields instead.
+func importEntry(
+	out io.Writer, name string, server *toolhiveRegistry.ImageMetadata, links serverLinks, outputDir string,
+	dryRun, merge bool,
+) (importOutcome, *readmeJob, error) {
 	// Sanitize the name for use as a directory
 	dirName := sanitizeName(name)
 	entryDir := filepath.Join(outputDir, dirName)
 	specPath := filepath.Join(entryDir, "spec.yaml")
 
-	if verbose || dryRun {
-		fmt.Printf("  %s -> %s\n", name, specPath)
-	}
-
-	if dryRun {
-		return nil
-	}
-
-	// Create the directory
-	if err := os.MkdirAll(entryDir, 0750); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	if showEntryLines() {
+		fmt.Fprintf(out, "  %s -> %s\n", name, specPath)
 	}
 
 	// Ensure the name is set in the metadata
@@ -196,15 +322,29 @@ func importEntry(name string, server *toolhiveRegistry.ImageMetadata, outputDir
 	var buf bytes.Buffer
 	encoder := yaml.NewEncoder(&buf)
 	encoder.SetIndent(2)
-	if err := encoder.Encode(server); err != nil {
-		return fmt.Errorf("failed to marshal YAML: %w", err)
+	if err := encoder.Encode(importedServer{ImageMetadata: server, serverLinks: links}); err != nil {
+		return 0, nil, fmt.Errorf("failed to marshal YAML: %w", err)
 	}
 	err := encoder.Close()
 	if err != nil {
-		return fmt.Errorf("failed to close YAML encoder: %w", err)
+		return 0, nil, fmt.Errorf("failed to close YAML encoder: %w", err)
 	}
 	yamlData := buf.Bytes()
 
+	outcome := specOutcome(specPath, yamlData)
+	if merge && outcome == outcomeUpdated {
+		outcome, err = mergeEntry(specPath, yamlData, dryRun)
+		return outcome, nil, err
+	}
+	if dryRun || outcome == outcomeSkipped {
+		return outcome, nil, nil
+	}
+
+	// Create the directory
+	if err := os.MkdirAll(entryDir, 0750); err != nil {
+		return 0, nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
 	// Add a header comment with metadata
 	header := fmt.Sprintf(`# %s MCP Server Registry Entry
 # Auto-imported from ToolHive registry.json
@@ -218,22 +358,58 @@ func importEntry(name string, server *toolhiveRegistry.ImageMetadata, outputDir
 
 	// Write the spec.yaml file
 	if err := os.WriteFile(specPath, []byte(finalContent), 0600); err != nil {
-		return fmt.Errorf("failed to write spec.yaml: %w", err)
+		return 0, nil, fmt.Errorf("failed to write spec.yaml: %w", err)
 	}
 
 	// Optionally create a README for complex entries
-	if shouldCreateReadme(server) {
-		readmePath := filepath.Join(entryDir, "README.md")
-		readmeContent := generateReadme(name, server)
-		if err := os.WriteFile(readmePath, []byte(readmeContent), 0600); err != nil {
-			// Non-fatal error
-			if verbose {
-				log.Printf("Warning: Failed to write README for %s: %v", name, err)
-			}
+	if readme.Needed(server) {
+		job := &readmeJob{name: name, server: server, links: links, path: filepath.Join(entryDir, "README.md")}
+		return outcome, job, nil
+	}
+
+	return outcome, nil, nil
+}
+
+// mergeEntry merges the imported YAML into an existing spec file with mergeSpec,
+// rewriting it only if an imported field changed
+func mergeEntry(specPath string, yamlData []byte, dryRun bool) (importOutcome, error) {
+	existing, err := types.ReadSpecFile(specPath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read spec.yaml: %w", err)
+	}
+
+	merged, changed, err := mergeSpec(existing, yamlData)
+	if err != nil {
+		return 0, err
+	}
+	if !changed {
+		return outcomeSkipped, nil
+	}
+
+	if !dryRun {
+		if err := os.WriteFile(specPath, merged, 0600); err != nil {
+			return 0, fmt.Errorf("failed to write spec.yaml: %w", err)
 		}
 	}
+	return outcomeUpdated, nil
+}
 
-	return nil
+// specOutcome compares the YAML that would be imported with an existing spec file.
+// The generated header is ignored since it contains the import timestamp.
+func specOutcome(specPath string, yamlData []byte) importOutcome {
+	existing, err := os.ReadFile(specPath)
+	if err != nil {
+		return outcomeCreated
+	}
+
+	body := string(existing)
+	if _, after, found := strings.Cut(body, specHeaderEnd); found {
+		body = after
+	}
+	if body == string(yamlData) {
+		return outcomeSkipped
+	}
+	return outcomeUpdated
 }
 
 func sanitizeName(name string) string {
@@ -268,168 +444,3 @@ func sanitizeName(name string) string {
 
 	return finalName
 }
-
-func shouldCreateReadme(server *toolhiveRegistry.ImageMetadata) bool {
-	// Create README for entries with substantial documentation needs
-	return len(server.Tools) > 10 || len(server.EnvVars) > 5 || len(server.Tags) > 10
-}
-
-func generateReadme(name string, server *toolhiveRegistry.ImageMetadata) string {
-	var readme strings.Builder
-
-	addReadmeHeader(&readme, name, server.Description)
-	addBasicInformation(&readme, server)
-	addToolsSection(&readme, server.Tools)
-	addEnvironmentVariablesSection(&readme, server.EnvVars)
-	addTagsSection(&readme, server.Tags)
-	addMetadataSection(&readme, server.Metadata)
-
-	return readme.String()
-}
-
-func addReadmeHeader(readme *strings.Builder, name, description string) {
-	fmt.Fprintf(readme, "# %s\n\n", name)
-	if description != "" {
-		fmt.Fprintf(readme, "%s\n\n", description)
-	}
-}
-
-func addBasicInformation(readme *strings.Builder, server *toolhiveRegistry.ImageMetadata) {
-	readme.WriteString("## Basic Information\n\n")
-
-	if server.Image != "" {
-		fmt.Fprintf(readme, "- **Image:** `%s`\n", server.Image)
-	}
-	if server.RepositoryURL != "" {
-		fmt.Fprintf(readme, "- **Repository:** [%s](%s)\n", server.RepositoryURL, server.RepositoryURL)
-	}
-	if server.Tier != "" {
-		fmt.Fprintf(readme, "- **Tier:** %s\n", server.Tier)
-	}
-	if server.Status != "" {
-		fmt.Fprintf(readme, "- **Status:** %s\n", server.Status)
-	}
-	if server.Transport != "" {
-		fmt.Fprintf(readme, "- **Transport:** %s\n", server.Transport)
-	}
-}
-
-func addToolsSection(readme *strings.Builder, tools []string) {
-	if len(tools) == 0 {
-		return
-	}
-
-	readme.WriteString("\n## Available Tools\n\n")
-	fmt.Fprintf(readme, "This server provides %d tools:\n\n", len(tools))
-
-	if len(tools) > 10 {
-		addToolsInColumns(readme, tools)
-	} else {
-		addToolsList(readme, tools)
-	}
-}
-
-func addToolsInColumns(readme *strings.Builder, tools []string) {
-	for i := 0; i < len(tools); i += 3 {
-		for j := 0; j < 3 && i+j < len(tools); j++ {
-			fmt.Fprintf(readme, "- `%s`", tools[i+j])
-			if j < 2 && i+j+1 < len(tools) {
-				readme.WriteString(" | ")
-			}
-		}
-		readme.WriteString("\n")
-	}
-}
-
-func addToolsList(readme *strings.Builder, tools []string) {
-	for _, tool := range tools {
-		fmt.Fprintf(readme, "- `%s`\n", tool)
-	}
-}
-
-func addEnvironmentVariablesSection(readme *strings.Builder, envVars []*toolhiveRegistry.EnvVar) {
-	if len(envVars) == 0 {
-		return
-	}
-
-	readme.WriteString("\n## Environment Variables\n\n")
-
-	required, optional := separateEnvVars(envVars)
-	addRequiredEnvVars(readme, required)
-	addOptionalEnvVars(readme, optional)
-}
-
-func separateEnvVars(envVars []*toolhiveRegistry.EnvVar) ([]*toolhiveRegistry.EnvVar, []*toolhiveRegistry.EnvVar) {
-	var required, optional []*toolhiveRegistry.EnvVar
-	for _, env := range envVars {
-		if env.Required {
-			required = append(required, env)
-		} else {
-			optional = append(optional, env)
-		}
-	}
-	return required, optional
-}
-
-func addRequiredEnvVars(readme *strings.Builder, required []*toolhiveRegistry.EnvVar) {
-	if len(required) == 0 {
-		return
-	}
-
-	readme.WriteString("### Required\n\n")
-	for _, env := range required {
-		secret := getSecretIndicator(env.Secret)
-		fmt.Fprintf(readme, "- **%s**%s: %s\n", env.Name, secret, env.Description)
-	}
-}
-
-func addOptionalEnvVars(readme *strings.Builder, optional []*toolhiveRegistry.EnvVar) {
-	if len(optional) == 0 {
-		return
-	}
-
-	readme.WriteString("\n### Optional\n\n")
-	for _, env := range optional {
-		secret := getSecretIndicator(env.Secret)
-		fmt.Fprintf(readme, "- **%s**%s: %s\n", env.Name, secret, env.Description)
-		if env.Default != "" {
-			fmt.Fprintf(readme, "  - Default: `%s`\n", env.Default)
-		}
-	}
-}
-
-func getSecretIndicator(isSecret bool) string {
-	if isSecret {
-		return " 🔒"
-	}
-	return ""
-}
-
-func addTagsSection(readme *strings.Builder, tags []string) {
-	if len(tags) == 0 {
-		return
-	}
-
-	readme.WriteString("\n## Tags\n\n")
-	for _, tag := range tags {
-		fmt.Fprintf(readme, "`%s` ", tag)
-	}
-	readme.WriteString("\n")
-}
-
-func addMetadataSection(readme *strings.Builder, metadata *toolhiveRegistry.Metadata) {
-	if metadata == nil {
-		return
-	}
-
-	readme.WriteString("\n## Statistics\n\n")
-	if metadata.Stars > 0 {
-		fmt.Fprintf(readme, "- ⭐ Stars: %d\n", metadata.Stars)
-	}
-	if metadata.Pulls > 0 {
-		fmt.Fprintf(readme, "- 📦 Pulls: %d\n", metadata.Pulls)
-	}
-	if metadata.LastUpdated != "" {
-		fmt.Fprintf(readme, "- 🕐 Last Updated: %s\n", metadata.LastUpdated)
-	}
-}
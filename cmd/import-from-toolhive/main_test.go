@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseServerLinks(t *testing.T) {
+	t.Parallel()
+
+	data := []byte(`{
+  "servers": {
+    "linked": {"image": "test/image:latest", "homepage": "https://example.com"},
+    "plain": {"image": "test/plain:latest"}
+  }
+}`)
+
+	links, err := parseServerLinks(data)
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com", links["linked"].Homepage)
+	assert.Empty(t, links["plain"].Homepage)
+}
+
+func newTestRegistry(description string) *toolhiveRegistry.Registry {
+	return &toolhiveRegistry.Registry{
+		Servers: map[string]*toolhiveRegistry.ImageMetadata{
+			"alpha": {
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: description,
+					Transport:   "stdio",
+					Tools:       []string{"alpha_tool"},
+				},
+				Image: "example/alpha:1.0.0",
+			},
+			"beta": {
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Beta server",
+					Transport:   "stdio",
+					Tools:       []string{"beta_tool"},
+				},
+				Image: "example/beta:1.0.0",
+			},
+		},
+	}
+}
+
+// TestProcessRegistryEntries_SummaryOnly isn't parallel because the import options are package globals
+//
+//nolint:paralleltest
+func TestProcessRegistryEntries_SummaryOnly(t *testing.T) {
+	oldOutputDir, oldSummaryOnly, oldVerbose, oldDryRun := outputDir, summaryOnly, verbose, dryRun
+	t.Cleanup(func() {
+		outputDir, summaryOnly, verbose, dryRun = oldOutputDir, oldSummaryOnly, oldVerbose, oldDryRun
+	})
+
+	outputDir = t.TempDir()
+	summaryOnly = true
+	verbose = false
+	dryRun = false
+
+	importAll := func(registry *toolhiveRegistry.Registry) string {
+		var out bytes.Buffer
+		stats := processRegistryEntries(&out, registry, nil)
+		printImportSummary(&out, stats, len(registry.Servers))
+		return out.String()
+	}
+
+	// First import creates every entry
+	output := importAll(newTestRegistry("Alpha server"))
+	assert.Equal(t,
+		"Imported 2/2 entries to "+outputDir+" (created: 2, updated: 0, skipped: 0, failed: 0)\n", output)
+	assert.FileExists(t, filepath.Join(outputDir, "alpha", "spec.yaml"))
+	assert.FileExists(t, filepath.Join(outputDir, "beta", "spec.yaml"))
+
+	// Re-importing updates changed entries and skips unchanged ones
+	betaBefore, err := os.ReadFile(filepath.Join(outputDir, "beta", "spec.yaml"))
+	require.NoError(t, err)
+
+	output = importAll(newTestRegistry("Alpha server, now improved"))
+	assert.Equal(t,
+		"Imported 2/2 entries to "+outputDir+" (created: 0, updated: 1, skipped: 1, failed: 0)\n", output)
+
+	betaAfter, err := os.ReadFile(filepath.Join(outputDir, "beta", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, string(betaBefore), string(betaAfter), "skipped entries must not be rewritten")
+
+	// Dry run reports the same counts without writing
+	dryRun = true
+	output = importAll(newTestRegistry("Alpha server, improved again"))
+	assert.Equal(t, "Would import 2/2 entries (created: 0, updated: 1, skipped: 1, failed: 0)\n", output)
+	assert.NotContains(t, output, "->")
+
+	// Verbose still shows per-entry lines
+	verbose = true
+	output = importAll(newTestRegistry("Alpha server, improved again"))
+	assert.Contains(t, output, "  alpha -> "+filepath.Join(outputDir, "alpha", "spec.yaml")+"\n")
+	assert.Contains(t, output, "  beta -> "+filepath.Join(outputDir, "beta", "spec.yaml")+"\n")
+}
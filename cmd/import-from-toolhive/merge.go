@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mergeSpec updates an existing spec with the fields of an imported one. Fields the import
+// doesn't set, such as local examples or license, are kept, as are comments and fields whose
+// value didn't change. It returns the merged spec and whether any field changed.
+func mergeSpec(existing, imported []byte) ([]byte, bool, error) {
+	var doc, importedDoc yaml.Node
+	if err := yaml.Unmarshal(existing, &doc); err != nil {
+		return nil, false, fmt.Errorf("failed to parse existing spec: %w", err)
+	}
+	if err := yaml.Unmarshal(imported, &importedDoc); err != nil {
+		return nil, false, fmt.Errorf("failed to parse imported spec: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("existing spec is not a mapping")
+	}
+	if len(importedDoc.Content) == 0 || importedDoc.Content[0].Kind != yaml.MappingNode {
+		return nil, false, fmt.Errorf("imported spec is not a mapping")
+	}
+
+	mapping := doc.Content[0]
+	importedMapping := importedDoc.Content[0]
+	changed := false
+	for i := 0; i+1 < len(importedMapping.Content); i += 2 {
+		key, value := importedMapping.Content[i], importedMapping.Content[i+1]
+
+		index := mappingIndex(mapping, key.Value)
+		if index < 0 {
+			mapping.Content = append(mapping.Content, key, value)
+			changed = true
+			continue
+		}
+
+		old := mapping.Content[index+1]
+		same, err := sameValue(old, value)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to compare %s: %w", key.Value, err)
+		}
+		if same {
+			continue
+		}
+		value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		mapping.Content[index+1] = value
+		changed = true
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, false, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, false, fmt.Errorf("failed to close YAML encoder: %w", err)
+	}
+
+	return buf.Bytes(), changed, nil
+}
+
+// mappingIndex returns the index of key in the content of a mapping node, or -1 if it isn't set
+func mappingIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameValue reports whether two nodes decode to the same value, regardless of style and comments
+func sameValue(a, b *yaml.Node) (bool, error) {
+	var aValue, bValue any
+	if err := a.Decode(&aValue); err != nil {
+		return false, err
+	}
+	if err := b.Decode(&bValue); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(aValue, bValue), nil
+}
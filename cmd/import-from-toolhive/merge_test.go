@@ -0,0 +1,109 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportEntry_Merge(t *testing.T) {
+	t.Parallel()
+
+	outDir := t.TempDir()
+	specPath := filepath.Join(outDir, "alpha", "spec.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(specPath), 0750))
+	curated := `# alpha MCP Server Registry Entry
+# Curated by hand
+# ---
+name: alpha
+description: Alpha server
+tier: Community
+status: Active
+transport: stdio
+tools:
+  - alpha_tool # The original tool
+image: example/alpha:1.0.0
+# Local additions
+license: MIT
+examples:
+  - Ask alpha a question
+`
+	require.NoError(t, os.WriteFile(specPath, []byte(curated), 0600))
+
+	server := func() *toolhiveRegistry.ImageMetadata {
+		return &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Name:        "alpha",
+				Description: "Alpha server",
+				Tier:        "Community",
+				Status:      "Active",
+				Transport:   "stdio",
+				Tools:       []string{"alpha_tool"},
+				Tags:        []string{"alpha"},
+			},
+			Image: "example/alpha:2.0.0",
+		}
+	}
+
+	// A dry run reports the update without writing
+	outcome, readme, err := importEntry(io.Discard, "alpha", server(), serverLinks{}, outDir, true, true)
+	require.NoError(t, err)
+	assert.Equal(t, outcomeUpdated, outcome)
+	assert.Nil(t, readme)
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, curated, string(data))
+
+	outcome, readme, err = importEntry(io.Discard, "alpha", server(), serverLinks{}, outDir, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, outcomeUpdated, outcome)
+	assert.Nil(t, readme)
+
+	// Imported fields are updated, and local additions and comments survive
+	data, err = os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, `# alpha MCP Server Registry Entry
+# Curated by hand
+# ---
+name: alpha
+description: Alpha server
+tier: Community
+status: Active
+transport: stdio
+tools:
+  - alpha_tool # The original tool
+image: example/alpha:2.0.0
+# Local additions
+license: MIT
+examples:
+  - Ask alpha a question
+tags:
+  - alpha
+`, string(data))
+
+	// Merging the same import again changes nothing
+	outcome, _, err = importEntry(io.Discard, "alpha", server(), serverLinks{}, outDir, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, outcomeSkipped, outcome)
+
+	// New entries are created as without merging
+	outcome, _, err = importEntry(io.Discard, "beta", server(), serverLinks{}, outDir, false, true)
+	require.NoError(t, err)
+	assert.Equal(t, outcomeCreated, outcome)
+	assert.FileExists(t, filepath.Join(outDir, "beta", "spec.yaml"))
+}
+
+func TestMergeSpec_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := mergeSpec([]byte("- not\n- a mapping\n"), []byte("name: alpha\n"))
+	assert.ErrorContains(t, err, "existing spec is not a mapping")
+
+	_, _, err = mergeSpec([]byte("name: alpha\n"), []byte("description: [unclosed\n"))
+	assert.ErrorContains(t, err, "failed to parse imported spec")
+}
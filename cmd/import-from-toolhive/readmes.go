@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/readme"
+)
+
+// readmeJob is a README to generate for an imported entry
+type readmeJob struct {
+	name   string
+	server *toolhiveRegistry.ImageMetadata
+	links  serverLinks
+	path   string
+}
+
+// write generates the README and writes it to its path
+func (j readmeJob) write() error {
+	content := readme.Generate(j.name, j.server, readme.Links(j.links))
+	if err := os.WriteFile(j.path, []byte(content), 0600); err != nil {
+		return fmt.Errorf("failed to write README: %w", err)
+	}
+	return nil
+}
+
+// writeReadmes generates and writes READMEs with a pool of workers. Each job writes
+// its own file and only reads its server, so jobs never touch the same data.
+// The returned errors are in the order of jobs, nil for the READMEs that were written.
+func writeReadmes(jobs []readmeJob, workers int) []error {
+	errs := make([]error, len(jobs))
+	workers = max(1, min(workers, len(jobs)))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = jobs[i].write()
+			}
+		}()
+	}
+
+	for i := range jobs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
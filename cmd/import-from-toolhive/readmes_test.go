@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/readme"
+)
+
+// newReadmeJobs returns count jobs writing to dir, one per server with enough tools to need a README
+func newReadmeJobs(t testing.TB, dir string, count int) []readmeJob {
+	t.Helper()
+
+	jobs := make([]readmeJob, 0, count)
+	for i := range count {
+		name := fmt.Sprintf("server-%03d", i)
+		tools := make([]string, 20)
+		for j := range tools {
+			tools[j] = fmt.Sprintf("tool_%d", j)
+		}
+
+		entryDir := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(entryDir, 0750))
+		jobs = append(jobs, readmeJob{
+			name: name,
+			server: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Generated server " + name,
+					Transport:   "stdio",
+					Tools:       tools,
+				},
+				Image: "example/" + name + ":1.0.0",
+			},
+			links: serverLinks{Homepage: "https://example.com/" + name},
+			path:  filepath.Join(entryDir, "README.md"),
+		})
+	}
+	return jobs
+}
+
+func TestWriteReadmes(t *testing.T) {
+	t.Parallel()
+
+	for _, workers := range []int{0, 1, 4, 100} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			jobs := newReadmeJobs(t, dir, 50)
+			// A job whose directory doesn't exist fails without affecting the others
+			jobs[10].path = filepath.Join(dir, "missing", "README.md")
+
+			errs := writeReadmes(jobs, workers)
+			require.Len(t, errs, len(jobs))
+
+			for i, job := range jobs {
+				if i == 10 {
+					assert.Error(t, errs[i])
+					continue
+				}
+				require.NoError(t, errs[i])
+				data, err := os.ReadFile(job.path)
+				require.NoError(t, err)
+				assert.Equal(t, readme.Generate(job.name, job.server, readme.Links(job.links)), string(data))
+			}
+		})
+	}
+}
+
+// TestProcessRegistryEntries_Readmes isn't parallel because the import options are package globals
+//
+//nolint:paralleltest
+func TestProcessRegistryEntries_Readmes(t *testing.T) {
+	oldOutputDir, oldSummaryOnly, oldVerbose, oldDryRun, oldWorkers := outputDir, summaryOnly, verbose, dryRun, readmeWorkers
+	t.Cleanup(func() {
+		outputDir, summaryOnly, verbose, dryRun, readmeWorkers = oldOutputDir, oldSummaryOnly, oldVerbose, oldDryRun, oldWorkers
+	})
+
+	outputDir = t.TempDir()
+	summaryOnly, verbose, dryRun = true, false, false
+	readmeWorkers = 8
+
+	// Every third server has enough tools to need a README
+	registry := &toolhiveRegistry.Registry{Servers: map[string]*toolhiveRegistry.ImageMetadata{}}
+	for i, job := range newReadmeJobs(t, t.TempDir(), 30) {
+		if i%3 != 0 {
+			job.server.Tools = job.server.Tools[:1]
+		}
+		registry.Servers[job.name] = job.server
+	}
+
+	var out bytes.Buffer
+	stats := processRegistryEntries(&out, registry, nil)
+	assert.Equal(t, 30, stats.created)
+
+	for i := range 30 {
+		name := fmt.Sprintf("server-%03d", i)
+		assert.FileExists(t, filepath.Join(outputDir, name, "spec.yaml"))
+		if i%3 == 0 {
+			data, err := os.ReadFile(filepath.Join(outputDir, name, "README.md"))
+			require.NoError(t, err)
+			assert.Equal(t, readme.Generate(name, registry.Servers[name], readme.Links{}), string(data))
+		} else {
+			assert.NoFileExists(t, filepath.Join(outputDir, name, "README.md"))
+		}
+	}
+}
+
+func TestWriteReadmesNoJobs(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, writeReadmes(nil, 4))
+}
+
+func BenchmarkWriteReadmes(b *testing.B) {
+	for _, workers := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			jobs := newReadmeJobs(b, b.TempDir(), 500)
+			for b.Loop() {
+				writeReadmes(jobs, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/progress"
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// entryNamePattern matches entry names: lowercase letters, numbers and single hyphens
+var entryNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var (
+	addImage       string
+	addRemoteURL   string
+	addTransport   string
+	addDescription string
+	addTools       []string
+	addForce       bool
+)
+
+var addCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Scaffold a new registry entry",
+	Long: `Scaffold registry/<name>/spec.yaml for a new container or remote server,
+with the tier set to Community and the status to Active.
+
+Give --image for a container server or --remote-url for a remote server. When
+run in a terminal, missing values are prompted for; otherwise --description and
+--tools are required too. The transport defaults to stdio for container servers
+and streamable-http for remote servers.
+
+The entry is validated before it's written, and an existing entry is only
+overwritten with --force.`,
+	Example: `  # Scaffold a container server
+  registry-builder add my-server --image ghcr.io/org/my-server:1.0.0 \
+    --description "Manages widgets" --tools list_widgets,create_widget
+
+  # Scaffold a remote server
+  registry-builder add my-remote --remote-url https://api.example.com/mcp \
+    --description "Hosted widget server" --tools list_widgets
+
+  # Prompt for everything
+  registry-builder add my-server`,
+	Args: cobra.ExactArgs(1),
+	RunE: runAdd,
+}
+
+func init() {
+	addCmd.Flags().StringVar(&addImage, "image", "", "Image reference of a container server")
+	addCmd.Flags().StringVar(&addRemoteURL, "remote-url", "", "URL of a remote server")
+	addCmd.Flags().StringVar(&addTransport, "transport", "",
+		"Transport (stdio, sse, streamable-http; defaults to stdio for images and streamable-http for remotes)")
+	addCmd.Flags().StringVar(&addDescription, "description", "", "One-line description of the server")
+	addCmd.Flags().StringSliceVar(&addTools, "tools", nil, "Tools the server provides")
+	addCmd.Flags().BoolVar(&addForce, "force", false, "Overwrite an existing entry")
+}
+
+// scaffold holds the values a new spec file is generated from
+type scaffold struct {
+	image       string
+	remoteURL   string
+	transport   string
+	description string
+	tools       []string
+}
+
+func runAdd(cmd *cobra.Command, args []string) error {
+	name := args[0]
+	if !entryNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid entry name %q: use lowercase letters, numbers and hyphens", name)
+	}
+
+	spec := scaffold{
+		image:       addImage,
+		remoteURL:   addRemoteURL,
+		transport:   addTransport,
+		description: addDescription,
+		tools:       addTools,
+	}
+	if progress.IsTerminal(os.Stdin) {
+		if err := promptScaffold(cmd.InOrStdin(), cmd.OutOrStdout(), &spec); err != nil {
+			return err
+		}
+	}
+
+	specPath, err := writeScaffold(registryPath, name, spec, addForce)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Created %s\n", specPath)
+	fmt.Println("  Add repository_url, tags and any env_vars, then run 'registry-builder validate'")
+	return nil
+}
+
+// promptScaffold asks for the values that weren't given as flags
+func promptScaffold(in io.Reader, out io.Writer, spec *scaffold) error {
+	scanner := bufio.NewScanner(in)
+	ask := func(prompt, fallback string) (string, error) {
+		if fallback != "" {
+			fmt.Fprintf(out, "%s [%s]: ", prompt, fallback)
+		} else {
+			fmt.Fprintf(out, "%s: ", prompt)
+		}
+		if !scanner.Scan() {
+			if err := scanner.Err(); err != nil {
+				return "", fmt.Errorf("failed to read input: %w", err)
+			}
+			return "", errors.New("input ended before all values were given")
+		}
+		if answer := strings.TrimSpace(scanner.Text()); answer != "" {
+			return answer, nil
+		}
+		return fallback, nil
+	}
+
+	var err error
+	if spec.image == "" && spec.remoteURL == "" {
+		if spec.image, err = ask("Image (leave empty for a remote server)", ""); err != nil {
+			return err
+		}
+		if spec.image == "" {
+			if spec.remoteURL, err = ask("Remote URL", ""); err != nil {
+				return err
+			}
+		}
+	}
+	if spec.description == "" {
+		if spec.description, err = ask("Description", ""); err != nil {
+			return err
+		}
+	}
+	if spec.transport == "" {
+		if spec.transport, err = ask("Transport", spec.defaultTransport()); err != nil {
+			return err
+		}
+	}
+	if len(spec.tools) == 0 {
+		tools, err := ask("Tools (comma-separated)", "")
+		if err != nil {
+			return err
+		}
+		for _, tool := range strings.Split(tools, ",") {
+			if tool = strings.TrimSpace(tool); tool != "" {
+				spec.tools = append(spec.tools, tool)
+			}
+		}
+	}
+	return nil
+}
+
+// defaultTransport returns the transport used when none is given
+func (s *scaffold) defaultTransport() string {
+	if s.remoteURL != "" {
+		return "streamable-http"
+	}
+	return "stdio"
+}
+
+// writeScaffold validates the spec and writes it to the entry's directory, returning the
+// spec file's path. An existing entry with the name, including one in a category
+// directory, is only overwritten if force is set.
+func writeScaffold(registryDir, name string, spec scaffold, force bool) (string, error) {
+	data, err := renderScaffold(spec)
+	if err != nil {
+		return "", err
+	}
+
+	var entry types.RegistryEntry
+	if err := yaml.Unmarshal(data, &entry); err != nil {
+		return "", fmt.Errorf("failed to parse generated spec: %w", err)
+	}
+	validator := registry.NewSchemaValidator()
+	validator.SetAllowLatest(allowLatest)
+	validator.SetAllowInsecureURL(allowInsecureURL)
+	if err := validator.ValidateComplete(&entry, name); err != nil {
+		return "", fmt.Errorf("generated spec is invalid: %w", err)
+	}
+
+	// A registry that can't be listed yet, e.g. because it doesn't exist, has no entry to clash with
+	specPath := filepath.Join(registryDir, name, "spec.yaml")
+	if specs, err := entrySpecPaths(registryDir); err == nil {
+		if existing, ok := specs[name]; ok {
+			specPath = existing
+		}
+	}
+	if _, err := os.Stat(specPath); err == nil && !force {
+		return "", fmt.Errorf("entry %q already exists at %s (use --force to overwrite it)", name, specPath)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(specPath), 0750); err != nil {
+		return "", fmt.Errorf("failed to create entry directory: %w", err)
+	}
+	if err := os.WriteFile(specPath, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to write spec file: %w", err)
+	}
+	return specPath, nil
+}
+
+// renderScaffold generates a commented spec file in the layout of existing entries
+func renderScaffold(spec scaffold) ([]byte, error) {
+	switch {
+	case spec.image != "" && spec.remoteURL != "":
+		return nil, errors.New("--image and --remote-url can't be combined")
+	case spec.image == "" && spec.remoteURL == "":
+		return nil, errors.New("either --image or --remote-url is required")
+	case spec.description == "":
+		return nil, errors.New("--description is required")
+	case len(spec.tools) == 0:
+		return nil, errors.New("at least one tool is required (--tools)")
+	}
+
+	transport := spec.transport
+	if transport == "" {
+		transport = spec.defaultTransport()
+	}
+	if normalized, ok := types.NormalizeTransport(transport); ok {
+		transport = normalized
+	}
+
+	var b strings.Builder
+	if spec.image != "" {
+		b.WriteString("# Docker/OCI image reference (REQUIRED)\n")
+		fmt.Fprintf(&b, "image: %s\n", yamlScalar(spec.image))
+	} else {
+		b.WriteString("# Remote server endpoint (REQUIRED)\n")
+		fmt.Fprintf(&b, "url: %s\n", yamlScalar(spec.remoteURL))
+	}
+	b.WriteString("# One-line description (REQUIRED)\n")
+	fmt.Fprintf(&b, "description: %s\n", yamlScalar(spec.description))
+	b.WriteString("# Communication protocol (REQUIRED)\n")
+	fmt.Fprintf(&b, "transport: %s\n", yamlScalar(transport))
+	b.WriteString("# Source code repository (HIGHLY RECOMMENDED)\n")
+	b.WriteString("# repository_url: https://github.com/organization/repository\n")
+	b.WriteString("# Classification tier\n")
+	b.WriteString("tier: Community\n")
+	b.WriteString("# Development status\n")
+	b.WriteString("status: Active\n")
+	b.WriteString("# Categorization tags (RECOMMENDED)\n")
+	b.WriteString("# tags:\n#   - example\n")
+	b.WriteString("# List of tools provided (HIGHLY RECOMMENDED)\n")
+	b.WriteString("tools:\n")
+	for _, tool := range spec.tools {
+		fmt.Fprintf(&b, "  - %s\n", yamlScalar(tool))
+	}
+	return []byte(b.String()), nil
+}
+
+// yamlScalar formats a string as a YAML scalar, quoting it only if needed
+func yamlScalar(value string) string {
+	out, err := yaml.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%q", value)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
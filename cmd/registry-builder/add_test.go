@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestWriteScaffold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		spec          scaffold
+		wantRemote    bool
+		wantTransport string
+	}{
+		{
+			name:          "image server",
+			spec:          scaffold{image: "ghcr.io/org/widgets:1.0.0", description: "Manages widgets", tools: []string{"list", "create"}},
+			wantTransport: "stdio",
+		},
+		{
+			name:          "remote server",
+			spec:          scaffold{remoteURL: "https://api.example.com/mcp", description: "Hosted: widgets", tools: []string{"list"}},
+			wantRemote:    true,
+			wantTransport: "streamable-http",
+		},
+		{
+			name:          "transport aliases are normalized",
+			spec:          scaffold{remoteURL: "https://api.example.com/sse", transport: "SSE", description: "Event stream server", tools: []string{"list"}},
+			wantRemote:    true,
+			wantTransport: "sse",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registryDir := t.TempDir()
+			specPath, err := writeScaffold(registryDir, "widgets", tt.spec, false)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(registryDir, "widgets", "spec.yaml"), specPath)
+
+			data, err := os.ReadFile(specPath)
+			require.NoError(t, err)
+			var entry types.RegistryEntry
+			require.NoError(t, yaml.Unmarshal(data, &entry))
+			require.NoError(t, registry.NewSchemaValidator().ValidateComplete(&entry, "widgets"))
+
+			assert.Equal(t, tt.wantRemote, entry.IsRemote())
+			assert.Equal(t, tt.wantTransport, entry.GetTransport())
+			assert.Equal(t, tt.spec.description, entry.GetDescription())
+			assert.Equal(t, tt.spec.tools, entry.GetTools())
+			assert.Equal(t, "Community", entry.GetTier())
+			assert.Equal(t, "Active", entry.GetStatus())
+
+			// The scaffold loads like any other entry
+			loader := registry.NewLoader(registryDir)
+			require.NoError(t, loader.LoadAll())
+			assert.Contains(t, loader.GetEntries(), "widgets")
+		})
+	}
+}
+
+func TestWriteScaffold_Errors(t *testing.T) {
+	t.Parallel()
+
+	valid := scaffold{image: "ghcr.io/org/widgets:1.0.0", description: "Manages widgets", tools: []string{"list"}}
+
+	tests := []struct {
+		name    string
+		spec    scaffold
+		wantErr string
+	}{
+		{
+			name:    "image and remote URL",
+			spec:    scaffold{image: valid.image, remoteURL: "https://api.example.com/mcp", description: "Both", tools: valid.tools},
+			wantErr: "can't be combined",
+		},
+		{name: "neither image nor remote URL", spec: scaffold{description: "None", tools: valid.tools}, wantErr: "either --image or --remote-url"},
+		{name: "no description", spec: scaffold{image: valid.image, tools: valid.tools}, wantErr: "--description is required"},
+		{name: "no tools", spec: scaffold{image: valid.image, description: "No tools"}, wantErr: "at least one tool"},
+		{
+			name:    "remote over stdio",
+			spec:    scaffold{remoteURL: "https://api.example.com/mcp", transport: "stdio", description: "Remote", tools: valid.tools},
+			wantErr: "generated spec is invalid",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registryDir := t.TempDir()
+			_, err := writeScaffold(registryDir, "widgets", tt.spec, false)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+			assert.NoDirExists(t, filepath.Join(registryDir, "widgets"))
+		})
+	}
+}
+
+func TestWriteScaffold_Overwrite(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	spec := scaffold{image: "ghcr.io/org/widgets:1.0.0", description: "Manages widgets", tools: []string{"list"}}
+	_, err := writeScaffold(registryDir, "widgets", spec, false)
+	require.NoError(t, err)
+
+	spec.description = "Manages widgets, updated"
+	_, err = writeScaffold(registryDir, "widgets", spec, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already exists")
+
+	specPath, err := writeScaffold(registryDir, "widgets", spec, true)
+	require.NoError(t, err)
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "Manages widgets, updated")
+
+	// Entries in category directories count too
+	nested := filepath.Join(registryDir, "databases", "postgres", "spec.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nested), 0755))
+	require.NoError(t, os.WriteFile(nested, []byte("image: test/postgres:1.0\n"), 0644))
+	_, err = writeScaffold(registryDir, "postgres", spec, false)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), nested)
+
+	specPath, err = writeScaffold(registryDir, "postgres", spec, true)
+	require.NoError(t, err)
+	assert.Equal(t, nested, specPath)
+}
+
+func TestPromptScaffold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		given   scaffold
+		input   string
+		want    scaffold
+		wantErr string
+	}{
+		{
+			name:  "image server",
+			input: "ghcr.io/org/widgets:1.0.0\nManages widgets\n\nlist, create,\n",
+			want: scaffold{
+				image: "ghcr.io/org/widgets:1.0.0", transport: "stdio", description: "Manages widgets", tools: []string{"list", "create"},
+			},
+		},
+		{
+			name:  "remote server",
+			input: "\nhttps://api.example.com/mcp\nHosted widgets\nsse\nlist\n",
+			want: scaffold{
+				remoteURL: "https://api.example.com/mcp", transport: "sse", description: "Hosted widgets", tools: []string{"list"},
+			},
+		},
+		{
+			name:  "flags aren't asked for",
+			given: scaffold{image: "ghcr.io/org/widgets:1.0.0", description: "Manages widgets", tools: []string{"list"}},
+			input: "streamable-http\n",
+			want: scaffold{
+				image: "ghcr.io/org/widgets:1.0.0", transport: "streamable-http", description: "Manages widgets", tools: []string{"list"},
+			},
+		},
+		{name: "input ends early", input: "ghcr.io/org/widgets:1.0.0\n", wantErr: "input ended"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			spec := tt.given
+			var out bytes.Buffer
+			err := promptScaffold(strings.NewReader(tt.input), &out, &spec)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, spec)
+		})
+	}
+}
+
+func TestEntryNamePattern(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"github", "aws-pricing", "k8s"} {
+		assert.True(t, entryNamePattern.MatchString(name), name)
+	}
+	for _, name := range []string{"", "GitHub", "my_server", "-github", "github-", "a--b", "../etc"} {
+		assert.False(t, entryNamePattern.MatchString(name), name)
+	}
+}
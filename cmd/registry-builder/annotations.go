@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/stacklok/toolhive-registry/pkg/annotations"
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var (
+	annotationsFormat string
+
+	// emitter writes CI annotations for warnings and errors when --annotations is set
+	emitter *annotations.Emitter
+
+	// collectedWarnings collects the warnings about entries for validate --format json, when set
+	collectedWarnings *[]registry.Diagnostic
+)
+
+// annotatedError marks an error whose findings were already annotated individually
+type annotatedError struct {
+	error
+}
+
+// Unwrap returns the underlying error
+func (e annotatedError) Unwrap() error {
+	return e.error
+}
+
+// setupAnnotations creates the emitter selected by --annotations, writing to out
+func setupAnnotations(out io.Writer) error {
+	var err error
+	emitter, err = annotations.NewEmitter(out, annotationsFormat)
+	return err
+}
+
+// warnEntry logs a warning about an entry and annotates the spec file field it concerns
+func warnEntry(loader *registry.Loader, name, field string, warning fmt.Stringer) {
+	log.Printf("Warning: %s", warning)
+	if collectedWarnings != nil {
+		*collectedWarnings = append(*collectedWarnings, registry.Diagnostic{
+			Entry: name, Field: field, Message: warning.String(), Severity: registry.SeverityWarning,
+		})
+	}
+	annotateEntry(annotations.LevelWarning, loader, name, field, warning.String())
+}
+
+// annotateEntry annotates a finding about an entry, pointing at the line of field in its spec
+// file when the field is set
+func annotateEntry(level annotations.Level, loader *registry.Loader, name, field, message string) {
+	if !emitter.Enabled() {
+		return
+	}
+
+	annotation := annotations.Annotation{Level: level, File: loader.SourcePath(name), Message: message}
+	if annotation.File != "" && field != "" {
+		annotation.Line = registry.SpecFieldLine(annotation.File, field)
+	}
+	emitter.Emit(annotation)
+}
+
+// annotateError annotates the error a command failed with, unless its findings were
+// already annotated. Errors loading a spec file point at the file and, when known, the line.
+func annotateError(err error) {
+	var annotated annotatedError
+	if !emitter.Enabled() || errors.As(err, &annotated) {
+		return
+	}
+
+	annotation := annotations.Annotation{Level: annotations.LevelError, Message: err.Error()}
+	var entryErr *registry.EntryError
+	if errors.As(err, &entryErr) {
+		annotation.File = entryErr.Path
+		annotation.Line = entryErr.Line()
+	}
+	emitter.Emit(annotation)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/annotations"
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+//nolint:paralleltest // sets the package-level annotation emitter
+func TestAnnotations(t *testing.T) {
+	var buf bytes.Buffer
+	var err error
+	emitter, err = annotations.NewEmitter(&buf, annotations.FormatGitHub)
+	require.NoError(t, err)
+	t.Cleanup(func() { emitter = nil })
+
+	registryDir := t.TempDir()
+	specPath := writeRawSpec(t, registryDir, "mismatch", `description: Mismatched server
+transport: stdio
+image: ghcr.io/someone-else/mismatch:1.0.0
+repository_url: https://github.com/example/mismatch
+tier: Community
+status: Active
+tools:
+  - test_tool
+`)
+
+	// Warnings point at the field they concern
+	require.NoError(t, checkImageOwners(loadTestRegistry(t, registryDir)))
+	assert.Regexp(t, `^::warning file=`+specPath+`,line=3::mismatch: `, buf.String())
+
+	// Load errors point at the spec file and the line of YAML syntax errors
+	buf.Reset()
+	brokenPath := writeRawSpec(t, registryDir, "broken", "description: Broken\ntools: [unclosed\n")
+
+	err = registry.NewLoader(registryDir).LoadAll()
+	require.Error(t, err)
+	annotateError(err)
+	assert.Regexp(t, `^::error file=`+brokenPath+`,line=\d+::failed to load `, buf.String())
+
+	// Errors whose findings were annotated individually aren't annotated again
+	buf.Reset()
+	annotateError(annotatedError{assert.AnError})
+	assert.Empty(t, buf.String())
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var (
+	auditDupesFormat string
+	auditDupesFail   bool
+)
+
+var auditDupesCmd = &cobra.Command{
+	Use:   "audit-dupes",
+	Short: "Detect possible duplicate registry entries",
+	Long: `Detect registry entries that may be accidental duplicates of each other.
+
+Entries are flagged when they:
+  - use the same image (ignoring tag and digest)
+  - point at the same repository URL
+  - have nearly identical names
+
+Entries from the same monorepo share a repository URL, so findings are
+advisory. Use --fail to exit with an error when anything is found.`,
+	Example: `  # Show possible duplicates
+  registry-builder audit-dupes
+
+  # Emit findings as JSON for tooling and fail if any are found
+  registry-builder audit-dupes --format json --fail`,
+	RunE: runAuditDupes,
+}
+
+func init() {
+	auditDupesCmd.Flags().StringVar(&auditDupesFormat, "format", "text", "Output format (text, json)")
+	auditDupesCmd.Flags().BoolVar(&auditDupesFail, "fail", false, "Exit with an error if any possible duplicates are found")
+}
+
+func runAuditDupes(_ *cobra.Command, _ []string) error {
+	loader := newLoader(registryPath)
+	if err := loader.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load registry entries: %w", err)
+	}
+
+	findings := registry.FindDuplicates(loader.GetEntries())
+
+	switch auditDupesFormat {
+	case "json":
+		if findings == nil {
+			findings = []registry.DuplicateFinding{}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(findings); err != nil {
+			return fmt.Errorf("failed to encode findings: %w", err)
+		}
+	case "text":
+		if len(findings) == 0 {
+			fmt.Printf("✓ No possible duplicates found among %d entries\n", len(loader.GetEntries()))
+			return nil
+		}
+		fmt.Printf("Found %d possible duplicate(s):\n\n", len(findings))
+		for _, finding := range findings {
+			fmt.Printf("  %s\n", finding)
+		}
+	default:
+		return fmt.Errorf("unknown format: %s", auditDupesFormat)
+	}
+
+	if auditDupesFail && len(findings) > 0 {
+		return fmt.Errorf("found %d possible duplicate(s)", len(findings))
+	}
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+// writeRawSpec writes spec to registryDir/name/spec.yaml and returns its path
+func writeRawSpec(t *testing.T, registryDir, name, spec string) string {
+	t.Helper()
+	specPath := filepath.Join(registryDir, name, "spec.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(specPath), 0755))
+	require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+	return specPath
+}
+
+// writeTestSpec writes a valid image-based spec for name
+func writeTestSpec(t *testing.T, registryDir, name, description string) {
+	t.Helper()
+	writeRawSpec(t, registryDir, name, fmt.Sprintf(`image: test/%s:1.0.0
+description: %s
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - test_tool
+`, name, description))
+}
+
+func loadTestRegistry(t *testing.T, registryDir string) *registry.Loader {
+	t.Helper()
+	loader := registry.NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	return loader
+}
+
+func TestDryRunBuild(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	outDir := t.TempDir()
+
+	// Build the committed output from the original entries
+	writeTestSpec(t, registryDir, "kept", "Kept server")
+	writeTestSpec(t, registryDir, "changed", "Original description")
+	writeTestSpec(t, registryDir, "removed", "Removed server")
+	require.NoError(t, buildToolhiveFormat(loadTestRegistry(t, registryDir), outDir))
+
+	existingPath := filepath.Join(outDir, "registry.json")
+	existing, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+
+	// Change the entries and do a dry run
+	writeTestSpec(t, registryDir, "changed", "New description")
+	writeTestSpec(t, registryDir, "added", "Added server")
+	require.NoError(t, os.RemoveAll(filepath.Join(registryDir, "removed")))
+
+	proposedPath, diff, err := dryRunBuild(loadTestRegistry(t, registryDir), outDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(proposedPath)) })
+
+	// The existing output is untouched and the proposed output is elsewhere
+	after, err := os.ReadFile(existingPath)
+	require.NoError(t, err)
+	assert.Equal(t, existing, after)
+	assert.NotEqual(t, outDir, filepath.Dir(proposedPath))
+	assert.FileExists(t, proposedPath)
+
+	assert.Equal(t, []registry.ServerChange{
+		{Name: "added", Kind: registry.ChangeAdded},
+		{Name: "changed", Kind: registry.ChangeModified, Fields: []registry.FieldChange{
+			{Field: "description", Old: "Original description", New: "New description"},
+		}},
+		{Name: "removed", Kind: registry.ChangeRemoved},
+	}, diff.Servers)
+}
+
+func TestDryRunBuild_NoExistingOutput(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	outDir := filepath.Join(t.TempDir(), "build")
+	writeTestSpec(t, registryDir, "first", "First server")
+
+	proposedPath, diff, err := dryRunBuild(loadTestRegistry(t, registryDir), outDir)
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(filepath.Dir(proposedPath)) })
+
+	assert.NoDirExists(t, outDir)
+	assert.Equal(t, []registry.ServerChange{{Name: "first", Kind: registry.ChangeAdded}}, diff.Servers)
+}
+
+func TestBuildToolhiveFormat_Digest(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	outDir := t.TempDir()
+	writeTestSpec(t, registryDir, "first", "First server")
+	writeTestSpec(t, registryDir, "second", "Second server")
+
+	require.NoError(t, buildToolhiveFormat(loadTestRegistry(t, registryDir), outDir))
+
+	// The .sha256 file checks out the way sha256sum -c reads it: the hash of the bytes of
+	// the file it names, relative to its own directory
+	line, err := os.ReadFile(filepath.Join(outDir, "registry.json"+registry.DigestSuffix))
+	require.NoError(t, err)
+	require.True(t, strings.HasSuffix(string(line), "\n"))
+	hash, name, ok := strings.Cut(strings.TrimSuffix(string(line), "\n"), "  ")
+	require.True(t, ok, "line %q isn't in the format of sha256sum", line)
+	assert.Equal(t, "registry.json", name)
+
+	data, err := os.ReadFile(filepath.Join(outDir, name))
+	require.NoError(t, err)
+	sum := sha256.Sum256(data)
+	assert.Equal(t, hex.EncodeToString(sum[:]), hash)
+}
+
+func TestDetermineFormats(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"toolhive"}, determineFormats("toolhive"))
+	assert.Equal(t, []string{"mcp-registry"}, determineFormats("mcp-registry"))
+	assert.Equal(t, []string{"mcp-registry"}, determineFormats("mcp"))
+	assert.Equal(t, []string{"toolhive", "mcp-registry"}, determineFormats("all"))
+}
+
+func TestBuildMCPRegistryFormat(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	outDir := t.TempDir()
+	writeTestSpec(t, registryDir, "first", "First server")
+	writeTestSpec(t, registryDir, "second", "Second server")
+
+	require.NoError(t, buildMCPRegistryFormat(loadTestRegistry(t, registryDir), outDir))
+
+	data, err := os.ReadFile(filepath.Join(outDir, registry.UpstreamFile))
+	require.NoError(t, err)
+	var output struct {
+		Servers []struct {
+			Server struct {
+				Name        string `json:"name"`
+				Description string `json:"description"`
+			} `json:"server"`
+		} `json:"servers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &output))
+	require.Len(t, output.Servers, 2)
+	assert.Equal(t, "first", output.Servers[0].Server.Name)
+	assert.Equal(t, "Second server", output.Servers[1].Server.Description)
+	assert.NoFileExists(t, filepath.Join(outDir, "registry.json"))
+}
+
+func TestCheckRequiredMetadata(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "enriched", "Enriched server")
+	writeTestSpec(t, registryDir, "bare", "Bare server")
+	writeRawSpec(t, registryDir, "enriched", `image: test/enriched:1.0.0
+description: Enriched server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - test_tool
+metadata:
+  stars: 12
+  pulls: 340
+  last_updated: "2025-01-01T00:00:00Z"
+`)
+
+	// Only the entry without metadata is reported
+	err := checkRequiredMetadata(loadTestRegistry(t, registryDir))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 image entries have no stars/pulls metadata")
+	assert.Contains(t, err.Error(), "\n  bare")
+	assert.NotContains(t, err.Error(), "enriched")
+
+	// Once every entry has metadata the check passes
+	require.NoError(t, os.RemoveAll(filepath.Join(registryDir, "bare")))
+	assert.NoError(t, checkRequiredMetadata(loadTestRegistry(t, registryDir)))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var (
+	catalogOutput   string
+	catalogTemplate string
+)
+
+var catalogCmd = &cobra.Command{
+	Use:   "catalog",
+	Short: "Generate an HTML catalog page of the registry",
+	Long: `Generate a static HTML page listing every registry entry, grouped by tier
+and by tag, with links to their repositories.
+
+The page is rendered with Go's html/template, so all entry content is escaped.
+Use --template to render with your own template instead of the built-in one;
+it receives the same data (Total, Tiers and Tags, each group holding Servers).`,
+	Example: `  # Write the catalog to catalog.html
+  registry-builder catalog
+
+  # Render a custom template to a different file
+  registry-builder catalog --output site/index.html --template catalog.tmpl`,
+	RunE: runCatalog,
+}
+
+func init() {
+	catalogCmd.Flags().StringVarP(&catalogOutput, "output", "o", "catalog.html", "Path of the HTML file to write")
+	catalogCmd.Flags().StringVar(&catalogTemplate, "template", "", "Path to a template that replaces the built-in one")
+}
+
+func runCatalog(_ *cobra.Command, _ []string) error {
+	tmpl, err := registry.LoadCatalogTemplate(catalogTemplate)
+	if err != nil {
+		return err
+	}
+
+	loader := newLoader(registryPath)
+	if err := loader.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load registry entries: %w", err)
+	}
+
+	// Render to memory first so a template error doesn't leave a partial file behind
+	var buf bytes.Buffer
+	if err := registry.RenderCatalog(&buf, tmpl, loader.GetEntries()); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(catalogOutput, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write catalog: %w", err)
+	}
+
+	fmt.Printf("✓ Wrote catalog of %d entries to %s\n", len(loader.GetEntries()), catalogOutput)
+	return nil
+}
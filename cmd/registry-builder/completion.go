@@ -0,0 +1,85 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generate shell completion scripts",
+	Long: `Generate a shell completion script for registry-builder.
+
+To load completions:
+
+Bash:
+  $ source <(registry-builder completion bash)
+
+Zsh:
+  $ registry-builder completion zsh > "${fpath[1]}/_registry-builder"
+
+Fish:
+  $ registry-builder completion fish > ~/.config/fish/completions/registry-builder.fish
+
+PowerShell:
+  PS> registry-builder completion powershell | Out-String | Invoke-Expression`,
+	DisableFlagsInUseLine: true,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(out, true)
+		case "zsh":
+			return cmd.Root().GenZshCompletion(out)
+		case "fish":
+			return cmd.Root().GenFishCompletion(out, true)
+		default:
+			return cmd.Root().GenPowerShellCompletionWithDesc(out)
+		}
+	},
+}
+
+// completeEntryNames provides dynamic shell completion of registry entry names.
+// It scans the registry directory rather than loading entries so that it stays
+// fast and works even when some entries fail validation.
+func completeEntryNames(_ *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names, err := listEntryNames(registryPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	// Don't suggest names that were already given
+	given := make(map[string]bool, len(args))
+	for _, arg := range args {
+		given[arg] = true
+	}
+
+	var completions []string
+	for _, name := range names {
+		if !given[name] && strings.HasPrefix(name, toComplete) {
+			completions = append(completions, name)
+		}
+	}
+
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// listEntryNames returns the sorted names of all directories in the registry that contain a spec file
+func listEntryNames(dir string) ([]string, error) {
+	specs, err := entrySpecPaths(dir)
+	if err != nil {
+		return nil, err
+	}
+	return slices.Sorted(maps.Keys(specs)), nil
+}
+
+// entrySpecPaths maps the names of the entry directories in the registry, including those in
+// categories, to their spec files
+func entrySpecPaths(dir string) (map[string]string, error) {
+	return newLoader(dir).DirSpecs()
+}
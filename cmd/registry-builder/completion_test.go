@@ -0,0 +1,55 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteEntryNames(t *testing.T) {
+	// Not parallel: completion reads the package-level registryPath flag
+	tmpDir := t.TempDir()
+	for _, name := range []string{"github", "gitlab", "fetch"} {
+		writeRawSpec(t, tmpDir, name, "image: test\n")
+	}
+	// Directories without a spec file and hidden directories are not entries
+	require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, "no-spec"), 0755))
+	writeRawSpec(t, tmpDir, ".hidden", "image: test\n")
+
+	oldPath := registryPath
+	registryPath = tmpDir
+	t.Cleanup(func() { registryPath = oldPath })
+
+	tests := []struct {
+		name       string
+		args       []string
+		toComplete string
+		want       []string
+	}{
+		{name: "all entries", want: []string{"fetch", "github", "gitlab"}},
+		{name: "prefix match", toComplete: "git", want: []string{"github", "gitlab"}},
+		{name: "already given names are skipped", args: []string{"github"}, toComplete: "git", want: []string{"gitlab"}},
+		{name: "no match", toComplete: "zzz"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, directive := completeEntryNames(listCmd, tt.args, tt.toComplete)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+		})
+	}
+}
+
+func TestCompleteEntryNames_MissingRegistry(t *testing.T) {
+	oldPath := registryPath
+	registryPath = filepath.Join(t.TempDir(), "missing")
+	t.Cleanup(func() { registryPath = oldPath })
+
+	_, directive := completeEntryNames(listCmd, nil, "")
+	assert.Equal(t, cobra.ShellCompDirectiveError, directive)
+}
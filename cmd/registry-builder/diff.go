@@ -0,0 +1,128 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var (
+	diffAgainst string
+	diffFormat  string
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Compare the built registry with an existing registry.json",
+	Long: `Build the registry in memory and compare it with an existing registry.json,
+such as the committed build output, without writing any files.
+
+Added, removed and changed servers are printed, with the changed fields of each
+server. The command fails if there are any differences, so CI can catch spec
+changes whose build output wasn't regenerated. Registry-level fields such as
+last_updated are ignored.`,
+	Example: `  # Check that the committed build output is up to date
+  registry-builder diff --against build/registry.json
+
+  # Print the differences as JSON
+  registry-builder diff --against build/registry.json --format json`,
+	Args: cobra.NoArgs,
+	RunE: runDiff,
+}
+
+func init() {
+	diffCmd.Flags().StringVar(&diffAgainst, "against", filepath.Join("build", "registry.json"),
+		"registry.json to compare the built registry with")
+	diffCmd.Flags().StringVar(&diffFormat, "format", "text", "Output format (text, json)")
+}
+
+func runDiff(_ *cobra.Command, _ []string) error {
+	if diffFormat != "text" && diffFormat != "json" {
+		return fmt.Errorf("unknown format: %s", diffFormat)
+	}
+
+	loader, err := loadEntries(registryPath, false)
+	if err != nil {
+		return err
+	}
+
+	diff, err := diffAgainstFile(loader, diffAgainst)
+	if err != nil {
+		return err
+	}
+	if err := writeRegistryDiff(os.Stdout, diff, diffAgainst, diffFormat); err != nil {
+		return err
+	}
+
+	if !diff.Empty() {
+		return fmt.Errorf("built registry differs from %s: %d added, %d removed, %d changed (run 'registry-builder build')",
+			diffAgainst, diff.Count(registry.ChangeAdded), diff.Count(registry.ChangeRemoved), diff.Count(registry.ChangeModified))
+	}
+	return nil
+}
+
+// diffAgainstFile builds the loaded entries and compares them with the registry in path.
+// Unlike dry-run builds, a missing file is an error rather than an empty registry.
+func diffAgainstFile(loader *registry.Loader, path string) (registry.RegistryDiff, error) {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return registry.RegistryDiff{}, fmt.Errorf("%s does not exist", path)
+	}
+
+	against, err := registry.LoadRegistryFile(path)
+	if err != nil {
+		return registry.RegistryDiff{}, err
+	}
+
+	built, err := registry.NewBuilder(loader).BuildRegistry()
+	if err != nil {
+		return registry.RegistryDiff{}, fmt.Errorf("failed to build registry: %w", err)
+	}
+
+	return registry.Diff(against, built), nil
+}
+
+// writeRegistryDiff writes a diff in the given format. The text format shows each changed
+// field as a go-cmp diff of its old and new JSON values.
+func writeRegistryDiff(w io.Writer, diff registry.RegistryDiff, againstPath, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(diff, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	if diff.Empty() {
+		fmt.Fprintf(w, "✓ Built registry matches %s\n", againstPath)
+		return nil
+	}
+
+	fmt.Fprintf(w, "Differences from %s: %d\n", againstPath, len(diff.Servers))
+	for _, change := range diff.Servers {
+		switch change.Kind {
+		case registry.ChangeAdded:
+			fmt.Fprintf(w, "  + %s (added)\n", change.Name)
+		case registry.ChangeRemoved:
+			fmt.Fprintf(w, "  - %s (removed)\n", change.Name)
+		default:
+			fmt.Fprintf(w, "  ~ %s (changed: %s)\n", change.Name, strings.Join(change.FieldNames(), ", "))
+			for _, field := range change.Fields {
+				fmt.Fprintf(w, "      %s:\n", field.Field)
+				for _, line := range strings.Split(strings.TrimRight(cmp.Diff(field.Old, field.New), "\n"), "\n") {
+					fmt.Fprintf(w, "        %s\n", line)
+				}
+			}
+		}
+	}
+	return nil
+}
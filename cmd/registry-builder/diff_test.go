@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+func TestDiffAgainstFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		change   func(t *testing.T, registryDir string)
+		want     []registry.ServerChange
+		wantText []string
+	}{
+		{
+			name:     "identical",
+			change:   func(*testing.T, string) {},
+			want:     []registry.ServerChange{},
+			wantText: []string{"✓ Built registry matches"},
+		},
+		{
+			name: "added entry",
+			change: func(t *testing.T, registryDir string) {
+				t.Helper()
+				writeTestSpec(t, registryDir, "added", "Added server")
+			},
+			want:     []registry.ServerChange{{Name: "added", Kind: registry.ChangeAdded}},
+			wantText: []string{"Differences from", "  + added (added)"},
+		},
+		{
+			name: "removed entry",
+			change: func(t *testing.T, registryDir string) {
+				t.Helper()
+				require.NoError(t, os.RemoveAll(filepath.Join(registryDir, "kept")))
+			},
+			want:     []registry.ServerChange{{Name: "kept", Kind: registry.ChangeRemoved}},
+			wantText: []string{"  - kept (removed)"},
+		},
+		{
+			name: "changed metadata",
+			change: func(t *testing.T, registryDir string) {
+				t.Helper()
+				writeTestSpec(t, registryDir, "changed", "New description")
+			},
+			want: []registry.ServerChange{
+				{Name: "changed", Kind: registry.ChangeModified, Fields: []registry.FieldChange{
+					{Field: "description", Old: "Original description", New: "New description"},
+				}},
+			},
+			wantText: []string{"  ~ changed (changed: description)", "      description:", `"Original description"`, `"New description"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Build the committed output from the original entries
+			registryDir, outDir := t.TempDir(), t.TempDir()
+			writeTestSpec(t, registryDir, "kept", "Kept server")
+			writeTestSpec(t, registryDir, "changed", "Original description")
+			require.NoError(t, buildToolhiveFormat(loadTestRegistry(t, registryDir), outDir))
+			againstPath := filepath.Join(outDir, "registry.json")
+
+			tt.change(t, registryDir)
+			diff, err := diffAgainstFile(loadTestRegistry(t, registryDir), againstPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, diff.Servers)
+
+			var text bytes.Buffer
+			require.NoError(t, writeRegistryDiff(&text, diff, againstPath, "text"))
+			for _, want := range tt.wantText {
+				assert.Contains(t, text.String(), want)
+			}
+
+			var out bytes.Buffer
+			require.NoError(t, writeRegistryDiff(&out, diff, againstPath, "json"))
+			var decoded registry.RegistryDiff
+			require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+			assert.Len(t, decoded.Servers, len(tt.want))
+		})
+	}
+}
+
+func TestDiffAgainstFile_Missing(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "server", "Test server")
+
+	_, err := diffAgainstFile(loadTestRegistry(t, registryDir), filepath.Join(t.TempDir(), "registry.json"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not exist")
+}
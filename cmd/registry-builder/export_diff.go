@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var exportDiffApply bool
+
+var exportDiffCmd = &cobra.Command{
+	Use:   "export-diff <registry.json>",
+	Short: "Show which spec files a registry.json would add, remove or change",
+	Long: `Compare a full registry.json, such as one received from an external
+contributor, with the entries in the registry directory, and list the spec
+files that would be added, removed or modified to match it. Both sides are
+compared as built, so defaults filled in by the build aren't reported, and
+entries with "enabled: false" are left out as they are of the build.
+
+Nothing is written unless --apply is passed. Then new servers get a new
+entry directory, removed servers have their entry directory deleted, and
+modified servers have only their changed fields rewritten, keeping comments
+and the fields only the registry reads, such as examples and license.`,
+	Example: `  # See what a contributed registry.json would change
+  registry-builder export-diff contributed/registry.json
+
+  # Update the spec files to match it
+  registry-builder export-diff contributed/registry.json --apply`,
+	Args: cobra.ExactArgs(1),
+	RunE: runExportDiff,
+}
+
+func init() {
+	exportDiffCmd.Flags().BoolVar(&exportDiffApply, "apply", false, "Write the changes to the spec files")
+}
+
+func runExportDiff(_ *cobra.Command, args []string) error {
+	// LoadRegistryFile treats a missing file as an empty registry, which would remove every entry
+	if _, err := os.Stat(args[0]); err != nil {
+		return fmt.Errorf("failed to read %s: %w", args[0], err)
+	}
+	incoming, err := registry.LoadRegistryFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	// registry.json only holds published entries, so disabled ones aren't compared
+	loader, err := loadEntries(registryPath, false)
+	if err != nil {
+		return err
+	}
+
+	diff, err := registry.ExportDiff(loader, incoming)
+	if err != nil {
+		return err
+	}
+	writeExportDiff(os.Stdout, loader, diff, args[0])
+
+	if exportDiffApply && !diff.Empty() {
+		if err := registry.ApplyExportDiff(loader, diff, incoming); err != nil {
+			return err
+		}
+		fmt.Printf("✓ Applied %d change(s) to %s\n", len(diff.Servers), registryPath)
+	}
+	return nil
+}
+
+// writeExportDiff lists the spec files an export diff adds, removes or modifies
+func writeExportDiff(w io.Writer, loader *registry.Loader, diff registry.RegistryDiff, incomingPath string) {
+	if diff.Empty() {
+		fmt.Fprintf(w, "✓ Spec files match %s\n", incomingPath)
+		return
+	}
+
+	fmt.Fprintf(w, "Spec files that differ from %s: %d\n", incomingPath, len(diff.Servers))
+	for _, change := range diff.Servers {
+		specPath := registry.ExportSpecPath(loader, change.Name)
+		switch change.Kind {
+		case registry.ChangeAdded:
+			fmt.Fprintf(w, "  + %s (added)\n", specPath)
+		case registry.ChangeRemoved:
+			fmt.Fprintf(w, "  - %s (removed)\n", specPath)
+		default:
+			fmt.Fprintf(w, "  ~ %s (changed: %s)\n", specPath, strings.Join(change.FieldNames(), ", "))
+		}
+	}
+}
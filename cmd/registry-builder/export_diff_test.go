@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+func TestWriteExportDiff(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "changed", "Changed server")
+	writeTestSpec(t, registryDir, "removed", "Removed server")
+	loader := loadTestRegistry(t, registryDir)
+
+	var out bytes.Buffer
+	writeExportDiff(&out, loader, registry.RegistryDiff{Servers: []registry.ServerChange{
+		{Name: "added", Kind: registry.ChangeAdded},
+		{Name: "changed", Kind: registry.ChangeModified, Fields: []registry.FieldChange{
+			{Field: "description", Old: "Changed server", New: "Changed again"},
+			{Field: "tools", Old: []any{"test_tool"}, New: []any{}},
+		}},
+		{Name: "removed", Kind: registry.ChangeRemoved},
+	}}, "contributed.json")
+	assert.Equal(t, "Spec files that differ from contributed.json: 3\n"+
+		"  + "+filepath.Join(registryDir, "added", "spec.yaml")+" (added)\n"+
+		"  ~ "+filepath.Join(registryDir, "changed", "spec.yaml")+" (changed: description, tools)\n"+
+		"  - "+filepath.Join(registryDir, "removed", "spec.yaml")+" (removed)\n",
+		out.String())
+
+	out.Reset()
+	writeExportDiff(&out, loader, registry.RegistryDiff{}, "contributed.json")
+	assert.Equal(t, "✓ Spec files match contributed.json\n", out.String())
+}
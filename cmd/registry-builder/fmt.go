@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var fmtCheck bool
+
+var fmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "Normalize the formatting of spec files",
+	Long: `Rewrite every spec file with its fields in canonical order (name,
+description, tier, status, transport, tools, ..., then the image or remote
+fields, then the registry-only fields), 2-space indentation, block-style
+lists and maps, and values only quoted where YAML requires it.
+
+Comments are kept with the fields they precede, and header comments stay at
+the top of the file. Fields the registry doesn't know follow the known ones in
+their original order.
+
+With --check, no files are written; the command lists the spec files that
+aren't formatted and fails if there are any.`,
+	Example: `  # Format every spec file in place
+  registry-builder fmt
+
+  # Fail in CI if a spec file isn't formatted
+  registry-builder fmt --check`,
+	Args: cobra.NoArgs,
+	RunE: runFmt,
+}
+
+func init() {
+	fmtCmd.Flags().BoolVar(&fmtCheck, "check", false, "List unformatted spec files and fail instead of rewriting them")
+}
+
+func runFmt(_ *cobra.Command, _ []string) error {
+	specs, err := entrySpecPaths(registryPath)
+	if err != nil {
+		return fmt.Errorf("failed to list registry entries: %w", err)
+	}
+
+	changed := 0
+	for _, name := range slices.Sorted(maps.Keys(specs)) {
+		specPath := specs[name]
+		unformatted, err := registry.FormatSpecFile(specPath, !fmtCheck)
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", specPath, err)
+		}
+		if !unformatted {
+			continue
+		}
+
+		changed++
+		if fmtCheck {
+			fmt.Printf("  %s\n", specPath)
+		} else {
+			fmt.Printf("  formatted %s\n", specPath)
+		}
+	}
+
+	switch {
+	case changed == 0:
+		fmt.Printf("✓ All %d spec files are formatted\n", len(specs))
+	case fmtCheck:
+		return fmt.Errorf("%d spec file(s) aren't formatted, run registry-builder fmt to fix them", changed)
+	default:
+		fmt.Printf("✓ Formatted %d spec file(s)\n", changed)
+	}
+
+	return nil
+}
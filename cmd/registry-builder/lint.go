@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var lintFix bool
+
+var lintCmd = &cobra.Command{
+	Use:   "lint",
+	Short: "Check spec files for style issues",
+	Long: `Check spec files for style issues that can be fixed automatically,
+such as malformed tags, licenses that aren't in canonical SPDX form and
+transport aliases (e.g. http for streamable-http). Spec files are read
+directly, so lint works even when entries fail validation.
+
+With --fix, issues are corrected in place, preserving comments and formatting.
+Licenses that can't be matched to an SPDX identifier and unknown transports
+must be fixed by hand.`,
+	Example: `  # Report issues
+  registry-builder lint
+
+  # Fix issues in place
+  registry-builder lint --fix`,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().BoolVar(&lintFix, "fix", false, "Fix issues in place")
+}
+
+// lintIssue is a single issue found by a lint check
+type lintIssue struct {
+	message string
+	fixable bool
+}
+
+// lintCheck checks a spec file, fixing the issues it can in place if fix is set
+type lintCheck func(specPath string, fix bool) ([]lintIssue, error)
+
+// lintChecks are run, in order, on every spec file
+var lintChecks = []lintCheck{lintTags, lintLicense, lintTransport}
+
+func runLint(_ *cobra.Command, _ []string) error {
+	specs, err := entrySpecPaths(registryPath)
+	if err != nil {
+		return fmt.Errorf("failed to list registry entries: %w", err)
+	}
+
+	issueCount := 0
+	unfixable := 0
+	for _, name := range slices.Sorted(maps.Keys(specs)) {
+		specPath := specs[name]
+
+		for _, check := range lintChecks {
+			issues, err := check(specPath, lintFix)
+			if err != nil {
+				return fmt.Errorf("failed to lint %s: %w", specPath, err)
+			}
+
+			for _, issue := range issues {
+				switch {
+				case !issue.fixable:
+					fmt.Printf("  %s: %s (fix by hand)\n", name, issue.message)
+					unfixable++
+				case lintFix:
+					fmt.Printf("  fixed %s: %s\n", name, issue.message)
+				default:
+					fmt.Printf("  %s: %s\n", name, issue.message)
+				}
+			}
+			issueCount += len(issues)
+		}
+	}
+
+	if lintFix && unfixable > 0 {
+		return fmt.Errorf("fixed %d lint issue(s), %d must be fixed by hand", issueCount-unfixable, unfixable)
+	}
+
+	switch {
+	case issueCount == 0:
+		fmt.Printf("✓ No lint issues found in %d entries\n", len(specs))
+	case lintFix:
+		fmt.Printf("✓ Fixed %d lint issue(s)\n", issueCount)
+	default:
+		return fmt.Errorf("found %d lint issue(s), run with --fix to correct them", issueCount)
+	}
+
+	return nil
+}
+
+// lintTags checks for malformed tags, which can always be fixed
+func lintTags(specPath string, fix bool) ([]lintIssue, error) {
+	check := registry.CheckSpecTags
+	if fix {
+		check = registry.FixSpecTags
+	}
+	tagIssues, err := check(specPath)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := make([]lintIssue, 0, len(tagIssues))
+	for _, issue := range tagIssues {
+		issues = append(issues, lintIssue{message: issue.String(), fixable: true})
+	}
+	return issues, nil
+}
+
+// lintLicense checks for a license that isn't in canonical SPDX form
+func lintLicense(specPath string, fix bool) ([]lintIssue, error) {
+	check := registry.CheckSpecLicense
+	if fix {
+		check = registry.FixSpecLicense
+	}
+	issue, err := check(specPath)
+	if err != nil || issue == nil {
+		return nil, err
+	}
+	return []lintIssue{{message: issue.String(), fixable: issue.Suggestion != ""}}, nil
+}
+
+// lintTransport checks for a transport alias or non-canonical casing
+func lintTransport(specPath string, fix bool) ([]lintIssue, error) {
+	check := registry.CheckSpecTransport
+	if fix {
+		check = registry.FixSpecTransport
+	}
+	issue, err := check(specPath)
+	if err != nil || issue == nil {
+		return nil, err
+	}
+	return []lintIssue{{message: issue.String(), fixable: issue.Suggestion != ""}}, nil
+}
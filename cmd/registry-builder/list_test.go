@@ -0,0 +1,171 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestWriteListJSON(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "local", "Local server")
+	hostedDir := filepath.Join(registryDir, "hosted")
+	require.NoError(t, os.MkdirAll(hostedDir, 0755))
+	hosted := `url: https://api.example.com/mcp
+description: Hosted server
+transport: http
+tier: Official
+status: Deprecated
+tools:
+  - search
+  - fetch
+`
+	require.NoError(t, os.WriteFile(filepath.Join(hostedDir, "spec.yaml"), []byte(hosted), 0644))
+
+	var out bytes.Buffer
+	require.NoError(t, writeListJSON(&out, loadTestRegistry(t, registryDir).GetSortedEntries()))
+
+	var entries []listEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &entries))
+	assert.Equal(t, []listEntry{
+		{
+			Name:      "hosted",
+			Type:      "remote",
+			Tier:      "Official",
+			Status:    "Deprecated",
+			Transport: "streamable-http",
+			URL:       "https://api.example.com/mcp",
+			ToolCount: 2,
+		},
+		{
+			Name:      "local",
+			Type:      "container",
+			Tier:      "Community",
+			Status:    "Active",
+			Transport: "stdio",
+			Image:     "test/local:1.0.0",
+			ToolCount: 1,
+		},
+	}, entries)
+
+	// Remote entries have no image field and container entries no url field
+	var raw []map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &raw))
+	assert.NotContains(t, raw[0], "image")
+	assert.NotContains(t, raw[1], "url")
+}
+
+func TestListDeprecatedEntry(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "current", "Current server")
+	writeRawSpec(t, registryDir, "legacy", `image: test/legacy:1.0.0
+description: Legacy server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - test_tool
+deprecated:
+  reason: Superseded by current
+  replaced_by: current
+`)
+
+	entries := loadTestRegistry(t, registryDir).GetSortedEntries()
+	require.Len(t, entries, 2)
+	assert.NotContains(t, entryLine(entries[0], "Community", "Active"), "[DEPRECATED]")
+	assert.Equal(t, fmt.Sprintf("%-30s [Community/Active] test/legacy:1.0.0 [DEPRECATED]", "legacy"),
+		entryLine(entries[1], "Community", "Active"))
+
+	var out bytes.Buffer
+	require.NoError(t, writeListJSON(&out, entries))
+	var listed []listEntry
+	require.NoError(t, json.Unmarshal(out.Bytes(), &listed))
+	assert.Nil(t, listed[0].Deprecated)
+	assert.Equal(t, &types.DeprecationInfo{Reason: "Superseded by current", ReplacedBy: "current"}, listed[1].Deprecated)
+}
+
+func TestWriteListJSON_NoEntries(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, writeListJSON(&out, nil))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+// filterFixture returns entries with a mix of tiers, statuses, transports and tags
+func filterFixture(t *testing.T) []*types.RegistryEntry {
+	t.Helper()
+
+	specs := []struct{ name, spec string }{
+		{"github", "image: test/github:1.0\ntransport: stdio\ntier: Official\ntags: [git, scm]\n"},
+		{"gitlab", "image: test/gitlab:1.0\ntransport: stdio\ntags: [git]\n"},
+		{"notion", "url: https://mcp.notion.com/mcp\ntransport: streamable-http\ntier: Official\ntags: [docs]\n"},
+		{"legacy", "url: https://legacy.example.com/sse\ntransport: sse\nstatus: Deprecated\ntags: [git, docs]\n"},
+	}
+
+	entries := make([]*types.RegistryEntry, 0, len(specs))
+	for _, s := range specs {
+		var entry types.RegistryEntry
+		require.NoError(t, yaml.Unmarshal([]byte("description: Test server\n"+s.spec), &entry))
+		entry.SetName(s.name)
+		entries = append(entries, &entry)
+	}
+	return entries
+}
+
+func TestEntryFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		filter  entryFilter
+		want    []string
+		wantErr string
+	}{
+		{name: "no filter", want: []string{"github", "gitlab", "notion", "legacy"}},
+		{name: "tier", filter: entryFilter{tier: "Official"}, want: []string{"github", "notion"}},
+		{name: "default tier", filter: entryFilter{tier: "community"}, want: []string{"gitlab", "legacy"}},
+		{name: "status", filter: entryFilter{status: "Deprecated"}, want: []string{"legacy"}},
+		{name: "default status", filter: entryFilter{status: "Active"}, want: []string{"github", "gitlab", "notion"}},
+		{name: "transport", filter: entryFilter{transport: "stdio"}, want: []string{"github", "gitlab"}},
+		{name: "transport alias", filter: entryFilter{transport: "http"}, want: []string{"notion"}},
+		{name: "tag", filter: entryFilter{tags: []string{"GIT"}}, want: []string{"github", "gitlab", "legacy"}},
+		{name: "every tag must match", filter: entryFilter{tags: []string{"git", "docs"}}, want: []string{"legacy"}},
+		{name: "tier and tag", filter: entryFilter{tier: "Official", tags: []string{"git"}}, want: []string{"github"}},
+		{name: "transport and status", filter: entryFilter{transport: "sse", status: "Active"}},
+		{name: "unknown transport", filter: entryFilter{transport: "websocket"}, wantErr: `unknown transport "websocket"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entries, err := tt.filter.apply(filterFixture(t))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.GetName())
+			}
+			assert.Equal(t, tt.want, names)
+		})
+	}
+}
@@ -2,14 +2,21 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"github.com/stacklok/toolhive-registry/pkg/annotations"
 	"github.com/stacklok/toolhive-registry/pkg/registry"
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
@@ -27,6 +34,7 @@ var rootCmd = &cobra.Command{
 	Long: `registry-builder is a tool for building and managing the ToolHive registry.
 It converts modular YAML registry entries into various output formats
 including ToolHive JSON and upstream MCP Registry formats.`,
+	PersistentPreRunE: parseEntryNamePolicy,
 }
 
 var buildCmd = &cobra.Command{
@@ -35,25 +43,182 @@ var buildCmd = &cobra.Command{
 	Long: `Build the registry by loading all YAML files from the registry directory
 and generating output in the specified format.
 
+Entries with "enabled: false" are still loaded and validated, but left out of
+the output.
+
+With --source, the registry is fetched from a git repository or a tarball URL
+into a temporary directory instead, so CI jobs don't need to clone it first.
+--registry is then the path of the registry directory within the source. A git
+source is a URL ending in .git or using the git://, ssh:// or file:// scheme,
+optionally followed by #<branch or tag>; anything else over http(s) is taken to
+be a tar or gzipped tar archive, of at most 256 MiB both as downloaded and
+once decompressed. Any other source is a local checkout.
+
+Next to registry.json, registry.json.sha256 holds its SHA-256 in the format of
+sha256sum, so downloads can be checked with 'sha256sum -c'. manifest.json
+records the entry count, build time and content_digest, the SHA-256 of the
+canonical JSON (compact, keys sorted, without last_updated), which only changes
+when the content does.
+
+With --split-by tier, registry-official.json, registry-community.json and a
+file for any other tier are written next to the combined registry.json, each
+holding only the entries of that tier in the same format.
+
 Supported formats:
   - toolhive: ToolHive JSON format (default)
-  - mcp-registry: Upstream MCP Registry format (future)
+  - mcp-registry: Upstream MCP Registry format, written to mcp-registry.json
+    after validating every server against the upstream server schema
   - all: Build all supported formats`,
+	Example: `  # Build the ToolHive registry.json into ./build
+  registry-builder build
+
+  # Build from a custom registry directory into a custom output directory
+  registry-builder build -r ./registry -o ./dist
+
+  # Build both the ToolHive and the upstream MCP Registry format
+  registry-builder build --format all
+
+  # Also write a registry file per tier, e.g. build/registry-official.json
+  registry-builder build --split-by tier
+
+  # Preview the proposed registry.json and how it differs from build/registry.json
+  registry-builder build --dry-run
+
+  # Fail if any image entry is missing stars/pulls metadata
+  registry-builder build --require-metadata
+
+  # Annotate the spec files of a pull request with errors in GitHub Actions
+  registry-builder build --annotations github
+
+  # Build the registry of a tagged release without cloning the repository
+  registry-builder build --source https://github.com/stacklok/toolhive-registry.git#v1.0.0`,
 	RunE: runBuild,
 }
 
 var validateCmd = &cobra.Command{
-	Use:   "validate",
+	Use:   "validate [name]",
 	Short: "Validate registry entries",
-	Long:  `Validate all registry entries without building the output files.`,
-	RunE:  runValidate,
+	Long: `Validate all registry entries without building the output files.
+
+Given an entry name, only that entry is loaded and validated, for fast feedback
+in pre-commit hooks. It fails if no entry has the name. Checks that compare
+entries with each other, such as for duplicate names, need the whole registry
+and are skipped.
+
+Entries with "enabled: false" are validated by default, so the full set is
+audited. Pass --include-disabled=false to validate only the published set.
+
+With --annotations github, warnings and errors are also emitted as GitHub
+Actions workflow commands pointing at the spec file (and line, where known)
+they concern, so they annotate pull requests.
+
+With --cache-dir, the result of validating each entry is cached by the hash
+of its content. Entries that passed with the same content are skipped on the
+next run; the cache is discarded when the schema version changes.
+
+With --upstream, the entries are also converted to the upstream MCP Registry
+format and validated against the upstream server schema, as done by
+'build --format mcp-registry'. It can be combined with either --format.
+
+With --format json, stdout carries only a JSON report for CI, editors and
+other tooling. Each invalid entry lists its diagnostics, one for every failing
+field with the field, message and severity, or one with an empty field if the
+failure concerns the entry as a whole. Warnings are listed the same way, and
+failures of the registry as a whole under errors.
+
+Each --rule enables a built-in policy rule that entries must also pass, given
+as name or name=arg1,arg2:
+  require-repository-url[=TIER,...]  entries (of the tiers) need a repository_url
+  allow-image-registries=REGISTRY,...  images must be hosted on these registries`,
+	Example: `  # Validate all entries and show each validated entry
+  registry-builder validate -v
+
+  # Validate only the fetch entry
+  registry-builder validate fetch
+
+  # Also check that remote servers respond, failing if any are unreachable
+  registry-builder validate --probe-remote --fail-on-unreachable
+
+  # Fail if any entry declares more than 200 tools
+  registry-builder validate --max-tools 200
+
+  # Emit a machine-readable result for CI, whether or not validation passes
+  registry-builder validate --format json
+
+  # Also check the entries convert to valid upstream MCP Registry servers
+  registry-builder validate --upstream
+
+  # List each failing field for an editor integration
+  registry-builder validate --format json | jq '.entries[].diagnostics[]?'
+
+  # Require Official entries to link their repository and images to be on ghcr.io
+  registry-builder validate --rule require-repository-url=Official --rule allow-image-registries=ghcr.io
+
+  # Fail instead of warning when an image and its repository_url have different owners
+  registry-builder validate --strict
+
+  # Fail instead of warning on env var names with lower-case letters or dashes
+  registry-builder validate --strict-env
+
+  # Fail instead of warning on licenses such as "MIT License" or "Apache2"
+  registry-builder validate --strict-license
+
+  # Fail if an example's sample has broken shell quoting
+  registry-builder validate --validate-example-syntax
+
+  # Skip entries that haven't changed since they last passed
+  registry-builder validate --cache-dir .cache/registry-builder
+
+  # Accept images tagged latest (images must otherwise be pinned to a version or digest)
+  registry-builder validate --allow-latest
+
+  # Accept remote servers served over http, e.g. in a local test registry
+  registry-builder validate --allow-insecure-url
+
+  # Annotate the spec files of a pull request with warnings and errors in GitHub Actions
+  registry-builder validate --annotations github`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeEntryNames,
+	RunE:              runValidate,
 }
 
 var listCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [name...]",
 	Short: "List all registry entries",
-	Long:  `List all registry entries found in the registry directory.`,
-	RunE:  runList,
+	Long: `List all registry entries found in the registry directory.
+If entry names are given, only those entries are listed.
+
+Entries with "enabled: false" are hidden by default, so the list matches the
+published set. Pass --include-disabled to list them too.
+
+--tier, --status, --transport and --tag narrow the list to matching entries.
+Combined filters must all match, and an entry must have every --tag given.
+Entries without a tier or status match Community and Active.
+
+With --format ndjson, each entry is written as one line of JSON in the same
+form as the per-entry files written by 'build --per-entry', ready to pipe into
+log processors. With --format json, a JSON array summarizing each entry (name,
+type, tier, status, transport, image or url and tool count) is written instead.
+--output is an alias of --format.`,
+	Example: `  # List all entries
+  registry-builder list
+
+  # Also list disabled entries
+  registry-builder list --include-disabled
+
+  # Show details for specific entries
+  registry-builder list -v github fetch
+
+  # List official remote servers tagged with database
+  registry-builder list --tier Official --transport streamable-http --tag database
+
+  # Stream entries as newline-delimited JSON
+  registry-builder list --format ndjson | jq -r .name
+
+  # Summarize entries as a JSON array
+  registry-builder list --format json | jq '.[] | select(.tool_count > 10) | .name'`,
+	ValidArgsFunction: completeEntryNames,
+	RunE:              runList,
 }
 
 var versionCmd = &cobra.Command{
@@ -67,44 +232,193 @@ var versionCmd = &cobra.Command{
 }
 
 var (
-	registryPath string
-	outputDir    string
-	outputFormat string
-	verbose      bool
+	registryPath            string
+	entryNameFrom           string
+	entryNamePolicy         registry.NamePolicy
+	categoryPrefix          bool
+	allowLatest             bool
+	allowInsecureURL        bool
+	outputDir               string
+	outputFormat            string
+	verbose                 bool
+	probeRemote             bool
+	failOnUnreachable       bool
+	probeTimeout            time.Duration
+	maxTools                int
+	schemaURL               string
+	verifyLoadable          bool
+	perEntry                bool
+	splitBy                 string
+	sinceRef                string
+	buildSource             string
+	changesFormat           string
+	buildDryRun             bool
+	requireMetadata         bool
+	knownTransports         string
+	validateFormat          string
+	validateUpstream        bool
+	validateIncludeDisabled bool
+	validateStrict          bool
+	validateStrictEnv       bool
+	validateStrictLicense   bool
+	validateExampleSyntax   bool
+	validateCacheDir        string
+	validateRules           []string
+	listIncludeDisabled     bool
+	listFormat              string
+	listFilter              entryFilter
 )
 
+// defaultSchemaURLs holds the $schema declared by each output format unless overridden
+var defaultSchemaURLs = map[string]string{
+	"toolhive": registry.DefaultSchemaURL,
+}
+
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVarP(&registryPath, "registry", "r", "registry", "Path to the registry directory")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.PersistentFlags().StringVar(&entryNameFrom, "entry-name-from", "",
+		"Require entry names to come from their directory (dir) or their name field (field); "+
+			"by default the directory is used unless the spec sets a name")
+	rootCmd.PersistentFlags().BoolVar(&allowInsecureURL, "allow-insecure-url", false,
+		"Accept remote servers with http URLs (https is required by default)")
+	rootCmd.PersistentFlags().BoolVar(&categoryPrefix, "category-prefix", false,
+		"Prefix the names of entries in category subdirectories with their category path (databases/postgres is databases-postgres)")
+
+	// Only build, validate and add require pinned images, so the other commands can read any registry
+	for _, cmd := range []*cobra.Command{buildCmd, validateCmd, addCmd} {
+		cmd.Flags().BoolVar(&allowLatest, "allow-latest", false,
+			"Accept images pinned only to the latest tag, not just those of the entries in the allow_latest list of "+
+				"registry.yaml (images without a tag or digest are always rejected)")
+	}
 
 	// Build command flags
 	buildCmd.Flags().StringVarP(&outputDir, "output-dir", "o", "build", "Output directory for built registry files")
 	buildCmd.Flags().StringVarP(&outputFormat, "format", "f", "toolhive", "Output format (toolhive, mcp-registry, all)")
+	buildCmd.Flags().StringVar(&schemaURL, "schema-url", "",
+		"Override the $schema URL (or file path) declared in the output and used for validation")
+	buildCmd.Flags().BoolVar(&verifyLoadable, "verify-loadable", false,
+		"Verify the written registry.json loads back into the toolhive registry format")
+	buildCmd.Flags().BoolVar(&perEntry, "per-entry", false,
+		"Also write one JSON file per server to <output-dir>/servers with an index.json")
+	buildCmd.Flags().StringVar(&splitBy, "split-by", "",
+		"Also write the entries of each value of a field to their own registry file (tier)")
+	buildCmd.Flags().StringVar(&sinceRef, "since", "", "Report servers whose built content changed since this git ref")
+	buildCmd.Flags().StringVar(&buildSource, "source", "",
+		"Fetch the registry from a git URL or an http(s) tarball instead of the local checkout")
+	buildCmd.Flags().StringVar(&changesFormat, "changes-format", "text",
+		"Format of the --since and --dry-run change reports (text, json)")
+	buildCmd.Flags().BoolVar(&buildDryRun, "dry-run", false,
+		"Write the proposed registry.json to a temporary directory and report how it differs from the output directory")
+	buildCmd.Flags().BoolVar(&requireMetadata, "require-metadata", false,
+		"Fail if any image entry has never been enriched with stars/pulls metadata")
+	buildCmd.Flags().StringVar(&annotationsFormat, "annotations", "",
+		"Also emit warnings and errors as CI annotations on stdout (github)")
+
+	// Validate command flags
+	validateCmd.Flags().BoolVar(&probeRemote, "probe-remote", false, "Check that remote server URLs respond to an MCP handshake")
+	validateCmd.Flags().BoolVar(&failOnUnreachable, "fail-on-unreachable", false,
+		"Fail validation if a probed remote server is unreachable or fails the handshake")
+	validateCmd.Flags().DurationVar(&probeTimeout, "probe-timeout", 10*time.Second, "Timeout for each remote server probe")
+	validateCmd.Flags().IntVar(&maxTools, "max-tools", 0, "Fail validation for entries with more than N tools (0 disables)")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Output format (text, json)")
+	validateCmd.Flags().BoolVar(&validateUpstream, "upstream", false,
+		"Also validate the entries converted to the upstream MCP Registry format against its server schema")
+	validateCmd.Flags().StringVar(&knownTransports, "known-transports", "",
+		"YAML file of image transports to check in addition to the bundled mapping")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false,
+		"Fail instead of warning when an image doesn't appear to belong to the repository_url owner")
+	validateCmd.Flags().BoolVar(&validateStrictEnv, "strict-env", false,
+		"Fail instead of warning when an env var name doesn't match ^[A-Z_][A-Z0-9_]*$")
+	validateCmd.Flags().BoolVar(&validateStrictLicense, "strict-license", false,
+		"Fail instead of warning when a license isn't a canonical SPDX identifier")
+	validateCmd.Flags().BoolVar(&validateIncludeDisabled, "include-disabled", true,
+		"Validate entries with enabled: false (use --include-disabled=false to validate only published entries)")
+	validateCmd.Flags().BoolVar(&validateExampleSyntax, "validate-example-syntax", false,
+		"Fail if an example's sample has unbalanced quotes or other shell syntax errors (multi-line prose is skipped)")
+	validateCmd.Flags().StringVar(&validateCacheDir, "cache-dir", "",
+		"Cache entry validation results in this directory and skip entries that passed with the same content")
+	validateCmd.Flags().StringArrayVar(&validateRules, "rule", nil,
+		"Enable a built-in validation rule, as name or name=arg1,arg2 (repeatable)")
+	validateCmd.Flags().StringVar(&annotationsFormat, "annotations", "",
+		"Also emit warnings and errors as CI annotations (github), on stdout or on stderr with --format json")
+
+	// List command flags
+	listCmd.Flags().BoolVar(&listIncludeDisabled, "include-disabled", false, "Also list entries with enabled: false")
+	listCmd.Flags().StringVar(&listFormat, "format", "text", "Output format (text, json, ndjson)")
+	listCmd.Flags().StringVar(&listFilter.tier, "tier", "", "Only list entries with this tier")
+	listCmd.Flags().StringVar(&listFilter.status, "status", "", "Only list entries with this status")
+	listCmd.Flags().StringVar(&listFilter.transport, "transport", "", "Only list entries with this transport")
+	listCmd.Flags().StringSliceVar(&listFilter.tags, "tag", nil, "Only list entries with this tag (repeatable)")
 
 	// Add commands
 	rootCmd.AddCommand(buildCmd)
 	rootCmd.AddCommand(validateCmd)
 	rootCmd.AddCommand(listCmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(completionCmd)
+	rootCmd.AddCommand(auditDupesCmd)
+	rootCmd.AddCommand(lintCmd)
+	rootCmd.AddCommand(refreshMetadataCmd)
+	rootCmd.AddCommand(catalogCmd)
+	rootCmd.AddCommand(verifyImagesCmd)
+	rootCmd.AddCommand(verifyToolsCmd)
+	rootCmd.AddCommand(readmesCmd)
+	rootCmd.AddCommand(addCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(statsCmd)
+	rootCmd.AddCommand(fmtCmd)
+	rootCmd.AddCommand(exportDiffCmd)
+	rootCmd.AddCommand(serveCmd)
+
+	// Use our own completion command instead of cobra's default
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
 }
 
 func main() {
 	if err := rootCmd.Execute(); err != nil {
+		annotateError(err)
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
 }
 
 func runBuild(_ *cobra.Command, _ []string) error {
-	if verbose {
-		log.Printf("Building registry from %s", registryPath)
+	if err := setupAnnotations(os.Stdout); err != nil {
+		return err
+	}
+
+	if buildSource != "" && sinceRef != "" {
+		return fmt.Errorf("--since can't be used with --source")
+	}
+	if splitBy != "" && splitBy != registry.SplitByTier {
+		return fmt.Errorf("unknown --split-by field %q (supported: %s)", splitBy, registry.SplitByTier)
 	}
 
-	// Create loader
-	loader := registry.NewLoader(registryPath)
+	dir := registryPath
+	switch {
+	case buildSource != "" && !registry.IsRemoteSource(buildSource):
+		// A local checkout of the source repository
+		dir = filepath.Join(buildSource, registryPath)
+	case buildSource != "":
+		sourceDir, cleanup, err := registry.NewSourceFetcher(registry.DefaultSourceTimeout).
+			Fetch(context.Background(), buildSource)
+		if err != nil {
+			return fmt.Errorf("failed to fetch registry source: %w", err)
+		}
+		defer cleanup()
+		dir = filepath.Join(sourceDir, registryPath)
+	}
 
-	// Load all entries
+	if verbose {
+		log.Printf("Building registry from %s", dir)
+	}
+
+	// Load the published entries, so disabled ones are left out of the output
+	loader := newLoader(dir)
+	loader.SetSkipDisabled(true)
+	requirePinnedImages(loader)
 	if err := loader.LoadAll(); err != nil {
 		return fmt.Errorf("failed to load registry entries: %w", err)
 	}
@@ -114,6 +428,12 @@ func runBuild(_ *cobra.Command, _ []string) error {
 		log.Printf("Loaded %d registry entries", len(entries))
 	}
 
+	if requireMetadata {
+		if err := checkRequiredMetadata(loader); err != nil {
+			return err
+		}
+	}
+
 	// Count image and remote servers
 	imageCount := 0
 	remoteCount := 0
@@ -125,6 +445,16 @@ func runBuild(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	if buildDryRun {
+		if err := runDryRunBuild(loader); err != nil {
+			return err
+		}
+		if sinceRef != "" {
+			return reportChangesSince(loader, sinceRef)
+		}
+		return nil
+	}
+
 	// Determine which formats to build
 	formats := determineFormats(outputFormat)
 
@@ -145,6 +475,108 @@ func runBuild(_ *cobra.Command, _ []string) error {
 	fmt.Printf("  Formats: %s\n", strings.Join(builtFormats, ", "))
 	fmt.Printf("  Output directory: %s\n", outputDir)
 
+	if sinceRef != "" {
+		return reportChangesSince(loader, sinceRef)
+	}
+
+	return nil
+}
+
+// checkRequiredMetadata fails if any image entry was never enriched with stars/pulls metadata
+func checkRequiredMetadata(loader *registry.Loader) error {
+	missing := registry.FindMissingMetadata(loader.GetEntries())
+	if len(missing) == 0 {
+		return nil
+	}
+
+	for _, name := range missing {
+		annotateEntry(annotations.LevelError, loader, name, "metadata", name+" has no stars/pulls metadata")
+	}
+	return annotatedError{fmt.Errorf("%d image entries have no stars/pulls metadata (run regup or refresh-metadata):\n  %s",
+		len(missing), strings.Join(missing, "\n  "))}
+}
+
+// reportChangesSince prints the servers whose built content differs from the build at a git ref
+func reportChangesSince(loader *registry.Loader, ref string) error {
+	current, err := registry.NewBuilder(loader).BuildRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to build registry: %w", err)
+	}
+
+	// The registry at ref is loaded with the same flags as the current one, without disabled entries
+	previous, err := registry.BuildAtRef(registryPath, ref, func(dir string) *registry.Loader {
+		loader := newLoader(dir)
+		loader.SetSkipDisabled(true)
+		return loader
+	})
+	if err != nil {
+		return fmt.Errorf("failed to build registry at %s: %w", ref, err)
+	}
+
+	return printChanges(fmt.Sprintf("Changed servers since %s", ref), registry.Diff(previous, current))
+}
+
+// runDryRunBuild builds the ToolHive registry into a temporary directory and
+// reports how it differs from the registry.json in the output directory
+func runDryRunBuild(loader *registry.Loader) error {
+	proposedPath, diff, err := dryRunBuild(loader, outputDir)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("✓ Dry run: proposed registry written to %s\n", proposedPath)
+	fmt.Printf("  Output directory %s was not modified\n", outputDir)
+
+	return printChanges(fmt.Sprintf("Changed servers compared to %s", filepath.Join(outputDir, "registry.json")), diff)
+}
+
+// dryRunBuild writes the ToolHive registry to a new temporary directory and
+// compares it against the registry.json in outputDir, which is left untouched
+func dryRunBuild(loader *registry.Loader, outputDir string) (string, registry.RegistryDiff, error) {
+	tmpDir, err := os.MkdirTemp("", "registry-dry-run-")
+	if err != nil {
+		return "", registry.RegistryDiff{}, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	if err := buildToolhiveFormat(loader, tmpDir); err != nil {
+		return "", registry.RegistryDiff{}, fmt.Errorf("failed to build toolhive format: %w", err)
+	}
+
+	proposedPath := filepath.Join(tmpDir, "registry.json")
+	diff, err := registry.CompareRegistryFiles(filepath.Join(outputDir, "registry.json"), proposedPath)
+	if err != nil {
+		return "", registry.RegistryDiff{}, fmt.Errorf("failed to compare with existing output: %w", err)
+	}
+
+	return proposedPath, diff, nil
+}
+
+// printChanges prints a change report in the format selected by --changes-format
+func printChanges(title string, diff registry.RegistryDiff) error {
+	changes := diff.Servers
+	switch changesFormat {
+	case "json":
+		if changes == nil {
+			changes = []registry.ServerChange{}
+		}
+		data, err := json.MarshalIndent(changes, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal changes: %w", err)
+		}
+		fmt.Println(string(data))
+	case "text":
+		fmt.Printf("\n%s: %d\n", title, len(changes))
+		for _, change := range changes {
+			if len(change.Fields) > 0 {
+				fmt.Printf("  - %s (%s: %s)\n", change.Name, change.Kind, strings.Join(change.FieldNames(), ", "))
+			} else {
+				fmt.Printf("  - %s (%s)\n", change.Name, change.Kind)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown changes format: %s", changesFormat)
+	}
+
 	return nil
 }
 
@@ -152,13 +584,9 @@ func determineFormats(format string) []string {
 	switch strings.ToLower(format) {
 	case "all":
 		// Return all supported formats
-		// For now, just toolhive, but will expand to include mcp-registry
-		return []string{"toolhive"}
+		return []string{"toolhive", "mcp-registry"}
 	case "mcp-registry", "mcp":
-		// Future: Upstream MCP Registry format
-		fmt.Println("Note: MCP Registry format support is planned for a future release")
-		fmt.Println("This will generate output compatible with https://github.com/modelcontextprotocol/registry")
-		return []string{}
+		return []string{"mcp-registry"}
 	case "toolhive":
 		fallthrough
 	default:
@@ -171,16 +599,24 @@ func buildFormat(loader *registry.Loader, format string, outputDir string) error
 	case "toolhive":
 		return buildToolhiveFormat(loader, outputDir)
 	case "mcp-registry":
-		// Future implementation
-		return fmt.Errorf("MCP Registry format not yet implemented")
+		return buildMCPRegistryFormat(loader, outputDir)
 	default:
 		return fmt.Errorf("unknown format: %s", format)
 	}
 }
 
+// schemaURLForFormat returns the schema to declare and validate against for a format
+func schemaURLForFormat(format string) string {
+	if schemaURL != "" {
+		return schemaURL
+	}
+	return defaultSchemaURLs[format]
+}
+
 func buildToolhiveFormat(loader *registry.Loader, outputDir string) error {
 	// Create builder
 	builder := registry.NewBuilder(loader)
+	builder.SetSchemaURL(schemaURLForFormat("toolhive"))
 
 	// Validate against schema
 	if err := builder.ValidateAgainstSchema(); err != nil {
@@ -198,6 +634,47 @@ func buildToolhiveFormat(loader *registry.Loader, outputDir string) error {
 		return fmt.Errorf("failed to write output: %w", err)
 	}
 
+	// Write the digest and manifest downstream caches are invalidated by
+	manifest, err := registry.WriteManifest(outputPath)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	if verbose {
+		log.Printf("Written %s with content digest %s", filepath.Join(outputDir, registry.ManifestFile), manifest.ContentDigest)
+	}
+
+	if perEntry {
+		if err := builder.WritePerEntryJSON(outputDir); err != nil {
+			return fmt.Errorf("failed to write per-entry output: %w", err)
+		}
+		if verbose {
+			log.Printf("Written per-entry files to %s", filepath.Join(outputDir, registry.PerEntryDir))
+		}
+	}
+
+	if splitBy == registry.SplitByTier {
+		paths, err := builder.WriteTierJSON(outputDir)
+		if err != nil {
+			return fmt.Errorf("failed to write per-tier output: %w", err)
+		}
+		if verbose {
+			log.Printf("Written per-tier files %s", strings.Join(paths, ", "))
+		}
+	}
+
+	if verifyLoadable {
+		expected, err := builder.BuildRegistry()
+		if err != nil {
+			return fmt.Errorf("failed to build registry: %w", err)
+		}
+		if err := registry.VerifyLoadableFile(outputPath, expected); err != nil {
+			return fmt.Errorf("built registry is not loadable: %w", err)
+		}
+		if verbose {
+			log.Printf("Verified %s loads in the toolhive registry format", outputPath)
+		}
+	}
+
 	if verbose {
 		log.Printf("Written ToolHive format to %s", outputPath)
 	}
@@ -205,25 +682,75 @@ func buildToolhiveFormat(loader *registry.Loader, outputDir string) error {
 	return nil
 }
 
-// Future: buildMCPRegistryFormat function will be added here
-// func buildMCPRegistryFormat(loader *registry.Loader, outputDir string) error {
-//     // Implementation for upstream MCP Registry format
-//     // This will create output compatible with the MCP Registry service:
-//     // https://github.com/modelcontextprotocol/registry
-//     // The format will evolve as the upstream standard evolves
-// }
+// buildMCPRegistryFormat writes the servers in the upstream MCP Registry format, compatible with
+// https://github.com/modelcontextprotocol/registry, after validating them against its schema
+func buildMCPRegistryFormat(loader *registry.Loader, outputDir string) error {
+	builder := registry.NewBuilder(loader)
+
+	if err := builder.ValidateAgainstUpstreamSchema(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	outputPath := filepath.Join(outputDir, registry.UpstreamFile)
+	if err := builder.WriteUpstreamJSON(outputPath); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	if verbose {
+		log.Printf("Written MCP Registry format to %s", outputPath)
+	}
+
+	return nil
+}
+
+func runValidate(_ *cobra.Command, args []string) error {
+	switch validateFormat {
+	case "json":
+		// Annotations go to stderr so stdout only carries the report
+		if err := setupAnnotations(os.Stderr); err != nil {
+			return err
+		}
+		return runValidateJSON(args)
+	case "text":
+		if err := setupAnnotations(os.Stdout); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown format: %s", validateFormat)
+	}
 
-func runValidate(_ *cobra.Command, _ []string) error {
 	if verbose {
 		log.Printf("Validating registry entries in %s", registryPath)
 	}
 
-	// Create loader
-	loader := registry.NewLoader(registryPath)
+	loader := newLoader(registryPath)
+	loader.SetSkipDisabled(!validateIncludeDisabled)
+	requirePinnedImages(loader)
+	loader.SetStrictEnv(validateStrictEnv)
+	loader.SetStrictLicense(validateStrictLicense)
+	if err := addValidationRules(loader); err != nil {
+		return err
+	}
+	saveCache, err := useValidationCache(loader)
+	if err != nil {
+		return err
+	}
 
-	// Load all entries
-	if err := loader.LoadAll(); err != nil {
-		return fmt.Errorf("failed to load registry entries: %w", err)
+	// Results are cached even when an entry fails, so the entries that passed are skipped next time
+	var loadErr error
+	if len(args) > 0 {
+		loadErr = loader.LoadNamed(args...)
+	} else {
+		loadErr = loader.LoadAll()
+	}
+	if err := saveCache(); err != nil {
+		return err
+	}
+	if errors.Is(loadErr, registry.ErrEntryNotFound) {
+		return fmt.Errorf("no registry entry named %q in %s: %w", args[0], registryPath, loadErr)
+	}
+	if loadErr != nil {
+		return fmt.Errorf("failed to load registry entries: %w", loadErr)
 	}
 
 	entries := loader.GetEntries()
@@ -236,6 +763,42 @@ func runValidate(_ *cobra.Command, _ []string) error {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Validate the entries as converted to the upstream MCP Registry format
+	if validateUpstream {
+		if err := builder.ValidateAgainstUpstreamSchema(); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+	}
+
+	// Check tool counts
+	if err := checkToolCounts(loader); err != nil {
+		return err
+	}
+
+	// Warn about transports their image is known not to support
+	if err := checkTransports(loader); err != nil {
+		return err
+	}
+
+	// Warn about images that don't belong to the repository's owner
+	if err := checkImageOwners(loader); err != nil {
+		return err
+	}
+
+	// Check that example samples are valid shell syntax
+	if err := checkExampleSyntax(loader); err != nil {
+		return err
+	}
+
+	// Warn about transport aliases that lint --fix can rewrite
+	checkTransportAliases(loader)
+
+	// Warn about env var names that break when passed to containers (--strict-env fails on load)
+	checkEnvVarNames(loader)
+
+	// Warn about licenses that aren't canonical SPDX identifiers (--strict-license fails on load)
+	checkLicenses(loader)
+
 	// Count image and remote servers
 	imageCount := 0
 	remoteCount := 0
@@ -247,7 +810,11 @@ func runValidate(_ *cobra.Command, _ []string) error {
 		}
 	}
 
-	fmt.Printf("✓ All %d registry entries are valid\n", len(entries))
+	if len(args) > 0 {
+		fmt.Printf("✓ Registry entry %s is valid\n", args[0])
+	} else {
+		fmt.Printf("✓ All %d registry entries are valid\n", len(entries))
+	}
 	if imageCount > 0 && remoteCount > 0 {
 		fmt.Printf("  - %d container-based servers\n", imageCount)
 		fmt.Printf("  - %d remote servers\n", remoteCount)
@@ -264,19 +831,377 @@ func runValidate(_ *cobra.Command, _ []string) error {
 		}
 	}
 
+	if probeRemote {
+		return probeRemoteEntries(loader, os.Stdout)
+	}
+
+	return nil
+}
+
+func checkToolCounts(loader *registry.Loader) error {
+	var errs []string
+	for _, issue := range registry.CheckToolCounts(loader.GetEntries(), maxTools) {
+		if issue.IsError {
+			errs = append(errs, issue.String())
+			annotateEntry(annotations.LevelError, loader, issue.Name, "tools", issue.String())
+		} else {
+			warnEntry(loader, issue.Name, "tools", issue)
+		}
+	}
+
+	if len(errs) > 0 {
+		return annotatedError{fmt.Errorf("tool count validation failed:\n  %s", strings.Join(errs, "\n  "))}
+	}
+
+	return nil
+}
+
+// checkExampleSyntax fails on example samples that aren't valid shell syntax, when --validate-example-syntax is set
+func checkExampleSyntax(loader *registry.Loader) error {
+	if !validateExampleSyntax {
+		return nil
+	}
+
+	var errs []string
+	for _, issue := range registry.CheckExampleSyntax(loader.GetEntries()) {
+		errs = append(errs, issue.String())
+		annotateEntry(annotations.LevelError, loader, issue.Name, "examples", issue.String())
+	}
+
+	if len(errs) > 0 {
+		return annotatedError{fmt.Errorf("example syntax validation failed:\n  %s", strings.Join(errs, "\n  "))}
+	}
+
+	return nil
+}
+
+// runValidateJSON validates every entry and always prints a JSON report, failing if anything is invalid.
+// Warnings are included in the report and logged to stderr, so stdout only carries the report.
+func runValidateJSON(names []string) error {
+	report, err := validationReportWithWarnings(registryPath, validateIncludeDisabled, names...)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation report: %w", err)
+	}
+	fmt.Println(string(data))
+
+	if !report.Valid {
+		for _, result := range report.Entries {
+			if !result.Valid {
+				annotateError(&registry.EntryError{Path: result.Path, Err: errors.New(result.Error)})
+			}
+		}
+		err := fmt.Errorf("validation failed: %d of %d entries invalid, %d registry error(s)",
+			report.Failed, report.Total, len(report.Errors))
+		if len(report.Errors) == 0 {
+			return annotatedError{err}
+		}
+		return err
+	}
+
 	return nil
 }
 
-func runList(_ *cobra.Command, _ []string) error {
-	// Create loader
-	loader := registry.NewLoader(registryPath)
+// validationReportWithWarnings returns the validationReport with the warnings its checks raised
+func validationReportWithWarnings(dir string, includeDisabled bool, names ...string) (*registry.ValidationReport, error) {
+	var warnings []registry.Diagnostic
+	collectedWarnings = &warnings
+	defer func() { collectedWarnings = nil }()
+
+	report, err := validationReport(dir, includeDisabled, names...)
+	if err != nil {
+		return nil, err
+	}
+	report.Warnings = warnings
+	return report, nil
+}
+
+// validationReport validates each entry individually, or only the named entries, then runs
+// the registry-wide checks when every entry is valid
+func validationReport(dir string, includeDisabled bool, names ...string) (*registry.ValidationReport, error) {
+	loader := newLoader(dir)
+	loader.SetSkipDisabled(!includeDisabled)
+	requirePinnedImages(loader)
+	loader.SetStrictEnv(validateStrictEnv)
+	loader.SetStrictLicense(validateStrictLicense)
+	if err := addValidationRules(loader); err != nil {
+		return nil, err
+	}
+	saveCache, err := useValidationCache(loader)
+	if err != nil {
+		return nil, err
+	}
+	var report *registry.ValidationReport
+	if len(names) > 0 {
+		report, err = loader.ValidateNamed(names...)
+	} else {
+		report, err = loader.ValidateEach()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := saveCache(); err != nil {
+		return nil, err
+	}
+	if !report.Valid {
+		return report, nil
+	}
+
+	builder := registry.NewBuilder(loader)
+	if err := builder.ValidateAgainstSchema(); err != nil {
+		report.AddError(err)
+	}
+	if validateUpstream {
+		if err := builder.ValidateAgainstUpstreamSchema(); err != nil {
+			report.AddError(err)
+		}
+	}
+	if err := checkToolCounts(loader); err != nil {
+		report.AddError(err)
+	}
+	if err := checkTransports(loader); err != nil {
+		report.AddError(err)
+	}
+	if err := checkImageOwners(loader); err != nil {
+		report.AddError(err)
+	}
+	if err := checkExampleSyntax(loader); err != nil {
+		report.AddError(err)
+	}
+	checkTransportAliases(loader)
+	checkEnvVarNames(loader)
+	checkLicenses(loader)
+	if probeRemote {
+		if err := probeRemoteEntries(loader, os.Stderr); err != nil {
+			report.AddError(err)
+		}
+	}
+
+	return report, nil
+}
+
+// checkTransports warns about entries whose transport contradicts the known transports of their image
+func checkTransports(loader *registry.Loader) error {
+	known, err := registry.DefaultKnownTransports()
+	if err != nil {
+		return err
+	}
+
+	if knownTransports != "" {
+		extra, err := registry.LoadKnownTransports(knownTransports)
+		if err != nil {
+			return err
+		}
+		known.Merge(extra)
+	}
+
+	for _, mismatch := range registry.CheckTransports(loader.GetEntries(), known) {
+		warnEntry(loader, mismatch.Name, "transport", mismatch)
+	}
+
+	return nil
+}
+
+// checkTransportAliases warns about entries that declare their transport with an alias or
+// non-canonical casing
+func checkTransportAliases(loader *registry.Loader) {
+	for _, issue := range registry.CheckTransportAliases(loader.GetEntries()) {
+		warnEntry(loader, issue.Name, "transport", issue)
+	}
+}
+
+// checkEnvVarNames warns about entries with env var names that don't follow shell conventions.
+// With --strict-env these entries already failed validation.
+func checkEnvVarNames(loader *registry.Loader) {
+	for _, issue := range registry.CheckEnvVarNames(loader.GetEntries()) {
+		warnEntry(loader, issue.Name, "env_vars", issue)
+	}
+}
+
+// checkLicenses warns about entries whose license isn't a canonical SPDX identifier.
+// With --strict-license these entries already failed validation.
+func checkLicenses(loader *registry.Loader) {
+	for _, issue := range registry.CheckLicenses(loader.GetEntries()) {
+		warnEntry(loader, issue.Name, "license", issue)
+	}
+}
+
+// checkImageOwners warns about entries whose image and repository_url have different owners,
+// or fails with --strict
+func checkImageOwners(loader *registry.Loader) error {
+	exceptions, err := registry.DefaultOwnerExceptions()
+	if err != nil {
+		return err
+	}
+
+	mismatches := registry.CheckImageOwners(loader.GetEntries(), exceptions)
+	if validateStrict && len(mismatches) > 0 {
+		errs := make([]string, 0, len(mismatches))
+		for _, mismatch := range mismatches {
+			errs = append(errs, mismatch.String())
+			annotateEntry(annotations.LevelError, loader, mismatch.Name, "image", mismatch.String())
+		}
+		return annotatedError{fmt.Errorf("image owner validation failed:\n  %s", strings.Join(errs, "\n  "))}
+	}
+
+	for _, mismatch := range mismatches {
+		warnEntry(loader, mismatch.Name, "image", mismatch)
+	}
+
+	return nil
+}
+
+// probeRemoteEntries probes every remote entry, writing the results to out
+func probeRemoteEntries(loader *registry.Loader, out io.Writer) error {
+	prober := registry.NewRemoteProber(probeTimeout)
+
+	var failed []string
+	fmt.Fprintln(out, "\nProbing remote servers:")
+	for _, entry := range loader.GetSortedEntries() {
+		if !entry.IsRemote() {
+			continue
+		}
+
+		result := prober.Probe(context.Background(), entry.GetName(), entry)
+		marker := "✓"
+		if !result.OK() {
+			marker = "✗"
+			failed = append(failed, result.Name)
+		}
+
+		if result.Detail != "" {
+			fmt.Fprintf(out, "  %s %s [%s]: %s\n", marker, result.Name, result.Status, result.Detail)
+		} else {
+			fmt.Fprintf(out, "  %s %s [%s]\n", marker, result.Name, result.Status)
+		}
+	}
+
+	if len(failed) > 0 && failOnUnreachable {
+		return fmt.Errorf("%d remote server(s) failed probing: %s", len(failed), strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// parseEntryNamePolicy parses --entry-name-from before any command runs
+func parseEntryNamePolicy(_ *cobra.Command, _ []string) error {
+	policy, err := registry.ParseNamePolicy(entryNameFrom)
+	if err != nil {
+		return fmt.Errorf("invalid --entry-name-from: %w", err)
+	}
+	entryNamePolicy = policy
+	return nil
+}
+
+// newLoader creates a loader for dir that applies the --entry-name-from policy, --category-prefix
+// and --allow-insecure-url. Images on the latest tag are accepted; commands that require them
+// to be pinned call requirePinnedImages.
+func newLoader(dir string) *registry.Loader {
+	loader := registry.NewLoader(dir)
+	loader.SetNamePolicy(entryNamePolicy)
+	loader.SetCategoryPrefix(categoryPrefix)
+	loader.SetAllowLatest(true)
+	loader.SetAllowInsecureURL(allowInsecureURL)
+	return loader
+}
+
+// requirePinnedImages makes the loader reject images on the latest tag, unless --allow-latest is
+// set or registry.yaml lists the entry under allow_latest
+func requirePinnedImages(loader *registry.Loader) {
+	loader.SetAllowLatest(allowLatest)
+}
+
+// useValidationCache makes the loader use the validation cache in --cache-dir, if set.
+// The returned function saves the cache once the entries are validated.
+func useValidationCache(loader *registry.Loader) (func() error, error) {
+	if validateCacheDir == "" {
+		return func() error { return nil }, nil
+	}
+
+	// Some entries only pass with --allow-latest or --allow-insecure-url, or fail with --strict-env,
+	// --strict-license or a --rule, so those results are cached apart
+	schema := registry.SchemaVersion()
+	if allowLatest {
+		schema += "+allow-latest"
+	}
+	if allowInsecureURL {
+		schema += "+allow-insecure-url"
+	}
+	if validateStrictEnv {
+		schema += "+strict-env"
+	}
+	if validateStrictLicense {
+		schema += "+strict-license"
+	}
+	for _, rule := range validateRules {
+		schema += "+rule=" + rule
+	}
+	cache, err := registry.OpenValidationCache(validateCacheDir, schema)
+	if err != nil {
+		return nil, err
+	}
+	loader.SetValidationCache(cache)
+
+	return func() error {
+		if verbose {
+			log.Printf("Skipped %d entries that passed validation with the same content", cache.Hits())
+		}
+		return cache.Save()
+	}, nil
+}
+
+// addValidationRules adds the built-in rules enabled with --rule to the loader
+func addValidationRules(loader *registry.Loader) error {
+	for _, spec := range validateRules {
+		rule, err := registry.ParseRule(spec)
+		if err != nil {
+			return err
+		}
+		loader.AddRule(rule)
+	}
+	return nil
+}
+
+// loadEntries loads every entry in dir, leaving out disabled entries unless includeDisabled is set
+func loadEntries(dir string, includeDisabled bool) (*registry.Loader, error) {
+	loader := newLoader(dir)
+	loader.SetSkipDisabled(!includeDisabled)
 
-	// Load all entries
 	if err := loader.LoadAll(); err != nil {
-		return fmt.Errorf("failed to load registry entries: %w", err)
+		return nil, fmt.Errorf("failed to load registry entries: %w", err)
+	}
+
+	return loader, nil
+}
+
+func runList(_ *cobra.Command, args []string) error {
+	if listFormat != "text" && listFormat != "json" && listFormat != "ndjson" {
+		return fmt.Errorf("unknown format: %s", listFormat)
 	}
 
-	entries := loader.GetSortedEntries()
+	loader, err := loadEntries(registryPath, listIncludeDisabled)
+	if err != nil {
+		return err
+	}
+
+	entries, err := filterEntriesByName(loader.GetSortedEntries(), args)
+	if err != nil {
+		return err
+	}
+	if entries, err = listFilter.apply(entries); err != nil {
+		return err
+	}
+
+	switch listFormat {
+	case "ndjson":
+		return registry.NewBuilder(loader).WriteNDJSON(os.Stdout, entries)
+	case "json":
+		return writeListJSON(os.Stdout, entries)
+	}
 
 	fmt.Printf("Found %d registry entries:\n\n", len(entries))
 
@@ -312,6 +1237,124 @@ func runList(_ *cobra.Command, _ []string) error {
 	return nil
 }
 
+// listEntry is the summary of an entry written by list --format json
+type listEntry struct {
+	Name      string `json:"name"`
+	Type      string `json:"type"`
+	Tier      string `json:"tier"`
+	Status    string `json:"status"`
+	Transport string `json:"transport"`
+	Image     string `json:"image,omitempty"`
+	URL       string `json:"url,omitempty"`
+	ToolCount int    `json:"tool_count"`
+
+	Deprecated *types.DeprecationInfo `json:"deprecated,omitempty"`
+}
+
+// writeListJSON writes a summary of each entry as an indented JSON array, in the given order
+func writeListJSON(w io.Writer, entries []*types.RegistryEntry) error {
+	summaries := make([]listEntry, 0, len(entries))
+	for _, entry := range entries {
+		summary := listEntry{
+			Name:      entry.GetName(),
+			Type:      "container",
+			Tier:      getEntryTier(entry),
+			Status:    getEntryStatus(entry),
+			Transport: entry.GetTransport(),
+			ToolCount: len(entry.GetTools()),
+
+			Deprecated: entry.Deprecated,
+		}
+		if entry.IsRemote() {
+			summary.Type = "remote"
+			summary.URL = entry.URL
+		} else {
+			summary.Image = entry.Image
+		}
+		summaries = append(summaries, summary)
+	}
+
+	data, err := json.MarshalIndent(summaries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal entries: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(data))
+	return err
+}
+
+// entryFilter selects entries by their metadata. Empty fields match every entry.
+type entryFilter struct {
+	tier      string
+	status    string
+	transport string
+	tags      []string
+}
+
+// apply returns the entries matching every field of the filter, in the given order
+func (f entryFilter) apply(entries []*types.RegistryEntry) ([]*types.RegistryEntry, error) {
+	transport := f.transport
+	if transport != "" {
+		normalized, ok := types.NormalizeTransport(transport)
+		if !ok {
+			return nil, fmt.Errorf("unknown transport %q (supported: %s)", transport, strings.Join(types.Transports, ", "))
+		}
+		transport = normalized
+	}
+
+	var filtered []*types.RegistryEntry
+	for _, entry := range entries {
+		if f.tier != "" && !strings.EqualFold(getEntryTier(entry), f.tier) {
+			continue
+		}
+		if f.status != "" && !strings.EqualFold(getEntryStatus(entry), f.status) {
+			continue
+		}
+		if transport != "" {
+			if entryTransport, _ := types.NormalizeTransport(entry.GetTransport()); entryTransport != transport {
+				continue
+			}
+		}
+		if !hasTags(entry.GetTags(), f.tags) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered, nil
+}
+
+// hasTags returns true if tags contains every wanted tag, ignoring case
+func hasTags(tags, wanted []string) bool {
+	for _, want := range wanted {
+		if !slices.ContainsFunc(tags, func(tag string) bool { return strings.EqualFold(tag, want) }) {
+			return false
+		}
+	}
+	return true
+}
+
+// filterEntriesByName returns only the entries with the given names, or all entries if no names are given
+func filterEntriesByName(entries []*types.RegistryEntry, names []string) ([]*types.RegistryEntry, error) {
+	if len(names) == 0 {
+		return entries, nil
+	}
+
+	byName := make(map[string]*types.RegistryEntry, len(entries))
+	for _, entry := range entries {
+		byName[entry.GetName()] = entry
+	}
+
+	var filtered []*types.RegistryEntry
+	for _, name := range names {
+		entry, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("registry entry not found: %s", name)
+		}
+		filtered = append(filtered, entry)
+	}
+
+	return filtered, nil
+}
+
 func displayEntry(entry *types.RegistryEntry, verbose bool) {
 	status := getEntryStatus(entry)
 	tier := getEntryTier(entry)
@@ -341,13 +1384,30 @@ func getEntryTier(entry *types.RegistryEntry) string {
 }
 
 func displayBasicEntryInfo(entry *types.RegistryEntry, tier, status string) {
-	if entry.IsImage() {
-		fmt.Printf("%-30s [%s/%s] %s\n", entry.GetName(), tier, status, entry.Image)
-	} else if entry.IsRemote() {
-		fmt.Printf("%-30s [%s/%s] %s\n", entry.GetName(), tier, status, entry.URL)
+	if line := entryLine(entry, tier, status); line != "" {
+		fmt.Println(line)
 	}
 }
 
+// entryLine returns the one-line summary of an entry printed by list, or "" if it's neither
+// an image nor a remote server
+func entryLine(entry *types.RegistryEntry, tier, status string) string {
+	var line string
+	switch {
+	case entry.IsImage():
+		line = fmt.Sprintf("%-30s [%s/%s] %s", entry.GetName(), tier, status, entry.Image)
+	case entry.IsRemote():
+		line = fmt.Sprintf("%-30s [%s/%s] %s", entry.GetName(), tier, status, entry.URL)
+	default:
+		return ""
+	}
+
+	if entry.Deprecated != nil {
+		line += " [DEPRECATED]"
+	}
+	return line
+}
+
 func displayVerboseEntryInfo(entry *types.RegistryEntry) {
 	fmt.Printf("  Type:        %s\n", getServerType(entry))
 	fmt.Printf("  Description: %s\n", entry.GetDescription())
@@ -356,6 +1416,7 @@ func displayVerboseEntryInfo(entry *types.RegistryEntry) {
 	displayToolsInfo(entry)
 	displayRepositoryInfo(entry)
 	displayLicenseInfo(entry)
+	displayDeprecationInfo(entry)
 	displayExamplesInfo(entry)
 	displayRemoteSpecificInfo(entry)
 
@@ -370,10 +1431,8 @@ func displayToolsInfo(entry *types.RegistryEntry) {
 }
 
 func displayRepositoryInfo(entry *types.RegistryEntry) {
-	if entry.IsImage() && entry.ImageMetadata.RepositoryURL != "" {
-		fmt.Printf("  Repository:  %s\n", entry.ImageMetadata.RepositoryURL)
-	} else if entry.IsRemote() && entry.RemoteServerMetadata.RepositoryURL != "" {
-		fmt.Printf("  Repository:  %s\n", entry.RemoteServerMetadata.RepositoryURL)
+	if repoURL := entry.GetRepositoryURL(); repoURL != "" {
+		fmt.Printf("  Repository:  %s\n", repoURL)
 	}
 }
 
@@ -383,6 +1442,19 @@ func displayLicenseInfo(entry *types.RegistryEntry) {
 	}
 }
 
+func displayDeprecationInfo(entry *types.RegistryEntry) {
+	if entry.Deprecated == nil {
+		return
+	}
+	fmt.Printf("  Deprecated:  %s\n", entry.Deprecated.Reason)
+	if entry.Deprecated.ReplacedBy != "" {
+		fmt.Printf("  Replaced by: %s\n", entry.Deprecated.ReplacedBy)
+	}
+	if entry.Deprecated.Since != "" {
+		fmt.Printf("  Since:       %s\n", entry.Deprecated.Since)
+	}
+}
+
 func displayExamplesInfo(entry *types.RegistryEntry) {
 	if len(entry.Examples) > 0 {
 		fmt.Printf("  Examples:    %d available\n", len(entry.Examples))
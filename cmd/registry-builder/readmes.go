@@ -0,0 +1,74 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+var readmesFix bool
+
+var readmesCmd = &cobra.Command{
+	Use:   "readmes",
+	Short: "Check entry README files against their specs",
+	Long: `Check that the README.md of every image entry matches the one generated
+from its spec.yaml. A README is stale when its content differs from the
+generated one (e.g. after an entry is renamed or its tools change), and
+orphaned when the entry no longer has enough tools, environment variables or
+tags to need one, or when its directory has no spec.yaml.
+
+The generated README includes the entry's statistics, so READMEs become stale
+whenever the metadata is refreshed. Entries without a README aren't checked.
+
+With --fix, stale READMEs are regenerated and orphaned ones are removed.`,
+	Example: `  # Report stale and orphaned READMEs
+  registry-builder readmes
+
+  # Regenerate stale READMEs and remove orphaned ones
+  registry-builder readmes --fix`,
+	RunE: runReadmes,
+}
+
+func init() {
+	readmesCmd.Flags().BoolVar(&readmesFix, "fix", false, "Regenerate stale READMEs and remove orphaned ones")
+}
+
+func runReadmes(_ *cobra.Command, _ []string) error {
+	loader := newLoader(registryPath)
+	if err := loader.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load registry: %w", err)
+	}
+
+	issues, err := registry.CheckReadmes(loader)
+	if err != nil {
+		return fmt.Errorf("failed to check READMEs: %w", err)
+	}
+
+	for _, issue := range issues {
+		if !readmesFix {
+			fmt.Printf("  %s\n", issue)
+			continue
+		}
+		if err := registry.FixReadme(issue); err != nil {
+			return err
+		}
+		if issue.Orphaned {
+			fmt.Printf("  removed %s\n", issue.Path)
+		} else {
+			fmt.Printf("  regenerated %s\n", issue.Path)
+		}
+	}
+
+	switch {
+	case len(issues) == 0:
+		fmt.Printf("✓ All READMEs match their specs\n")
+	case readmesFix:
+		fmt.Printf("✓ Fixed %d README(s)\n", len(issues))
+	default:
+		return fmt.Errorf("found %d stale or orphaned README(s), run with --fix to correct them", len(issues))
+	}
+
+	return nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/metadata"
+	"github.com/stacklok/toolhive-registry/pkg/progress"
+)
+
+var (
+	refreshAll              bool
+	refreshDryRun           bool
+	refreshGitHubToken      string
+	refreshVerifyProvenance bool
+	refreshInterval         time.Duration
+	refreshFormat           string
+	refreshCommit           bool
+	refreshCommitFile       string
+	refreshSkipPullHosts    []string
+	refreshRecordSources    bool
+)
+
+var refreshMetadataCmd = &cobra.Command{
+	Use:     "refresh-metadata [name...]",
+	Aliases: []string{"refresh"},
+	Short:   "Refresh GitHub stars and container pull counts",
+	Long: `Refresh the GitHub stars and container pull counts of registry entries
+and write them to their spec files, preserving comments and formatting.
+
+Either name the entries to refresh or pass --all. API requests are spaced out
+by --interval to stay within rate limits. Entries whose counts can't be fetched
+keep their current values. Pull counts for hosts listed with --skip-pull-hosts
+(such as private registries) are skipped without a warning. With --record-sources,
+the API URL and fetch time of each fetched value are written to metadata.sources.
+
+When stderr is a terminal, progress and an estimated time remaining are shown
+there (except with --format json).
+
+With --commit, a conventional-commit message summarizing the changed entries
+is written to --commit-file, or to stdout (moving the report to stderr) when no
+file is given. Nothing is written when no entry changed. No git commands are
+run; the message is meant for CI to pass to git commit -F.`,
+	Example: `  # Refresh every entry
+  registry-builder refresh-metadata --all
+
+  # Preview the new counts for two entries
+  registry-builder refresh-metadata github fetch --dry-run
+
+  # Refresh every entry and write a commit message for CI
+  registry-builder refresh --all --commit --commit-file commit-msg.txt`,
+	ValidArgsFunction: completeEntryNames,
+	RunE:              runRefreshMetadata,
+}
+
+func init() {
+	refreshMetadataCmd.Flags().BoolVar(&refreshAll, "all", false, "Refresh every registry entry")
+	refreshMetadataCmd.Flags().BoolVar(&refreshDryRun, "dry-run", false, "Report the new counts without writing them")
+	refreshMetadataCmd.Flags().StringVar(&refreshGitHubToken, "github-token", "",
+		"GitHub token for API authentication (can also be set via GITHUB_TOKEN env var)")
+	refreshMetadataCmd.Flags().BoolVar(&refreshVerifyProvenance, "verify-provenance", false,
+		"Verify provenance information and fail the entry if verification fails")
+	refreshMetadataCmd.Flags().DurationVar(&refreshInterval, "interval", 500*time.Millisecond,
+		"Minimum time between API requests")
+	refreshMetadataCmd.Flags().StringVar(&refreshFormat, "format", "text", "Output format (text, json)")
+	refreshMetadataCmd.Flags().BoolVar(&refreshCommit, "commit", false,
+		"Generate a conventional-commit message summarizing the changed entries")
+	refreshMetadataCmd.Flags().StringVar(&refreshCommitFile, "commit-file", "",
+		"File to write the commit message to (defaults to stdout)")
+	refreshMetadataCmd.Flags().StringSliceVar(&refreshSkipPullHosts, "skip-pull-hosts", nil,
+		"Registry hosts to skip pull counts for without warning (repeatable, *.example.com matches subdomains)")
+	refreshMetadataCmd.Flags().BoolVar(&refreshRecordSources, "record-sources", false,
+		"Record the API URL and fetch time of each value in metadata.sources")
+}
+
+func runRefreshMetadata(_ *cobra.Command, args []string) error {
+	if refreshAll == (len(args) > 0) {
+		return fmt.Errorf("specify either entry names or --all")
+	}
+	if refreshFormat != "text" && refreshFormat != "json" {
+		return fmt.Errorf("unknown format: %s", refreshFormat)
+	}
+	if refreshCommitFile != "" && !refreshCommit {
+		return fmt.Errorf("--commit-file requires --commit")
+	}
+
+	// The commit message owns stdout when it isn't written to a file
+	var out io.Writer = os.Stdout
+	if refreshCommit && refreshCommitFile == "" {
+		if refreshFormat == "json" {
+			return fmt.Errorf("--commit with --format json requires --commit-file")
+		}
+		out = os.Stderr
+	}
+
+	specs, err := entrySpecPaths(registryPath)
+	if err != nil {
+		return fmt.Errorf("failed to list registry entries: %w", err)
+	}
+	names := args
+	if refreshAll {
+		names = slices.Sorted(maps.Keys(specs))
+	}
+
+	if refreshGitHubToken == "" {
+		refreshGitHubToken = os.Getenv("GITHUB_TOKEN")
+	}
+
+	updater := metadata.NewUpdater(metadata.Options{
+		GitHubToken:      refreshGitHubToken,
+		DryRun:           refreshDryRun,
+		VerifyProvenance: refreshVerifyProvenance,
+		RequestInterval:  refreshInterval,
+		SkipPullHosts:    refreshSkipPullHosts,
+		RecordSources:    refreshRecordSources,
+	})
+
+	// Progress is shown on stderr when it's a terminal, and never in JSON mode
+	var progressOut io.Writer = os.Stderr
+	if refreshFormat == "json" {
+		progressOut = io.Discard
+	}
+	bar := progress.New(progressOut, len(names))
+
+	ctx := context.Background()
+	var results []metadata.Result
+	failed := 0
+	for _, name := range names {
+		specPath, ok := specs[name]
+		if !ok {
+			specPath = filepath.Join(registryPath, name, "spec.yaml")
+		}
+		result, err := updater.UpdateSpec(ctx, specPath)
+		if err != nil {
+			bar.Clear()
+			fmt.Fprintf(os.Stderr, "  ✗ %s: %v\n", name, err)
+			failed++
+			bar.Increment()
+			continue
+		}
+		results = append(results, result)
+
+		if refreshFormat == "text" && (verbose || result.Changed()) {
+			bar.Clear()
+			fmt.Fprintf(out, "  %s: stars %d -> %d, pulls %d -> %d\n",
+				name, result.OldStars, result.NewStars, result.OldPulls, result.NewPulls)
+		}
+		bar.Increment()
+	}
+	bar.Finish()
+
+	if refreshFormat == "json" {
+		if results == nil {
+			results = []metadata.Result{}
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return fmt.Errorf("failed to encode results: %w", err)
+		}
+	} else {
+		changed := 0
+		for _, result := range results {
+			if result.Changed() {
+				changed++
+			}
+		}
+		if refreshDryRun {
+			fmt.Fprintf(out, "✓ Would update %d of %d entries (dry run)\n", changed, len(results))
+		} else {
+			fmt.Fprintf(out, "✓ Refreshed %d entries, %d with new counts\n", len(results), changed)
+		}
+	}
+
+	if refreshCommit {
+		if err := writeCommitMessage(metadata.CommitMessage(results), refreshCommitFile); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to refresh %d entries", failed)
+	}
+
+	return nil
+}
+
+// writeCommitMessage writes a non-empty commit message to path, or to stdout if path is empty
+func writeCommitMessage(message, path string) error {
+	if message == "" {
+		return nil
+	}
+	if path == "" {
+		fmt.Print(message)
+		return nil
+	}
+	if err := os.WriteFile(path, []byte(message), 0600); err != nil {
+		return fmt.Errorf("failed to write commit message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+// serveShutdownTimeout is how long in-flight requests get to finish once the server is stopped
+const serveShutdownTimeout = 5 * time.Second
+
+var serveAddr string
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve the built registry over HTTP for local testing",
+	Long: `Serve the registry over HTTP, so thv can be pointed at a local registry
+with http://<addr>/registry.json as its registry URL.
+
+The registry is built in memory from the registry directory on every request,
+so edits to spec files show up without restarting. Nothing is written to disk.
+Entries with "enabled: false" are not served, as they are left out of 'build'.
+
+Endpoints:
+  /registry.json          the registry, as written by 'build'
+  /servers/<name>[.json]  a single server, as written by 'build --per-entry'
+
+Unknown servers get a 404, and a registry that fails to build a 500 with the
+error. Stop the server with Ctrl-C.`,
+	Example: `  # Serve the registry on the default address
+  registry-builder serve
+
+  # Serve on all interfaces, port 9090
+  registry-builder serve --addr :9090
+
+  # Fetch a single server
+  curl http://127.0.0.1:8080/servers/fetch`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func init() {
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "127.0.0.1:8080", "Address to listen on")
+}
+
+func runServe(_ *cobra.Command, _ []string) error {
+	// Fail fast rather than serving a registry that doesn't build
+	if _, _, err := buildServedRegistry(registryPath); err != nil {
+		return err
+	}
+
+	server := &http.Server{
+		Addr:              serveAddr,
+		Handler:           newRegistryHandler(registryPath),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- server.ListenAndServe()
+	}()
+	fmt.Printf("Serving %s at http://%s/registry.json\n", registryPath, serveAddr)
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("failed to serve registry: %w", err)
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to stop server: %w", err)
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve registry: %w", err)
+	}
+	return nil
+}
+
+// newRegistryHandler returns a handler serving the registry built from dir and its servers
+func newRegistryHandler(dir string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /registry.json", func(w http.ResponseWriter, _ *http.Request) {
+		builder, _, err := buildServedRegistry(dir)
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		data, err := builder.MarshalJSON()
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		writeJSONResponse(w, data)
+	})
+	mux.HandleFunc("GET /servers/{name}", func(w http.ResponseWriter, r *http.Request) {
+		builder, loader, err := buildServedRegistry(dir)
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+
+		name := strings.TrimSuffix(r.PathValue("name"), ".json")
+		entry, ok := loader.GetEntries()[name]
+		if !ok {
+			http.Error(w, fmt.Sprintf("no registry entry named %q", name), http.StatusNotFound)
+			return
+		}
+		output, err := builder.EntryOutput(entry)
+		if err != nil {
+			serveError(w, err)
+			return
+		}
+		data, err := json.MarshalIndent(output, "", "  ")
+		if err != nil {
+			serveError(w, fmt.Errorf("failed to marshal entry %s: %w", name, err))
+			return
+		}
+		writeJSONResponse(w, data)
+	})
+	return mux
+}
+
+// buildServedRegistry loads the published entries in dir afresh and returns a builder for them
+func buildServedRegistry(dir string) (*registry.Builder, *registry.Loader, error) {
+	loader, err := loadEntries(dir, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	return registry.NewBuilder(loader), loader, nil
+}
+
+// serveError logs a failure to build the response and returns it to the client
+func serveError(w http.ResponseWriter, err error) {
+	log.Printf("Error: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+// writeJSONResponse writes a JSON response body
+func writeJSONResponse(w http.ResponseWriter, data []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func getServed(t *testing.T, url string) (*http.Response, []byte) {
+	t.Helper()
+	resp, err := http.Get(url) // #nosec G107 - test server URL
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, body
+}
+
+func TestRegistryHandler(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "server1", "First server")
+	writeTestSpec(t, registryDir, "server2", "Second server")
+	writeDisabledSpec(t, registryDir, "disabled")
+
+	server := httptest.NewServer(newRegistryHandler(registryDir))
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantJSON    bool
+		wantServers []string
+		wantName    string
+	}{
+		{name: "registry", path: "/registry.json", wantStatus: http.StatusOK, wantJSON: true, wantServers: []string{"server1", "server2"}},
+		{name: "server", path: "/servers/server1", wantStatus: http.StatusOK, wantJSON: true, wantName: "server1"},
+		{name: "server with extension", path: "/servers/server2.json", wantStatus: http.StatusOK, wantJSON: true, wantName: "server2"},
+		{name: "unknown server", path: "/servers/missing", wantStatus: http.StatusNotFound},
+		{name: "disabled server", path: "/servers/disabled", wantStatus: http.StatusNotFound},
+		{name: "unknown path", path: "/catalog.json", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, body := getServed(t, server.URL+tt.path)
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			if !tt.wantJSON {
+				assert.NotEqual(t, "application/json", resp.Header.Get("Content-Type"))
+				return
+			}
+			assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			var output struct {
+				Name    string                     `json:"name"`
+				Servers map[string]json.RawMessage `json:"servers"`
+			}
+			require.NoError(t, json.Unmarshal(body, &output))
+			assert.Equal(t, tt.wantName, output.Name)
+			assert.ElementsMatch(t, tt.wantServers, jsonKeys(output.Servers))
+		})
+	}
+
+	resp, body := getServed(t, server.URL+"/servers/missing")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	assert.Contains(t, string(body), `no registry entry named "missing"`)
+}
+
+func jsonKeys(m map[string]json.RawMessage) []string {
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestRegistryHandler_RebuildsOnDemand(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "server1", "First server")
+
+	server := httptest.NewServer(newRegistryHandler(registryDir))
+	t.Cleanup(server.Close)
+
+	resp, _ := getServed(t, server.URL+"/servers/server2")
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	// A spec added while serving is picked up by the next request
+	writeTestSpec(t, registryDir, "server2", "Second server")
+	resp, body := getServed(t, server.URL+"/servers/server2")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), "Second server")
+
+	// A spec that breaks the build is reported rather than serving stale content
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "server2", "spec.yaml"), []byte("image: [unclosed\n"), 0644))
+	resp, body = getServed(t, server.URL+"/registry.json")
+	assert.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	assert.Contains(t, string(body), "failed to load registry entries")
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+// statsTopTags is the number of most common tags shown in the text output
+const statsTopTags = 10
+
+var statsFormat string
+
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Summarize the composition of the registry",
+	Long: `Load all registry entries and print how many there are by server type,
+tier, status, transport and tag, along with the total number of tools and the
+number of entries missing a repository URL or a license.
+
+Entries without a tier or status are counted as Community and Active. The text
+output shows the 10 most common tags; --format json includes every tag.
+--output is an alias of --format.`,
+	Example: `  # Print a summary
+  registry-builder stats
+
+  # Feed a dashboard
+  registry-builder stats --output json`,
+	Args: cobra.NoArgs,
+	RunE: runStats,
+}
+
+func init() {
+	statsCmd.Flags().StringVar(&statsFormat, "format", "text", "Output format (text, json)")
+	statsCmd.Flags().StringVar(&statsFormat, "output", "text", "Alias of --format")
+}
+
+func runStats(_ *cobra.Command, _ []string) error {
+	if statsFormat != "text" && statsFormat != "json" {
+		return fmt.Errorf("unknown format: %s", statsFormat)
+	}
+
+	loader := newLoader(registryPath)
+	if err := loader.LoadAll(); err != nil {
+		return fmt.Errorf("failed to load registry entries: %w", err)
+	}
+
+	return writeStats(os.Stdout, registry.ComputeStats(loader.GetEntries()), statsFormat)
+}
+
+// writeStats writes registry statistics in the given format
+func writeStats(w io.Writer, stats registry.RegistryStats, format string) error {
+	if format == "json" {
+		data, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal stats: %w", err)
+		}
+		fmt.Fprintln(w, string(data))
+		return nil
+	}
+
+	fmt.Fprintf(w, "Entries: %d (%d container, %d remote)\n", stats.Total, stats.Types["container"], stats.Types["remote"])
+	fmt.Fprintf(w, "Tools:   %d\n", stats.Tools)
+
+	breakdowns := []struct {
+		title  string
+		counts map[string]int
+		limit  int
+	}{
+		{title: "By tier", counts: stats.Tiers},
+		{title: "By status", counts: stats.Statuses},
+		{title: "By transport", counts: stats.Transports},
+		{title: fmt.Sprintf("Top tags (%d distinct)", len(stats.Tags)), counts: stats.Tags, limit: statsTopTags},
+	}
+	for _, breakdown := range breakdowns {
+		fmt.Fprintf(w, "\n%s:\n", breakdown.title)
+		counts := registry.SortedCounts(breakdown.counts)
+		if breakdown.limit > 0 && len(counts) > breakdown.limit {
+			counts = counts[:breakdown.limit]
+		}
+		for _, count := range counts {
+			fmt.Fprintf(w, "  %-20s %d\n", count.Value, count.Count)
+		}
+	}
+
+	fmt.Fprintf(w, "\nMissing repository URL: %d\n", stats.MissingRepositoryURL)
+	fmt.Fprintf(w, "Missing license:        %d\n", stats.MissingLicense)
+	return nil
+}
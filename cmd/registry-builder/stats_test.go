@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+func TestWriteStats(t *testing.T) {
+	t.Parallel()
+
+	tags := make(map[string]int)
+	for i := range statsTopTags + 2 {
+		tags[fmt.Sprintf("tag-%02d", i)] = i + 1
+	}
+	stats := registry.RegistryStats{
+		Total:                3,
+		Tools:                12,
+		Types:                map[string]int{"container": 2, "remote": 1},
+		Tiers:                map[string]int{"Official": 1, "Community": 2},
+		Statuses:             map[string]int{"Active": 3},
+		Transports:           map[string]int{"stdio": 2, "sse": 1},
+		Tags:                 tags,
+		MissingRepositoryURL: 1,
+		MissingLicense:       2,
+	}
+
+	var text bytes.Buffer
+	require.NoError(t, writeStats(&text, stats, "text"))
+	out := text.String()
+	assert.Contains(t, out, "Entries: 3 (2 container, 1 remote)")
+	assert.Contains(t, out, "Tools:   12")
+	assert.Contains(t, out, "Top tags (12 distinct):")
+	assert.Contains(t, out, "Missing repository URL: 1")
+	assert.Contains(t, out, "Missing license:        2")
+	// The most common tier comes first, and only the top tags are shown
+	assert.Less(t, strings.Index(out, "Community"), strings.Index(out, "Official"))
+	assert.Contains(t, out, "tag-11")
+	assert.NotContains(t, out, "tag-00")
+
+	var jsonOut bytes.Buffer
+	require.NoError(t, writeStats(&jsonOut, stats, "json"))
+	var decoded registry.RegistryStats
+	require.NoError(t, json.Unmarshal(jsonOut.Bytes(), &decoded))
+	assert.Equal(t, stats, decoded)
+}
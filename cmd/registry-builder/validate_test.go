@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+)
+
+func TestValidationReport(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "first", "First server")
+	writeTestSpec(t, registryDir, "second", "Second server")
+
+	report, err := validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 2, report.Total)
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, map[string]int{"container": 2, "remote": 0}, report.Types)
+	assert.Empty(t, report.Errors)
+
+	// A spec with an unknown transport fails on its own without hiding the others
+	dir := filepath.Join(registryDir, "bad-transport")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(`image: test/bad:1.0.0
+description: Bad server
+transport: carrier-pigeon
+tools:
+  - test_tool
+`), 0644))
+
+	report, err = validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Entries, 3)
+	assert.Equal(t, "bad-transport", report.Entries[0].Name)
+	assert.False(t, report.Entries[0].Valid)
+	assert.NotEmpty(t, report.Entries[0].Error)
+}
+
+func writeDisabledSpec(t *testing.T, registryDir, name string) {
+	t.Helper()
+	dir := filepath.Join(registryDir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(`image: test/`+name+`:1.0.0
+description: Disabled server
+transport: stdio
+tier: Community
+status: Active
+enabled: false
+tools:
+  - test_tool
+`), 0644))
+}
+
+func TestIncludeDisabledDefaults(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "published", "Published server")
+	writeDisabledSpec(t, registryDir, "disabled")
+
+	tests := []struct {
+		name        string
+		flagDefault string
+		wantEntries []string
+	}{
+		{
+			name:        "list",
+			flagDefault: listCmd.Flags().Lookup("include-disabled").DefValue,
+			wantEntries: []string{"published"},
+		},
+		{
+			name:        "validate",
+			flagDefault: validateCmd.Flags().Lookup("include-disabled").DefValue,
+			wantEntries: []string{"disabled", "published"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			loader, err := loadEntries(registryDir, tt.flagDefault == "true")
+			require.NoError(t, err)
+
+			var names []string
+			for _, entry := range loader.GetSortedEntries() {
+				names = append(names, entry.GetName())
+			}
+			assert.Equal(t, tt.wantEntries, names)
+		})
+	}
+}
+
+func TestValidationReport_Disabled(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "published", "Published server")
+	writeDisabledSpec(t, registryDir, "disabled")
+
+	report, err := validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Total)
+
+	report, err = validationReport(registryDir, false)
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Total)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "published", report.Entries[0].Name)
+}
+
+// TestEntryNamePolicy isn't parallel because --entry-name-from is a package global
+//
+//nolint:paralleltest
+func TestEntryNamePolicy(t *testing.T) {
+	oldFrom, oldPolicy := entryNameFrom, entryNamePolicy
+	t.Cleanup(func() { entryNameFrom, entryNamePolicy = oldFrom, oldPolicy })
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "unnamed", "Unnamed server")
+
+	entryNameFrom = "field"
+	require.NoError(t, parseEntryNamePolicy(nil, nil))
+	report, err := validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	require.Len(t, report.Entries, 1)
+	assert.Contains(t, report.Entries[0].Error, "name field is required")
+
+	entryNameFrom = "dir"
+	require.NoError(t, parseEntryNamePolicy(nil, nil))
+	report, err = validationReportWithWarnings(registryDir, true)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+
+	entryNameFrom = "folder"
+	assert.ErrorContains(t, parseEntryNamePolicy(nil, nil), "invalid --entry-name-from")
+}
+
+// TestValidationReport_ExampleSyntax isn't parallel because --validate-example-syntax is a package global
+//
+//nolint:paralleltest
+func TestValidationReport_ExampleSyntax(t *testing.T) {
+	oldValidate := validateExampleSyntax
+	t.Cleanup(func() { validateExampleSyntax = oldValidate })
+
+	registryDir := t.TempDir()
+	writeRawSpec(t, registryDir, "quoted", `image: test/quoted:1.0.0
+description: Server with a broken example
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - test_tool
+examples:
+  - name: Search
+    description: Search for a term
+    sample: thv run quoted --query 'term
+`)
+
+	// The check is off by default
+	validateExampleSyntax = false
+	report, err := validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.Empty(t, report.Errors)
+
+	validateExampleSyntax = true
+	report, err = validationReport(registryDir, true)
+	require.NoError(t, err)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], `quoted: example "Search": unbalanced single quote`)
+}
+
+// TestValidationReport_CacheDir isn't parallel because --cache-dir is a package global
+//
+//nolint:paralleltest
+func TestValidationReport_CacheDir(t *testing.T) {
+	oldCacheDir := validateCacheDir
+	t.Cleanup(func() { validateCacheDir = oldCacheDir })
+
+	registryDir := t.TempDir()
+	validateCacheDir = filepath.Join(t.TempDir(), "cache")
+	writeTestSpec(t, registryDir, "cached", "Cached server")
+
+	for range 2 {
+		report, err := validationReport(registryDir, true)
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+	}
+
+	data, err := os.ReadFile(filepath.Join(validateCacheDir, registry.ValidationCacheFile))
+	require.NoError(t, err)
+	var cache struct {
+		Schema  string                               `json:"schema"`
+		Entries map[string]registry.CachedValidation `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(data, &cache))
+	assert.Equal(t, registry.SchemaVersion(), cache.Schema)
+	require.Contains(t, cache.Entries, "cached")
+	assert.True(t, cache.Entries["cached"].Valid)
+}
+
+// TestValidationReport_Upstream isn't parallel because --upstream is a package global
+//
+//nolint:paralleltest
+func TestValidationReport_Upstream(t *testing.T) {
+	oldUpstream := validateUpstream
+	t.Cleanup(func() { validateUpstream = oldUpstream })
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "valid", "Valid server")
+	// Upstream server names are at most 200 characters
+	longName := strings.Repeat("a", 201)
+	writeTestSpec(t, registryDir, longName, "Server with a long name")
+
+	// The upstream schema is only checked with --upstream, and the result is in the JSON report
+	validateUpstream = false
+	report, err := validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+
+	validateUpstream = true
+	report, err = validationReport(registryDir, true)
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	require.Len(t, report.Errors, 1)
+	assert.Contains(t, report.Errors[0], "upstream schema validation failed for server '"+longName+"'")
+}
+
+func TestValidationReport_Named(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "first", "First server")
+	writeTestSpec(t, registryDir, "second", "Second server")
+	dir := filepath.Join(registryDir, "bad-transport")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(`image: test/bad:1.0.0
+description: Bad server
+transport: carrier-pigeon
+tools:
+  - test_tool
+`), 0644))
+
+	// Only the named entry is validated, so the invalid one doesn't fail it
+	report, err := validationReport(registryDir, true, "first")
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, 1, report.Total)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "first", report.Entries[0].Name)
+	assert.Equal(t, filepath.Join(registryDir, "first", "spec.yaml"), report.Entries[0].Path)
+
+	report, err = validationReport(registryDir, true, "bad-transport")
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+	assert.Equal(t, 1, report.Failed)
+	require.Len(t, report.Entries, 1)
+	assert.Equal(t, "bad-transport", report.Entries[0].Name)
+	assert.Equal(t, filepath.Join(dir, "spec.yaml"), report.Entries[0].Path)
+	assert.NotEmpty(t, report.Entries[0].Error)
+
+	_, err = validationReport(registryDir, true, "missing")
+	assert.ErrorIs(t, err, registry.ErrEntryNotFound)
+}
+
+// TestValidationReportWithWarnings isn't parallel because warnings are collected through a
+// package global and --strict-license is a flag variable
+//
+//nolint:paralleltest
+func TestValidationReportWithWarnings(t *testing.T) {
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "valid", "Valid server")
+	writeRawSpec(t, registryDir, "many-problems", `image: test/many-problems:1.0.0
+transport: carrier-pigeon
+license: not-a-license
+`)
+	writeRawSpec(t, registryDir, "remote-stdio", `url: https://example.com/mcp
+description: Remote server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - test_tool
+`)
+	writeRawSpec(t, registryDir, "unparsable", "image: [unclosed\n")
+
+	// With --strict-license, licenses are reported along with the other failing fields
+	validateStrictLicense = true
+	report, err := validationReportWithWarnings(registryDir, true)
+	validateStrictLicense = false
+	require.NoError(t, err)
+
+	// The diagnostics round-trip through the JSON report tooling reads
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	var parsed struct {
+		Entries []struct {
+			Name        string              `json:"name"`
+			Diagnostics []map[string]string `json:"diagnostics"`
+		} `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(data, &parsed))
+
+	byEntry := make(map[string][]string)
+	for _, entry := range parsed.Entries {
+		for _, diagnostic := range entry.Diagnostics {
+			assert.Equal(t, entry.Name, diagnostic["entry"])
+			assert.Equal(t, "error", diagnostic["severity"])
+			assert.NotEmpty(t, diagnostic["message"])
+			byEntry[entry.Name] = append(byEntry[entry.Name], diagnostic["field"])
+		}
+	}
+	assert.Equal(t, map[string][]string{
+		"many-problems": {"description", "transport", "tools", "license"},
+		"remote-stdio":  {"transport"},
+		"unparsable":    {""},
+	}, byEntry)
+	assert.NotContains(t, string(data), `"warnings"`)
+
+	// Warnings are reported once every entry is valid; without --strict-license that
+	// includes non-canonical licenses
+	registryDir = t.TempDir()
+	writeRawSpec(t, registryDir, "alias", `image: test/alias:1.0.0
+description: Alias server
+transport: Stdio
+tier: Community
+status: Active
+license: mit
+tools:
+  - test_tool
+`)
+	report, err = validationReportWithWarnings(registryDir, true)
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	assert.Equal(t, []registry.Diagnostic{
+		{
+			Entry: "alias", Field: "transport", Severity: registry.SeverityWarning,
+			Message: `alias: transport "Stdio" is not canonical (suggested: "stdio")`,
+		},
+		{
+			Entry: "alias", Field: "license", Severity: registry.SeverityWarning,
+			Message: `alias: license "mit" is not a valid SPDX identifier (suggested: "MIT")`,
+		},
+	}, report.Warnings)
+	assert.Nil(t, collectedWarnings)
+}
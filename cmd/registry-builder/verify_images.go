@@ -0,0 +1,87 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/progress"
+	"github.com/stacklok/toolhive-registry/pkg/registry"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+var verifyImagesTimeout time.Duration
+
+var verifyImagesCmd = &cobra.Command{
+	Use:   "verify-images [name...]",
+	Short: "Verify that images provide their declared platforms",
+	Long: `Verify that the image of every entry declaring platforms provides each of
+them, by reading the image's manifest list from its registry.
+
+Entries without a platforms field are skipped. If entry names are given,
+only those entries are verified.`,
+	Example: `  # Verify every entry that declares platforms
+  registry-builder verify-images
+
+  # Verify a single entry
+  registry-builder verify-images github`,
+	ValidArgsFunction: completeEntryNames,
+	RunE:              runVerifyImages,
+}
+
+func init() {
+	verifyImagesCmd.Flags().DurationVar(&verifyImagesTimeout, "timeout", 30*time.Second, "Timeout for each registry request")
+}
+
+func runVerifyImages(_ *cobra.Command, args []string) error {
+	loader, err := loadEntries(registryPath, true)
+	if err != nil {
+		return err
+	}
+
+	entries, err := filterEntriesByName(loader.GetSortedEntries(), args)
+	if err != nil {
+		return err
+	}
+
+	var toVerify []*types.RegistryEntry
+	for _, entry := range entries {
+		if entry.IsImage() && len(entry.Platforms) > 0 {
+			toVerify = append(toVerify, entry)
+		}
+	}
+
+	checker := registry.NewPlatformChecker(verifyImagesTimeout)
+	bar := progress.New(os.Stderr, len(toVerify))
+	ctx := context.Background()
+
+	var failed []string
+	for _, entry := range toVerify {
+		missing, err := checker.MissingPlatforms(ctx, entry)
+		bar.Clear()
+		switch {
+		case err != nil:
+			fmt.Printf("  ✗ %s: %v\n", entry.GetName(), err)
+			failed = append(failed, entry.GetName())
+		case len(missing) > 0:
+			fmt.Printf("  ✗ %s: %s does not provide %s\n", entry.GetName(), entry.Image, strings.Join(missing, ", "))
+			failed = append(failed, entry.GetName())
+		case verbose:
+			fmt.Printf("  ✓ %s: %s\n", entry.GetName(), strings.Join(entry.Platforms, ", "))
+		}
+		bar.Increment()
+	}
+	bar.Finish()
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d image(s) failed verification: %s", len(failed), len(toVerify), strings.Join(failed, ", "))
+	}
+
+	fmt.Printf("✓ Verified platforms of %d image(s), skipped %d entries without platforms\n",
+		len(toVerify), len(entries)-len(toVerify))
+	return nil
+}
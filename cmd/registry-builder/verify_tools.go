@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/stacklok/toolhive-registry/pkg/toolhive"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+var (
+	verifyToolsThvPath        string
+	verifyToolsStartupTimeout time.Duration
+	verifyToolsSecretsFromEnv bool
+	verifyToolsFailOnMismatch bool
+)
+
+var verifyToolsCmd = &cobra.Command{
+	Use:   "verify-tools [name...]",
+	Short: "Verify that servers expose the tools their specs declare",
+	Long: `Verify the tools list of every image-based entry against the live server, by
+running it with thv, listing its tools and stopping it again, as update-tools
+does. Spec files are never modified.
+
+Each entry is reported with the tools it declares that the server doesn't
+expose, and the tools the server exposes that it doesn't declare. Mismatches
+are only reported unless --fail-on-mismatch is set; entries whose server can't
+be run or lists no tools always fail the command.
+
+Remote servers are skipped. If entry names are given, only those entries are
+verified.`,
+	Example: `  # Report every image-based entry whose tools list is out of date
+  registry-builder verify-tools
+
+  # Fail CI if a tools list doesn't match its server
+  registry-builder verify-tools --fail-on-mismatch
+
+  # Verify a single entry, starting it with real secrets from the environment
+  registry-builder verify-tools github --secrets-from-env`,
+	ValidArgsFunction: completeEntryNames,
+	RunE:              runVerifyTools,
+}
+
+func init() {
+	verifyToolsCmd.Flags().StringVar(&verifyToolsThvPath, "thv-path", "", "Path to thv binary (defaults to searching PATH)")
+	verifyToolsCmd.Flags().DurationVar(&verifyToolsStartupTimeout, "startup-timeout", toolhive.DefaultStartupTimeout,
+		"How long to wait for a server to be running before listing its tools")
+	verifyToolsCmd.Flags().BoolVar(&verifyToolsSecretsFromEnv, "secrets-from-env", false,
+		"Use real secret values from the environment when running servers")
+	verifyToolsCmd.Flags().BoolVar(&verifyToolsFailOnMismatch, "fail-on-mismatch", false,
+		"Fail if any entry's declared tools don't match the tools its server exposes")
+}
+
+// toolFetcher lists the tools of the server a spec describes; *toolhive.Client implements it
+type toolFetcher interface {
+	FetchTools(spec *types.RegistryEntry, serverName string) ([]toolhive.Tool, error)
+}
+
+// toolsReport is the outcome of verifying the tools of a set of entries
+type toolsReport struct {
+	Verified   int
+	Mismatched []string
+	Failed     []string
+}
+
+func runVerifyTools(_ *cobra.Command, args []string) error {
+	loader, err := loadEntries(registryPath, true)
+	if err != nil {
+		return err
+	}
+
+	entries, err := filterEntriesByName(loader.GetSortedEntries(), args)
+	if err != nil {
+		return err
+	}
+
+	client, err := toolhive.NewClient(verifyToolsThvPath, verbose, toolhive.WithStartupTimeout(verifyToolsStartupTimeout))
+	if err != nil {
+		return fmt.Errorf("failed to create ToolHive client: %w", err)
+	}
+	runOptions := toolhive.RunCommandOptions{SecretPlaceholder: toolhive.DefaultSecretPlaceholder}
+	if verifyToolsSecretsFromEnv {
+		runOptions.LookupEnv = os.LookupEnv
+	}
+	client.SetRunOptions(runOptions)
+
+	report := verifyTools(os.Stdout, client, entries)
+
+	if len(report.Failed) > 0 {
+		return fmt.Errorf("failed to verify the tools of %d of %d server(s): %s",
+			len(report.Failed), report.Verified+len(report.Failed), strings.Join(report.Failed, ", "))
+	}
+	if len(report.Mismatched) > 0 && verifyToolsFailOnMismatch {
+		return fmt.Errorf("%d of %d server(s) don't expose the tools they declare: %s",
+			len(report.Mismatched), report.Verified, strings.Join(report.Mismatched, ", "))
+	}
+
+	fmt.Printf("✓ Verified the tools of %d server(s), %d mismatched\n", report.Verified, len(report.Mismatched))
+	return nil
+}
+
+// verifyTools fetches the tools of each image-based entry and writes the mismatches with
+// its declared tools to w. Remote entries are skipped.
+func verifyTools(w io.Writer, fetcher toolFetcher, entries []*types.RegistryEntry) toolsReport {
+	var report toolsReport
+	for _, entry := range entries {
+		if !entry.IsImage() {
+			continue
+		}
+
+		name := entry.GetName()
+		tools, err := fetcher.FetchTools(entry, name)
+		if err == nil && len(tools) == 0 {
+			err = fmt.Errorf("the server listed no tools")
+		}
+		if err != nil {
+			fmt.Fprintf(w, "  ✗ %s: %v\n", name, err)
+			report.Failed = append(report.Failed, name)
+			continue
+		}
+		report.Verified++
+
+		missing, undeclared := compareTools(entry.GetTools(), toolhive.ToolNames(tools))
+		if len(missing) == 0 && len(undeclared) == 0 {
+			if verbose {
+				fmt.Fprintf(w, "  ✓ %s: %d tools\n", name, len(tools))
+			}
+			continue
+		}
+
+		report.Mismatched = append(report.Mismatched, name)
+		fmt.Fprintf(w, "  ✗ %s:\n", name)
+		if len(missing) > 0 {
+			fmt.Fprintf(w, "      declared but missing: %s\n", strings.Join(missing, ", "))
+		}
+		if len(undeclared) > 0 {
+			fmt.Fprintf(w, "      present but undeclared: %s\n", strings.Join(undeclared, ", "))
+		}
+	}
+	return report
+}
+
+// compareTools returns the declared tools the server doesn't expose and the tools it
+// exposes that aren't declared, each sorted
+func compareTools(declared, exposed []string) (missing, undeclared []string) {
+	for _, tool := range declared {
+		if !slices.Contains(exposed, tool) {
+			missing = append(missing, tool)
+		}
+	}
+	for _, tool := range exposed {
+		if !slices.Contains(declared, tool) {
+			undeclared = append(undeclared, tool)
+		}
+	}
+	slices.Sort(missing)
+	slices.Sort(undeclared)
+	return missing, undeclared
+}
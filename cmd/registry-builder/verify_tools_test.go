@@ -0,0 +1,93 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/toolhive"
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// fakeToolFetcher stands in for thv, returning a known tool set for each server
+type fakeToolFetcher struct {
+	tools   map[string][]string
+	fetched []string
+}
+
+func (f *fakeToolFetcher) FetchTools(_ *types.RegistryEntry, serverName string) ([]toolhive.Tool, error) {
+	f.fetched = append(f.fetched, serverName)
+	names, ok := f.tools[serverName]
+	if !ok {
+		return nil, errors.New("failed to run server: image not found")
+	}
+	tools := make([]toolhive.Tool, 0, len(names))
+	for _, name := range names {
+		tools = append(tools, toolhive.Tool{Name: name})
+	}
+	return tools, nil
+}
+
+func TestVerifyTools(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeTestSpec(t, registryDir, "matching", "Matching server")
+	writeTestSpec(t, registryDir, "mismatched", "Mismatched server")
+	writeTestSpec(t, registryDir, "broken", "Broken server")
+	writeTestSpec(t, registryDir, "empty", "Server without tools")
+	writeRawSpec(t, registryDir, "remote", `url: https://api.example.com/mcp
+description: Remote server
+transport: streamable-http
+tier: Community
+status: Active
+tools:
+  - test_tool
+`)
+
+	fetcher := &fakeToolFetcher{tools: map[string][]string{
+		"matching":   {"test_tool"},
+		"mismatched": {"new_tool", "other_tool"},
+		"empty":      {},
+	}}
+	var out bytes.Buffer
+	report := verifyTools(&out, fetcher, loadTestRegistry(t, registryDir).GetSortedEntries())
+
+	assert.Equal(t, toolsReport{Verified: 2, Mismatched: []string{"mismatched"}, Failed: []string{"broken", "empty"}}, report)
+	// Remote servers aren't run
+	assert.NotContains(t, fetcher.fetched, "remote")
+	assert.Equal(t, `  ✗ broken: failed to run server: image not found
+  ✗ empty: the server listed no tools
+  ✗ mismatched:
+      declared but missing: test_tool
+      present but undeclared: new_tool, other_tool
+`, out.String())
+}
+
+func TestCompareTools(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		declared       []string
+		exposed        []string
+		wantMissing    []string
+		wantUndeclared []string
+	}{
+		{name: "same tools in another order", declared: []string{"b", "a"}, exposed: []string{"a", "b"}},
+		{name: "missing", declared: []string{"a", "c", "b"}, exposed: []string{"a"}, wantMissing: []string{"b", "c"}},
+		{name: "undeclared", declared: []string{"a"}, exposed: []string{"a", "z", "y"}, wantUndeclared: []string{"y", "z"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			missing, undeclared := compareTools(tt.declared, tt.exposed)
+			assert.Equal(t, tt.wantMissing, missing)
+			assert.Equal(t, tt.wantUndeclared, undeclared)
+		})
+	}
+}
@@ -2,56 +2,114 @@
 package main
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/stacklok/toolhive/pkg/container/verifier"
 	"github.com/stacklok/toolhive/pkg/logger"
-	"github.com/stacklok/toolhive/pkg/registry"
-	"gopkg.in/yaml.v3"
 
-	"github.com/stacklok/toolhive-registry/pkg/types"
+	"github.com/stacklok/toolhive-registry/pkg/metadata"
 )
 
 var (
-	specPath         string
 	dryRun           bool
 	githubToken      string
 	verifyProvenance bool
+	skipPullHosts    []string
+	recordSources    bool
+	retries          int
+	waitForRateLimit bool
+	latestRelease    bool
+	concurrency      int
+	httpTimeout      time.Duration
+	githubBaseURL    string
+	gitLabHosts      []string
+	extendedMetadata bool
+	failOnStale      time.Duration
 )
 
-type serverWithName struct {
-	name  string
-	path  string
-	entry *types.RegistryEntry
-}
-
-// ProvenanceVerificationError represents an error during provenance verification
-type ProvenanceVerificationError struct {
-	ServerName string
-	Reason     string
-}
-
-func (e *ProvenanceVerificationError) Error() string {
-	return fmt.Sprintf("provenance verification failed for server %s: %s", e.ServerName, e.Reason)
-}
+// defaultConcurrency is how many spec files are updated at a time in directory mode by default
+const defaultConcurrency = 4
 
 var rootCmd = &cobra.Command{
-	Use:   "regup [spec-file]",
-	Short: "Update a single MCP server registry entry with latest information",
+	Use:   "regup <spec-file | directory>",
+	Short: "Update MCP server registry entries with latest information",
 	Long: `regup is a utility for updating a single MCP server registry entry with the latest information.
-It updates the GitHub stars and pulls data for the specified spec.yaml file.
-This tool is designed to be run by Renovate when updating image versions.`,
+It updates the GitHub stars and pulls data for the specified spec.yaml file. With
+--latest-release, it also records the tag of the latest GitHub release of the
+repository (the stars_source if set) in metadata.latest_release, removing it if
+the repository has no releases.
+This tool is designed to be run by Renovate when updating image versions.
+
+Given a directory instead, regup updates every */spec.yaml under it, running up to
+--concurrency updates (and so GitHub and registry requests) at a time. A failing
+entry doesn't stop the others; the failures are listed in a summary at the end and
+regup exits with an error.
+
+Pull counts are fetched from Docker Hub, Quay.io and the ECR Public gallery.
+Private ECR registries have no pull counts, and other registries are reported as
+unknown; list private or internal registries with --skip-pull-hosts to skip
+them quietly instead.
+
+With --verify-provenance, image signatures are verified before updating, and
+remote servers with a provenance block must publish the same provenance at
+/.well-known/mcp-provenance.json on their host. A successful verification is
+recorded in metadata.provenance_verified and metadata.provenance_verified_at;
+without --verify-provenance, both are removed.
+
+With --extended-metadata, the repository's forks and open issues counts are
+also written to metadata.forks and metadata.open_issues, as extra signal for
+ranking servers. Without it, those fields are left as they are.
+
+With --record-sources, the API URL and fetch time of each fetched value are
+written to a metadata.sources block next to the values, for auditing.
+
+GitHub API requests that fail with a server error or a network error are
+retried with exponential backoff, up to --retries attempts. Client errors
+such as a missing repository are never retried. Once the GitHub rate limit
+is exhausted, the current stars are kept, or with --wait-for-rate-limit regup
+sleeps until the limit resets. Each request may take up to --http-timeout;
+raise it on slow networks where requests time out and updates are lost.
+
+Repositories hosted on GitHub Enterprise are queried through --github-base-url,
+the API URL of the instance (https://github.example.com/api/v3). Stars of
+repositories on gitlab.com are fetched from the GitLab API instead; list
+self-hosted GitLab instances with --gitlab-hosts to do the same for them.
+
+In CI, --fail-on-stale catches entries whose upstream may have gone away: regup
+exits with an error if an entry's metadata.last_updated is older than the given
+duration (or missing) and the refresh didn't change anything.
+
+To refresh many entries at once, use 'registry-builder refresh-metadata'.`,
+	Example: `  # Update an entry
+  regup registry/fetch/spec.yaml
+
+  # Skip pull counts for an internal registry without warnings
+  regup registry/internal/spec.yaml --skip-pull-hosts registry.internal.example.com
+
+  # Also record the tag of the repository's latest release
+  regup registry/fetch/spec.yaml --latest-release
+
+  # Record where and when the stars and pulls were fetched
+  regup registry/fetch/spec.yaml --record-sources
+
+  # Update an entry whose repository is on GitHub Enterprise
+  regup registry/internal/spec.yaml --github-base-url https://github.example.com/api/v3
+
+  # Update an entry whose repository is on a self-hosted GitLab instance
+  regup registry/internal/spec.yaml --gitlab-hosts gitlab.example.com
+
+  # Update every entry of the registry, eight at a time
+  regup registry --concurrency 8
+
+  # Fail for entries that haven't changed in 90 days
+  regup registry --dry-run --fail-on-stale 2160h`,
 	Args: cobra.ExactArgs(1),
 	RunE: runUpdate,
 }
@@ -65,6 +123,30 @@ func init() {
 		"GitHub token for API authentication (can also be set via GITHUB_TOKEN env var)")
 	rootCmd.Flags().BoolVar(&verifyProvenance, "verify-provenance", false,
 		"Verify provenance information and fail if verification fails")
+	rootCmd.Flags().StringSliceVar(&skipPullHosts, "skip-pull-hosts", nil,
+		"Registry hosts to skip pull counts for without warning (repeatable, *.example.com matches subdomains)")
+	rootCmd.Flags().BoolVar(&recordSources, "record-sources", false,
+		"Record the API URL and fetch time of each value in metadata.sources")
+	rootCmd.Flags().IntVar(&retries, "retries", metadata.DefaultMaxAttempts,
+		"Maximum attempts for GitHub API requests that fail with a server or network error (1 disables retries)")
+	rootCmd.Flags().BoolVar(&waitForRateLimit, "wait-for-rate-limit", false,
+		"Sleep until the GitHub API rate limit resets instead of keeping the current stars")
+	rootCmd.Flags().BoolVar(&latestRelease, "latest-release", false,
+		"Record the tag of the repository's latest GitHub release in metadata.latest_release")
+	rootCmd.Flags().BoolVar(&extendedMetadata, "extended-metadata", false,
+		"Also record the repository's forks and open issues counts in metadata.forks and metadata.open_issues")
+	rootCmd.Flags().IntVar(&concurrency, "concurrency", defaultConcurrency,
+		"Number of spec files updated at a time when given a directory")
+	rootCmd.Flags().StringVar(&githubBaseURL, "github-base-url", "",
+		"GitHub API base URL, e.g. for GitHub Enterprise (can also be set via GITHUB_API_URL env var; "+
+			"defaults to "+metadata.DefaultGitHubAPIURL+")")
+	rootCmd.Flags().StringSliceVar(&gitLabHosts, "gitlab-hosts", nil,
+		"Hosts of self-hosted GitLab instances whose repositories get stars from the GitLab API "+
+			"("+metadata.DefaultGitLabHost+" always does)")
+	rootCmd.Flags().DurationVar(&failOnStale, "fail-on-stale", 0,
+		"Fail if an entry's metadata was last updated longer ago than this and nothing changed (0 disables)")
+	rootCmd.Flags().DurationVar(&httpTimeout, "http-timeout", metadata.DefaultHTTPTimeout,
+		"How long a single GitHub or container registry API request may take")
 }
 
 func main() {
@@ -75,582 +157,186 @@ func main() {
 }
 
 func runUpdate(_ *cobra.Command, args []string) error {
-	specPath = args[0]
+	if retries < 1 {
+		return fmt.Errorf("--retries must be at least 1, got %d", retries)
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1, got %d", concurrency)
+	}
+	if failOnStale < 0 {
+		return fmt.Errorf("--fail-on-stale must not be negative, got %s", failOnStale)
+	}
+	if httpTimeout <= 0 {
+		return fmt.Errorf("--http-timeout must be positive, got %s", httpTimeout)
+	}
 
 	// If token not provided via flag, check environment variable
 	if githubToken == "" {
 		githubToken = os.Getenv("GITHUB_TOKEN")
 	}
+	if githubBaseURL == "" {
+		githubBaseURL = os.Getenv("GITHUB_API_URL")
+	}
 
-	// Load the single spec file
-	server, err := loadSpec(specPath)
-	if err != nil {
-		return fmt.Errorf("failed to load spec file: %w", err)
+	updater := metadata.NewUpdater(metadata.Options{
+		GitHubToken:      githubToken,
+		DryRun:           dryRun,
+		VerifyProvenance: verifyProvenance,
+		SkipPullHosts:    skipPullHosts,
+		RecordSources:    recordSources,
+		MaxAttempts:      retries,
+		WaitForRateLimit: waitForRateLimit,
+		LatestRelease:    latestRelease,
+		ExtendedMetadata: extendedMetadata,
+		HTTPTimeout:      httpTimeout,
+		GitHubAPIURL:     githubBaseURL,
+		GitLabHosts:      gitLabHosts,
+	})
+
+	if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+		return runUpdateDir(updater, args[0])
 	}
 
-	// Update the server
-	if err := updateServerInfo(server); err != nil {
-		var provenanceErr *ProvenanceVerificationError
+	result, err := updater.UpdateSpec(context.Background(), args[0])
+	if err != nil {
+		var provenanceErr *metadata.ProvenanceVerificationError
 		if errors.As(err, &provenanceErr) {
 			return fmt.Errorf("provenance verification failed: %w", err)
 		}
 		return fmt.Errorf("failed to update server: %w", err)
 	}
 
+	logResult(result)
 	if dryRun {
 		logger.Info("Dry run completed, no changes made")
 	} else {
-		logger.Infof("Successfully updated %s", server.name)
-	}
-
-	return nil
-}
-
-func loadSpec(path string) (serverWithName, error) {
-	// Check if file exists
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return serverWithName{}, fmt.Errorf("spec file not found: %s", path)
-	}
-
-	// Read the spec file
-	data, err := os.ReadFile(path) // #nosec G304 - file path is constructed from known directory
-	if err != nil {
-		return serverWithName{}, fmt.Errorf("failed to read spec file: %w", err)
-	}
-
-	// Parse YAML into our RegistryEntry type
-	var entry types.RegistryEntry
-	if err := yaml.Unmarshal(data, &entry); err != nil {
-		return serverWithName{}, fmt.Errorf("failed to parse YAML: %w", err)
-	}
-
-	// Extract server name from path (parent directory name)
-	dir := filepath.Dir(path)
-	name := filepath.Base(dir)
-
-	// Set the name if not already set
-	if entry.GetName() == "" {
-		entry.SetName(name)
+		logger.Infof("Successfully updated %s", result.Name)
 	}
 
-	return serverWithName{
-		name:  name,
-		path:  path,
-		entry: &entry,
-	}, nil
+	return checkStale([]metadata.Result{result}, failOnStale, time.Now())
 }
 
-func updateServerInfo(server serverWithName) error {
-	// Verify provenance if requested
-	if verifyProvenance {
-		if err := verifyServerProvenance(server); err != nil {
-			return &ProvenanceVerificationError{
-				ServerName: server.name,
-				Reason:     err.Error(),
-			}
-		}
-	}
-
-	repoURL, metadata, err := getServerMetadata(server)
+// runUpdateDir updates every spec file under dir and logs a summary
+func runUpdateDir(updater *metadata.Updater, dir string) error {
+	results, err := updateDir(context.Background(), updater, dir, concurrency)
 	if err != nil {
 		return err
 	}
 
-	currentStars := metadata.Stars
-	currentPulls := metadata.Pulls
-
-	newStars := getUpdatedStars(repoURL, currentStars, server.name)
-	newPulls := getUpdatedPulls(server, currentPulls)
-
-	return updateServerMetadata(server, currentStars, newStars, currentPulls, newPulls)
-}
-
-func getServerMetadata(server serverWithName) (string, *registry.Metadata, error) {
-	var repoURL string
-	var metadata *registry.Metadata
-
-	if server.entry.IsImage() && server.entry.ImageMetadata != nil {
-		repoURL = server.entry.ImageMetadata.RepositoryURL
-		if server.entry.ImageMetadata.Metadata == nil {
-			server.entry.ImageMetadata.Metadata = &registry.Metadata{}
+	var failures []specResult
+	changed := 0
+	for _, result := range results {
+		if result.err != nil {
+			failures = append(failures, result)
+			continue
 		}
-		metadata = server.entry.ImageMetadata.Metadata
-	} else if server.entry.IsRemote() && server.entry.RemoteServerMetadata != nil {
-		repoURL = server.entry.RemoteServerMetadata.RepositoryURL
-		if server.entry.RemoteServerMetadata.Metadata == nil {
-			server.entry.RemoteServerMetadata.Metadata = &registry.Metadata{}
+		logResult(result.result)
+		if result.result.Changed() {
+			changed++
 		}
-		metadata = server.entry.RemoteServerMetadata.Metadata
-	} else {
-		return "", nil, fmt.Errorf("unable to determine server type for %s", server.name)
-	}
-
-	if repoURL == "" {
-		logger.Warnf("Server %s has no repository URL, skipping GitHub stars update", server.name)
-	}
-
-	return repoURL, metadata, nil
-}
-
-func getUpdatedStars(repoURL string, currentStars int, serverName string) int {
-	if repoURL == "" {
-		return currentStars
-	}
-
-	owner, repo, err := extractOwnerRepo(repoURL)
-	if err != nil {
-		logger.Warnf("Failed to extract owner/repo from URL %s: %v", repoURL, err)
-		return currentStars
-	}
-
-	// Get repository info from GitHub API
-	stars, _, err := getGitHubRepoInfo(owner, repo, serverName, currentStars)
-	if err != nil {
-		logger.Warnf("Failed to get GitHub repo info for %s: %v", serverName, err)
-		return currentStars
 	}
 
-	return stars
-}
-
-func getUpdatedPulls(server serverWithName, currentPulls int) int {
-	if !server.entry.IsImage() || server.entry.ImageMetadata == nil || server.entry.Image == "" {
-		return currentPulls
-	}
-
-	pullCount, err := getContainerPullCount(server.entry.Image)
-	if err != nil {
-		logger.Warnf("Failed to get pull count for image %s: %v", server.entry.Image, err)
-		return currentPulls
-	}
-
-	if pullCount > 0 {
-		return pullCount
-	}
-
-	// No pull count available (GHCR or private registry)
-	return currentPulls
-}
-
-func updateServerMetadata(server serverWithName, currentStars, newStars, currentPulls, newPulls int) error {
+	updated := len(results) - len(failures)
 	if dryRun {
-		logger.Infof("[DRY RUN] Would update %s: stars %d -> %d, pulls %d -> %d",
-			server.name, currentStars, newStars, currentPulls, newPulls)
-		return nil
-	}
-
-	// Log the changes
-	logger.Infof("Updating %s: stars %d -> %d, pulls %d -> %d",
-		server.name, currentStars, newStars, currentPulls, newPulls)
-
-	// Use yaml.v3 Node API to preserve comments and structure
-	return updateYAMLPreservingStructure(server.path, newStars, newPulls)
-}
-
-// updateYAMLPreservingStructure updates the YAML file while preserving comments and structure
-func updateYAMLPreservingStructure(path string, stars, pulls int) error {
-	// Read the original file
-	data, err := os.ReadFile(path) // #nosec G304 - file path is constructed from known directory
-	if err != nil {
-		return fmt.Errorf("failed to read file: %w", err)
-	}
-
-	// Parse with yaml.v3 to preserve structure
-	var doc yaml.Node
-	if err := yaml.Unmarshal(data, &doc); err != nil {
-		return fmt.Errorf("failed to parse YAML: %w", err)
-	}
-
-	// Update the metadata fields
-	if err := updateMetadataInNode(&doc, stars, pulls); err != nil {
-		return fmt.Errorf("failed to update metadata: %w", err)
-	}
-
-	// Marshal back preserving structure
-	var buf bytes.Buffer
-	encoder := yaml.NewEncoder(&buf)
-	encoder.SetIndent(2)
-	if err := encoder.Encode(&doc); err != nil {
-		return fmt.Errorf("failed to encode YAML: %w", err)
-	}
-
-	// Write back to file
-	return os.WriteFile(path, buf.Bytes(), 0600)
-}
-
-// updateMetadataInNode updates metadata fields in the YAML node tree
-func updateMetadataInNode(node *yaml.Node, stars, pulls int) error {
-	// Navigate to the document content
-	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
-		return updateMetadataInNode(node.Content[0], stars, pulls)
-	}
-
-	if node.Kind != yaml.MappingNode {
-		return fmt.Errorf("expected mapping node, got %v", node.Kind)
+		logger.Infof("[DRY RUN] Would update %d of %d entries with new values", changed, updated)
+	} else {
+		logger.Infof("Updated %d entries, %d with new values", updated, changed)
 	}
-
-	// Find or create metadata section
-	metadataIndex := -1
-	for i := 0; i < len(node.Content); i += 2 {
-		if node.Content[i].Value == "metadata" {
-			metadataIndex = i
-			break
+	if len(failures) == 0 {
+		var updatedResults []metadata.Result
+		for _, result := range results {
+			updatedResults = append(updatedResults, result.result)
 		}
+		return checkStale(updatedResults, failOnStale, time.Now())
 	}
 
-	now := time.Now().UTC().Format(time.RFC3339)
-
-	if metadataIndex >= 0 {
-		// Update existing metadata
-		metadataNode := node.Content[metadataIndex+1]
-		if metadataNode.Kind != yaml.MappingNode {
-			return fmt.Errorf("metadata is not a mapping")
-		}
-
-		// Update or add fields
-		updated := map[string]bool{
-			"stars":        false,
-			"pulls":        false,
-			"last_updated": false,
-		}
-
-		for i := 0; i < len(metadataNode.Content); i += 2 {
-			key := metadataNode.Content[i].Value
-			switch key {
-			case "stars":
-				metadataNode.Content[i+1].Value = fmt.Sprintf("%d", stars)
-				updated["stars"] = true
-			case "pulls":
-				metadataNode.Content[i+1].Value = fmt.Sprintf("%d", pulls)
-				updated["pulls"] = true
-			case "last_updated":
-				metadataNode.Content[i+1].Value = now
-				updated["last_updated"] = true
-			}
-		}
-
-		// Add missing fields
-		if !updated["stars"] {
-			metadataNode.Content = append(metadataNode.Content,
-				&yaml.Node{Kind: yaml.ScalarNode, Value: "stars"},
-				&yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", stars)})
-		}
-		if !updated["pulls"] {
-			metadataNode.Content = append(metadataNode.Content,
-				&yaml.Node{Kind: yaml.ScalarNode, Value: "pulls"},
-				&yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", pulls)})
-		}
-		if !updated["last_updated"] {
-			metadataNode.Content = append(metadataNode.Content,
-				&yaml.Node{Kind: yaml.ScalarNode, Value: "last_updated"},
-				&yaml.Node{Kind: yaml.ScalarNode, Value: now})
-		}
-	} else {
-		// Add new metadata section
-		metadataKey := &yaml.Node{Kind: yaml.ScalarNode, Value: "metadata"}
-		metadataValue := &yaml.Node{
-			Kind: yaml.MappingNode,
-			Content: []*yaml.Node{
-				{Kind: yaml.ScalarNode, Value: "stars"},
-				{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", stars)},
-				{Kind: yaml.ScalarNode, Value: "pulls"},
-				{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", pulls)},
-				{Kind: yaml.ScalarNode, Value: "last_updated"},
-				{Kind: yaml.ScalarNode, Value: now},
-			},
-		}
-		node.Content = append(node.Content, metadataKey, metadataValue)
+	for _, failure := range failures {
+		logger.Errorf("  %s: %v", failure.path, failure.err)
 	}
-
-	return nil
+	return fmt.Errorf("failed to update %d of %d entries", len(failures), len(results))
 }
 
-// verifyServerProvenance verifies the provenance information for a server
-func verifyServerProvenance(server serverWithName) error {
-	// Check if provenance information exists
-	if server.entry.Provenance == nil {
-		logger.Warnf("Server %s has no provenance information, skipping verification", server.name)
-		return nil
-	}
-
-	// Get image reference
-	if server.entry.Image == "" {
-		return fmt.Errorf("no image reference provided")
-	}
-
-	logger.Infof("Verifying provenance for server %s with image %s", server.name, server.entry.Image)
-
-	// The entry already has ImageMetadata embedded, so we can use it directly
-	v, err := verifier.New(server.entry.ImageMetadata)
-	if err != nil {
-		return fmt.Errorf("failed to create verifier: %w", err)
-	}
-
-	// Get verification results
-	isVerified, err := v.VerifyServer(server.entry.Image, server.entry.ImageMetadata)
-	if err != nil {
-		return fmt.Errorf("verification failed: %w", err)
-	}
-
-	// Check if we have valid verification results
-	if isVerified {
-		logger.Infof("Server %s verified successfully", server.name)
+// checkStale returns an error listing the results that are stale at now, if maxAge is set
+func checkStale(results []metadata.Result, maxAge time.Duration, now time.Time) error {
+	if maxAge <= 0 {
 		return nil
 	}
 
-	return fmt.Errorf("no verified signatures found")
-}
-
-// extractOwnerRepo extracts the owner and repo from a GitHub repository URL
-func extractOwnerRepo(url string) (string, string, error) {
-	// Remove trailing .git if present
-	url = strings.TrimSuffix(url, ".git")
-
-	// Handle different GitHub URL formats
-	parts := strings.Split(url, "/")
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid GitHub URL format: %s", url)
-	}
-
-	// The owner and repo should be the last two parts
-	owner := parts[len(parts)-2]
-	repo := parts[len(parts)-1]
-
-	return owner, repo, nil
-}
-
-// getGitHubRepoInfo gets the stars count for a GitHub repository
-func getGitHubRepoInfo(owner, repo, _ string, currentPulls int) (stars int, pulls int, err error) {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	// Create request
-	url := fmt.Sprintf("https://api.github.com/repos/%s/%s", owner, repo)
-	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Add headers
-	req.Header.Add("Accept", "application/vnd.github.v3+json")
-	if githubToken != "" {
-		req.Header.Add("Authorization", "token "+githubToken)
-	}
-
-	// Send request
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, 0, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return 0, 0, fmt.Errorf("GitHub API returned %s: %s", resp.Status, string(body))
-	}
-
-	// Parse response
-	var repoInfo struct {
-		StargazersCount int `json:"stargazers_count"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&repoInfo); err != nil {
-		return 0, 0, fmt.Errorf("failed to parse response: %w", err)
-	}
-
-	// Return current pulls - we'll fetch container pulls separately
-	return repoInfo.StargazersCount, currentPulls, nil
-}
-
-// getContainerPullCount fetches the pull count for a container image
-func getContainerPullCount(image string) (int, error) {
-	// Parse the image reference
-	parts := strings.Split(image, ":")
-	if len(parts) < 1 {
-		return 0, fmt.Errorf("invalid image format: %s", image)
-	}
-
-	imageName := parts[0]
-
-	// Determine registry and fetch accordingly
-	if strings.HasPrefix(imageName, "ghcr.io/") {
-		return getGHCRPullCount(imageName)
-	} else if strings.Contains(imageName, "/") && !strings.Contains(imageName, ".") {
-		// Likely Docker Hub (no dots in the hostname part)
-		return getDockerHubPullCount(imageName)
-	}
-
-	// Unknown registry, return 0
-	logger.Warnf("Unknown registry for image %s, cannot fetch pull count", image)
-	return 0, nil
-}
-
-// getGHCRPullCount fetches pull count for GitHub Container Registry images
-func getGHCRPullCount(imageName string) (int, error) {
-	// GHCR requires authentication to get package statistics
-	if githubToken == "" {
-		logger.Debugf("No GitHub token available, cannot fetch GHCR pull count for %s", imageName)
-		return 0, nil
-	}
-
-	owner, packageName, err := parseGHCRImageName(imageName)
-	if err != nil {
-		return 0, err
-	}
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	url, err := fetchGHCRPackageInfo(client, owner, packageName)
-	if err != nil {
-		return 0, err
-	}
-
-	return fetchGHCRVersions(client, url, imageName)
-}
-
-func parseGHCRImageName(imageName string) (string, string, error) {
-	// Parse the image name: ghcr.io/owner/repo/package or ghcr.io/owner/package
-	imageName = strings.TrimPrefix(imageName, "ghcr.io/")
-	parts := strings.Split(imageName, "/")
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("invalid GHCR image format: %s", imageName)
-	}
-
-	owner := parts[0]
-	// The package name is everything after the owner
-	packageName := strings.Join(parts[1:], "/")
-	return owner, packageName, nil
-}
-
-func fetchGHCRPackageInfo(client *http.Client, owner, packageName string) (string, error) {
-	// GitHub Packages API endpoint for container packages
-	url := fmt.Sprintf("https://api.github.com/users/%s/packages/container/%s", owner, packageName)
-
-	resp, err := makeGHCRRequest(client, url)
-	if err != nil {
-		// Try org endpoint if user endpoint fails
-		url = fmt.Sprintf("https://api.github.com/orgs/%s/packages/container/%s", owner, packageName)
-		resp, err = makeGHCRRequest(client, url)
-		if err != nil {
-			return "", err
+	var stale []string
+	for _, result := range results {
+		if !result.Stale(now, maxAge) {
+			continue
 		}
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode == http.StatusNotFound && strings.Contains(url, "/users/") {
-		// Try org endpoint if user endpoint returned 404
-		url = strings.Replace(url, "/users/", "/orgs/", 1)
-		resp, err = makeGHCRRequest(client, url)
-		if err != nil {
-			return "", err
+		lastUpdated := result.OldLastUpdated
+		if lastUpdated == "" {
+			lastUpdated = "never"
 		}
-		defer resp.Body.Close()
-	}
-
-	if resp.StatusCode != http.StatusOK {
-		logger.Debugf("Could not fetch GHCR package stats (status %d)", resp.StatusCode)
-		return "", fmt.Errorf("package not found or no access")
+		logger.Errorf("  %s: nothing changed, metadata last updated %s", result.Name, lastUpdated)
+		stale = append(stale, result.Name)
 	}
 
-	var packageInfo struct {
-		ID   int    `json:"id"`
-		Name string `json:"name"`
-	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&packageInfo); err != nil {
-		return "", fmt.Errorf("failed to parse response: %w", err)
+	if len(stale) == 0 {
+		return nil
 	}
-
-	return url, nil
+	return fmt.Errorf("%d of %d entries have stale metadata (older than %s): %s",
+		len(stale), len(results), maxAge, strings.Join(stale, ", "))
 }
 
-func makeGHCRRequest(client *http.Client, url string) (*http.Response, error) {
-	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	req.Header.Add("Accept", "application/vnd.github.v3+json")
-	req.Header.Add("Authorization", "token "+githubToken)
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %w", err)
-	}
-
-	return resp, nil
+// specResult is the outcome of updating one spec file in directory mode
+type specResult struct {
+	path   string
+	result metadata.Result
+	err    error
 }
 
-func fetchGHCRVersions(client *http.Client, baseURL, imageName string) (int, error) {
-	versionsURL := fmt.Sprintf("%s/versions?per_page=100", baseURL)
-	resp, err := makeGHCRRequest(client, versionsURL)
+// updateDir updates every */spec.yaml under dir with up to concurrency updates at a time.
+// Every spec file is attempted even if others fail; the results are in path order.
+func updateDir(ctx context.Context, updater *metadata.Updater, dir string, concurrency int) ([]specResult, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "spec.yaml"))
 	if err != nil {
-		return 0, fmt.Errorf("failed to create versions request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		logger.Debugf("Could not fetch GHCR package versions (status %d) for %s", resp.StatusCode, imageName)
-		return 0, nil
-	}
-
-	var versions []struct {
-		Metadata struct {
-			Container struct {
-				Tags []string `json:"tags"`
-			} `json:"container"`
-		} `json:"metadata"`
-		// Unfortunately, GitHub API doesn't expose download_count for container packages
-		// in the same way it does for other package types
+		return nil, fmt.Errorf("failed to list spec files: %w", err)
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no */spec.yaml files found in %s", dir)
+	}
+
+	results := make([]specResult, len(paths))
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, len(paths)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				result, err := updater.UpdateSpec(ctx, paths[i])
+				results[i] = specResult{path: paths[i], result: result, err: err}
+			}
+		}()
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
-		return 0, fmt.Errorf("failed to parse versions response: %w", err)
+	for i := range paths {
+		indexes <- i
 	}
+	close(indexes)
+	wg.Wait()
 
-	// GitHub doesn't expose container download counts through the API
-	// even with authentication. This is a known limitation.
-	// Return 0 to indicate we couldn't get the data
-	logger.Debugf("GHCR package found but download count not available through API for %s", imageName)
-	return 0, nil
+	return results, nil
 }
 
-// getDockerHubPullCount fetches pull count for Docker Hub images
-func getDockerHubPullCount(imageName string) (int, error) {
-	// Remove docker.io prefix if present
-	imageName = strings.TrimPrefix(imageName, "docker.io/")
-
-	// Docker Hub API endpoint
-	url := fmt.Sprintf("https://hub.docker.com/v2/repositories/%s/", imageName)
-
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("failed to send request: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		// Not found or error - return 0
-		return 0, nil
+// logResult logs the old and new values of an updated entry
+func logResult(result metadata.Result) {
+	if dryRun {
+		logger.Infof("[DRY RUN] Would update %s: stars %d -> %d, pulls %d -> %d",
+			result.Name, result.OldStars, result.NewStars, result.OldPulls, result.NewPulls)
+	} else {
+		logger.Infof("Updated %s: stars %d -> %d, pulls %d -> %d",
+			result.Name, result.OldStars, result.NewStars, result.OldPulls, result.NewPulls)
 	}
-
-	var dockerHubResp struct {
-		PullCount int `json:"pull_count"`
+	if extendedMetadata && (result.OldForks != result.NewForks || result.OldOpenIssues != result.NewOpenIssues) {
+		logger.Infof("Forks of %s: %d -> %d, open issues %d -> %d",
+			result.Name, result.OldForks, result.NewForks, result.OldOpenIssues, result.NewOpenIssues)
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&dockerHubResp); err != nil {
-		return 0, fmt.Errorf("failed to parse response: %w", err)
+	if latestRelease && result.OldLatestRelease != result.NewLatestRelease {
+		logger.Infof("Latest release of %s: %q -> %q", result.Name, result.OldLatestRelease, result.NewLatestRelease)
 	}
-
-	return dockerHubResp.PullCount, nil
 }
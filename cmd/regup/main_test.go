@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/metadata"
+)
+
+// fakeAPI serves stars and pull counts slowly enough for concurrent requests to overlap,
+// recording the most requests it handled at once
+func fakeAPI(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var inFlight, maxInFlight atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			highest := maxInFlight.Load()
+			if current <= highest || maxInFlight.CompareAndSwap(highest, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+
+		switch r.URL.Path {
+		case "/repos/example/alpha", "/repos/example/beta", "/repos/example/gamma":
+			fmt.Fprint(w, `{"stargazers_count": 42}`)
+		case "/v2/repositories/example/alpha/", "/v2/repositories/example/beta/", "/v2/repositories/example/gamma/":
+			fmt.Fprint(w, `{"pull_count": 1234}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &maxInFlight
+}
+
+func writeSpecs(t *testing.T, specs map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, spec := range specs {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, "spec.yaml"), []byte(spec), 0644))
+	}
+	return dir
+}
+
+func testSpec(name string) string {
+	return `image: example/` + name + `:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/` + name + `
+tools:
+  - example_tool
+metadata:
+  stars: 1
+  pulls: 2
+`
+}
+
+func TestUpdateDir(t *testing.T) {
+	t.Parallel()
+
+	api, maxInFlight := fakeAPI(t)
+	dir := writeSpecs(t, map[string]string{
+		"alpha":  testSpec("alpha"),
+		"beta":   testSpec("beta"),
+		"broken": "image: [unclosed\n",
+		"gamma":  testSpec("gamma"),
+	})
+	// Files other than */spec.yaml are ignored
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte("not: a registry entry\n"), 0644))
+
+	updater := metadata.NewUpdater(metadata.Options{GitHubAPIURL: api.URL, DockerHubAPIURL: api.URL})
+	results, err := updateDir(context.Background(), updater, dir, 2)
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+
+	// Results are in path order, and the broken spec doesn't stop the others
+	for i, name := range []string{"alpha", "beta", "broken", "gamma"} {
+		assert.Equal(t, filepath.Join(dir, name, "spec.yaml"), results[i].path)
+		if name == "broken" {
+			assert.ErrorContains(t, results[i].err, "failed to parse YAML")
+			continue
+		}
+		require.NoError(t, results[i].err)
+		assert.Equal(t, name, results[i].result.Name)
+		assert.Equal(t, 42, results[i].result.NewStars)
+		assert.Equal(t, 1234, results[i].result.NewPulls)
+		assert.True(t, results[i].result.Written)
+	}
+
+	// No more than the given number of entries were updated at a time
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(2))
+}
+
+func TestUpdateDir_Sequential(t *testing.T) {
+	t.Parallel()
+
+	api, maxInFlight := fakeAPI(t)
+	dir := writeSpecs(t, map[string]string{"alpha": testSpec("alpha"), "beta": testSpec("beta")})
+
+	updater := metadata.NewUpdater(metadata.Options{GitHubAPIURL: api.URL, DockerHubAPIURL: api.URL})
+	results, err := updateDir(context.Background(), updater, dir, 1)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, int32(1), maxInFlight.Load())
+}
+
+func TestUpdateDir_NoSpecs(t *testing.T) {
+	t.Parallel()
+
+	updater := metadata.NewUpdater(metadata.Options{})
+	_, err := updateDir(context.Background(), updater, t.TempDir(), 1)
+	assert.ErrorContains(t, err, "no */spec.yaml files found")
+}
+
+func TestRunUpdateDir_Failures(t *testing.T) {
+	t.Parallel()
+
+	api, _ := fakeAPI(t)
+	dir := writeSpecs(t, map[string]string{"alpha": testSpec("alpha"), "broken": "image: [unclosed\n"})
+
+	updater := metadata.NewUpdater(metadata.Options{GitHubAPIURL: api.URL, DockerHubAPIURL: api.URL})
+	err := runUpdateDir(updater, dir)
+	assert.EqualError(t, err, "failed to update 1 of 2 entries")
+
+	// The entry that could be updated still was
+	data, err := os.ReadFile(filepath.Join(dir, "alpha", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "stars: 42")
+}
+
+func TestCheckStale(t *testing.T) {
+	t.Parallel()
+
+	api, _ := fakeAPI(t)
+	now := time.Now()
+	lastUpdated := func(age time.Duration) string {
+		return "  last_updated: \"" + now.Add(-age).UTC().Format(time.RFC3339) + "\"\n"
+	}
+	// The fake API reports 42 stars and 1234 pulls, so these specs don't change
+	unchanged := func(name string) string {
+		return strings.Replace(strings.Replace(testSpec(name), "stars: 1", "stars: 42", 1), "pulls: 2", "pulls: 1234", 1)
+	}
+
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr string
+	}{
+		{name: "fresh", spec: unchanged("alpha") + lastUpdated(24*time.Hour)},
+		{
+			name:    "stale",
+			spec:    unchanged("alpha") + lastUpdated(60*24*time.Hour),
+			wantErr: "1 of 1 entries have stale metadata (older than 720h0m0s): alpha",
+		},
+		{
+			name:    "missing timestamp",
+			spec:    unchanged("alpha"),
+			wantErr: "1 of 1 entries have stale metadata (older than 720h0m0s): alpha",
+		},
+		{name: "stale but changed", spec: testSpec("alpha") + lastUpdated(60*24*time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := writeSpecs(t, map[string]string{"alpha": tt.spec})
+			updater := metadata.NewUpdater(metadata.Options{
+				GitHubAPIURL:    api.URL,
+				DockerHubAPIURL: api.URL,
+				DryRun:          true,
+			})
+			result, err := updater.UpdateSpec(context.Background(), filepath.Join(dir, "alpha", "spec.yaml"))
+			require.NoError(t, err)
+
+			err = checkStale([]metadata.Result{result}, 30*24*time.Hour, now)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			// Without a threshold nothing is stale
+			assert.NoError(t, checkStale([]metadata.Result{result}, 0, now))
+		})
+	}
+}
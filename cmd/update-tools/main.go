@@ -2,38 +2,97 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/spf13/cobra"
 	"github.com/stacklok/toolhive/pkg/logger"
 	"gopkg.in/yaml.v3"
 
+	"github.com/stacklok/toolhive-registry/pkg/registry"
 	"github.com/stacklok/toolhive-registry/pkg/toolhive"
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
 var (
-	specPath    string
-	dryRun      bool
-	thvPath     string
-	addWarnings bool
-	verbose     bool
+	specPath          string
+	dryRun            bool
+	thvPath           string
+	addWarnings       bool
+	verbose           bool
+	secretPlaceholder string
+	secretsFromEnv    bool
+	secretsFile       string
+	updateAll         bool
+	registryPath      string
+	transports        []string
+	startupTimeout    time.Duration
+	withDescriptions  bool
+)
+
+// Warnings added to specs whose tools couldn't be updated, removed once an update succeeds
+const (
+	fetchFailedWarning = "Tool list fetch failed"
+	emptyToolsWarning  = "Tool list could not be auto-updated"
 )
 
 var rootCmd = &cobra.Command{
-	Use:   "update-tools [spec-file]",
+	Use:   "update-tools [spec-file | --all]",
 	Short: "Update tool lists in MCP server spec files using thv mcp list",
 	Long: `update-tools fetches the current list of tools from an MCP server using
 'thv mcp list --server <name>' and updates the tools section in the spec.yaml file.
+Remote servers are listed directly by URL with a built-in MCP client, as thv mcp
+list can't send headers; headers declared with 'from_env' are read from the
+environment for this, sent only to the server and never passed on a command
+line or written back to the spec. Remote servers that use OAuth are skipped
+unless an Authorization header is available.
+Local servers are started with 'thv run', and their tools are listed once
+'thv list' reports them running, waiting up to --startup-timeout.
+
+Secret env vars are passed real values when available, from THV_SECRET_<NAME>
+environment variables, the <NAME> variables themselves with --secrets-from-env,
+or a --secrets-file of NAME=value lines. Real values reach thv through a
+temporary --env-file, never its command line. Otherwise required secrets get
+their tool_discovery_value or --secret-placeholder.
 
 If no tools are detected but the spec had tools before, it keeps the old list
-and adds a warning comment.`,
-	Args: cobra.ExactArgs(1),
+and adds a warning comment.
+
+With --all, every entry in the registry is updated in turn. Stdio servers and
+remote servers have very different discovery paths, so --transport limits a
+batch run to entries with the given transports, e.g. to refresh stdio servers
+and remote servers in separate jobs with different resource limits.`,
+	Example: `  # Update the tools of a single server
+  update-tools registry/github/spec.yaml
+
+  # Update every server in the registry
+  update-tools --all
+
+  # Update only the servers that run locally over stdio
+  update-tools --all --transport stdio
+
+  # Update only the servers reached over HTTP
+  update-tools --all --transport sse --transport streamable-http
+
+  # Record what each tool does next to its name
+  update-tools registry/github/spec.yaml --with-descriptions
+
+  # Start a server that validates its token at startup
+  THV_SECRET_GITHUB_PERSONAL_ACCESS_TOKEN=ghp_... update-tools registry/github/spec.yaml
+
+  # Read secret values from a file
+  update-tools --all --secrets-file secrets.env
+
+  # Give heavy images longer to start
+  update-tools registry/github/spec.yaml --startup-timeout 3m`,
+	Args: cobra.MaximumNArgs(1),
 	RunE: runUpdate,
 }
 
@@ -44,6 +103,20 @@ func init() {
 	rootCmd.Flags().StringVar(&thvPath, "thv-path", "", "Path to thv binary (defaults to searching PATH)")
 	rootCmd.Flags().BoolVar(&addWarnings, "add-warnings", true, "Add warning comments when tools can't be fetched")
 	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
+	rootCmd.Flags().StringVar(&secretPlaceholder, "secret-placeholder", toolhive.DefaultSecretPlaceholder,
+		"Value passed for required secrets that have no tool_discovery_value or environment value")
+	rootCmd.Flags().BoolVar(&secretsFromEnv, "secrets-from-env", false,
+		"Use real secret values from the environment when running the server for tool discovery")
+	rootCmd.Flags().StringVar(&secretsFile, "secrets-file", "",
+		"File of NAME=value lines with real secret values for running the server for tool discovery")
+	rootCmd.Flags().BoolVar(&updateAll, "all", false, "Update every entry in the registry instead of a single spec file")
+	rootCmd.Flags().StringVarP(&registryPath, "registry", "r", "registry", "Path to the registry directory used with --all")
+	rootCmd.Flags().DurationVar(&startupTimeout, "startup-timeout", toolhive.DefaultStartupTimeout,
+		"How long to wait for a local server to be running before listing its tools")
+	rootCmd.Flags().BoolVar(&withDescriptions, "with-descriptions", false,
+		"Also write each tool's description as a comment after its name in the spec")
+	rootCmd.Flags().StringSliceVar(&transports, "transport", nil,
+		"With --all, only update entries with this transport (stdio, sse, streamable-http); repeatable")
 }
 
 func main() {
@@ -54,7 +127,72 @@ func main() {
 }
 
 func runUpdate(_ *cobra.Command, args []string) error {
-	specPath = args[0]
+	switch {
+	case updateAll && len(args) > 0:
+		return errors.New("--all can't be combined with a spec file")
+	case updateAll:
+		return runBatch()
+	case len(args) == 0:
+		return errors.New("a spec file is required, or --all to update every entry")
+	case len(transports) > 0:
+		return errors.New("--transport can only be used with --all")
+	}
+	return updateSpec(args[0])
+}
+
+// runBatch updates the tools of every entry that passes the --transport filter. A failure
+// doesn't stop the batch; the failed entries are reported once every entry was processed.
+func runBatch() error {
+	specs, err := batchSpecs(registryPath, transports)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Updating tools for %d servers", len(specs))
+
+	var failed []string
+	for _, spec := range specs {
+		if err := updateSpec(spec); err != nil {
+			logger.Errorf("Failed to update %s: %v", spec, err)
+			failed = append(failed, spec)
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to update tools for %d of %d servers", len(failed), len(specs))
+	}
+	return nil
+}
+
+// batchSpecs returns the spec files of the registry entries with one of the given transports,
+// or of every entry if no transports are given, sorted by entry name
+func batchSpecs(dir string, transportFilter []string) ([]string, error) {
+	canonical := make([]string, 0, len(transportFilter))
+	for _, transport := range transportFilter {
+		normalized, ok := types.NormalizeTransport(transport)
+		if !ok {
+			return nil, fmt.Errorf("unknown transport %q (supported: %s)", transport, strings.Join(types.Transports, ", "))
+		}
+		canonical = append(canonical, normalized)
+	}
+
+	loader := registry.NewLoader(dir)
+	loader.SetTransportFilter(canonical...)
+	// Discovering tools doesn't depend on how an image is pinned
+	loader.SetAllowLatest(true)
+	if err := loader.LoadAll(); err != nil {
+		return nil, fmt.Errorf("failed to load registry entries: %w", err)
+	}
+
+	specs := make([]string, 0, len(loader.GetEntries()))
+	for _, entry := range loader.GetSortedEntries() {
+		specs = append(specs, loader.SourcePath(entry.GetName()))
+	}
+	return specs, nil
+}
+
+// updateSpec updates the tools of the server whose spec file is at path
+func updateSpec(path string) error {
+	specPath = path
 
 	// Verify spec file exists
 	if _, err := os.Stat(specPath); os.IsNotExist(err) {
@@ -78,6 +216,11 @@ func runUpdate(_ *cobra.Command, args []string) error {
 
 	// Fetch new tools from thv
 	newTools, err := fetchToolsFromMCP(serverName)
+	if errors.Is(err, toolhive.ErrCredentialsRequired) {
+		// Without credentials the tool list can't be checked, which isn't a reason to flag the spec
+		logger.Warnf("Skipping %s: %v", serverName, err)
+		return nil
+	}
 	if err != nil {
 		return handleFetchError(err, currentTools)
 	}
@@ -85,12 +228,30 @@ func runUpdate(_ *cobra.Command, args []string) error {
 	logger.Infof("New tools count: %d", len(newTools))
 
 	// Handle empty tools case
-	if err := handleEmptyTools(newTools, currentTools); err != nil {
+	if err := handleEmptyTools(toolhive.ToolNames(newTools), currentTools); err != nil {
 		return err
 	}
 
 	// Compare and update tools
-	return compareAndUpdateTools(currentTools, newTools)
+	if err := compareAndUpdateTools(currentTools, newTools); err != nil {
+		return err
+	}
+
+	removeStaleWarnings()
+	return nil
+}
+
+// removeStaleWarnings removes the warnings earlier failed updates added to the spec, now
+// that its tools were updated
+func removeStaleWarnings() {
+	if dryRun {
+		return
+	}
+	for _, warning := range []string{fetchFailedWarning, emptyToolsWarning} {
+		if err := toolhive.RemoveWarningComment(specPath, warning); err != nil {
+			logger.Warnf("Failed to remove warning comment: %v", err)
+		}
+	}
 }
 
 func getCurrentTools() ([]string, error) {
@@ -109,7 +270,7 @@ func handleFetchError(err error, currentTools []string) error {
 
 	if len(currentTools) > 0 && addWarnings {
 		if !dryRun {
-			if err := toolhive.AddWarningComment(specPath, "Tool list fetch failed", "Manual verification may be required"); err != nil {
+			if err := toolhive.AddWarningComment(specPath, fetchFailedWarning, "Manual verification may be required"); err != nil {
 				logger.Warnf("Failed to add warning comment: %v", err)
 			}
 		} else {
@@ -126,7 +287,7 @@ func handleEmptyTools(newTools, currentTools []string) error {
 
 		if addWarnings {
 			if !dryRun {
-				if err := toolhive.AddWarningComment(specPath, "Tool list could not be auto-updated",
+				if err := toolhive.AddWarningComment(specPath, emptyToolsWarning,
 					"Please verify the tools list manually"); err != nil {
 					logger.Warnf("Failed to add warning comment: %v", err)
 				}
@@ -139,28 +300,39 @@ func handleEmptyTools(newTools, currentTools []string) error {
 	return nil
 }
 
-func compareAndUpdateTools(currentTools, newTools []string) error {
-	// Sort both lists for comparison
+// compareAndUpdateTools writes the new tools to the spec if their names changed, or with
+// --with-descriptions, to refresh their descriptions
+func compareAndUpdateTools(currentTools []string, newTools []toolhive.Tool) error {
+	// Sort both lists for comparison; the parsed tools are already sorted by name
 	sort.Strings(currentTools)
-	sort.Strings(newTools)
+	newNames := toolhive.ToolNames(newTools)
 
 	// Check if tools changed using slices.Equal
-	if slices.Equal(currentTools, newTools) {
+	switch {
+	case slices.Equal(currentTools, newNames) && !withDescriptions:
 		logger.Info("Tools list is already up to date")
 		return nil
-	}
-
-	// Show changes
-	logger.Info("Tools list changes detected:")
-	if verbose {
-		showDetailedDiff(currentTools, newTools)
-	} else {
-		showSummaryDiff(currentTools, newTools)
+	case slices.Equal(currentTools, newNames):
+		logger.Info("Tools list is already up to date, refreshing tool descriptions")
+	default:
+		// Show changes
+		logger.Info("Tools list changes detected:")
+		if verbose {
+			showDetailedDiff(currentTools, newNames)
+		} else {
+			showSummaryDiff(currentTools, newNames)
+		}
 	}
 
 	// Update the spec file
 	if !dryRun {
-		if err := toolhive.UpdateSpecTools(specPath, newTools); err != nil {
+		var err error
+		if withDescriptions {
+			err = toolhive.UpdateSpecToolsWithDescriptions(specPath, newTools)
+		} else {
+			err = toolhive.UpdateSpecTools(specPath, newNames)
+		}
+		if err != nil {
 			return fmt.Errorf("failed to update spec file: %w", err)
 		}
 		logger.Info("Successfully updated tools list")
@@ -172,7 +344,7 @@ func compareAndUpdateTools(currentTools, newTools []string) error {
 }
 
 func loadSpec(path string) (*types.RegistryEntry, error) {
-	data, err := os.ReadFile(path) // #nosec G304 - path is controlled by application
+	data, err := types.ReadSpecFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
@@ -185,7 +357,9 @@ func loadSpec(path string) (*types.RegistryEntry, error) {
 	return &entry, nil
 }
 
-func fetchToolsFromMCP(serverName string) ([]string, error) {
+// fetchToolsFromMCP lists the tools of the server whose spec is at specPath, running it
+// with thv first unless it's a remote server
+func fetchToolsFromMCP(serverName string) ([]toolhive.Tool, error) {
 	// Load the spec to get the configuration
 	spec, err := loadSpec(specPath)
 	if err != nil {
@@ -193,33 +367,25 @@ func fetchToolsFromMCP(serverName string) ([]string, error) {
 	}
 
 	// Create ToolHive client
-	client, err := toolhive.NewClient(thvPath, verbose)
+	client, err := toolhive.NewClient(thvPath, verbose, toolhive.WithStartupTimeout(startupTimeout))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create ToolHive client: %w", err)
 	}
 
-	// Run the MCP server
-	tempName, err := client.RunServer(spec, serverName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to run server: %w", err)
+	runOptions := toolhive.RunCommandOptions{SecretPlaceholder: secretPlaceholder}
+	if secretsFromEnv {
+		runOptions.LookupEnv = os.LookupEnv
 	}
-	defer func() {
-		// Clean up the temporary server
-		if err := client.StopServer(tempName); err != nil {
-			logger.Warnf("Failed to stop temporary server %s: %v", tempName, err)
+	if secretsFile != "" {
+		secrets, err := toolhive.LoadSecretsFile(secretsFile)
+		if err != nil {
+			return nil, err
 		}
-		if err := client.RemoveServer(tempName); err != nil {
-			logger.Warnf("Failed to remove temporary server %s: %v", tempName, err)
-		}
-	}()
-
-	// Query the server for tools
-	tools, err := client.ListTools(tempName)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list tools: %w", err)
+		runOptions.Secrets = secrets
 	}
+	client.SetRunOptions(runOptions)
 
-	return tools, nil
+	return client.FetchTools(spec, serverName)
 }
 
 func showDetailedDiff(current, newTools []string) {
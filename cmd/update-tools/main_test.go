@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/toolhive"
+)
+
+// writeBatchRegistry writes a registry with one entry per transport and returns its directory
+func writeBatchRegistry(t *testing.T) string {
+	t.Helper()
+
+	registryDir := t.TempDir()
+	for name, spec := range map[string]string{
+		"local":  "description: Stdio server\ntransport: stdio\ntier: Community\nstatus: Active\nimage: test/local:latest\ntools:\n  - tool1\n",
+		"events": "description: SSE server\ntransport: sse\ntier: Community\nstatus: Active\nimage: test/events:latest\ntarget_port: 8080\ntools:\n  - tool1\n",
+		"hosted": "description: Remote server\ntransport: streamable-http\ntier: Community\nstatus: Active\nurl: https://api.example.com/mcp\ntools:\n  - tool1\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(registryDir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, name, "spec.yaml"), []byte(spec), 0644))
+	}
+	return registryDir
+}
+
+func TestBatchSpecs(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeBatchRegistry(t)
+	spec := func(name string) string { return filepath.Join(registryDir, name, "spec.yaml") }
+
+	tests := []struct {
+		name       string
+		transports []string
+		want       []string
+		wantErr    string
+	}{
+		{name: "every entry", want: []string{spec("events"), spec("hosted"), spec("local")}},
+		{name: "stdio only", transports: []string{"stdio"}, want: []string{spec("local")}},
+		{name: "remote transports", transports: []string{"sse", "streamable-http"}, want: []string{spec("events"), spec("hosted")}},
+		{name: "aliases are normalized", transports: []string{"HTTP"}, want: []string{spec("hosted")}},
+		{name: "unknown transport", transports: []string{"websocket"}, wantErr: `unknown transport "websocket"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			specs, err := batchSpecs(registryDir, tt.transports)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, specs)
+		})
+	}
+}
+
+// TestRunUpdate_Args isn't parallel because the flags are package globals
+//
+//nolint:paralleltest
+func TestRunUpdate_Args(t *testing.T) {
+	oldAll, oldTransports := updateAll, transports
+	t.Cleanup(func() { updateAll, transports = oldAll, oldTransports })
+
+	updateAll, transports = false, nil
+	assert.ErrorContains(t, runUpdate(nil, nil), "a spec file is required")
+
+	transports = []string{"stdio"}
+	assert.ErrorContains(t, runUpdate(nil, []string{"registry/local/spec.yaml"}), "--transport can only be used with --all")
+
+	updateAll = true
+	assert.ErrorContains(t, runUpdate(nil, []string{"registry/local/spec.yaml"}), "--all can't be combined")
+}
+
+// TestUpdateSpec_Remote isn't parallel because the flags are package globals
+//
+//nolint:paralleltest
+func TestUpdateSpec_Remote(t *testing.T) {
+	oldThvPath, oldDryRun, oldAddWarnings, oldWithDescriptions := thvPath, dryRun, addWarnings, withDescriptions
+	t.Cleanup(func() {
+		thvPath, dryRun, addWarnings, withDescriptions = oldThvPath, oldDryRun, oldAddWarnings, oldWithDescriptions
+	})
+
+	// Remote servers are listed without thv, by connecting to them directly
+	mcpServer := server.NewMCPServer("remote", "1.0.0", server.WithToolCapabilities(false))
+	for name, description := range map[string]string{"search": "Search the web", "fetch": "Fetch a page"} {
+		mcpServer.AddTool(mcp.NewTool(name, mcp.WithDescription(description)),
+			func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(name), nil
+			})
+	}
+	var requests atomic.Int32
+	remote := server.NewTestStreamableHTTPServer(mcpServer,
+		server.WithHTTPContextFunc(func(ctx context.Context, _ *http.Request) context.Context {
+			requests.Add(1)
+			return ctx
+		}))
+	t.Cleanup(remote.Close)
+
+	thvPath = filepath.Join(t.TempDir(), "thv")
+	dryRun, addWarnings, withDescriptions = false, true, false
+
+	registryDir := t.TempDir()
+	writeSpec := func(name, spec string) string {
+		t.Helper()
+		path := filepath.Join(registryDir, name, "spec.yaml")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(spec), 0644))
+		return path
+	}
+
+	hosted := writeSpec("hosted", `description: Remote server
+transport: streamable-http
+url: `+remote.URL+`/mcp
+tier: Community
+status: Active
+tools:
+  - search
+`)
+	require.NoError(t, updateSpec(hosted))
+	assert.Positive(t, requests.Load())
+	data, err := os.ReadFile(hosted)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  - fetch\n  - search\n")
+
+	// A successful update removes the warnings of earlier failed updates
+	require.NoError(t, toolhive.AddWarningComment(hosted, fetchFailedWarning, "Manual verification may be required"))
+	require.NoError(t, updateSpec(hosted))
+	data, err = os.ReadFile(hosted)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "WARNING")
+	assert.NotContains(t, string(data), "Manual verification may be required")
+
+	// With --with-descriptions, the descriptions are written even though the names are unchanged
+	withDescriptions = true
+	require.NoError(t, updateSpec(hosted))
+	data, err = os.ReadFile(hosted)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "  - fetch # Fetch a page\n  - search # Search the web\n")
+
+	// A server that uses OAuth is skipped without connecting to it or flagging the spec
+	requests.Store(0)
+	oauthSpec := `description: OAuth server
+transport: streamable-http
+url: ` + remote.URL + `/mcp
+tier: Community
+status: Active
+tools:
+  - search
+oauth_config:
+  issuer: https://auth.example.com
+`
+	oauth := writeSpec("oauth", oauthSpec)
+	require.NoError(t, updateSpec(oauth))
+	assert.Zero(t, requests.Load())
+	data, err = os.ReadFile(oauth)
+	require.NoError(t, err)
+	assert.Equal(t, oauthSpec, string(data))
+}
@@ -0,0 +1,92 @@
+// Package annotations formats validation findings as CI annotations
+package annotations
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Level is the severity of an annotation
+type Level string
+
+const (
+	// LevelWarning annotates a finding that doesn't fail the command
+	LevelWarning Level = "warning"
+	// LevelError annotates a finding that fails the command
+	LevelError Level = "error"
+)
+
+// FormatGitHub emits GitHub Actions workflow commands
+const FormatGitHub = "github"
+
+// Formats are the supported annotation formats
+var Formats = []string{FormatGitHub}
+
+// Annotation is a finding attached to a file and, when known, a line
+type Annotation struct {
+	Level   Level
+	File    string
+	Line    int
+	Message string
+}
+
+// GitHub returns the annotation as a GitHub Actions workflow command, such as
+// "::warning file=registry/fetch/spec.yaml,line=3::message". The file and line
+// are omitted when unknown.
+func (a Annotation) GitHub() string {
+	var props []string
+	if a.File != "" {
+		props = append(props, "file="+escapeProperty(filepath.ToSlash(a.File)))
+		if a.Line > 0 {
+			props = append(props, fmt.Sprintf("line=%d", a.Line))
+		}
+	}
+
+	command := "::" + string(a.Level)
+	if len(props) > 0 {
+		command += " " + strings.Join(props, ",")
+	}
+	return command + "::" + escapeData(a.Message)
+}
+
+// Emitter writes annotations in a given format
+type Emitter struct {
+	out    io.Writer
+	format string
+}
+
+// NewEmitter returns an emitter writing annotations to out in the given format.
+// An empty format returns an emitter that discards annotations.
+func NewEmitter(out io.Writer, format string) (*Emitter, error) {
+	switch format {
+	case "", FormatGitHub:
+		return &Emitter{out: out, format: format}, nil
+	default:
+		return nil, fmt.Errorf("unknown annotations format %q (supported: %s)", format, strings.Join(Formats, ", "))
+	}
+}
+
+// Enabled returns true if the emitter writes annotations
+func (e *Emitter) Enabled() bool {
+	return e != nil && e.format != ""
+}
+
+// Emit writes an annotation, if the emitter is enabled
+func (e *Emitter) Emit(a Annotation) {
+	if !e.Enabled() {
+		return
+	}
+	fmt.Fprintln(e.out, a.GitHub())
+}
+
+// escapeData escapes a workflow command message
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// escapeProperty escapes a workflow command property value
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
@@ -0,0 +1,83 @@
+package annotations
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnotation_GitHub(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		annotation Annotation
+		want       string
+	}{
+		{
+			name: "warning with file and line",
+			annotation: Annotation{
+				Level:   LevelWarning,
+				File:    "registry/fetch/spec.yaml",
+				Line:    4,
+				Message: `fetch: transport "sse" is not supported by image mcp/fetch`,
+			},
+			want: `::warning file=registry/fetch/spec.yaml,line=4::fetch: transport "sse" is not supported by image mcp/fetch`,
+		},
+		{
+			name: "error with file but unknown line",
+			annotation: Annotation{
+				Level:   LevelError,
+				File:    "registry/github/spec.yaml",
+				Message: "failed to load registry/github/spec.yaml: description is required",
+			},
+			want: "::error file=registry/github/spec.yaml::failed to load registry/github/spec.yaml: description is required",
+		},
+		{
+			name:       "error without file",
+			annotation: Annotation{Level: LevelError, Message: "registry validation failed"},
+			want:       "::error::registry validation failed",
+		},
+		{
+			name: "message and file are escaped",
+			annotation: Annotation{
+				Level:   LevelError,
+				File:    "registry/a,b:c/spec.yaml",
+				Line:    2,
+				Message: "tool count validation failed:\n  100% wrong",
+			},
+			want: "::error file=registry/a%2Cb%3Ac/spec.yaml,line=2::tool count validation failed:%0A  100%25 wrong",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.annotation.GitHub())
+		})
+	}
+}
+
+func TestEmitter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	emitter, err := NewEmitter(&buf, FormatGitHub)
+	require.NoError(t, err)
+	assert.True(t, emitter.Enabled())
+	emitter.Emit(Annotation{Level: LevelWarning, File: "spec.yaml", Line: 1, Message: "hello"})
+	assert.Equal(t, "::warning file=spec.yaml,line=1::hello\n", buf.String())
+
+	buf.Reset()
+	disabled, err := NewEmitter(&buf, "")
+	require.NoError(t, err)
+	assert.False(t, disabled.Enabled())
+	disabled.Emit(Annotation{Level: LevelWarning, Message: "hello"})
+	assert.Empty(t, buf.String())
+
+	_, err = NewEmitter(&buf, "gitlab")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown annotations format "gitlab"`)
+}
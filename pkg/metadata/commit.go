@@ -0,0 +1,64 @@
+package metadata
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// commitScope is the conventional-commit scope used for metadata refresh commits
+const commitScope = "registry"
+
+// CommitMessage returns a conventional-commit message summarizing the changed results,
+// or an empty string if none of them changed. The body lists every changed entry in
+// alphabetical order with its old and new counts.
+func CommitMessage(results []Result) string {
+	var changed []Result
+	for _, result := range results {
+		if result.Changed() {
+			changed = append(changed, result)
+		}
+	}
+	if len(changed) == 0 {
+		return ""
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Name < changed[j].Name })
+
+	noun := "servers"
+	if len(changed) == 1 {
+		noun = "server"
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "chore(%s): bump stars/pulls for %d %s\n\n", commitScope, len(changed), noun)
+	for _, result := range changed {
+		var parts []string
+		if result.OldStars != result.NewStars {
+			parts = append(parts, fmt.Sprintf("stars %d -> %d", result.OldStars, result.NewStars))
+		}
+		if result.OldPulls != result.NewPulls {
+			parts = append(parts, fmt.Sprintf("pulls %d -> %d", result.OldPulls, result.NewPulls))
+		}
+		if result.OldForks != result.NewForks {
+			parts = append(parts, fmt.Sprintf("forks %d -> %d", result.OldForks, result.NewForks))
+		}
+		if result.OldOpenIssues != result.NewOpenIssues {
+			parts = append(parts, fmt.Sprintf("open issues %d -> %d", result.OldOpenIssues, result.NewOpenIssues))
+		}
+		if result.OldLatestRelease != result.NewLatestRelease {
+			parts = append(parts, fmt.Sprintf("latest release %s -> %s",
+				releaseOrNone(result.OldLatestRelease), releaseOrNone(result.NewLatestRelease)))
+		}
+		fmt.Fprintf(&b, "- %s: %s\n", result.Name, strings.Join(parts, ", "))
+	}
+
+	return b.String()
+}
+
+// releaseOrNone returns a release tag, or "none" for an empty one
+func releaseOrNone(release string) string {
+	if release == "" {
+		return "none"
+	}
+	return release
+}
@@ -0,0 +1,72 @@
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitMessage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		results []Result
+		want    string
+	}{
+		{
+			name: "no changes",
+			results: []Result{
+				{Name: "fetch", OldStars: 10, NewStars: 10, OldPulls: 5, NewPulls: 5},
+			},
+			want: "",
+		},
+		{
+			name: "single server",
+			results: []Result{
+				{Name: "fetch", OldStars: 10, NewStars: 12, OldPulls: 5, NewPulls: 5},
+			},
+			want: "chore(registry): bump stars/pulls for 1 server\n\n- fetch: stars 10 -> 12\n",
+		},
+		{
+			name: "only changed servers are listed in name order",
+			results: []Result{
+				{Name: "github", OldStars: 100, NewStars: 110, OldPulls: 1000, NewPulls: 1200},
+				{Name: "fetch", OldStars: 10, NewStars: 10, OldPulls: 5, NewPulls: 5},
+				{Name: "context7", OldStars: 3, NewStars: 3, OldPulls: 40, NewPulls: 45},
+			},
+			want: "chore(registry): bump stars/pulls for 2 servers\n\n" +
+				"- context7: pulls 40 -> 45\n" +
+				"- github: stars 100 -> 110, pulls 1000 -> 1200\n",
+		},
+		{
+			name: "latest release",
+			results: []Result{
+				{Name: "fetch", OldLatestRelease: "v1.0.0", NewLatestRelease: "v1.1.0"},
+				{Name: "github", OldStars: 100, NewStars: 110, NewLatestRelease: "v0.1.0"},
+				{Name: "time", OldLatestRelease: "v2.0.0"},
+			},
+			want: "chore(registry): bump stars/pulls for 3 servers\n\n" +
+				"- fetch: latest release v1.0.0 -> v1.1.0\n" +
+				"- github: stars 100 -> 110, latest release none -> v0.1.0\n" +
+				"- time: latest release v2.0.0 -> none\n",
+		},
+		{
+			name: "forks and open issues",
+			results: []Result{
+				{Name: "fetch", OldStars: 10, NewStars: 11, OldForks: 2, NewForks: 3, OldOpenIssues: 5, NewOpenIssues: 4},
+				{Name: "time", NewOpenIssues: 1},
+			},
+			want: "chore(registry): bump stars/pulls for 2 servers\n\n" +
+				"- fetch: stars 10 -> 11, forks 2 -> 3, open issues 5 -> 4\n" +
+				"- time: open issues 0 -> 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, CommitMessage(tt.results))
+		})
+	}
+}
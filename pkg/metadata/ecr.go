@@ -0,0 +1,81 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stacklok/toolhive/pkg/logger"
+)
+
+const (
+	// DefaultECRPublicAPIURL is the base URL of the ECR Public gallery API
+	DefaultECRPublicAPIURL = "https://api.us-east-1.gallery.ecr.aws"
+	// ecrPublicRegistry is the registry host of ECR Public images
+	ecrPublicRegistry = "public.ecr.aws"
+)
+
+// isPrivateECRHost returns true for private ECR registries such as
+// 123456789012.dkr.ecr.us-east-1.amazonaws.com, which don't report pull counts
+func isPrivateECRHost(host string) bool {
+	host = strings.ToLower(host)
+	return strings.Contains(host, ".dkr.ecr.") &&
+		(strings.HasSuffix(host, ".amazonaws.com") || strings.HasSuffix(host, ".amazonaws.com.cn"))
+}
+
+// ecrPublicCatalogURL returns the ECR Public gallery API URL repository catalog data,
+// including download counts, is fetched from
+func (u *Updater) ecrPublicCatalogURL() string {
+	return u.opts.ECRPublicAPIURL + "/getRepositoryCatalogData"
+}
+
+// getECRPublicPullCount fetches the download count of an ECR Public repository from the
+// gallery. Repositories are always alias/name; ones the gallery doesn't list have a pull
+// count of 0.
+func (u *Updater) getECRPublicPullCount(ctx context.Context, repository string) (int, error) {
+	alias, name, ok := strings.Cut(repository, "/")
+	if !ok || alias == "" || name == "" {
+		logger.Warnf("Invalid ECR Public repository %s, cannot fetch pull count", repository)
+		return 0, nil
+	}
+
+	body, err := json.Marshal(map[string]string{"registryAliasName": alias, "repositoryName": name})
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	if err := u.wait(ctx); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u.ecrPublicCatalogURL(), bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Not found or error - return 0
+		logger.Debugf("Could not fetch ECR Public catalog data (status %d) for %s", resp.StatusCode, repository)
+		return 0, nil
+	}
+
+	var catalogResp struct {
+		InsightData struct {
+			DownloadCount int `json:"downloadCount"`
+		} `json:"insightData"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&catalogResp); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return catalogResp.InsightData.DownloadCount, nil
+}
@@ -0,0 +1,73 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// DefaultGitLabHost is the host of repositories whose stars are always fetched from GitLab
+const DefaultGitLabHost = types.GitLabHost
+
+// gitLabProject returns the host and full project path of a repository hosted on GitLab,
+// either gitlab.com or one of Options.GitLabHosts. Projects can be nested in subgroups.
+func (u *Updater) gitLabProject(repoURL string) (string, string, bool) {
+	host, path, ok := types.SplitRepositoryURL(repoURL)
+	if !ok || !u.isGitLabHost(host) {
+		return "", "", false
+	}
+	return host, path, true
+}
+
+// isGitLabHost returns true if repositories on host are hosted on GitLab
+func (u *Updater) isGitLabHost(host string) bool {
+	host = strings.ToLower(host)
+	return host == DefaultGitLabHost || slices.ContainsFunc(u.opts.GitLabHosts, func(gitLabHost string) bool {
+		return strings.ToLower(gitLabHost) == host
+	})
+}
+
+// gitLabProjectURL returns the GitLab API URL of a project, whose path is passed URL-encoded
+func (u *Updater) gitLabProjectURL(host, project string) string {
+	apiURL := u.opts.GitLabAPIURL
+	if apiURL == "" {
+		apiURL = "https://" + host + "/api/v4"
+	}
+	return fmt.Sprintf("%s/projects/%s", strings.TrimRight(apiURL, "/"), url.PathEscape(project))
+}
+
+// getGitLabProjectInfo gets the stars, forks and open issues counts for a GitLab project
+func (u *Updater) getGitLabProjectInfo(ctx context.Context, host, project string) (repoInfo, error) {
+	resp, err := u.getWithRetry(ctx, u.gitLabProjectURL(host, project), false)
+	if err != nil {
+		return repoInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return repoInfo{}, fmt.Errorf("GitLab API returned %s: %s", resp.Status, string(body))
+	}
+
+	var projectInfo struct {
+		StarCount       int `json:"star_count"`
+		ForksCount      int `json:"forks_count"`
+		OpenIssuesCount int `json:"open_issues_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&projectInfo); err != nil {
+		return repoInfo{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return repoInfo{
+		Stars:      projectInfo.StarCount,
+		Forks:      projectInfo.ForksCount,
+		OpenIssues: projectInfo.OpenIssuesCount,
+	}, nil
+}
@@ -0,0 +1,132 @@
+package metadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdater_GitLabStars(t *testing.T) {
+	t.Parallel()
+
+	// The project path is a single URL-encoded path segment
+	gitlab := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.EscapedPath() {
+		case "/projects/example%2Fserver":
+			_, _ = w.Write([]byte(`{"id": 1, "star_count": 42}`))
+		case "/projects/example%2Fplatform%2Ftools%2Fserver":
+			_, _ = w.Write([]byte(`{"id": 2, "star_count": 7}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(gitlab.Close)
+
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/example/server" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`{"stargazers_count": 100}`))
+	}))
+	t.Cleanup(github.Close)
+
+	tests := []struct {
+		name       string
+		repoURL    string
+		wantStars  int
+		wantSource string
+	}{
+		{
+			name:       "gitlab.com project",
+			repoURL:    "https://gitlab.com/example/server",
+			wantStars:  42,
+			wantSource: gitlab.URL + "/projects/example%2Fserver",
+		},
+		{
+			name:       "project in subgroups",
+			repoURL:    "https://gitlab.com/example/platform/tools/server.git",
+			wantStars:  7,
+			wantSource: gitlab.URL + "/projects/example%2Fplatform%2Ftools%2Fserver",
+		},
+		{
+			name:       "project page URL",
+			repoURL:    "https://gitlab.com/example/platform/tools/server/-/tree/main",
+			wantStars:  7,
+			wantSource: gitlab.URL + "/projects/example%2Fplatform%2Ftools%2Fserver",
+		},
+		{
+			name:       "SSH URL",
+			repoURL:    "git@gitlab.com:example/server.git",
+			wantStars:  42,
+			wantSource: gitlab.URL + "/projects/example%2Fserver",
+		},
+		{
+			name:       "configured self-hosted instance",
+			repoURL:    "https://GitLab.Example.com/example/server",
+			wantStars:  42,
+			wantSource: gitlab.URL + "/projects/example%2Fserver",
+		},
+		{
+			name:      "unknown project keeps the current stars",
+			repoURL:   "https://gitlab.com/example/missing",
+			wantStars: 5,
+		},
+		{
+			name:       "other hosts use GitHub",
+			repoURL:    "https://github.com/example/server",
+			wantStars:  100,
+			wantSource: github.URL + "/repos/example/server",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := NewUpdater(Options{
+				GitHubAPIURL: github.URL,
+				GitLabAPIURL: gitlab.URL,
+				GitLabHosts:  []string{"gitlab.example.com"},
+				MaxAttempts:  1,
+			})
+			info, source := updater.updatedRepoInfo(context.Background(), "server", tt.repoURL, repoInfo{Stars: 5})
+			assert.Equal(t, tt.wantStars, info.Stars)
+			if tt.wantSource == "" {
+				assert.Nil(t, source)
+				return
+			}
+			require.NotNil(t, source)
+			assert.Equal(t, tt.wantSource, source.URL)
+		})
+	}
+}
+
+func TestUpdater_GitLabProjectURL(t *testing.T) {
+	t.Parallel()
+
+	updater := NewUpdater(Options{GitLabHosts: []string{"gitlab.example.com"}})
+
+	host, project, ok := updater.gitLabProject("https://gitlab.example.com/group/sub/project.git")
+	require.True(t, ok)
+	assert.Equal(t, "https://gitlab.example.com/api/v4/projects/group%2Fsub%2Fproject",
+		updater.gitLabProjectURL(host, project))
+
+	_, _, ok = updater.gitLabProject("https://gitlab.other.example.com/group/project")
+	assert.False(t, ok, "unconfigured hosts aren't GitLab")
+	_, _, ok = updater.gitLabProject("https://gitlab.com/group")
+	assert.False(t, ok, "a group isn't a project")
+}
+
+func TestUpdater_GitLabKeepsLatestRelease(t *testing.T) {
+	t.Parallel()
+
+	// Releases are only looked up on GitHub, so the API is never called for GitLab projects
+	updater := NewUpdater(Options{GitHubAPIURL: "http://127.0.0.1:0", LatestRelease: true})
+	assert.Equal(t, "v1.0.0",
+		updater.updatedLatestRelease(context.Background(), "server", "https://gitlab.com/example/server", "v1.0.0"))
+}
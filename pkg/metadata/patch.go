@@ -0,0 +1,259 @@
+package metadata
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// patchMetadata applies the changes between the metadata blocks of two parses of a spec to
+// its original bytes: changed scalars are replaced in place, removed keys have their lines
+// deleted and new keys are appended to their mapping, so the rest of the file keeps its
+// layout byte for byte. It returns false if the original metadata block can't be patched
+// in place, such as when it uses flow style or multi-line values.
+func patchMetadata(data []byte, original, updated *yaml.Node) ([]byte, bool) {
+	if len(original.Content) == 0 || len(updated.Content) == 0 {
+		return nil, false
+	}
+	root, updatedRoot := original.Content[0], updated.Content[0]
+	if root.Kind != yaml.MappingNode || root.Style&yaml.FlowStyle != 0 || updatedRoot.Kind != yaml.MappingNode {
+		return nil, false
+	}
+
+	updatedIndex := mappingKeyIndex(updatedRoot, "metadata")
+	if updatedIndex < 0 {
+		return nil, false
+	}
+	p := newLinePatcher(data)
+
+	index := mappingKeyIndex(root, "metadata")
+	if index < 0 {
+		// Append the whole block, as a full re-encode would
+		block, err := renderPairs(updatedRoot.Content[updatedIndex:updatedIndex+2], "")
+		if err != nil {
+			return nil, false
+		}
+		p.append(block)
+		return p.bytes(), true
+	}
+
+	if !p.patchMapping(root.Content[index+1], updatedRoot.Content[updatedIndex+1]) {
+		return nil, false
+	}
+	return p.bytes(), true
+}
+
+// linePatcher edits the lines of a file, addressed by their original 1-based line numbers
+type linePatcher struct {
+	lines   []string
+	deleted []bool
+	// inserted holds the lines added after each original line
+	inserted map[int][]string
+	// appended holds the lines added at the end of the file
+	appended []string
+}
+
+func newLinePatcher(data []byte) *linePatcher {
+	lines := strings.Split(string(data), "\n")
+	return &linePatcher{
+		lines:    lines,
+		deleted:  make([]bool, len(lines)),
+		inserted: make(map[int][]string),
+	}
+}
+
+// patchMapping patches the lines of the original mapping so it matches the updated one
+func (p *linePatcher) patchMapping(original, updated *yaml.Node) bool {
+	if original.Kind != yaml.MappingNode || updated.Kind != yaml.MappingNode || !patchable(original) ||
+		len(original.Content) == 0 {
+		return false
+	}
+
+	var added []*yaml.Node
+	for i := 0; i+1 < len(updated.Content); i += 2 {
+		key, value := updated.Content[i], updated.Content[i+1]
+		index := mappingKeyIndex(original, key.Value)
+		if index < 0 {
+			added = append(added, key, value)
+			continue
+		}
+
+		oldValue := original.Content[index+1]
+		switch {
+		case oldValue.Kind == yaml.ScalarNode && value.Kind == yaml.ScalarNode:
+			if oldValue.Value != value.Value || oldValue.Tag != value.Tag {
+				if !p.replaceScalar(oldValue, value) {
+					return false
+				}
+			}
+		case oldValue.Kind == yaml.MappingNode && value.Kind == yaml.MappingNode:
+			if !p.patchMapping(oldValue, value) {
+				return false
+			}
+		default:
+			return false
+		}
+	}
+
+	for i := 0; i+1 < len(original.Content); i += 2 {
+		if mappingKeyIndex(updated, original.Content[i].Value) < 0 {
+			for line := original.Content[i].Line; line <= lastLine(original.Content[i+1]); line++ {
+				p.deleted[line-1] = true
+			}
+		}
+	}
+
+	if len(added) > 0 {
+		lines, err := renderPairs(added, strings.Repeat(" ", original.Content[0].Column-1))
+		if err != nil {
+			return false
+		}
+		after := lastLine(original)
+		p.inserted[after] = append(p.inserted[after], lines...)
+	}
+	return true
+}
+
+// replaceScalar replaces the text of a single-line scalar with the rendering of its new value
+func (p *linePatcher) replaceScalar(original, updated *yaml.Node) bool {
+	line := p.lines[original.Line-1]
+	start := original.Column - 1
+	if start >= len(line) {
+		return false
+	}
+	end := scalarEnd(line, start, original.Style)
+	if end < 0 {
+		return false
+	}
+
+	// The comments around the scalar stay where they are in the line
+	value := *updated
+	value.HeadComment, value.LineComment, value.FootComment = "", "", ""
+	rendered, err := yaml.Marshal(&value)
+	if err != nil {
+		return false
+	}
+	text := strings.TrimSuffix(string(rendered), "\n")
+	if strings.Contains(text, "\n") {
+		return false
+	}
+
+	p.lines[original.Line-1] = line[:start] + text + line[end:]
+	return true
+}
+
+// append adds lines at the end of the file
+func (p *linePatcher) append(lines []string) {
+	p.appended = append(p.appended, lines...)
+}
+
+// bytes returns the patched file
+func (p *linePatcher) bytes() []byte {
+	var out []string
+	for i, line := range p.lines {
+		if !p.deleted[i] {
+			out = append(out, line)
+		}
+		out = append(out, p.inserted[i+1]...)
+	}
+
+	if len(p.appended) > 0 {
+		// The last line is empty when the file ends with a newline
+		if last := len(out) - 1; last >= 0 && out[last] == "" {
+			out = out[:last]
+		}
+		out = append(append(out, p.appended...), "")
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+// scalarEnd returns the byte offset just past a single-line scalar starting at start,
+// excluding any trailing comment, or -1 if the scalar doesn't end on this line
+func scalarEnd(line string, start int, style yaml.Style) int {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
+		}
+		return -1
+	case style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] != '\'' {
+				continue
+			}
+			if i+1 < len(line) && line[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1
+		}
+		return -1
+	case style == 0:
+		text := line[start:]
+		if comment := strings.Index(text, " #"); comment >= 0 {
+			text = text[:comment]
+		}
+		return start + len(strings.TrimRight(text, " \t"))
+	}
+	return -1
+}
+
+// patchable returns true if a node and its children are laid out one block-style value per
+// line, so their lines can be edited independently
+func patchable(node *yaml.Node) bool {
+	if node.Style&(yaml.FlowStyle|yaml.LiteralStyle|yaml.FoldedStyle|yaml.TaggedStyle) != 0 ||
+		node.Kind == yaml.AliasNode || node.Anchor != "" || strings.Contains(node.Value, "\n") {
+		return false
+	}
+	for _, child := range node.Content {
+		if !patchable(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// lastLine returns the last line a node or any of its children is on
+func lastLine(node *yaml.Node) int {
+	last := node.Line
+	for _, child := range node.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
+
+// mappingKeyIndex returns the index of key in the content of a mapping node, or -1 if it isn't set
+func mappingKeyIndex(mapping *yaml.Node, key string) int {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// renderPairs encodes key/value pairs as block mapping lines, each prefixed with indent
+func renderPairs(pairs []*yaml.Node, indent string) ([]string, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.MappingNode, Content: pairs}); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return lines, nil
+}
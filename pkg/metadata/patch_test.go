@@ -0,0 +1,124 @@
+package metadata
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// representativeSpec is laid out the way hand-written specs are, with layout a full
+// re-encode would change: four-space indentation, unindented and flow sequences,
+// quoting that isn't needed and comments
+const representativeSpec = `# example MCP Server Registry Entry
+name: example
+description: 'An example server with a long description that goes on for a while, past the usual line width'
+tier: Community
+status: Active
+transport: stdio
+tools:
+- search    # the main tool
+- fetch
+tags: [example, test]
+image: "example/server:1.0.0"
+env_vars:
+    -   name: EXAMPLE_TOKEN
+        description: Token
+        required: true
+metadata:
+  stars: 10 # from GitHub
+  pulls: 200
+  last_updated: "2026-10-01T00:00:00Z"
+repository_url: https://github.com/example/server
+`
+
+var lastUpdatedPattern = regexp.MustCompile(`last_updated: ["']?[0-9T:Z-]+["']?`)
+
+func TestWriteMetadata_OnlyChangedLines(t *testing.T) {
+	t.Parallel()
+
+	release := "v1.2.0"
+	noRelease := ""
+
+	tests := []struct {
+		name   string
+		spec   string
+		update metadataUpdate
+		want   string
+	}{
+		{
+			name:   "changed stars",
+			spec:   representativeSpec,
+			update: metadataUpdate{stars: 42, pulls: 200},
+			want: strings.NewReplacer(
+				"stars: 10 # from GitHub", "stars: 42 # from GitHub",
+				`last_updated: "2026-10-01T00:00:00Z"`, "last_updated: <now>",
+			).Replace(representativeSpec),
+		},
+		{
+			name: "added and removed keys",
+			spec: strings.Replace(representativeSpec, "  pulls: 200\n",
+				"  pulls: 200\n  latest_release: v1.0.0\n  provenance_verified: true\n", 1),
+			update: metadataUpdate{stars: 10, pulls: 200, latestRelease: &noRelease, counts: &repoInfo{Forks: 3}},
+			want: strings.NewReplacer(
+				`last_updated: "2026-10-01T00:00:00Z"`, "last_updated: <now>\n  forks: 3\n  open_issues: 0",
+			).Replace(representativeSpec),
+		},
+		{
+			name: "nested sources",
+			spec: strings.Replace(representativeSpec, "  last_updated: \"2026-10-01T00:00:00Z\"\n",
+				"  last_updated: \"2026-10-01T00:00:00Z\"\n  sources:\n    stars:\n"+
+					"      url: https://api.github.com/repos/example/server\n"+
+					"      fetched_at: \"2026-10-01T00:00:00Z\"\n", 1),
+			update: metadataUpdate{
+				stars: 42, pulls: 200, latestRelease: &release,
+				sources: map[string]Source{"stars": {
+					URL: "https://api.github.com/repos/example/server", FetchedAt: "2026-10-16T00:00:00Z",
+				}},
+			},
+			want: strings.Replace(representativeSpec, "  stars: 10 # from GitHub\n  pulls: 200\n"+
+				"  last_updated: \"2026-10-01T00:00:00Z\"\n",
+				"  stars: 42 # from GitHub\n  pulls: 200\n  last_updated: <now>\n  sources:\n    stars:\n"+
+					"      url: https://api.github.com/repos/example/server\n"+
+					"      fetched_at: \"2026-10-16T00:00:00Z\"\n  latest_release: v1.2.0\n", 1),
+		},
+		{
+			name: "missing metadata is appended",
+			spec: strings.Replace(representativeSpec, "metadata:\n  stars: 10 # from GitHub\n  pulls: 200\n"+
+				"  last_updated: \"2026-10-01T00:00:00Z\"\n", "", 1),
+			update: metadataUpdate{stars: 42, pulls: 200},
+			want: strings.Replace(representativeSpec, "metadata:\n  stars: 10 # from GitHub\n  pulls: 200\n"+
+				"  last_updated: \"2026-10-01T00:00:00Z\"\n", "", 1) +
+				"metadata:\n  stars: 42\n  pulls: 200\n  last_updated: <now>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeSpec(t, "example", tt.spec)
+			require.NoError(t, writeMetadata(path, tt.update))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, lastUpdatedPattern.ReplaceAllString(string(data), "last_updated: <now>"))
+		})
+	}
+}
+
+func TestWriteMetadata_FlowStyleFallsBack(t *testing.T) {
+	t.Parallel()
+
+	// A flow-style metadata block can't be patched line by line, so the file is re-encoded
+	path := writeSpec(t, "example", "image: example/server:1.0.0\nmetadata: {stars: 10, pulls: 200}\n")
+	require.NoError(t, writeMetadata(path, metadataUpdate{stars: 42, pulls: 200}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "image: example/server:1.0.0\nmetadata: {stars: 42, pulls: 200, last_updated: <now>}\n",
+		lastUpdatedPattern.ReplaceAllString(string(data), "last_updated: <now>"))
+}
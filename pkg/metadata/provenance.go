@@ -0,0 +1,97 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/stacklok/toolhive/pkg/logger"
+	"github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ProvenanceWellKnownPath is where a remote server publishes its provenance, relative to
+// the origin of its URL
+const ProvenanceWellKnownPath = "/.well-known/mcp-provenance.json"
+
+// RemoteVerifier verifies the provenance of remote servers, which have no image to verify
+type RemoteVerifier interface {
+	// VerifyRemoteServer returns true if the server at serverURL matches its declared provenance
+	VerifyRemoteServer(ctx context.Context, serverURL string, provenance *registry.Provenance) (bool, error)
+}
+
+// wellKnownVerifier verifies remote servers against the provenance they publish at
+// ProvenanceWellKnownPath, which must name the same repository, signer and issuer as
+// the spec
+type wellKnownVerifier struct {
+	u *Updater
+}
+
+// VerifyRemoteServer implements RemoteVerifier
+func (v wellKnownVerifier) VerifyRemoteServer(
+	ctx context.Context, serverURL string, provenance *registry.Provenance,
+) (bool, error) {
+	parsed, err := url.Parse(serverURL)
+	if err != nil || parsed.Host == "" {
+		return false, fmt.Errorf("invalid server URL %q", serverURL)
+	}
+	wellKnownURL := (&url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: ProvenanceWellKnownPath}).String()
+
+	resp, err := v.u.get(ctx, wellKnownURL, false)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		logger.Warnf("No provenance published at %s", wellKnownURL)
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s returned %s", wellKnownURL, resp.Status)
+	}
+
+	var published registry.Provenance
+	if err := json.NewDecoder(resp.Body).Decode(&published); err != nil {
+		return false, fmt.Errorf("failed to parse provenance: %w", err)
+	}
+
+	return published.RepositoryURI == provenance.RepositoryURI &&
+		published.SignerIdentity == provenance.SignerIdentity &&
+		published.CertIssuer == provenance.CertIssuer, nil
+}
+
+// verifyProvenance verifies the provenance of an image or remote server, returning false if
+// it has none
+func (u *Updater) verifyProvenance(ctx context.Context, name string, entry *types.RegistryEntry) (bool, error) {
+	if entry.IsRemote() {
+		return u.verifyRemoteProvenance(ctx, name, entry)
+	}
+	return verifyServerProvenance(name, entry)
+}
+
+// verifyRemoteProvenance verifies the provenance information for a remote server, returning
+// false if it has none
+func (u *Updater) verifyRemoteProvenance(ctx context.Context, name string, entry *types.RegistryEntry) (bool, error) {
+	if entry.RemoteProvenance == nil {
+		logger.Warnf("Server %s has no provenance information, skipping verification", name)
+		return false, nil
+	}
+
+	logger.Infof("Verifying provenance for remote server %s at %s", name, entry.URL)
+
+	isVerified, err := u.opts.RemoteVerifier.VerifyRemoteServer(ctx, entry.URL, entry.RemoteProvenance)
+	if err != nil {
+		return false, fmt.Errorf("verification failed: %w", err)
+	}
+
+	if isVerified {
+		logger.Infof("Server %s verified successfully", name)
+		return true, nil
+	}
+
+	return false, fmt.Errorf("published provenance doesn't match the declared provenance")
+}
@@ -0,0 +1,222 @@
+package metadata
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRemoteVerifier records the server it was asked about and returns a fixed result
+type stubRemoteVerifier struct {
+	verified bool
+	err      error
+
+	serverURL  string
+	provenance *registry.Provenance
+}
+
+func (v *stubRemoteVerifier) VerifyRemoteServer(
+	_ context.Context, serverURL string, provenance *registry.Provenance,
+) (bool, error) {
+	v.serverURL, v.provenance = serverURL, provenance
+	return v.verified, v.err
+}
+
+const remoteProvenanceSpec = `url: https://mcp.example.com/mcp
+description: Example remote server
+transport: streamable-http
+provenance:
+  repository_uri: https://github.com/example/server
+  signer_identity: /.github/workflows/release.yml
+  cert_issuer: https://token.actions.githubusercontent.com
+`
+
+func TestUpdater_VerifyRemoteProvenance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		spec         string
+		verifier     *stubRemoteVerifier
+		wantErr      string
+		wantVerified bool
+	}{
+		{
+			name:         "verified",
+			spec:         remoteProvenanceSpec,
+			verifier:     &stubRemoteVerifier{verified: true},
+			wantVerified: true,
+		},
+		{
+			name:     "not verified",
+			spec:     remoteProvenanceSpec,
+			verifier: &stubRemoteVerifier{},
+			wantErr:  "doesn't match the declared provenance",
+		},
+		{
+			name:     "verifier error",
+			spec:     remoteProvenanceSpec,
+			verifier: &stubRemoteVerifier{err: errors.New("connection refused")},
+			wantErr:  "verification failed: connection refused",
+		},
+		{
+			name: "no provenance is skipped",
+			spec: `url: https://mcp.example.com/mcp
+description: Example remote server
+transport: streamable-http
+`,
+			verifier: &stubRemoteVerifier{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeSpec(t, "example", tt.spec)
+			updater := NewUpdater(Options{VerifyProvenance: true, DryRun: true, RemoteVerifier: tt.verifier})
+
+			_, err := updater.UpdateSpec(context.Background(), path)
+			if tt.wantErr != "" {
+				var provenanceErr *ProvenanceVerificationError
+				require.ErrorAs(t, err, &provenanceErr)
+				assert.Equal(t, "example", provenanceErr.ServerName)
+				assert.Contains(t, provenanceErr.Reason, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tt.wantVerified || tt.wantErr != "" {
+				assert.Equal(t, "https://mcp.example.com/mcp", tt.verifier.serverURL)
+				require.NotNil(t, tt.verifier.provenance)
+				assert.Equal(t, "https://github.com/example/server", tt.verifier.provenance.RepositoryURI)
+			} else {
+				assert.Nil(t, tt.verifier.provenance, "the verifier isn't called without provenance")
+			}
+		})
+	}
+}
+
+func TestUpdater_RecordsProvenanceVerification(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		spec         string
+		verify       bool
+		wantVerified bool
+	}{
+		{name: "verified", spec: remoteProvenanceSpec, verify: true, wantVerified: true},
+		{
+			name: "verified previously but not verified now",
+			spec: remoteProvenanceSpec + `metadata:
+  stars: 0
+  pulls: 0
+  last_updated: "2026-10-01T00:00:00Z"
+  provenance_verified: true
+  provenance_verified_at: "2026-10-01T00:00:00Z"
+`,
+		},
+		{
+			name: "no provenance to verify",
+			spec: `url: https://mcp.example.com/mcp
+description: Example remote server
+transport: streamable-http
+`,
+			verify: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeSpec(t, "example", tt.spec)
+			updater := NewUpdater(Options{
+				VerifyProvenance: tt.verify,
+				RemoteVerifier:   &stubRemoteVerifier{verified: true},
+			})
+
+			result, err := updater.UpdateSpec(context.Background(), path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantVerified, result.ProvenanceVerified)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if tt.wantVerified {
+				assert.Contains(t, string(data), "  provenance_verified: true\n")
+				assert.Regexp(t, `  provenance_verified_at: "?\d{4}-\d{2}-\d{2}T`, string(data))
+			} else {
+				assert.NotContains(t, string(data), "provenance_verified")
+			}
+		})
+	}
+}
+
+func TestWellKnownVerifier(t *testing.T) {
+	t.Parallel()
+
+	declared := &registry.Provenance{
+		RepositoryURI:  "https://github.com/example/server",
+		SignerIdentity: "/.github/workflows/release.yml",
+		CertIssuer:     "https://token.actions.githubusercontent.com",
+	}
+
+	tests := []struct {
+		name      string
+		published string
+		status    int
+		want      bool
+		wantErr   bool
+	}{
+		{
+			name: "matching provenance",
+			published: `{"repository_uri": "https://github.com/example/server",
+				"signer_identity": "/.github/workflows/release.yml",
+				"cert_issuer": "https://token.actions.githubusercontent.com"}`,
+			want: true,
+		},
+		{
+			name: "different signer",
+			published: `{"repository_uri": "https://github.com/example/server",
+				"signer_identity": "/.github/workflows/other.yml",
+				"cert_issuer": "https://token.actions.githubusercontent.com"}`,
+			want: false,
+		},
+		{name: "nothing published", status: http.StatusNotFound, want: false},
+		{name: "server error", status: http.StatusInternalServerError, wantErr: true},
+		{name: "invalid document", published: `not json`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != ProvenanceWellKnownPath || tt.status != 0 {
+					w.WriteHeader(max(tt.status, http.StatusNotFound))
+					return
+				}
+				_, _ = w.Write([]byte(tt.published))
+			}))
+			t.Cleanup(server.Close)
+
+			updater := NewUpdater(Options{})
+			verified, err := updater.opts.RemoteVerifier.VerifyRemoteServer(
+				context.Background(), server.URL+"/mcp", declared)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, verified)
+		})
+	}
+}
@@ -0,0 +1,570 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stacklok/toolhive/pkg/logger"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// extractOwnerRepo extracts the owner and repo from a GitHub repository URL, either
+// an HTTPS URL such as https://github.com/owner/repo or an SSH URL such as
+// git@github.com:owner/repo.git. Any host is accepted, so repositories on GitHub
+// Enterprise instances are parsed the same way.
+func extractOwnerRepo(url string) (string, string, error) {
+	_, path, ok := types.SplitRepositoryURL(url)
+	if !ok {
+		return "", "", fmt.Errorf("invalid GitHub URL format: %s", url)
+	}
+
+	// The owner and repo are the first two parts. Later parts point into the repository,
+	// such as the directory of a server in a monorepo.
+	parts := strings.Split(path, "/")
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
+
+// wait blocks until RequestInterval has passed since the previous API request
+func (u *Updater) wait(ctx context.Context) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if u.opts.RequestInterval > 0 && !u.lastRequest.IsZero() {
+		if delay := time.Until(u.lastRequest.Add(u.opts.RequestInterval)); delay > 0 {
+			if err := sleep(ctx, delay); err != nil {
+				return err
+			}
+		}
+	}
+
+	u.lastRequest = time.Now()
+	return nil
+}
+
+// get sends a rate-limited GET request, authenticating with the GitHub token if requested
+func (u *Updater) get(ctx context.Context, url string, githubAuth bool) (*http.Response, error) {
+	if err := u.wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if githubAuth {
+		req.Header.Add("Accept", "application/vnd.github.v3+json")
+		if u.opts.GitHubToken != "" {
+			req.Header.Add("Authorization", "token "+u.opts.GitHubToken)
+		}
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	return resp, nil
+}
+
+// getWithRetry sends a GET request like get, retrying network errors and server errors
+// with exponential backoff and jitter. Client errors are never retried; like the response
+// of the last attempt, they're returned for the caller to handle.
+func (u *Updater) getWithRetry(ctx context.Context, url string, githubAuth bool) (*http.Response, error) {
+	delay := u.opts.RetryDelay
+	for attempt := 1; ; attempt++ {
+		resp, err := u.get(ctx, url, githubAuth)
+		retryable := (err != nil && ctx.Err() == nil) || (err == nil && resp.StatusCode >= http.StatusInternalServerError)
+		if !retryable || attempt >= u.opts.MaxAttempts {
+			return resp, err
+		}
+
+		if err != nil {
+			logger.Debugf("Request to %s failed (attempt %d of %d): %v", url, attempt, u.opts.MaxAttempts, err)
+		} else {
+			logger.Debugf("Request to %s returned %s (attempt %d of %d)", url, resp.Status, attempt, u.opts.MaxAttempts)
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleep(ctx, jitter(delay)); err != nil {
+			return nil, err
+		}
+		delay *= 2
+	}
+}
+
+// jitter returns a random duration between half of d and d, so clients that failed
+// together don't retry together
+func jitter(d time.Duration) time.Duration {
+	half := int64(d / 2)
+	if half <= 0 {
+		return d
+	}
+	return time.Duration(half + rand.Int64N(half)) // #nosec G404 - jitter doesn't need a secure random source
+}
+
+// sleep waits for d, returning early with the context's error if it's canceled
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// getGitHub sends a GET request to the GitHub API like getWithRetry. Once the rate limit
+// is exhausted, requests wait for it to reset with Options.WaitForRateLimit, or fail with
+// a RateLimitError until it resets.
+func (u *Updater) getGitHub(ctx context.Context, url string) (*http.Response, error) {
+	for {
+		if err := u.waitForRateLimit(ctx); err != nil {
+			return nil, err
+		}
+
+		resp, err := u.getWithRetry(ctx, url, true)
+		if err != nil {
+			return nil, err
+		}
+		reset, rejected := u.trackRateLimit(resp)
+		if !rejected {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		// Without a reset time there's nothing to wait for
+		if !u.opts.WaitForRateLimit || reset.IsZero() {
+			return nil, &RateLimitError{Reset: reset}
+		}
+		// Wait at least a second, in case GitHub's clock is behind ours
+		logger.Infof("GitHub API rate limit exceeded, waiting until %s", reset.Format(time.RFC3339))
+		if err := sleep(ctx, max(time.Until(reset), time.Second)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// waitForRateLimit returns a RateLimitError while the GitHub rate limit is known to be
+// exhausted, or sleeps until it resets with Options.WaitForRateLimit
+func (u *Updater) waitForRateLimit(ctx context.Context) error {
+	u.rateLimitMu.Lock()
+	reset := u.rateLimitReset
+	u.rateLimitMu.Unlock()
+
+	if reset.IsZero() || !time.Now().Before(reset) {
+		return nil
+	}
+	if !u.opts.WaitForRateLimit {
+		return &RateLimitError{Reset: reset}
+	}
+
+	logger.Infof("GitHub API rate limit exhausted, waiting until %s", reset.Format(time.RFC3339))
+	return sleep(ctx, time.Until(reset))
+}
+
+// trackRateLimit records when the GitHub rate limit resets from the X-RateLimit headers of a
+// response, once the limit is exhausted. It returns the reset time and true if the response
+// was rejected by the rate limit.
+func (u *Updater) trackRateLimit(resp *http.Response) (time.Time, bool) {
+	remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	var reset time.Time
+	if remaining == 0 {
+		if seconds, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			reset = time.Unix(seconds, 0)
+		}
+	}
+
+	u.rateLimitMu.Lock()
+	u.rateLimitReset = reset
+	u.rateLimitMu.Unlock()
+
+	rejected := resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusTooManyRequests
+	return reset, remaining == 0 && rejected
+}
+
+// githubRepoURL returns the GitHub API URL of a repository
+func (u *Updater) githubRepoURL(owner, repo string) string {
+	return fmt.Sprintf("%s/repos/%s/%s", u.opts.GitHubAPIURL, owner, repo)
+}
+
+// repoInfo holds the counts fetched for a repository
+type repoInfo struct {
+	Stars      int
+	Forks      int
+	OpenIssues int
+}
+
+// getGitHubStars gets the stars count for a GitHub repository
+func (u *Updater) getGitHubStars(ctx context.Context, owner, repo string) (int, error) {
+	info, err := u.getGitHubRepoInfo(ctx, owner, repo)
+	return info.Stars, err
+}
+
+// getGitHubRepoInfo gets the stars, forks and open issues counts for a GitHub repository.
+// GitHub counts open pull requests as issues too.
+func (u *Updater) getGitHubRepoInfo(ctx context.Context, owner, repo string) (repoInfo, error) {
+	url := u.githubRepoURL(owner, repo)
+	resp, err := u.getGitHub(ctx, url)
+	if err != nil {
+		return repoInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return repoInfo{}, fmt.Errorf("GitHub API returned %s: %s", resp.Status, string(body))
+	}
+
+	var info struct {
+		StargazersCount int `json:"stargazers_count"`
+		ForksCount      int `json:"forks_count"`
+		OpenIssuesCount int `json:"open_issues_count"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return repoInfo{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return repoInfo{Stars: info.StargazersCount, Forks: info.ForksCount, OpenIssues: info.OpenIssuesCount}, nil
+}
+
+// githubLatestReleaseURL returns the GitHub API URL of the latest release of a repository
+func (u *Updater) githubLatestReleaseURL(owner, repo string) string {
+	return u.githubRepoURL(owner, repo) + "/releases/latest"
+}
+
+// getGitHubLatestRelease gets the tag of the latest release of a GitHub repository,
+// or an empty string if it has no releases
+func (u *Updater) getGitHubLatestRelease(ctx context.Context, owner, repo string) (string, error) {
+	resp, err := u.getGitHub(ctx, u.githubLatestReleaseURL(owner, repo))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	// The API answers 404 for repositories without a (non-draft, non-prerelease) release
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitHub API returned %s: %s", resp.Status, string(body))
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return release.TagName, nil
+}
+
+// getContainerPullCount fetches the pull count for a container image
+func (u *Updater) getContainerPullCount(ctx context.Context, image string) (int, error) {
+	ref, err := types.ParseImageReference(image)
+	if err != nil {
+		return 0, err
+	}
+
+	switch u.pullSource(ref) {
+	case pullSourceGHCR:
+		return u.getGHCRPullCount(ctx, ref.Name())
+	case pullSourceDockerHub:
+		return u.getDockerHubPullCount(ctx, ref.Repository)
+	case pullSourceQuay:
+		return u.getQuayPullCount(ctx, ref.Repository)
+	case pullSourceECRPublic:
+		return u.getECRPublicPullCount(ctx, ref.Repository)
+	case pullSourceECRPrivate:
+		logger.Debugf("Private ECR registry %s doesn't report pull counts for image %s", ref.Registry, image)
+		return 0, nil
+	case pullSourceSkipped:
+		logger.Debugf("Skipping pull count for image %s on configured host %s", image, ref.Registry)
+		return 0, nil
+	}
+
+	// Unknown registry, return 0
+	logger.Warnf("Unknown registry for image %s, cannot fetch pull count", image)
+	return 0, nil
+}
+
+// pullCountURL returns the API URL the pull count of an image is fetched from, or "" if
+// its registry doesn't report pull counts
+func (u *Updater) pullCountURL(image string) string {
+	ref, err := types.ParseImageReference(image)
+	if err != nil {
+		return ""
+	}
+
+	switch u.pullSource(ref) {
+	case pullSourceDockerHub:
+		return u.dockerHubRepoURL(ref.Repository)
+	case pullSourceQuay:
+		return u.quayRepoURL(ref.Repository)
+	case pullSourceECRPublic:
+		return u.ecrPublicCatalogURL()
+	}
+	return ""
+}
+
+// pullSourceKind identifies where the pull count of an image comes from
+type pullSourceKind int
+
+const (
+	pullSourceUnknown pullSourceKind = iota
+	pullSourceSkipped
+	pullSourceGHCR
+	pullSourceDockerHub
+	pullSourceQuay
+	pullSourceECRPublic
+	pullSourceECRPrivate
+)
+
+// pullSource returns where to fetch the pull count of an image from. Hosts in
+// SkipPullHosts are consulted first, so they can also turn off known registries.
+func (u *Updater) pullSource(ref *types.ImageReference) pullSourceKind {
+	if u.skipsPullHost(ref.Registry) {
+		return pullSourceSkipped
+	}
+
+	switch {
+	case ref.Registry == "ghcr.io":
+		return pullSourceGHCR
+	case ref.IsDockerHub():
+		return pullSourceDockerHub
+	case ref.Registry == "quay.io":
+		return pullSourceQuay
+	case ref.Registry == ecrPublicRegistry:
+		return pullSourceECRPublic
+	case isPrivateECRHost(ref.Registry):
+		return pullSourceECRPrivate
+	}
+	return pullSourceUnknown
+}
+
+// skipsPullHost returns true if the registry host matches an entry of SkipPullHosts
+func (u *Updater) skipsPullHost(host string) bool {
+	host = strings.ToLower(host)
+	for _, skip := range u.opts.SkipPullHosts {
+		skip = strings.ToLower(strings.TrimSpace(skip))
+		if suffix, ok := strings.CutPrefix(skip, "*."); ok {
+			if strings.HasSuffix(host, "."+suffix) {
+				return true
+			}
+			continue
+		}
+		if host == skip {
+			return true
+		}
+	}
+	return false
+}
+
+// getGHCRPullCount fetches pull count for GitHub Container Registry images
+func (u *Updater) getGHCRPullCount(ctx context.Context, imageName string) (int, error) {
+	// GHCR requires authentication to get package statistics
+	if u.opts.GitHubToken == "" {
+		logger.Debugf("No GitHub token available, cannot fetch GHCR pull count for %s", imageName)
+		return 0, nil
+	}
+
+	owner, packageName, err := parseGHCRImageName(imageName)
+	if err != nil {
+		return 0, err
+	}
+
+	url, err := u.fetchGHCRPackageInfo(ctx, owner, packageName)
+	if err != nil {
+		return 0, err
+	}
+
+	return u.fetchGHCRVersions(ctx, url, imageName)
+}
+
+func parseGHCRImageName(imageName string) (string, string, error) {
+	// Parse the image name: ghcr.io/owner/repo/package or ghcr.io/owner/package
+	imageName = strings.TrimPrefix(imageName, "ghcr.io/")
+	parts := strings.Split(imageName, "/")
+	if len(parts) < 2 {
+		return "", "", fmt.Errorf("invalid GHCR image format: %s", imageName)
+	}
+
+	owner := parts[0]
+	// The package name is everything after the owner
+	packageName := strings.Join(parts[1:], "/")
+	return owner, packageName, nil
+}
+
+func (u *Updater) fetchGHCRPackageInfo(ctx context.Context, owner, packageName string) (string, error) {
+	// GitHub Packages API endpoint for container packages
+	url := fmt.Sprintf("%s/users/%s/packages/container/%s", u.opts.GitHubAPIURL, owner, packageName)
+
+	resp, err := u.getGitHub(ctx, url)
+	if err != nil {
+		// Try org endpoint if user endpoint fails
+		url = fmt.Sprintf("%s/orgs/%s/packages/container/%s", u.opts.GitHubAPIURL, owner, packageName)
+		resp, err = u.getGitHub(ctx, url)
+		if err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound && strings.Contains(url, "/users/") {
+		// Try org endpoint if user endpoint returned 404
+		url = strings.Replace(url, "/users/", "/orgs/", 1)
+		resp, err = u.getGitHub(ctx, url)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Debugf("Could not fetch GHCR package stats (status %d)", resp.StatusCode)
+		return "", fmt.Errorf("package not found or no access")
+	}
+
+	var packageInfo struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&packageInfo); err != nil {
+		return "", fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return url, nil
+}
+
+func (u *Updater) fetchGHCRVersions(ctx context.Context, baseURL, imageName string) (int, error) {
+	versionsURL := fmt.Sprintf("%s/versions?per_page=100", baseURL)
+	resp, err := u.getGitHub(ctx, versionsURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create versions request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		logger.Debugf("Could not fetch GHCR package versions (status %d) for %s", resp.StatusCode, imageName)
+		return 0, nil
+	}
+
+	var versions []struct {
+		Metadata struct {
+			Container struct {
+				Tags []string `json:"tags"`
+			} `json:"container"`
+		} `json:"metadata"`
+		// Unfortunately, GitHub API doesn't expose download_count for container packages
+		// in the same way it does for other package types
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&versions); err != nil {
+		return 0, fmt.Errorf("failed to parse versions response: %w", err)
+	}
+
+	// GitHub doesn't expose container download counts through the API
+	// even with authentication. This is a known limitation.
+	// Return 0 to indicate we couldn't get the data
+	logger.Debugf("GHCR package found but download count not available through API for %s", imageName)
+	return 0, nil
+}
+
+// dockerHubRepoURL returns the Docker Hub API URL of a repository
+func (u *Updater) dockerHubRepoURL(imageName string) string {
+	// Remove docker.io prefix if present
+	imageName = strings.TrimPrefix(imageName, "docker.io/")
+	return fmt.Sprintf("%s/v2/repositories/%s/", u.opts.DockerHubAPIURL, imageName)
+}
+
+// getDockerHubPullCount fetches pull count for Docker Hub images
+func (u *Updater) getDockerHubPullCount(ctx context.Context, imageName string) (int, error) {
+	url := u.dockerHubRepoURL(imageName)
+	resp, err := u.get(ctx, url, false)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Not found or error - return 0
+		return 0, nil
+	}
+
+	var dockerHubResp struct {
+		PullCount int `json:"pull_count"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&dockerHubResp); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return dockerHubResp.PullCount, nil
+}
+
+// quayRepoURL returns the Quay API URL of a repository, including its statistics
+func (u *Updater) quayRepoURL(repository string) string {
+	return fmt.Sprintf("%s/api/v1/repository/%s?includeStats=true", u.opts.QuayAPIURL, repository)
+}
+
+// getQuayPullCount fetches the pull count for Quay.io images by adding up the
+// repository's pull statistics. Private repositories and repositories the API
+// can't be asked about, such as ones without a namespace, have a pull count of 0.
+func (u *Updater) getQuayPullCount(ctx context.Context, repository string) (int, error) {
+	// Quay repositories are always namespace/name
+	if parts := strings.Split(repository, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		logger.Warnf("Invalid Quay repository %s, cannot fetch pull count", repository)
+		return 0, nil
+	}
+
+	resp, err := u.get(ctx, u.quayRepoURL(repository), false)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		// Private, not found or error - return 0
+		logger.Debugf("Could not fetch Quay repository stats (status %d) for %s", resp.StatusCode, repository)
+		return 0, nil
+	}
+
+	var quayResp struct {
+		IsPublic bool `json:"is_public"`
+		Stats    []struct {
+			Count int `json:"count"`
+		} `json:"stats"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&quayResp); err != nil {
+		return 0, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	if !quayResp.IsPublic {
+		return 0, nil
+	}
+
+	pulls := 0
+	for _, stat := range quayResp.Stats {
+		pulls += stat.Count
+	}
+	return pulls, nil
+}
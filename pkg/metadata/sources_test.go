@@ -0,0 +1,597 @@
+package metadata
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestUpdater_PullSource(t *testing.T) {
+	t.Parallel()
+
+	skipHosts := []string{"registry.internal.example.com", "*.corp.example.com", "GHCR.io"}
+
+	tests := []struct {
+		name      string
+		image     string
+		skipHosts []string
+		want      pullSourceKind
+	}{
+		{name: "docker hub", image: "mcp/fetch:latest", want: pullSourceDockerHub},
+		{name: "ghcr", image: "ghcr.io/example/server:latest", want: pullSourceGHCR},
+		{name: "quay", image: "quay.io/example/server:latest", want: pullSourceQuay},
+		{name: "ecr public", image: "public.ecr.aws/a1b2c3/server:latest", want: pullSourceECRPublic},
+		{name: "private ecr", image: "123456789012.dkr.ecr.us-east-1.amazonaws.com/server:latest", want: pullSourceECRPrivate},
+		{name: "unconfigured unknown host warns", image: "registry.example.com/example/server:latest", want: pullSourceUnknown},
+		{
+			name:      "unknown host not in skip list still warns",
+			image:     "registry.other.example.com/server:latest",
+			skipHosts: skipHosts,
+			want:      pullSourceUnknown,
+		},
+		{
+			name:      "configured host is skipped",
+			image:     "registry.internal.example.com/team/server:latest",
+			skipHosts: skipHosts,
+			want:      pullSourceSkipped,
+		},
+		{
+			name:      "wildcard matches subdomains",
+			image:     "images.corp.example.com/server:latest",
+			skipHosts: skipHosts,
+			want:      pullSourceSkipped,
+		},
+		{
+			name:      "wildcard doesn't match the bare domain",
+			image:     "corp.example.com/server:latest",
+			skipHosts: skipHosts,
+			want:      pullSourceUnknown,
+		},
+		{
+			name:      "configured hosts take precedence over known registries",
+			image:     "ghcr.io/example/server:latest",
+			skipHosts: skipHosts,
+			want:      pullSourceSkipped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ref, err := types.ParseImageReference(tt.image)
+			require.NoError(t, err)
+
+			updater := NewUpdater(Options{SkipPullHosts: tt.skipHosts})
+			assert.Equal(t, tt.want, updater.pullSource(ref))
+		})
+	}
+}
+
+func TestUpdater_SkippedHostKeepsPulls(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+	path := writeSpec(t, "example", `image: registry.internal.example.com/example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+`)
+
+	updater := NewUpdater(Options{
+		DryRun:          true,
+		GitHubAPIURL:    github.URL,
+		DockerHubAPIURL: dockerHub.URL,
+		SkipPullHosts:   []string{"registry.internal.example.com"},
+	})
+
+	result, err := updater.UpdateSpec(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, 42, result.NewStars)
+	assert.Equal(t, 100, result.NewPulls)
+}
+
+func TestUpdater_DockerHubPullCount(t *testing.T) {
+	t.Parallel()
+
+	dockerHub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/repositories/library/redis/":
+			_, _ = w.Write([]byte(`{"pull_count": 5000}`))
+		case "/v2/repositories/user/repo/":
+			_, _ = w.Write([]byte(`{"pull_count": 42}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(dockerHub.Close)
+
+	tests := []struct {
+		name  string
+		image string
+		want  int
+	}{
+		{name: "official image shorthand", image: "redis", want: 5000},
+		{name: "official image with library namespace", image: "library/redis:7", want: 5000},
+		{name: "fully qualified official image", image: "docker.io/library/redis:7", want: 5000},
+		{name: "index host", image: "index.docker.io/library/redis:7", want: 5000},
+		{name: "registry host without library namespace", image: "registry-1.docker.io/redis:7", want: 5000},
+		{name: "user image", image: "user/repo:1.0.0", want: 42},
+		{name: "fully qualified user image", image: "docker.io/user/repo:1.0.0", want: 42},
+		{name: "unknown repository", image: "user/missing:1.0.0", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := NewUpdater(Options{DockerHubAPIURL: dockerHub.URL})
+			pulls, err := updater.getContainerPullCount(context.Background(), tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pulls)
+		})
+	}
+}
+
+func TestUpdater_QuayPullCount(t *testing.T) {
+	t.Parallel()
+
+	quay := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/repository/example/server":
+			_, _ = w.Write([]byte(`{"is_public": true, "stats": [{"date": "2026-10-01", "count": 120}, {"date": "2026-10-02", "count": 80}]}`))
+		case "/api/v1/repository/example/unlisted":
+			_, _ = w.Write([]byte(`{"is_public": false, "stats": [{"date": "2026-10-01", "count": 5}]}`))
+		case "/api/v1/repository/foo":
+			// Quay never serves repositories without a namespace, so this is never requested
+			_, _ = w.Write([]byte(`{"is_public": true, "stats": [{"date": "2026-10-01", "count": 999}]}`))
+		case "/api/v1/repository/example/private":
+			http.Error(w, `{"error": "Unauthorized"}`, http.StatusUnauthorized)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(quay.Close)
+
+	tests := []struct {
+		name  string
+		image string
+		want  int
+	}{
+		{name: "public repository", image: "quay.io/example/server:1.0.0", want: 200},
+		{name: "public repository without tag", image: "quay.io/example/server", want: 200},
+		{name: "private repository", image: "quay.io/example/private:1.0.0", want: 0},
+		{name: "repository that isn't public", image: "quay.io/example/unlisted:1.0.0", want: 0},
+		{name: "unknown repository", image: "quay.io/example/missing:1.0.0", want: 0},
+		{name: "repository without namespace", image: "quay.io/foo", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := NewUpdater(Options{QuayAPIURL: quay.URL})
+			pulls, err := updater.getContainerPullCount(context.Background(), tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pulls)
+		})
+	}
+}
+
+func TestUpdater_ECRPublicPullCount(t *testing.T) {
+	t.Parallel()
+
+	ecrPublic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/getRepositoryCatalogData" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			RegistryAliasName string `json:"registryAliasName"`
+			RepositoryName    string `json:"repositoryName"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		switch req.RegistryAliasName + "/" + req.RepositoryName {
+		case "a1b2c3/server":
+			_, _ = w.Write([]byte(`{"catalogData": {"description": "Example server"}, ` +
+				`"insightData": {"downloadCount": 4321, "lastUpdated": "2026-10-01T00:00:00Z"}}`))
+		case "a1b2c3/team/server":
+			_, _ = w.Write([]byte(`{"insightData": {"downloadCount": 12}}`))
+		case "a1b2c3/new":
+			_, _ = w.Write([]byte(`{"catalogData": {}}`))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type": "RepositoryNotFoundException"}`))
+		}
+	}))
+	t.Cleanup(ecrPublic.Close)
+
+	tests := []struct {
+		name  string
+		image string
+		want  int
+	}{
+		{name: "public repository", image: "public.ecr.aws/a1b2c3/server:1.0.0", want: 4321},
+		{name: "nested repository", image: "public.ecr.aws/a1b2c3/team/server@sha256:" + strings.Repeat("a", 64), want: 12},
+		{name: "repository without insights", image: "public.ecr.aws/a1b2c3/new:1.0.0", want: 0},
+		{name: "unknown repository", image: "public.ecr.aws/a1b2c3/missing:1.0.0", want: 0},
+		{name: "repository without alias", image: "public.ecr.aws/server:1.0.0", want: 0},
+		{name: "private registry", image: "123456789012.dkr.ecr.us-east-1.amazonaws.com/server:1.0.0", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := NewUpdater(Options{ECRPublicAPIURL: ecrPublic.URL})
+			pulls, err := updater.getContainerPullCount(context.Background(), tt.image)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, pulls)
+		})
+	}
+}
+
+func TestExtractOwnerRepo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		url       string
+		wantOwner string
+		wantRepo  string
+		wantErr   bool
+	}{
+		{name: "https", url: "https://github.com/example/server", wantOwner: "example", wantRepo: "server"},
+		{name: "https with .git", url: "https://github.com/example/server.git", wantOwner: "example", wantRepo: "server"},
+		{name: "https with trailing slash", url: "https://github.com/example/server/", wantOwner: "example", wantRepo: "server"},
+		{name: "https with .git and trailing slash", url: "https://github.com/example/server.git/", wantOwner: "example", wantRepo: "server"},
+		{name: "ssh", url: "git@github.com:example/server", wantOwner: "example", wantRepo: "server"},
+		{name: "ssh with .git", url: "git@github.com:example/server.git", wantOwner: "example", wantRepo: "server"},
+		{name: "ssh with trailing slash", url: "git@github.com:example/server/", wantOwner: "example", wantRepo: "server"},
+		{name: "ssh scheme", url: "ssh://git@github.com/example/server.git", wantOwner: "example", wantRepo: "server"},
+		{name: "repo name with dots", url: "git@github.com:example/server.js.git", wantOwner: "example", wantRepo: "server.js"},
+		{name: "path into the repository", url: "https://github.com/example/servers/tree/main/src/fetch", wantOwner: "example", wantRepo: "servers"},
+		{name: "enterprise https", url: "https://github.example.com/team/server", wantOwner: "team", wantRepo: "server"},
+		{name: "enterprise https with port", url: "https://github.example.com:8443/team/server.git", wantOwner: "team", wantRepo: "server"},
+		{name: "enterprise ssh", url: "git@github.example.com:team/server.git", wantOwner: "team", wantRepo: "server"},
+		{name: "enterprise ssh scheme with port", url: "ssh://git@github.example.com:7999/team/server", wantOwner: "team", wantRepo: "server"},
+		{name: "ssh without repo", url: "git@github.com:example", wantErr: true},
+		{name: "https without repo", url: "https://github.com/example", wantErr: true},
+		{name: "neither form", url: "example/server", wantErr: true},
+		{name: "empty", url: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			owner, repo, err := extractOwnerRepo(tt.url)
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "invalid GitHub URL format")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantOwner, owner)
+			assert.Equal(t, tt.wantRepo, repo)
+		})
+	}
+}
+
+func TestUpdater_GitHubRetries(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// failures are the responses before the API succeeds: a status code, or 0 to drop the connection
+		failures     []int
+		maxAttempts  int
+		wantStars    int
+		wantErr      bool
+		wantRequests int
+	}{
+		{name: "fails twice then succeeds", failures: []int{0, http.StatusBadGateway}, wantStars: 42, wantRequests: 3},
+		{name: "succeeds first time", wantStars: 42, wantRequests: 1},
+		{name: "gives up after max attempts", failures: []int{500, 502, 503}, wantErr: true, wantRequests: 3},
+		{name: "max attempts is configurable", failures: []int{500, 502}, maxAttempts: 2, wantErr: true, wantRequests: 2},
+		{name: "client errors are not retried", failures: []int{http.StatusNotFound}, wantErr: true, wantRequests: 1},
+		{name: "rate limits are not retried", failures: []int{http.StatusForbidden}, wantErr: true, wantRequests: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var requests atomic.Int32
+			github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				attempt := int(requests.Add(1))
+				if attempt > len(tt.failures) {
+					_, _ = w.Write([]byte(`{"stargazers_count": 42}`))
+					return
+				}
+				if status := tt.failures[attempt-1]; status != 0 {
+					w.WriteHeader(status)
+					return
+				}
+				conn, _, err := w.(http.Hijacker).Hijack()
+				require.NoError(t, err)
+				conn.Close()
+			}))
+			t.Cleanup(github.Close)
+
+			updater := NewUpdater(Options{
+				GitHubAPIURL: github.URL,
+				MaxAttempts:  tt.maxAttempts,
+				RetryDelay:   time.Millisecond,
+				Transport:    &http.Transport{},
+			})
+			stars, err := updater.getGitHubStars(context.Background(), "example", "server")
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantStars, stars)
+			}
+			assert.Equal(t, tt.wantRequests, int(requests.Load()))
+		})
+	}
+}
+
+func TestUpdater_GitHubRepoInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+		want repoInfo
+	}{
+		{
+			name: "all counts",
+			body: `{"stargazers_count": 42, "forks_count": 8, "open_issues_count": 3, "watchers_count": 42}`,
+			want: repoInfo{Stars: 42, Forks: 8, OpenIssues: 3},
+		},
+		{
+			name: "missing counts are zero",
+			body: `{"stargazers_count": 42}`,
+			want: repoInfo{Stars: 42},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			t.Cleanup(github.Close)
+
+			updater := NewUpdater(Options{GitHubAPIURL: github.URL})
+			info, err := updater.getGitHubRepoInfo(context.Background(), "example", "server")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, info)
+		})
+	}
+}
+
+func TestUpdater_RetryStopsWhenCanceled(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(github.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	updater := NewUpdater(Options{GitHubAPIURL: github.URL, MaxAttempts: 10, RetryDelay: time.Hour})
+	_, err := updater.getGitHubStars(ctx, "example", "server")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestJitter(t *testing.T) {
+	t.Parallel()
+
+	for range 100 {
+		d := jitter(time.Second)
+		assert.GreaterOrEqual(t, d, 500*time.Millisecond)
+		assert.Less(t, d, time.Second)
+	}
+	assert.Equal(t, time.Duration(1), jitter(1))
+}
+
+// newRateLimitedGitHub returns a fake GitHub API whose first response is rejected by an exhausted
+// rate limit that resets at reset, and a counter of the requests it received
+func newRateLimitedGitHub(t *testing.T, reset time.Time) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+			http.Error(w, `{"message": "API rate limit exceeded"}`, http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		_, _ = w.Write([]byte(`{"stargazers_count": 42}`))
+	}))
+	t.Cleanup(github.Close)
+
+	return github, &requests
+}
+
+func TestUpdater_RateLimitError(t *testing.T) {
+	t.Parallel()
+
+	reset := time.Now().Add(time.Hour).Truncate(time.Second)
+	github, requests := newRateLimitedGitHub(t, reset)
+	updater := NewUpdater(Options{GitHubAPIURL: github.URL, RetryDelay: time.Millisecond})
+
+	_, err := updater.getGitHubStars(context.Background(), "example", "server")
+	var rateLimitErr *RateLimitError
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.True(t, reset.Equal(rateLimitErr.Reset))
+	assert.Equal(t, int32(1), requests.Load())
+
+	// Later requests fail without reaching the API until the reset
+	_, err = updater.getGitHubStars(context.Background(), "example", "other")
+	require.ErrorAs(t, err, &rateLimitErr)
+	assert.Equal(t, int32(1), requests.Load())
+
+	// The stars in the spec are kept
+	info, source := updater.updatedRepoInfo(context.Background(), "server", "https://github.com/example/server", repoInfo{Stars: 10})
+	assert.Equal(t, 10, info.Stars)
+	assert.Nil(t, source)
+}
+
+func TestUpdater_WaitForRateLimit(t *testing.T) {
+	t.Parallel()
+
+	// The reset is already due, so only the minimum wait applies
+	github, requests := newRateLimitedGitHub(t, time.Now())
+	updater := NewUpdater(Options{GitHubAPIURL: github.URL, RetryDelay: time.Millisecond, WaitForRateLimit: true})
+
+	start := time.Now()
+	stars, err := updater.getGitHubStars(context.Background(), "example", "server")
+	require.NoError(t, err)
+	assert.Equal(t, 42, stars)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+}
+
+func TestUpdater_RateLimitWithoutExhaustion(t *testing.T) {
+	t.Parallel()
+
+	// A 403 that isn't from the rate limit is an ordinary error
+	github := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "12")
+		http.Error(w, `{"message": "Forbidden"}`, http.StatusForbidden)
+	}))
+	t.Cleanup(github.Close)
+
+	updater := NewUpdater(Options{GitHubAPIURL: github.URL, WaitForRateLimit: true})
+	_, err := updater.getGitHubStars(context.Background(), "example", "server")
+	require.Error(t, err)
+	var rateLimitErr *RateLimitError
+	assert.NotErrorAs(t, err, &rateLimitErr)
+}
+
+func TestUpdater_HTTPTimeout(t *testing.T) {
+	t.Parallel()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(500 * time.Millisecond):
+		case <-r.Context().Done():
+			return
+		}
+		_, _ = w.Write([]byte(`{"stargazers_count": 42, "pull_count": 1234}`))
+	}))
+	t.Cleanup(slow.Close)
+
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		wantErr bool
+	}{
+		{name: "too short", timeout: 50 * time.Millisecond, wantErr: true},
+		{name: "long enough", timeout: 5 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			updater := NewUpdater(Options{
+				GitHubAPIURL:    slow.URL,
+				DockerHubAPIURL: slow.URL,
+				HTTPTimeout:     tt.timeout,
+				MaxAttempts:     1,
+			})
+
+			// The timeout applies to GitHub and container registry requests alike
+			start := time.Now()
+			stars, starsErr := updater.getGitHubStars(context.Background(), "example", "server")
+			pulls, pullsErr := updater.getContainerPullCount(context.Background(), "example/server:1.0.0")
+			if tt.wantErr {
+				assert.ErrorContains(t, starsErr, "Client.Timeout exceeded")
+				assert.ErrorContains(t, pullsErr, "Client.Timeout exceeded")
+				assert.Less(t, time.Since(start), 500*time.Millisecond)
+				return
+			}
+			require.NoError(t, starsErr)
+			require.NoError(t, pullsErr)
+			assert.Equal(t, 42, stars)
+			assert.Equal(t, 1234, pulls)
+		})
+	}
+}
+
+func TestNewUpdater_DefaultHTTPTimeout(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultHTTPTimeout, NewUpdater(Options{}).client.Timeout)
+	assert.Equal(t, time.Minute, NewUpdater(Options{HTTPTimeout: time.Minute}).client.Timeout)
+}
+
+func TestUpdater_GitHubEnterprise(t *testing.T) {
+	t.Parallel()
+
+	// An enterprise instance serves its API under /api/v3
+	enterprise := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/team/server":
+			_, _ = w.Write([]byte(`{"stargazers_count": 17}`))
+		case "/api/v3/repos/team/server/releases/latest":
+			_, _ = w.Write([]byte(`{"tag_name": "v2.0.0"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(enterprise.Close)
+
+	dir := filepath.Join(t.TempDir(), "internal")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`url: https://mcp.internal.example.com/mcp
+description: Internal server
+transport: streamable-http
+repository_url: https://github.example.com/team/server
+tools:
+  - search
+`), 0644))
+
+	updater := NewUpdater(Options{GitHubAPIURL: enterprise.URL + "/api/v3/", LatestRelease: true})
+	result, err := updater.UpdateSpec(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, 17, result.NewStars)
+	assert.Equal(t, "v2.0.0", result.NewLatestRelease)
+	assert.Equal(t, enterprise.URL+"/api/v3/repos/team/server", updater.githubRepoURL("team", "server"))
+}
@@ -0,0 +1,37 @@
+package metadata
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// maxIdleConnsPerHost bounds the idle connections kept open to each API host
+	maxIdleConnsPerHost = 4
+	// maxConnsPerHost bounds the concurrent connections to each API host
+	maxConnsPerHost = 8
+	// idleConnTimeout is how long an idle connection is kept before it's closed
+	idleConnTimeout = 90 * time.Second
+)
+
+// sharedTransport is the transport used by updaters that aren't given one
+var sharedTransport = sync.OnceValue(NewTransport)
+
+// NewTransport creates an HTTP transport for the metadata fetchers.
+// Connections are kept alive and reused across requests, and the pool is
+// bounded since batch runs only talk to a handful of API hosts.
+func NewTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = false
+	transport.MaxIdleConns = maxIdleConnsPerHost * 4
+	transport.MaxIdleConnsPerHost = maxIdleConnsPerHost
+	transport.MaxConnsPerHost = maxConnsPerHost
+	transport.IdleConnTimeout = idleConnTimeout
+	return transport
+}
+
+// SharedTransport returns the transport shared by every updater created without Options.Transport
+func SharedTransport() *http.Transport {
+	return sharedTransport()
+}
@@ -0,0 +1,65 @@
+package metadata
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTransport(t *testing.T) {
+	t.Parallel()
+
+	transport := NewTransport()
+	assert.False(t, transport.DisableKeepAlives)
+	assert.Equal(t, maxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+	assert.Equal(t, maxConnsPerHost, transport.MaxConnsPerHost)
+	assert.Positive(t, transport.MaxIdleConns)
+}
+
+func TestNewUpdater_Transport(t *testing.T) {
+	t.Parallel()
+
+	// Updaters without a transport share the same one
+	first := NewUpdater(Options{})
+	second := NewUpdater(Options{})
+	assert.Same(t, SharedTransport(), first.client.Transport)
+	assert.Same(t, first.client.Transport, second.client.Transport)
+	assert.False(t, SharedTransport().DisableKeepAlives)
+
+	// An explicit transport is used as is
+	transport := NewTransport()
+	updater := NewUpdater(Options{Transport: transport})
+	assert.Same(t, transport, updater.client.Transport)
+}
+
+func TestUpdater_ReusesConnections(t *testing.T) {
+	t.Parallel()
+
+	var connections atomic.Int32
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprint(w, `{"stargazers_count": 42}`)
+	}))
+	server.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			connections.Add(1)
+		}
+	}
+	server.Start()
+	t.Cleanup(server.Close)
+
+	updater := NewUpdater(Options{GitHubAPIURL: server.URL, Transport: NewTransport()})
+	for range 3 {
+		stars, err := updater.getGitHubStars(context.Background(), "example", "server")
+		require.NoError(t, err)
+		assert.Equal(t, 42, stars)
+	}
+
+	assert.Equal(t, int32(1), connections.Load())
+}
@@ -0,0 +1,748 @@
+// Package metadata refreshes the GitHub stars and container pull counts of registry entries
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stacklok/toolhive/pkg/container/verifier"
+	"github.com/stacklok/toolhive/pkg/logger"
+	"github.com/stacklok/toolhive/pkg/registry"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+const (
+	// DefaultGitHubAPIURL is the base URL of the GitHub API
+	DefaultGitHubAPIURL = "https://api.github.com"
+	// DefaultDockerHubAPIURL is the base URL of the Docker Hub API
+	DefaultDockerHubAPIURL = "https://hub.docker.com"
+	// DefaultQuayAPIURL is the base URL of the Quay.io API
+	DefaultQuayAPIURL = "https://quay.io"
+	// DefaultMaxAttempts is how many times a GitHub API request is attempted by default
+	DefaultMaxAttempts = 3
+	// DefaultRetryDelay is the backoff before the first retry of a GitHub API request by default
+	DefaultRetryDelay = 500 * time.Millisecond
+	// DefaultHTTPTimeout is how long a single API request may take by default
+	DefaultHTTPTimeout = 10 * time.Second
+)
+
+// Options configures an Updater
+type Options struct {
+	// GitHubToken authenticates GitHub API requests. It is optional, but
+	// unauthenticated requests are heavily rate limited and GHCR lookups are skipped.
+	GitHubToken string
+	// DryRun reports the new values without writing them to the spec files
+	DryRun bool
+	// VerifyProvenance verifies image or remote server provenance before updating and fails
+	// if verification fails
+	VerifyProvenance bool
+	// RemoteVerifier verifies the provenance of remote servers. Defaults to checking the
+	// provenance the server publishes at ProvenanceWellKnownPath.
+	RemoteVerifier RemoteVerifier
+	// RequestInterval is the minimum time between API requests (zero disables rate limiting)
+	RequestInterval time.Duration
+	// GitHubAPIURL overrides DefaultGitHubAPIURL, such as with the API of a GitHub Enterprise
+	// instance (https://github.example.com/api/v3) when repositories are hosted there
+	GitHubAPIURL string
+	// DockerHubAPIURL overrides DefaultDockerHubAPIURL
+	DockerHubAPIURL string
+	// QuayAPIURL overrides DefaultQuayAPIURL
+	QuayAPIURL string
+	// ECRPublicAPIURL overrides DefaultECRPublicAPIURL
+	ECRPublicAPIURL string
+	// MaxAttempts is how many times a GitHub API request that fails with a server error
+	// or a network error is attempted. Defaults to DefaultMaxAttempts; 1 disables retries.
+	MaxAttempts int
+	// RetryDelay is the backoff before the first retry, doubled for each further retry and
+	// jittered. Defaults to DefaultRetryDelay.
+	RetryDelay time.Duration
+	// Transport is used for all API requests. Defaults to SharedTransport so
+	// connections are reused across every updater in a batch run.
+	Transport *http.Transport
+	// HTTPTimeout is how long a single API request may take, including reading the
+	// response. Defaults to DefaultHTTPTimeout.
+	HTTPTimeout time.Duration
+	// SkipPullHosts lists registry hosts (such as private or internal registries) whose
+	// pull counts are skipped without warning. A leading "*." matches any subdomain.
+	SkipPullHosts []string
+	// RecordSources writes the API URL and fetch time of each fetched value to a
+	// metadata.sources block. Without it, an existing sources block is left untouched.
+	RecordSources bool
+	// WaitForRateLimit sleeps until the GitHub rate limit resets once it's exhausted.
+	// Without it, GitHub requests fail with a RateLimitError until the reset.
+	WaitForRateLimit bool
+	// GitLabHosts lists the hosts of self-hosted GitLab instances, whose repositories get
+	// their stars from the GitLab API like gitlab.com repositories
+	GitLabHosts []string
+	// GitLabAPIURL overrides the GitLab API URL, otherwise https://<host>/api/v4 for the
+	// repository's host
+	GitLabAPIURL string
+	// ExtendedMetadata also writes the repository's forks and open issues counts to
+	// metadata.forks and metadata.open_issues. Without it, those fields are left untouched.
+	ExtendedMetadata bool
+	// LatestRelease also fetches the tag of the latest GitHub release of the repository, or of
+	// stars_source if set, into metadata.latest_release, removing the field if it has no releases
+	LatestRelease bool
+}
+
+// Source records where and when a metadata value was fetched
+type Source struct {
+	URL       string `json:"url" yaml:"url"`
+	FetchedAt string `json:"fetched_at" yaml:"fetched_at"`
+}
+
+// ProvenanceVerificationError represents an error during provenance verification
+type ProvenanceVerificationError struct {
+	ServerName string
+	Reason     string
+}
+
+func (e *ProvenanceVerificationError) Error() string {
+	return fmt.Sprintf("provenance verification failed for server %s: %s", e.ServerName, e.Reason)
+}
+
+// RateLimitError is returned for GitHub API requests made while the rate limit is exhausted
+type RateLimitError struct {
+	// Reset is when the rate limit resets
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("GitHub API rate limit exceeded, resets at %s", e.Reset.Format(time.RFC3339))
+}
+
+// Result describes the metadata refresh of a single spec file
+type Result struct {
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	OldStars int    `json:"old_stars"`
+	NewStars int    `json:"new_stars"`
+	OldPulls int    `json:"old_pulls"`
+	NewPulls int    `json:"new_pulls"`
+	Written  bool   `json:"written"`
+	// OldLastUpdated is the metadata.last_updated of the spec before the refresh
+	OldLastUpdated string `json:"old_last_updated,omitempty"`
+	// Sources maps the fields that were fetched (stars, pulls) to where they came from.
+	// It's only set with Options.RecordSources.
+	Sources map[string]Source `json:"sources,omitempty"`
+	// OldLatestRelease and NewLatestRelease are the latest release tag before and after the
+	// refresh, empty without releases. They're only set with Options.LatestRelease.
+	OldLatestRelease string `json:"old_latest_release,omitempty"`
+	NewLatestRelease string `json:"new_latest_release,omitempty"`
+	// OldForks, NewForks, OldOpenIssues and NewOpenIssues are the repository's forks and
+	// open issues counts before and after the refresh. They're only set with
+	// Options.ExtendedMetadata.
+	OldForks      int `json:"old_forks,omitempty"`
+	NewForks      int `json:"new_forks,omitempty"`
+	OldOpenIssues int `json:"old_open_issues,omitempty"`
+	NewOpenIssues int `json:"new_open_issues,omitempty"`
+	// ProvenanceVerified is true if the entry's provenance was verified, which is recorded
+	// in the spec. It's only set with Options.VerifyProvenance.
+	ProvenanceVerified bool `json:"provenance_verified,omitempty"`
+}
+
+// Changed returns true if any of the refreshed values differ from the values in the spec file
+func (r Result) Changed() bool {
+	return r.OldStars != r.NewStars || r.OldPulls != r.NewPulls || r.OldLatestRelease != r.NewLatestRelease ||
+		r.OldForks != r.NewForks || r.OldOpenIssues != r.NewOpenIssues
+}
+
+// Stale returns true if nothing changed and the spec's metadata was last updated more than
+// maxAge before now, or has no valid last_updated timestamp. A stale entry's upstream may
+// have gone away.
+func (r Result) Stale(now time.Time, maxAge time.Duration) bool {
+	if r.Changed() {
+		return false
+	}
+	lastUpdated, err := time.Parse(time.RFC3339, r.OldLastUpdated)
+	return err != nil || now.Sub(lastUpdated) > maxAge
+}
+
+// Updater fetches the latest stars and pulls for registry entries and writes them to their spec files
+type Updater struct {
+	opts   Options
+	client *http.Client
+
+	mu          sync.Mutex
+	lastRequest time.Time
+
+	// rateLimitMu guards rateLimitReset separately from mu, which is held while waiting between requests
+	rateLimitMu    sync.Mutex
+	rateLimitReset time.Time
+}
+
+// NewUpdater creates a new metadata updater
+func NewUpdater(opts Options) *Updater {
+	if opts.GitHubAPIURL == "" {
+		opts.GitHubAPIURL = DefaultGitHubAPIURL
+	}
+	opts.GitHubAPIURL = strings.TrimRight(opts.GitHubAPIURL, "/")
+	if opts.DockerHubAPIURL == "" {
+		opts.DockerHubAPIURL = DefaultDockerHubAPIURL
+	}
+	if opts.QuayAPIURL == "" {
+		opts.QuayAPIURL = DefaultQuayAPIURL
+	}
+	if opts.ECRPublicAPIURL == "" {
+		opts.ECRPublicAPIURL = DefaultECRPublicAPIURL
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultRetryDelay
+	}
+	if opts.Transport == nil {
+		opts.Transport = SharedTransport()
+	}
+	if opts.HTTPTimeout <= 0 {
+		opts.HTTPTimeout = DefaultHTTPTimeout
+	}
+
+	u := &Updater{
+		opts:   opts,
+		client: &http.Client{Transport: opts.Transport, Timeout: opts.HTTPTimeout},
+	}
+	if u.opts.RemoteVerifier == nil {
+		u.opts.RemoteVerifier = wellKnownVerifier{u: u}
+	}
+	return u
+}
+
+// UpdateSpec refreshes the metadata of the spec file at path.
+// Failures to fetch stars or pulls are logged and leave the current values in place;
+// an error is only returned when the spec can't be read or written, or provenance
+// verification fails.
+func (u *Updater) UpdateSpec(ctx context.Context, path string) (Result, error) {
+	name, entry, err := loadSpec(path)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to load spec file: %w", err)
+	}
+
+	verified := false
+	if u.opts.VerifyProvenance {
+		verified, err = u.verifyProvenance(ctx, name, entry)
+		if err != nil {
+			return Result{}, &ProvenanceVerificationError{
+				ServerName: name,
+				Reason:     err.Error(),
+			}
+		}
+	}
+
+	repoURL, metadata, err := serverMetadata(name, entry)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Name:     name,
+		Path:     path,
+		OldStars: metadata.Stars,
+		OldPulls: metadata.Pulls,
+
+		OldLastUpdated:     metadata.LastUpdated,
+		ProvenanceVerified: verified,
+	}
+	extras := specExtras(path)
+	current := repoInfo{Stars: metadata.Stars, Forks: extras.Forks, OpenIssues: extras.OpenIssues}
+	info, starsFrom := u.updatedRepoInfo(ctx, name, starsSource(entry, repoURL), current)
+	result.NewStars = info.Stars
+
+	var counts *repoInfo
+	if u.opts.ExtendedMetadata {
+		result.OldForks, result.NewForks = extras.Forks, info.Forks
+		result.OldOpenIssues, result.NewOpenIssues = extras.OpenIssues, info.OpenIssues
+		counts = &info
+	}
+
+	var pullsFrom *Source
+	result.NewPulls, pullsFrom = u.updatedPulls(ctx, pullsSource(entry), metadata.Pulls)
+	if u.opts.RecordSources {
+		result.Sources = recordedSources(starsFrom, pullsFrom)
+	}
+
+	var latestRelease *string
+	if u.opts.LatestRelease {
+		result.OldLatestRelease = extras.LatestRelease
+		result.NewLatestRelease = u.updatedLatestRelease(ctx, name, starsSource(entry, repoURL), result.OldLatestRelease)
+		latestRelease = &result.NewLatestRelease
+	}
+
+	if u.opts.DryRun {
+		return result, nil
+	}
+
+	update := metadataUpdate{
+		stars:              result.NewStars,
+		pulls:              result.NewPulls,
+		sources:            result.Sources,
+		latestRelease:      latestRelease,
+		counts:             counts,
+		provenanceVerified: verified,
+	}
+	if err := writeMetadata(path, update); err != nil {
+		return result, fmt.Errorf("failed to update %s: %w", path, err)
+	}
+	result.Written = true
+
+	return result, nil
+}
+
+// loadSpec reads a spec file, naming the entry after its parent directory
+func loadSpec(path string) (string, *types.RegistryEntry, error) {
+	// Check if file exists
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return "", nil, fmt.Errorf("spec file not found: %s", path)
+	}
+
+	data, err := types.ReadSpecFile(path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read spec file: %w", err)
+	}
+
+	var entry types.RegistryEntry
+	if err := yaml.Unmarshal(data, &entry); err != nil {
+		return "", nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	name := filepath.Base(filepath.Dir(path))
+	if entry.GetName() == "" {
+		entry.SetName(name)
+	}
+
+	return name, &entry, nil
+}
+
+// metadataExtras are the metadata fields regup writes that aren't part of the toolhive metadata
+type metadataExtras struct {
+	LatestRelease string `yaml:"latest_release"`
+	Forks         int    `yaml:"forks"`
+	OpenIssues    int    `yaml:"open_issues"`
+}
+
+// specExtras returns the metadata fields of a spec file that aren't part of the toolhive
+// metadata, which are read separately from the entry. Missing fields are left empty.
+func specExtras(path string) metadataExtras {
+	data, err := types.ReadSpecFile(path)
+	if err != nil {
+		return metadataExtras{}
+	}
+
+	var spec struct {
+		Metadata metadataExtras `yaml:"metadata"`
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return metadataExtras{}
+	}
+	return spec.Metadata
+}
+
+// serverMetadata returns the repository URL and metadata of an entry, creating empty metadata if needed
+func serverMetadata(name string, entry *types.RegistryEntry) (string, *registry.Metadata, error) {
+	var repoURL string
+	var metadata *registry.Metadata
+
+	switch {
+	case entry.IsImage() && entry.ImageMetadata != nil:
+		repoURL = entry.ImageMetadata.RepositoryURL
+		if entry.ImageMetadata.Metadata == nil {
+			entry.ImageMetadata.Metadata = &registry.Metadata{}
+		}
+		metadata = entry.ImageMetadata.Metadata
+	case entry.IsRemote() && entry.RemoteServerMetadata != nil:
+		repoURL = entry.RemoteServerMetadata.RepositoryURL
+		if entry.RemoteServerMetadata.Metadata == nil {
+			entry.RemoteServerMetadata.Metadata = &registry.Metadata{}
+		}
+		metadata = entry.RemoteServerMetadata.Metadata
+	default:
+		return "", nil, fmt.Errorf("unable to determine server type for %s", name)
+	}
+
+	if repoURL == "" && entry.StarsSource == "" {
+		logger.Warnf("Server %s has no repository URL, skipping GitHub stars update", name)
+	}
+
+	return repoURL, metadata, nil
+}
+
+// starsSource returns the repository to count stars from and look up releases of, preferring
+// the entry's stars_source override
+func starsSource(entry *types.RegistryEntry, repoURL string) string {
+	if entry.StarsSource != "" {
+		return entry.StarsSource
+	}
+	return repoURL
+}
+
+// pullsSource returns the image to count pulls from, preferring the entry's pulls_source override.
+// Remote servers without an override have no image and so no pulls.
+func pullsSource(entry *types.RegistryEntry) string {
+	if entry.PullsSource != "" {
+		return entry.PullsSource
+	}
+	if entry.IsImage() && entry.ImageMetadata != nil {
+		return entry.Image
+	}
+	return ""
+}
+
+// recordedSources returns the sources of the fetched fields, or nil if none were fetched
+func recordedSources(stars, pulls *Source) map[string]Source {
+	sources := make(map[string]Source)
+	if stars != nil {
+		sources["stars"] = *stars
+	}
+	if pulls != nil {
+		sources["pulls"] = *pulls
+	}
+	if len(sources) == 0 {
+		return nil
+	}
+	return sources
+}
+
+// fetchedFrom returns the source of a value fetched from url just now
+func fetchedFrom(url string) *Source {
+	return &Source{URL: url, FetchedAt: time.Now().UTC().Format(time.RFC3339)}
+}
+
+// updatedRepoInfo returns the current counts of the repository and where they were fetched
+// from, or current and a nil source if they can't be fetched
+func (u *Updater) updatedRepoInfo(ctx context.Context, name, repoURL string, current repoInfo) (repoInfo, *Source) {
+	if repoURL == "" {
+		return current, nil
+	}
+
+	if host, project, ok := u.gitLabProject(repoURL); ok {
+		info, err := u.getGitLabProjectInfo(ctx, host, project)
+		if err != nil {
+			logger.Warnf("Failed to get GitLab project info for %s: %v", name, err)
+			return current, nil
+		}
+		return info, fetchedFrom(u.gitLabProjectURL(host, project))
+	}
+
+	owner, repo, err := extractOwnerRepo(repoURL)
+	if err != nil {
+		logger.Warnf("Failed to extract owner/repo from URL %s: %v", repoURL, err)
+		return current, nil
+	}
+
+	info, err := u.getGitHubRepoInfo(ctx, owner, repo)
+	var rateLimitErr *RateLimitError
+	if errors.As(err, &rateLimitErr) {
+		logger.Warnf("Keeping stars of %s: %v", name, err)
+		return current, nil
+	}
+	if err != nil {
+		logger.Warnf("Failed to get GitHub repo info for %s: %v", name, err)
+		return current, nil
+	}
+
+	return info, fetchedFrom(u.githubRepoURL(owner, repo))
+}
+
+// updatedLatestRelease returns the tag of the latest release of the repository, "" if it has
+// no releases, or currentRelease if it can't be fetched
+func (u *Updater) updatedLatestRelease(ctx context.Context, name, repoURL, currentRelease string) string {
+	// Only GitHub releases are looked up
+	if _, _, ok := u.gitLabProject(repoURL); repoURL == "" || ok {
+		return currentRelease
+	}
+
+	owner, repo, err := extractOwnerRepo(repoURL)
+	if err != nil {
+		return currentRelease
+	}
+
+	release, err := u.getGitHubLatestRelease(ctx, owner, repo)
+	if err != nil {
+		logger.Warnf("Failed to get the latest release of %s: %v", name, err)
+		return currentRelease
+	}
+	return release
+}
+
+// updatedPulls returns the current pull count of the image and where it was fetched from,
+// or currentPulls and a nil source if it isn't available
+func (u *Updater) updatedPulls(ctx context.Context, image string, currentPulls int) (int, *Source) {
+	if image == "" {
+		return currentPulls, nil
+	}
+
+	pullCount, err := u.getContainerPullCount(ctx, image)
+	if err != nil {
+		logger.Warnf("Failed to get pull count for image %s: %v", image, err)
+		return currentPulls, nil
+	}
+
+	if pullCount > 0 {
+		return pullCount, fetchedFrom(u.pullCountURL(image))
+	}
+
+	// No pull count available (GHCR or private registry)
+	return currentPulls, nil
+}
+
+// verifyServerProvenance verifies the provenance information for an image server, returning
+// false if it has none
+func verifyServerProvenance(name string, entry *types.RegistryEntry) (bool, error) {
+	if entry.Provenance == nil {
+		logger.Warnf("Server %s has no provenance information, skipping verification", name)
+		return false, nil
+	}
+
+	if entry.Image == "" {
+		return false, fmt.Errorf("no image reference provided")
+	}
+
+	logger.Infof("Verifying provenance for server %s with image %s", name, entry.Image)
+
+	// The entry already has ImageMetadata embedded, so we can use it directly
+	v, err := verifier.New(entry.ImageMetadata)
+	if err != nil {
+		return false, fmt.Errorf("failed to create verifier: %w", err)
+	}
+
+	isVerified, err := v.VerifyServer(entry.Image, entry.ImageMetadata)
+	if err != nil {
+		return false, fmt.Errorf("verification failed: %w", err)
+	}
+
+	if isVerified {
+		logger.Infof("Server %s verified successfully", name)
+		return true, nil
+	}
+
+	return false, fmt.Errorf("no verified signatures found")
+}
+
+// metadataUpdate holds the values written to the metadata block of a spec file
+type metadataUpdate struct {
+	stars int
+	pulls int
+	// sources are recorded in metadata.sources when given
+	sources map[string]Source
+	// latestRelease is written to metadata.latest_release unless nil, and an empty one removes it
+	latestRelease *string
+	// counts are written to metadata.forks and metadata.open_issues unless nil
+	counts *repoInfo
+	// provenanceVerified records a successful provenance verification in
+	// metadata.provenance_verified and metadata.provenance_verified_at. Without it, both are removed.
+	provenanceVerified bool
+}
+
+// writeMetadata updates the metadata of a spec file while preserving comments and structure.
+// Only the changed metadata lines are rewritten; the whole file is only re-encoded when its
+// metadata block can't be patched in place.
+func writeMetadata(path string, update metadataUpdate) error {
+	data, err := types.ReadSpecFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var original, doc yaml.Node
+	if err := yaml.Unmarshal(data, &original); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := updateMetadataInNode(&doc, update); err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+
+	if patched, ok := patchMetadata(data, &original, &doc); ok {
+		return os.WriteFile(path, patched, 0600)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0600)
+}
+
+// updateMetadataInNode updates metadata fields in the YAML node tree
+func updateMetadataInNode(node *yaml.Node, update metadataUpdate) error {
+	// Navigate to the document content
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		return updateMetadataInNode(node.Content[0], update)
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("expected mapping node, got %v", node.Kind)
+	}
+
+	// Find or create metadata section
+	metadataIndex := -1
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == "metadata" {
+			metadataIndex = i
+			break
+		}
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+
+	var metadataNode *yaml.Node
+	if metadataIndex >= 0 {
+		// Update existing metadata
+		metadataNode = node.Content[metadataIndex+1]
+		if metadataNode.Kind != yaml.MappingNode {
+			return fmt.Errorf("metadata is not a mapping")
+		}
+
+		// Update or add fields
+		updated := map[string]bool{
+			"stars":        false,
+			"pulls":        false,
+			"last_updated": false,
+		}
+
+		for i := 0; i < len(metadataNode.Content); i += 2 {
+			key := metadataNode.Content[i].Value
+			switch key {
+			case "stars":
+				metadataNode.Content[i+1].Value = fmt.Sprintf("%d", update.stars)
+				updated["stars"] = true
+			case "pulls":
+				metadataNode.Content[i+1].Value = fmt.Sprintf("%d", update.pulls)
+				updated["pulls"] = true
+			case "last_updated":
+				metadataNode.Content[i+1].Value = now
+				updated["last_updated"] = true
+			}
+		}
+
+		// Add missing fields
+		if !updated["stars"] {
+			metadataNode.Content = append(metadataNode.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "stars"},
+				&yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", update.stars)})
+		}
+		if !updated["pulls"] {
+			metadataNode.Content = append(metadataNode.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "pulls"},
+				&yaml.Node{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", update.pulls)})
+		}
+		if !updated["last_updated"] {
+			metadataNode.Content = append(metadataNode.Content,
+				&yaml.Node{Kind: yaml.ScalarNode, Value: "last_updated"},
+				&yaml.Node{Kind: yaml.ScalarNode, Value: now})
+		}
+	} else {
+		// Add new metadata section
+		metadataKey := &yaml.Node{Kind: yaml.ScalarNode, Value: "metadata"}
+		metadataNode = &yaml.Node{
+			Kind: yaml.MappingNode,
+			Content: []*yaml.Node{
+				{Kind: yaml.ScalarNode, Value: "stars"},
+				{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", update.stars)},
+				{Kind: yaml.ScalarNode, Value: "pulls"},
+				{Kind: yaml.ScalarNode, Value: fmt.Sprintf("%d", update.pulls)},
+				{Kind: yaml.ScalarNode, Value: "last_updated"},
+				{Kind: yaml.ScalarNode, Value: now},
+			},
+		}
+		node.Content = append(node.Content, metadataKey, metadataNode)
+	}
+
+	if update.latestRelease != nil {
+		setLatestRelease(metadataNode, *update.latestRelease)
+	}
+	if update.counts != nil {
+		mappingValue(metadataNode, "forks", yaml.ScalarNode).Value = fmt.Sprintf("%d", update.counts.Forks)
+		mappingValue(metadataNode, "open_issues", yaml.ScalarNode).Value = fmt.Sprintf("%d", update.counts.OpenIssues)
+	}
+	if update.provenanceVerified {
+		verified := mappingValue(metadataNode, "provenance_verified", yaml.ScalarNode)
+		verified.Tag, verified.Value = "!!bool", "true"
+		mappingValue(metadataNode, "provenance_verified_at", yaml.ScalarNode).Value = now
+	} else {
+		removeMappingKey(metadataNode, "provenance_verified")
+		removeMappingKey(metadataNode, "provenance_verified_at")
+	}
+
+	return setMetadataSources(metadataNode, update.sources)
+}
+
+// setLatestRelease sets metadata.latest_release, or removes it if release is empty
+func setLatestRelease(metadataNode *yaml.Node, release string) {
+	if release != "" {
+		// Tagged as a string so tags such as 1.0 are quoted rather than read back as numbers
+		value := mappingValue(metadataNode, "latest_release", yaml.ScalarNode)
+		value.Tag, value.Value = "!!str", release
+		return
+	}
+
+	removeMappingKey(metadataNode, "latest_release")
+}
+
+// setMetadataSources records the sources of fetched fields in the metadata.sources block,
+// creating it if needed. Sources of fields that weren't fetched are left as they are.
+func setMetadataSources(metadataNode *yaml.Node, sources map[string]Source) error {
+	if len(sources) == 0 {
+		return nil
+	}
+
+	sourcesNode := mappingValue(metadataNode, "sources", yaml.MappingNode)
+	if sourcesNode.Kind != yaml.MappingNode {
+		return fmt.Errorf("metadata.sources is not a mapping")
+	}
+
+	for _, field := range []string{"stars", "pulls"} {
+		source, ok := sources[field]
+		if !ok {
+			continue
+		}
+		sourceNode := mappingValue(sourcesNode, field, yaml.MappingNode)
+		if sourceNode.Kind != yaml.MappingNode {
+			return fmt.Errorf("metadata.sources.%s is not a mapping", field)
+		}
+		mappingValue(sourceNode, "url", yaml.ScalarNode).Value = source.URL
+		mappingValue(sourceNode, "fetched_at", yaml.ScalarNode).Value = source.FetchedAt
+	}
+
+	return nil
+}
+
+// removeMappingKey removes key and its value from a mapping node, if it's set
+func removeMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// mappingValue returns the value of key in a mapping node, appending the key with an empty
+// value of the given kind if it's missing
+func mappingValue(mapping *yaml.Node, key string, kind yaml.Kind) *yaml.Node {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+
+	value := &yaml.Node{Kind: kind}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	return value
+}
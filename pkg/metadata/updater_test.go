@@ -0,0 +1,672 @@
+package metadata
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func newFakeAPIs(t *testing.T) (github, dockerHub *httptest.Server) {
+	t.Helper()
+
+	github = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/example/server":
+			fmt.Fprint(w, `{"stargazers_count": 42, "forks_count": 8, "open_issues_count": 3}`)
+		case "/repos/example/monorepo":
+			fmt.Fprint(w, `{"stargazers_count": 7}`)
+		case "/repos/example/server/releases/latest":
+			fmt.Fprint(w, `{"tag_name": "v1.2.0", "name": "Release 1.2.0"}`)
+		case "/repos/example/numeric/releases/latest":
+			fmt.Fprint(w, `{"tag_name": "1.10"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(github.Close)
+
+	dockerHub = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/repositories/example/server/":
+			fmt.Fprint(w, `{"pull_count": 1234}`)
+		case "/v2/repositories/example/mirror/":
+			fmt.Fprint(w, `{"pull_count": 99}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(dockerHub.Close)
+
+	return github, dockerHub
+}
+
+func writeSpec(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	path := filepath.Join(dir, "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestUpdater_UpdateSpec(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+
+	tests := []struct {
+		name      string
+		spec      string
+		dryRun    bool
+		wantStars int
+		wantPulls int
+		changed   bool
+	}{
+		{
+			name: "stars and pulls are updated",
+			spec: `# Example server
+image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+`,
+			wantStars: 42,
+			wantPulls: 1234,
+			changed:   true,
+		},
+		{
+			name: "metadata section is added",
+			spec: `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+`,
+			wantStars: 42,
+			wantPulls: 1234,
+			changed:   true,
+		},
+		{
+			name: "unknown repository and image keep current values",
+			spec: `image: example/missing:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/missing
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+`,
+			wantStars: 10,
+			wantPulls: 100,
+		},
+		{
+			name: "remote server only gets stars",
+			spec: `url: https://api.example.com/mcp
+description: Example remote server
+transport: streamable-http
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 0
+`,
+			wantStars: 42,
+			wantPulls: 0,
+			changed:   true,
+		},
+		{
+			name: "sources override repository and image",
+			spec: `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+stars_source: https://github.com/example/monorepo
+pulls_source: docker.io/example/mirror:1.0.0
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+`,
+			wantStars: 7,
+			wantPulls: 99,
+			changed:   true,
+		},
+		{
+			name: "remote server with pulls source gets pulls",
+			spec: `url: https://api.example.com/mcp
+description: Example remote server
+transport: streamable-http
+stars_source: https://github.com/example/monorepo
+pulls_source: example/mirror
+tools:
+  - example_tool
+`,
+			wantStars: 7,
+			wantPulls: 99,
+			changed:   true,
+		},
+		{
+			name: "dry run leaves the spec untouched",
+			spec: `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+`,
+			dryRun:    true,
+			wantStars: 42,
+			wantPulls: 1234,
+			changed:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := writeSpec(t, "example", tt.spec)
+
+			updater := NewUpdater(Options{
+				DryRun:          tt.dryRun,
+				GitHubAPIURL:    github.URL,
+				DockerHubAPIURL: dockerHub.URL,
+			})
+
+			result, err := updater.UpdateSpec(context.Background(), path)
+			require.NoError(t, err)
+
+			assert.Equal(t, "example", result.Name)
+			assert.Equal(t, tt.wantStars, result.NewStars)
+			assert.Equal(t, tt.wantPulls, result.NewPulls)
+			assert.Equal(t, tt.changed, result.Changed())
+			assert.Equal(t, !tt.dryRun, result.Written)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if tt.dryRun {
+				assert.Equal(t, tt.spec, string(data))
+				return
+			}
+
+			var entry types.RegistryEntry
+			require.NoError(t, yaml.Unmarshal(data, &entry))
+			_, meta, err := serverMetadata(result.Name, &entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantStars, meta.Stars)
+			assert.Equal(t, tt.wantPulls, meta.Pulls)
+			assert.NotEmpty(t, meta.LastUpdated)
+		})
+	}
+}
+
+func TestUpdater_UpdateSpecPreservesComments(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+	path := writeSpec(t, "example", `# Example server
+image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool # the only tool
+`)
+
+	updater := NewUpdater(Options{GitHubAPIURL: github.URL, DockerHubAPIURL: dockerHub.URL})
+	_, err := updater.UpdateSpec(context.Background(), path)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "# Example server")
+	assert.Contains(t, string(data), "# the only tool")
+}
+
+func TestUpdater_LatestRelease(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+
+	tests := []struct {
+		name        string
+		repo        string
+		starsSource string
+		current     string
+		disabled    bool
+		wantRelease string
+		wantSpec    string
+		changed     bool
+	}{
+		{
+			name:        "release is added",
+			repo:        "example/server",
+			wantRelease: "v1.2.0",
+			wantSpec:    "  latest_release: v1.2.0\n",
+			changed:     true,
+		},
+		{
+			name:        "release is updated",
+			repo:        "example/server",
+			current:     "v1.1.0",
+			wantRelease: "v1.2.0",
+			wantSpec:    "  latest_release: v1.2.0\n",
+			changed:     true,
+		},
+		{
+			name:        "numeric tags stay strings",
+			repo:        "example/numeric",
+			wantRelease: "1.10",
+			wantSpec:    "  latest_release: \"1.10\"\n",
+			changed:     true,
+		},
+		{
+			name: "no releases leave the field absent",
+			repo: "example/monorepo",
+		},
+		{
+			name:    "no releases remove a stale field",
+			repo:    "example/monorepo",
+			current: "v0.1.0",
+			changed: true,
+		},
+		{
+			name:        "stars source is preferred over the repository",
+			repo:        "example/monorepo",
+			starsSource: "example/server",
+			wantRelease: "v1.2.0",
+			wantSpec:    "  latest_release: v1.2.0\n",
+			changed:     true,
+		},
+		{
+			name:     "disabled leaves the field untouched",
+			repo:     "example/monorepo",
+			current:  "v0.1.0",
+			disabled: true,
+			wantSpec: "  latest_release: v0.1.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			spec := `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/` + tt.repo + `
+tools:
+  - example_tool
+metadata:
+  stars: 42
+  pulls: 1234
+`
+			if tt.starsSource != "" {
+				spec = "stars_source: https://github.com/" + tt.starsSource + "\n" + spec
+			}
+			if tt.current != "" {
+				spec += "  latest_release: " + tt.current + "\n"
+			}
+			path := writeSpec(t, "example", spec)
+
+			updater := NewUpdater(Options{
+				LatestRelease:   !tt.disabled,
+				GitHubAPIURL:    github.URL,
+				DockerHubAPIURL: dockerHub.URL,
+			})
+			result, err := updater.UpdateSpec(context.Background(), path)
+			require.NoError(t, err)
+			if !tt.disabled {
+				assert.Equal(t, tt.current, result.OldLatestRelease)
+			}
+			assert.Equal(t, tt.wantRelease, result.NewLatestRelease)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if tt.wantSpec != "" {
+				assert.Contains(t, string(data), tt.wantSpec)
+			} else {
+				assert.NotContains(t, string(data), "latest_release")
+			}
+
+			// The spec still loads with the field, and the next refresh reads it back
+			var entry types.RegistryEntry
+			require.NoError(t, yaml.Unmarshal(data, &entry))
+			if !tt.disabled {
+				assert.Equal(t, tt.wantRelease, specExtras(path).LatestRelease)
+			}
+		})
+	}
+}
+
+func TestUpdater_ExtendedMetadata(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+
+	tests := []struct {
+		name           string
+		enabled        bool
+		wantForks      int
+		wantOpenIssues int
+		wantSpec       []string
+		wantMissing    []string
+	}{
+		{
+			name:           "enabled",
+			enabled:        true,
+			wantForks:      8,
+			wantOpenIssues: 3,
+			wantSpec:       []string{"  forks: 8\n", "  open_issues: 3\n"},
+		},
+		{
+			name:        "disabled",
+			wantMissing: []string{"forks", "open_issues"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := writeSpec(t, "example", `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+`)
+
+			updater := NewUpdater(Options{
+				ExtendedMetadata: tt.enabled,
+				GitHubAPIURL:     github.URL,
+				DockerHubAPIURL:  dockerHub.URL,
+			})
+			result, err := updater.UpdateSpec(context.Background(), path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantForks, result.NewForks)
+			assert.Equal(t, tt.wantOpenIssues, result.NewOpenIssues)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			for _, want := range tt.wantSpec {
+				assert.Contains(t, string(data), want)
+			}
+			for _, missing := range tt.wantMissing {
+				assert.NotContains(t, string(data), missing)
+			}
+
+			// The next refresh reads the counts back, so nothing changes
+			result, err = updater.UpdateSpec(context.Background(), path)
+			require.NoError(t, err)
+			assert.False(t, result.Changed())
+		})
+	}
+}
+
+func TestUpdateMetadataInNode_Counts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		spec   string
+		counts *repoInfo
+		want   string
+	}{
+		{
+			name:   "adds counts to new metadata",
+			spec:   "description: Example server\n",
+			counts: &repoInfo{Forks: 8, OpenIssues: 3},
+			want:   "metadata:\n  stars: 42\n  pulls: 1234\n  last_updated: <now>\n  forks: 8\n  open_issues: 3\n",
+		},
+		{
+			name:   "updates existing counts in place",
+			spec:   "metadata:\n  forks: 1 # forks\n  stars: 10\n  open_issues: 9\n",
+			counts: &repoInfo{Forks: 8, OpenIssues: 3},
+			want:   "metadata:\n  forks: 8 # forks\n  stars: 42\n  open_issues: 3\n  pulls: 1234\n  last_updated: <now>\n",
+		},
+		{
+			name: "nil counts leave existing counts untouched",
+			spec: "metadata:\n  forks: 1\n  open_issues: 9\n",
+			want: "metadata:\n  forks: 1\n  open_issues: 9\n  stars: 42\n  pulls: 1234\n  last_updated: <now>\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var doc yaml.Node
+			require.NoError(t, yaml.Unmarshal([]byte(tt.spec), &doc))
+			require.NoError(t, updateMetadataInNode(&doc, metadataUpdate{stars: 42, pulls: 1234, counts: tt.counts}))
+
+			metadataNode := mappingValue(doc.Content[0], "metadata", yaml.MappingNode)
+			mappingValue(metadataNode, "last_updated", yaml.ScalarNode).Value = "<now>"
+			var buf bytes.Buffer
+			encoder := yaml.NewEncoder(&buf)
+			encoder.SetIndent(2)
+			require.NoError(t, encoder.Encode(&doc))
+			assert.Contains(t, buf.String(), tt.want)
+		})
+	}
+}
+
+func TestUpdater_RequestInterval(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+	path := writeSpec(t, "example", `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+`)
+
+	interval := 50 * time.Millisecond
+	updater := NewUpdater(Options{
+		DryRun:          true,
+		RequestInterval: interval,
+		GitHubAPIURL:    github.URL,
+		DockerHubAPIURL: dockerHub.URL,
+	})
+
+	// Stars and pulls are two requests, so the second waits for the interval
+	start := time.Now()
+	_, err := updater.UpdateSpec(context.Background(), path)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), interval)
+}
+
+func TestResult_Stale(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	maxAge := 30 * 24 * time.Hour
+
+	tests := []struct {
+		name   string
+		result Result
+		want   bool
+	}{
+		{
+			name:   "fresh",
+			result: Result{OldStars: 10, NewStars: 10, OldLastUpdated: "2026-10-01T00:00:00Z"},
+			want:   false,
+		},
+		{
+			name:   "stale",
+			result: Result{OldStars: 10, NewStars: 10, OldLastUpdated: "2026-06-01T00:00:00Z"},
+			want:   true,
+		},
+		{
+			name:   "missing timestamp",
+			result: Result{OldStars: 10, NewStars: 10},
+			want:   true,
+		},
+		{
+			name:   "invalid timestamp",
+			result: Result{OldStars: 10, NewStars: 10, OldLastUpdated: "last week"},
+			want:   true,
+		},
+		{
+			name:   "old but changed",
+			result: Result{OldStars: 10, NewStars: 12, OldLastUpdated: "2026-06-01T00:00:00Z"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, tt.result.Stale(now, maxAge))
+		})
+	}
+}
+
+func TestUpdater_UpdateSpecMissingFile(t *testing.T) {
+	t.Parallel()
+
+	updater := NewUpdater(Options{})
+	_, err := updater.UpdateSpec(context.Background(), filepath.Join(t.TempDir(), "missing", "spec.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "spec file not found")
+}
+
+func TestUpdater_RecordSources(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+	spec := `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/missing
+pulls_source: docker.io/example/server:1.0.0
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+`
+
+	tests := []struct {
+		name   string
+		record bool
+	}{
+		{name: "recorded", record: true},
+		{name: "omitted when disabled", record: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := writeSpec(t, "example", spec)
+
+			updater := NewUpdater(Options{
+				RecordSources:   tt.record,
+				GitHubAPIURL:    github.URL,
+				DockerHubAPIURL: dockerHub.URL,
+			})
+			result, err := updater.UpdateSpec(context.Background(), path)
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			if !tt.record {
+				assert.Nil(t, result.Sources)
+				assert.NotContains(t, string(data), "sources")
+				return
+			}
+
+			// Only the pulls were fetched, the stars kept their value
+			require.Len(t, result.Sources, 1)
+			assert.Equal(t, dockerHub.URL+"/v2/repositories/example/server/", result.Sources["pulls"].URL)
+			assert.NotEmpty(t, result.Sources["pulls"].FetchedAt)
+
+			var spec struct {
+				Metadata struct {
+					Pulls   int               `yaml:"pulls"`
+					Sources map[string]Source `yaml:"sources"`
+				} `yaml:"metadata"`
+			}
+			require.NoError(t, yaml.Unmarshal(data, &spec))
+			assert.Equal(t, 1234, spec.Metadata.Pulls)
+			assert.Equal(t, result.Sources, spec.Metadata.Sources)
+		})
+	}
+}
+
+func TestUpdater_RecordSourcesRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	github, dockerHub := newFakeAPIs(t)
+	path := writeSpec(t, "example", `image: example/server:1.0.0
+description: Example server
+transport: stdio
+repository_url: https://github.com/example/server
+tools:
+  - example_tool
+metadata:
+  stars: 10
+  pulls: 100
+  sources:
+    stars:
+      url: https://api.github.com/repos/example/old
+      fetched_at: "2024-01-01T00:00:00Z"
+`)
+
+	// Recording updates the existing source and adds the missing one
+	updater := NewUpdater(Options{RecordSources: true, GitHubAPIURL: github.URL, DockerHubAPIURL: dockerHub.URL})
+	result, err := updater.UpdateSpec(context.Background(), path)
+	require.NoError(t, err)
+	assert.Equal(t, github.URL+"/repos/example/server", result.Sources["stars"].URL)
+	assert.Equal(t, dockerHub.URL+"/v2/repositories/example/server/", result.Sources["pulls"].URL)
+
+	recorded, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(recorded), "sources:"))
+	assert.NotContains(t, string(recorded), "example/old")
+
+	// The spec still loads, and refreshing without recording leaves the sources untouched
+	var entry types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal(recorded, &entry))
+	assert.Equal(t, 42, entry.ImageMetadata.Metadata.Stars)
+
+	updater = NewUpdater(Options{GitHubAPIURL: github.URL, DockerHubAPIURL: dockerHub.URL})
+	_, err = updater.UpdateSpec(context.Background(), path)
+	require.NoError(t, err)
+
+	var before, after struct {
+		Metadata struct {
+			Sources map[string]Source `yaml:"sources"`
+		} `yaml:"metadata"`
+	}
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(recorded, &before))
+	require.NoError(t, yaml.Unmarshal(data, &after))
+	assert.Equal(t, before.Metadata.Sources, after.Metadata.Sources)
+}
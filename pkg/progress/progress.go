@@ -0,0 +1,123 @@
+// Package progress provides a progress indicator for long-running batch commands
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// clearLine returns the cursor to the start of the line and erases it
+const clearLine = "\r\033[K"
+
+// Reporter tracks how many of a known number of items have been processed and
+// renders processed/total with an ETA on a single, continuously updated line.
+// Rendering only happens when the output is a terminal, so logs and CI output
+// stay clean; the counts are tracked either way. It is safe for concurrent use.
+type Reporter struct {
+	out     io.Writer
+	total   int
+	enabled bool
+	now     func() time.Time
+
+	mu    sync.Mutex
+	done  int
+	start time.Time
+}
+
+// New creates a reporter for total items that renders to out when out is a terminal.
+// Pass io.Discard to track progress silently, e.g. in JSON or quiet modes.
+func New(out io.Writer, total int) *Reporter {
+	return newReporter(out, total, IsTerminal(out), time.Now)
+}
+
+func newReporter(out io.Writer, total int, enabled bool, now func() time.Time) *Reporter {
+	return &Reporter{
+		out:     out,
+		total:   total,
+		enabled: enabled,
+		now:     now,
+		start:   now(),
+	}
+}
+
+// IsTerminal returns true if w is a file connected to a terminal
+func IsTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// Increment records that one more item has been processed and redraws the progress line
+func (r *Reporter) Increment() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.done < r.total {
+		r.done++
+	}
+	r.render()
+}
+
+// Done returns the number of processed items
+func (r *Reporter) Done() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.done
+}
+
+// ETA estimates the time left from the average time per processed item.
+// It is zero until the first item is processed and once all items are.
+func (r *Reporter) ETA() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.eta()
+}
+
+func (r *Reporter) eta() time.Duration {
+	if r.done == 0 || r.done >= r.total {
+		return 0
+	}
+	perItem := r.now().Sub(r.start) / time.Duration(r.done)
+	return perItem * time.Duration(r.total-r.done)
+}
+
+// Clear erases the progress line so other output can be printed cleanly.
+// The line is redrawn on the next Increment.
+func (r *Reporter) Clear() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.enabled {
+		fmt.Fprint(r.out, clearLine)
+	}
+}
+
+// Finish erases the progress line once processing is complete
+func (r *Reporter) Finish() {
+	r.Clear()
+}
+
+// render draws the progress line; the caller must hold the lock
+func (r *Reporter) render() {
+	if !r.enabled {
+		return
+	}
+
+	line := fmt.Sprintf("%d/%d", r.done, r.total)
+	if r.total > 0 {
+		line += fmt.Sprintf(" (%d%%)", r.done*100/r.total)
+	}
+	if eta := r.eta(); eta > 0 {
+		line += fmt.Sprintf(" ETA %s", eta.Round(time.Second))
+	}
+	fmt.Fprint(r.out, clearLine+line)
+}
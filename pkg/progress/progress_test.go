@@ -0,0 +1,93 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock only moves when advanced
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func TestReporter_Counts(t *testing.T) {
+	t.Parallel()
+
+	reporter := New(&bytes.Buffer{}, 10)
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reporter.Increment()
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 8, reporter.Done())
+
+	// Extra increments never count past the total
+	for range 5 {
+		reporter.Increment()
+	}
+	assert.Equal(t, 10, reporter.Done())
+	assert.Zero(t, reporter.ETA())
+}
+
+func TestReporter_SilentWhenNotTerminal(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	reporter := New(&buf, 3)
+	assert.False(t, IsTerminal(&buf))
+
+	reporter.Increment()
+	reporter.Clear()
+	reporter.Increment()
+	reporter.Increment()
+	reporter.Finish()
+
+	assert.Equal(t, 3, reporter.Done())
+	assert.Empty(t, buf.String())
+}
+
+func TestReporter_RenderAndETA(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	reporter := newReporter(&buf, 4, true, clock.Now)
+	assert.Zero(t, reporter.ETA())
+
+	// Each item takes 10 seconds
+	clock.Advance(10 * time.Second)
+	reporter.Increment()
+
+	lines := strings.Split(buf.String(), clearLine)
+	assert.Equal(t, "1/4 (25%) ETA 30s", lines[len(lines)-1])
+
+	clock.Advance(10 * time.Second)
+	reporter.Increment()
+	assert.Equal(t, 20*time.Second, reporter.ETA())
+
+	reporter.Increment()
+	reporter.Increment()
+	lines = strings.Split(buf.String(), clearLine)
+	assert.Equal(t, "4/4 (100%)", lines[len(lines)-1])
+
+	reporter.Finish()
+	assert.True(t, strings.HasSuffix(buf.String(), clearLine))
+}
@@ -0,0 +1,188 @@
+// Package readme generates the README.md files of registry entries with substantial documentation needs
+package readme
+
+import (
+	"fmt"
+	"strings"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+)
+
+// Links holds the link fields of a server that the toolhive types don't carry
+type Links struct {
+	Homepage         string
+	DocumentationURL string
+}
+
+// Needed returns true if a server has enough tools, environment variables or tags to warrant a README
+func Needed(server *toolhiveRegistry.ImageMetadata) bool {
+	// Create README for entries with substantial documentation needs
+	return len(server.Tools) > 10 || len(server.EnvVars) > 5 || len(server.Tags) > 10
+}
+
+// Generate returns the README.md content for a server
+func Generate(name string, server *toolhiveRegistry.ImageMetadata, links Links) string {
+	var readme strings.Builder
+
+	addReadmeHeader(&readme, name, server.Description)
+	addBasicInformation(&readme, server, links)
+	addToolsSection(&readme, server.Tools)
+	addEnvironmentVariablesSection(&readme, server.EnvVars)
+	addTagsSection(&readme, server.Tags)
+	addMetadataSection(&readme, server.Metadata)
+
+	return readme.String()
+}
+
+func addReadmeHeader(readme *strings.Builder, name, description string) {
+	fmt.Fprintf(readme, "# %s\n\n", name)
+	if description != "" {
+		fmt.Fprintf(readme, "%s\n\n", description)
+	}
+}
+
+func addBasicInformation(readme *strings.Builder, server *toolhiveRegistry.ImageMetadata, links Links) {
+	readme.WriteString("## Basic Information\n\n")
+
+	if server.Image != "" {
+		fmt.Fprintf(readme, "- **Image:** `%s`\n", server.Image)
+	}
+	if server.RepositoryURL != "" {
+		fmt.Fprintf(readme, "- **Repository:** [%s](%s)\n", server.RepositoryURL, server.RepositoryURL)
+	}
+	if links.Homepage != "" {
+		fmt.Fprintf(readme, "- **Homepage:** [%s](%s)\n", links.Homepage, links.Homepage)
+	}
+	if links.DocumentationURL != "" {
+		fmt.Fprintf(readme, "- **Documentation:** [%s](%s)\n", links.DocumentationURL, links.DocumentationURL)
+	}
+	if server.Tier != "" {
+		fmt.Fprintf(readme, "- **Tier:** %s\n", server.Tier)
+	}
+	if server.Status != "" {
+		fmt.Fprintf(readme, "- **Status:** %s\n", server.Status)
+	}
+	if server.Transport != "" {
+		fmt.Fprintf(readme, "- **Transport:** %s\n", server.Transport)
+	}
+}
+
+func addToolsSection(readme *strings.Builder, tools []string) {
+	if len(tools) == 0 {
+		return
+	}
+
+	readme.WriteString("\n## Available Tools\n\n")
+	fmt.Fprintf(readme, "This server provides %d tools:\n\n", len(tools))
+
+	if len(tools) > 10 {
+		addToolsInColumns(readme, tools)
+	} else {
+		addToolsList(readme, tools)
+	}
+}
+
+func addToolsInColumns(readme *strings.Builder, tools []string) {
+	for i := 0; i < len(tools); i += 3 {
+		for j := 0; j < 3 && i+j < len(tools); j++ {
+			fmt.Fprintf(readme, "- `%s`", tools[i+j])
+			if j < 2 && i+j+1 < len(tools) {
+				readme.WriteString(" | ")
+			}
+		}
+		readme.WriteString("\n")
+	}
+}
+
+func addToolsList(readme *strings.Builder, tools []string) {
+	for _, tool := range tools {
+		fmt.Fprintf(readme, "- `%s`\n", tool)
+	}
+}
+
+func addEnvironmentVariablesSection(readme *strings.Builder, envVars []*toolhiveRegistry.EnvVar) {
+	if len(envVars) == 0 {
+		return
+	}
+
+	readme.WriteString("\n## Environment Variables\n\n")
+
+	required, optional := separateEnvVars(envVars)
+	addRequiredEnvVars(readme, required)
+	addOptionalEnvVars(readme, optional)
+}
+
+func separateEnvVars(envVars []*toolhiveRegistry.EnvVar) ([]*toolhiveRegistry.EnvVar, []*toolhiveRegistry.EnvVar) {
+	var required, optional []*toolhiveRegistry.EnvVar
+	for _, env := range envVars {
+		if env.Required {
+			required = append(required, env)
+		} else {
+			optional = append(optional, env)
+		}
+	}
+	return required, optional
+}
+
+func addRequiredEnvVars(readme *strings.Builder, required []*toolhiveRegistry.EnvVar) {
+	if len(required) == 0 {
+		return
+	}
+
+	readme.WriteString("### Required\n\n")
+	for _, env := range required {
+		secret := getSecretIndicator(env.Secret)
+		fmt.Fprintf(readme, "- **%s**%s: %s\n", env.Name, secret, env.Description)
+	}
+}
+
+func addOptionalEnvVars(readme *strings.Builder, optional []*toolhiveRegistry.EnvVar) {
+	if len(optional) == 0 {
+		return
+	}
+
+	readme.WriteString("\n### Optional\n\n")
+	for _, env := range optional {
+		secret := getSecretIndicator(env.Secret)
+		fmt.Fprintf(readme, "- **%s**%s: %s\n", env.Name, secret, env.Description)
+		if env.Default != "" {
+			fmt.Fprintf(readme, "  - Default: `%s`\n", env.Default)
+		}
+	}
+}
+
+func getSecretIndicator(isSecret bool) string {
+	if isSecret {
+		return " 🔒"
+	}
+	return ""
+}
+
+func addTagsSection(readme *strings.Builder, tags []string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	readme.WriteString("\n## Tags\n\n")
+	for _, tag := range tags {
+		fmt.Fprintf(readme, "`%s` ", tag)
+	}
+	readme.WriteString("\n")
+}
+
+func addMetadataSection(readme *strings.Builder, metadata *toolhiveRegistry.Metadata) {
+	if metadata == nil {
+		return
+	}
+
+	readme.WriteString("\n## Statistics\n\n")
+	if metadata.Stars > 0 {
+		fmt.Fprintf(readme, "- ⭐ Stars: %d\n", metadata.Stars)
+	}
+	if metadata.Pulls > 0 {
+		fmt.Fprintf(readme, "- 📦 Pulls: %d\n", metadata.Pulls)
+	}
+	if metadata.LastUpdated != "" {
+		fmt.Fprintf(readme, "- 🕐 Last Updated: %s\n", metadata.LastUpdated)
+	}
+}
@@ -0,0 +1,57 @@
+package readme
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateReadme_Links(t *testing.T) {
+	t.Parallel()
+
+	server := &toolhiveRegistry.ImageMetadata{
+		BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+			Description:   "Test server",
+			RepositoryURL: "https://github.com/example/server",
+		},
+		Image: "test/image:latest",
+	}
+
+	tests := []struct {
+		name     string
+		links    Links
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "with links",
+			links: Links{
+				Homepage:         "https://example.com",
+				DocumentationURL: "https://docs.example.com/mcp",
+			},
+			contains: []string{
+				"- **Homepage:** [https://example.com](https://example.com)\n",
+				"- **Documentation:** [https://docs.example.com/mcp](https://docs.example.com/mcp)\n",
+			},
+		},
+		{
+			name:     "without links",
+			excludes: []string{"**Homepage:**", "**Documentation:**"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			readme := Generate("test-server", server, tt.links)
+			assert.Contains(t, readme, "## Basic Information")
+			for _, s := range tt.contains {
+				assert.Contains(t, readme, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, readme, s)
+			}
+		})
+	}
+}
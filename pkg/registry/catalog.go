@@ -0,0 +1,164 @@
+package registry
+
+import (
+	_ "embed" // for the built-in catalog template
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+//go:embed catalog.html.tmpl
+var defaultCatalogTemplate string
+
+// tierOrder lists the tiers shown first in the catalog, in order. Other tiers follow alphabetically.
+var tierOrder = []string{"Official", "Community"}
+
+// untaggedGroup is the tag group for entries without tags
+const untaggedGroup = "untagged"
+
+// CatalogServer is a single server as presented in the catalog
+type CatalogServer struct {
+	Name             string
+	Description      string
+	Type             string
+	Tier             string
+	Status           string
+	Transport        string
+	Tools            int
+	Tags             []string
+	RepositoryURL    string
+	Homepage         string
+	DocumentationURL string
+}
+
+// CatalogGroup is a named group of servers, such as a tier or a tag
+type CatalogGroup struct {
+	Name    string
+	Servers []CatalogServer
+}
+
+// Catalog is the data passed to the catalog template
+type Catalog struct {
+	Total int
+	Tiers []CatalogGroup
+	Tags  []CatalogGroup
+}
+
+// BuildCatalog groups the entries by tier and by tag. Servers within a group are sorted by name.
+func BuildCatalog(entries map[string]*types.RegistryEntry) Catalog {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	tiers := make(map[string][]CatalogServer)
+	tags := make(map[string][]CatalogServer)
+	for _, name := range names {
+		server := catalogServer(name, entries[name])
+		tiers[server.Tier] = append(tiers[server.Tier], server)
+
+		if len(server.Tags) == 0 {
+			tags[untaggedGroup] = append(tags[untaggedGroup], server)
+		}
+		for _, tag := range server.Tags {
+			tags[tag] = append(tags[tag], server)
+		}
+	}
+
+	return Catalog{
+		Total: len(entries),
+		Tiers: catalogGroups(tiers, tierRank),
+		Tags:  catalogGroups(tags, nil),
+	}
+}
+
+// catalogServer converts a registry entry to its catalog representation
+func catalogServer(name string, entry *types.RegistryEntry) CatalogServer {
+	server := CatalogServer{
+		Name:             name,
+		Description:      entry.GetDescription(),
+		Tier:             entry.GetTier(),
+		Status:           entry.GetStatus(),
+		Transport:        entry.GetTransport(),
+		Tools:            len(entry.GetTools()),
+		Tags:             entry.GetTags(),
+		RepositoryURL:    entry.GetRepositoryURL(),
+		Homepage:         entry.Homepage,
+		DocumentationURL: entry.DocumentationURL,
+	}
+
+	if entry.IsRemote() {
+		server.Type = "remote"
+	} else {
+		server.Type = "image"
+	}
+	if server.Tier == "" {
+		server.Tier = "Community"
+	}
+
+	return server
+}
+
+// catalogGroups sorts groups by rank (if given) and then by name
+func catalogGroups(groups map[string][]CatalogServer, rank func(string) int) []CatalogGroup {
+	result := make([]CatalogGroup, 0, len(groups))
+	for name, servers := range groups {
+		result = append(result, CatalogGroup{Name: name, Servers: servers})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if rank != nil {
+			if ri, rj := rank(result[i].Name), rank(result[j].Name); ri != rj {
+				return ri < rj
+			}
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	return result
+}
+
+// tierRank returns the position of a tier in tierOrder, or len(tierOrder) for other tiers
+func tierRank(tier string) int {
+	for i, t := range tierOrder {
+		if t == tier {
+			return i
+		}
+	}
+	return len(tierOrder)
+}
+
+// LoadCatalogTemplate parses the catalog template at path, or the built-in template if path is empty
+func LoadCatalogTemplate(path string) (*template.Template, error) {
+	text := defaultCatalogTemplate
+	name := "catalog"
+	if path != "" {
+		data, err := os.ReadFile(path) // #nosec G304 - path is provided by the user
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		text = string(data)
+		name = path
+	}
+
+	tmpl, err := template.New(name).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+// RenderCatalog renders the catalog of entries with the given template.
+// html/template escapes all entry content, so descriptions and links can't inject markup.
+func RenderCatalog(w io.Writer, tmpl *template.Template, entries map[string]*types.RegistryEntry) error {
+	if err := tmpl.Execute(w, BuildCatalog(entries)); err != nil {
+		return fmt.Errorf("failed to render catalog: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package registry
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func catalogTestEntries() map[string]*types.RegistryEntry {
+	return map[string]*types.RegistryEntry{
+		"fetch": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description:   "Fetches web pages",
+					Tier:          "Community",
+					Status:        "Active",
+					Transport:     "stdio",
+					Tools:         []string{"fetch"},
+					Tags:          []string{"web"},
+					RepositoryURL: "https://github.com/example/fetch",
+				},
+				Image: "example/fetch:1.0.0",
+			},
+		},
+		"github": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description:   "GitHub <b>integration</b> & more",
+					Tier:          "Official",
+					Status:        "Active",
+					Transport:     "stdio",
+					Tools:         []string{"create_issue", "list_issues"},
+					Tags:          []string{"git", "web"},
+					RepositoryURL: "https://github.com/github/github-mcp-server",
+				},
+				Image: "ghcr.io/github/github-mcp-server:1.0.0",
+			},
+			Homepage: "https://github.com/features",
+		},
+		"remote": {
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description:   "<script>alert('x')</script>",
+					Status:        "Active",
+					Transport:     "streamable-http",
+					Tools:         []string{"remote_tool"},
+					RepositoryURL: "javascript:alert('x')",
+				},
+				URL: "https://api.example.com/mcp",
+			},
+		},
+	}
+}
+
+func TestBuildCatalog(t *testing.T) {
+	t.Parallel()
+
+	catalog := BuildCatalog(catalogTestEntries())
+	assert.Equal(t, 3, catalog.Total)
+
+	groupNames := func(groups []CatalogGroup) map[string][]string {
+		names := make(map[string][]string)
+		for _, group := range groups {
+			for _, server := range group.Servers {
+				names[group.Name] = append(names[group.Name], server.Name)
+			}
+		}
+		return names
+	}
+
+	require.Len(t, catalog.Tiers, 2)
+	assert.Equal(t, "Official", catalog.Tiers[0].Name)
+	assert.Equal(t, "Community", catalog.Tiers[1].Name)
+	assert.Equal(t, map[string][]string{
+		"Official":  {"github"},
+		"Community": {"fetch", "remote"},
+	}, groupNames(catalog.Tiers))
+
+	assert.Equal(t, map[string][]string{
+		"git":         {"github"},
+		"web":         {"fetch", "github"},
+		untaggedGroup: {"remote"},
+	}, groupNames(catalog.Tags))
+}
+
+func TestRenderCatalog(t *testing.T) {
+	t.Parallel()
+
+	tmpl, err := LoadCatalogTemplate("")
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderCatalog(&buf, tmpl, catalogTestEntries()))
+	html := buf.String()
+
+	for _, name := range []string{"fetch", "github", "remote"} {
+		assert.Contains(t, html, `id="server-`+name+`"`)
+	}
+	assert.Contains(t, html, `<a href="https://github.com/example/fetch">Repository</a>`)
+	assert.Contains(t, html, `<a href="https://github.com/github/github-mcp-server">Repository</a>`)
+	assert.Contains(t, html, `<a href="https://github.com/features">Homepage</a>`)
+
+	// User content is escaped
+	assert.Contains(t, html, "GitHub &lt;b&gt;integration&lt;/b&gt; &amp; more")
+	assert.NotContains(t, html, "<script>")
+	assert.NotContains(t, html, `href="javascript:`)
+}
+
+func TestLoadCatalogTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	custom := filepath.Join(dir, "custom.tmpl")
+	require.NoError(t, os.WriteFile(custom,
+		[]byte(`{{ range .Tiers }}{{ range .Servers }}<p>{{ .Name }}: {{ .Description }}</p>{{ end }}{{ end }}`), 0600))
+	invalid := filepath.Join(dir, "invalid.tmpl")
+	require.NoError(t, os.WriteFile(invalid, []byte(`{{ range .Tiers }}`), 0600))
+
+	tmpl, err := LoadCatalogTemplate(custom)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, RenderCatalog(&buf, tmpl, catalogTestEntries()))
+	assert.Equal(t,
+		"<p>github: GitHub &lt;b&gt;integration&lt;/b&gt; &amp; more</p>"+
+			"<p>fetch: Fetches web pages</p>"+
+			"<p>remote: &lt;script&gt;alert(&#39;x&#39;)&lt;/script&gt;</p>",
+		buf.String())
+
+	_, err = LoadCatalogTemplate(invalid)
+	assert.ErrorContains(t, err, "failed to parse template")
+
+	_, err = LoadCatalogTemplate(filepath.Join(dir, "missing.tmpl"))
+	assert.ErrorContains(t, err, "failed to read template")
+}
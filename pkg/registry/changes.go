@@ -0,0 +1,186 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+)
+
+// ContentHash returns a stable SHA-256 hash of a server's built JSON content
+func ContentHash(server any) (string, error) {
+	data, err := json.Marshal(server)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal server: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// ServerHashes returns the content hash of every server in a built registry, keyed by name
+func ServerHashes(registry *toolhiveRegistry.Registry) (map[string]string, error) {
+	hashes := make(map[string]string, len(registry.Servers)+len(registry.RemoteServers))
+	for name, server := range registry.Servers {
+		hash, err := ContentHash(server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		hashes[name] = hash
+	}
+	for name, server := range registry.RemoteServers {
+		hash, err := ContentHash(server)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash %s: %w", name, err)
+		}
+		hashes[name] = hash
+	}
+	return hashes, nil
+}
+
+// LoadRegistryFile reads a built registry.json. A missing file is treated as an empty registry
+// so that a first build compares as every server being added.
+func LoadRegistryFile(path string) (*toolhiveRegistry.Registry, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the caller
+	if errors.Is(err, os.ErrNotExist) {
+		return &toolhiveRegistry.Registry{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	var registry toolhiveRegistry.Registry
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &registry, nil
+}
+
+// CompareRegistryFiles returns the difference between two built registry.json files
+func CompareRegistryFiles(previousPath, currentPath string) (RegistryDiff, error) {
+	previous, err := LoadRegistryFile(previousPath)
+	if err != nil {
+		return RegistryDiff{}, err
+	}
+	current, err := LoadRegistryFile(currentPath)
+	if err != nil {
+		return RegistryDiff{}, err
+	}
+	return Diff(previous, current), nil
+}
+
+// BuildAtRef builds the registry as it was at a git ref. The registry directory
+// is exported from git into a temporary directory and loaded from there with a
+// loader from newLoader, so it's configured like the current build, or NewLoader if nil.
+func BuildAtRef(registryPath, ref string, newLoader func(dir string) *Loader) (*toolhiveRegistry.Registry, error) {
+	absPath, err := filepath.Abs(registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve registry path: %w", err)
+	}
+
+	topLevel, err := gitOutput(absPath, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	// Resolve symlinks so the relative path is computed between like paths
+	root, err := filepath.EvalSymlinks(strings.TrimSpace(string(topLevel)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve repository root: %w", err)
+	}
+	if absPath, err = filepath.EvalSymlinks(absPath); err != nil {
+		return nil, fmt.Errorf("failed to resolve registry path: %w", err)
+	}
+	relPath, err := filepath.Rel(root, absPath)
+	if err != nil {
+		return nil, fmt.Errorf("registry path is not inside the git repository: %w", err)
+	}
+
+	archive, err := gitOutput(root, "archive", "--format=tar", ref, "--", filepath.ToSlash(relPath))
+	if err != nil {
+		return nil, err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "registry-at-ref-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if err := extractTar(bytes.NewReader(archive), tmpDir); err != nil {
+		return nil, fmt.Errorf("failed to extract registry at %s: %w", ref, err)
+	}
+
+	if newLoader == nil {
+		newLoader = func(dir string) *Loader { return NewLoader(dir) }
+	}
+	loader := newLoader(filepath.Join(tmpDir, relPath))
+	if err := loader.LoadAll(); err != nil {
+		return nil, fmt.Errorf("failed to load registry at %s: %w", ref, err)
+	}
+
+	return NewBuilder(loader).BuildRegistry()
+}
+
+// gitOutput runs a git command in dir and returns its standard output
+func gitOutput(dir string, args ...string) ([]byte, error) {
+	return gitOutputContext(context.Background(), dir, args...)
+}
+
+// gitOutputContext is gitOutput with a context that kills git when it's done
+func gitOutputContext(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", dir}, args...)...) // #nosec G204 - args are built internally
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// extractTar extracts regular files and directories from a tar stream into dir
+func extractTar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.FromSlash(header.Name)) // #nosec G305 - checked below
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("invalid path in archive: %s", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+				return err
+			}
+			data, err := io.ReadAll(tr)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(target, data, 0600); err != nil {
+				return err
+			}
+		}
+	}
+}
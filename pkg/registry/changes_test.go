@@ -0,0 +1,129 @@
+package registry
+
+import (
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+}
+
+func writeSpec(t *testing.T, registryDir, name, description string) {
+	t.Helper()
+	dir := filepath.Join(registryDir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	spec := "description: " + description + "\nimage: test/" + name + ":1.0\ntransport: stdio\ntier: Community\nstatus: Active\ntools:\n  - tool1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(spec), 0644))
+}
+
+func TestBuildAtRef_ChangedEntries(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := t.TempDir()
+	registryDir := filepath.Join(repoDir, "registry")
+
+	writeSpec(t, registryDir, "server1", "First server")
+	writeSpec(t, registryDir, "server2", "Second server")
+	runGit(t, repoDir, "init", "-q")
+	runGit(t, repoDir, "add", "-A")
+	runGit(t, repoDir, "commit", "-q", "-m", "initial")
+
+	// Change one entry and add another in the working tree
+	writeSpec(t, registryDir, "server2", "Second server, updated")
+	writeSpec(t, registryDir, "server3", "Third server")
+
+	previous, err := BuildAtRef(registryDir, "HEAD", nil)
+	require.NoError(t, err)
+	assert.Len(t, previous.Servers, 2)
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	current, err := NewBuilder(loader).BuildRegistry()
+	require.NoError(t, err)
+
+	assert.Equal(t, []ServerChange{
+		{Name: "server2", Kind: ChangeModified, Fields: []FieldChange{
+			{Field: "description", Old: "Second server", New: "Second server, updated"},
+		}},
+		{Name: "server3", Kind: ChangeAdded},
+	}, Diff(previous, current).Servers)
+}
+
+func TestBuildAtRef_InvalidRef(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := t.TempDir()
+	registryDir := filepath.Join(repoDir, "registry")
+	writeSpec(t, registryDir, "server1", "First server")
+	runGit(t, repoDir, "init", "-q")
+
+	_, err := BuildAtRef(registryDir, "does-not-exist", nil)
+	assert.Error(t, err)
+}
+
+func TestBuildAtRef_LoaderOptions(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := t.TempDir()
+	registryDir := filepath.Join(repoDir, "registry")
+	writeSpec(t, registryDir, "server1", "First server")
+	writeRegistryFile(t, registryDir, "latest/spec.yaml", `description: Server pinned to latest
+image: test/latest:latest
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1
+`)
+	writeRegistryFile(t, registryDir, "disabled/spec.yaml", `description: Disabled server
+image: test/disabled:1.0
+transport: stdio
+tier: Community
+status: Active
+enabled: false
+tools:
+  - tool1
+`)
+	runGit(t, repoDir, "init", "-q")
+	runGit(t, repoDir, "add", "-A")
+	runGit(t, repoDir, "commit", "-q", "-m", "initial")
+
+	// A default loader rejects the latest tag
+	_, err := BuildAtRef(registryDir, "HEAD", nil)
+	assert.ErrorContains(t, err, "uses the latest tag")
+
+	// The loader is configured like the current build's
+	previous, err := BuildAtRef(registryDir, "HEAD", func(dir string) *Loader {
+		loader := NewLoader(dir)
+		loader.SetAllowLatest(true)
+		loader.SetSkipDisabled(true)
+		return loader
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"server1", "latest"}, slices.Collect(maps.Keys(previous.Servers)))
+}
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ConfigFile is the name of the registry-wide metadata file in the registry directory
+const ConfigFile = "registry.yaml"
+
+const (
+	// defaultTier is the tier of entries that don't set one, unless the registry config overrides it
+	defaultTier = "Community"
+	// defaultStatus is the status of entries that don't set one, unless the registry config overrides it
+	defaultStatus = "Active"
+)
+
+// statuses are the entry statuses the toolhive registry schema allows
+var statuses = []string{"Active", "Deprecated"}
+
+// Publication describes who publishes the registry. It's written to the output
+// alongside version and last_updated.
+type Publication struct {
+	// Maintainer is the organization that maintains the registry
+	Maintainer string `yaml:"maintainer,omitempty" json:"maintainer,omitempty"`
+	// Contact is an email address or https URL to reach the maintainer
+	Contact string `yaml:"contact,omitempty" json:"contact,omitempty"`
+	// Homepage is the registry's homepage
+	Homepage string `yaml:"homepage,omitempty" json:"homepage,omitempty"`
+	// License is the SPDX license of the registry itself, not of its servers
+	License string `yaml:"license,omitempty" json:"license,omitempty"`
+}
+
+// Defaults are applied to entries that don't set the field themselves
+type Defaults struct {
+	Tier   string `yaml:"tier,omitempty"`
+	Status string `yaml:"status,omitempty"`
+}
+
+// apply fills in the tier and status of an entry that doesn't set them itself
+func (d Defaults) apply(entry *types.RegistryEntry) {
+	var base *toolhiveRegistry.BaseServerMetadata
+	switch {
+	case entry.ImageMetadata != nil:
+		base = &entry.ImageMetadata.BaseServerMetadata
+	case entry.RemoteServerMetadata != nil:
+		base = &entry.RemoteServerMetadata.BaseServerMetadata
+	default:
+		return
+	}
+
+	if base.Tier == "" {
+		base.Tier = d.Tier
+	}
+	if base.Status == "" {
+		base.Status = d.Status
+	}
+}
+
+// Config is the registry-wide metadata read from registry.yaml
+type Config struct {
+	Publication `yaml:",inline"`
+	Defaults    Defaults `yaml:"defaults,omitempty"`
+	// AllowLatest names the entries whose image may stay on the latest tag until it's pinned
+	AllowLatest []string `yaml:"allow_latest,omitempty"`
+}
+
+// DefaultConfig returns the config used when the registry has no registry.yaml
+func DefaultConfig() *Config {
+	return &Config{Defaults: Defaults{Tier: defaultTier, Status: defaultStatus}}
+}
+
+// LoadConfig reads registry.yaml from the registry directory. Defaults the file
+// doesn't set are filled in, and a missing file yields DefaultConfig.
+func LoadConfig(registryPath string) (*Config, error) {
+	path := filepath.Join(registryPath, ConfigFile)
+	data, err := os.ReadFile(path) // #nosec G304 - path is constructed from known directory structure
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultConfig(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	config, err := ParseConfig(data)
+	if err != nil {
+		return nil, &EntryError{Path: path, Err: err}
+	}
+	return config, nil
+}
+
+// ParseConfig parses and validates the content of a registry.yaml file.
+// Unknown fields are rejected so typos don't go unnoticed.
+func ParseConfig(data []byte) (*Config, error) {
+	config := DefaultConfig()
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Validate checks the publication fields and defaults
+func (c *Config) Validate() error {
+	if err := validateLinkURL(c.Homepage); err != nil {
+		return fmt.Errorf("invalid homepage: %w", err)
+	}
+	if err := validateContact(c.Contact); err != nil {
+		return fmt.Errorf("invalid contact: %w", err)
+	}
+	if issue := CheckLicense(c.License); issue != nil {
+		return fmt.Errorf("registry %s", issue)
+	}
+
+	if !slices.Contains(tierOrder, c.Defaults.Tier) {
+		return fmt.Errorf("invalid default tier %q (supported: %s)", c.Defaults.Tier, strings.Join(tierOrder, ", "))
+	}
+	if !slices.Contains(statuses, c.Defaults.Status) {
+		return fmt.Errorf("invalid default status %q (supported: %s)", c.Defaults.Status, strings.Join(statuses, ", "))
+	}
+
+	return nil
+}
+
+// validateContact checks that an optional contact is an email address or an absolute https URL
+func validateContact(contact string) error {
+	if contact == "" || !strings.Contains(contact, "@") || strings.Contains(contact, "://") {
+		return validateLinkURL(contact)
+	}
+
+	address, err := mail.ParseAddress(contact)
+	if err != nil || address.Address != contact {
+		return fmt.Errorf("%q must be an email address or an absolute https URL", contact)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    *Config
+		wantErr string
+	}{
+		{
+			name: "empty file uses defaults",
+			data: "",
+			want: DefaultConfig(),
+		},
+		{
+			name: "publication and defaults",
+			data: `maintainer: Example Org
+contact: registry@example.com
+homepage: https://example.com/registry
+license: Apache-2.0
+defaults:
+  tier: Official
+  status: Deprecated
+`,
+			want: &Config{
+				Publication: Publication{
+					Maintainer: "Example Org",
+					Contact:    "registry@example.com",
+					Homepage:   "https://example.com/registry",
+					License:    "Apache-2.0",
+				},
+				Defaults: Defaults{Tier: "Official", Status: "Deprecated"},
+			},
+		},
+		{
+			name: "unset defaults are filled in",
+			data: "maintainer: Example Org\ndefaults:\n  tier: Official\n",
+			want: &Config{
+				Publication: Publication{Maintainer: "Example Org"},
+				Defaults:    Defaults{Tier: "Official", Status: "Active"},
+			},
+		},
+		{
+			name: "contact can be a URL",
+			data: "contact: https://github.com/example/registry/issues\n",
+			want: &Config{
+				Publication: Publication{Contact: "https://github.com/example/registry/issues"},
+				Defaults:    Defaults{Tier: "Community", Status: "Active"},
+			},
+		},
+		{name: "unknown field", data: "maintainr: Example Org\n", wantErr: "field maintainr not found"},
+		{name: "wrong shape", data: "defaults: Official\n", wantErr: "failed to parse YAML"},
+		{name: "insecure homepage", data: "homepage: http://example.com\n", wantErr: "invalid homepage"},
+		{name: "invalid contact", data: "contact: not an address@\n", wantErr: "invalid contact"},
+		{name: "non-canonical license", data: "license: apache 2\n", wantErr: `registry license "apache 2"`},
+		{name: "invalid default tier", data: "defaults:\n  tier: Gold\n", wantErr: `invalid default tier "Gold"`},
+		{name: "invalid default status", data: "defaults:\n  status: Retired\n", wantErr: `invalid default status "Retired"`},
+		{name: "status outside the schema", data: "defaults:\n  status: Beta\n", wantErr: `invalid default status "Beta"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			config, err := ParseConfig([]byte(tt.data))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, config)
+		})
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	// A registry without registry.yaml uses the defaults
+	config, err := LoadConfig(t.TempDir())
+	require.NoError(t, err)
+	assert.Equal(t, DefaultConfig(), config)
+
+	// Errors point at the file and its line
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFile), []byte("maintainer: Example Org\nfoo: bar\n"), 0644))
+	_, err = LoadConfig(dir)
+	var entryErr *EntryError
+	require.ErrorAs(t, err, &entryErr)
+	assert.Equal(t, filepath.Join(dir, ConfigFile), entryErr.Path)
+	assert.Equal(t, 2, entryErr.Line())
+}
+
+func TestBuilder_AppliesRegistryConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFile), []byte(`maintainer: Example Org
+contact: registry@example.com
+homepage: https://example.com/registry
+license: Apache-2.0
+defaults:
+  tier: Official
+  status: Deprecated
+`), 0644))
+	for name, spec := range map[string]string{
+		"defaulted": `image: example/defaulted:1.0.0
+description: Server without tier or status
+transport: stdio
+tools:
+  - example_tool
+`,
+		"explicit": `url: https://api.example.com/mcp
+description: Server with its own tier and status
+transport: streamable-http
+tier: Community
+status: Active
+tools:
+  - example_tool
+`,
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, "spec.yaml"), []byte(spec), 0644))
+	}
+
+	// Defaults are filled in when loading, so entries relying on them pass validation
+	loader := NewLoader(dir)
+	require.NoError(t, loader.LoadAll())
+	assert.Equal(t, "Official", loader.GetEntries()["defaulted"].GetTier())
+	builder := NewBuilder(loader)
+
+	// Defaults only apply to entries that don't set the field
+	registry, err := builder.BuildRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "Official", registry.Servers["defaulted"].Tier)
+	assert.Equal(t, "Deprecated", registry.Servers["defaulted"].Status)
+	assert.Equal(t, "Community", registry.RemoteServers["explicit"].Tier)
+	assert.Equal(t, "Active", registry.RemoteServers["explicit"].Status)
+
+	// Publication metadata is written alongside version and last_updated
+	data, err := builder.MarshalJSON()
+	require.NoError(t, err)
+	var output map[string]any
+	require.NoError(t, json.Unmarshal(data, &output))
+	assert.Equal(t, "1.0.0", output["version"])
+	assert.Equal(t, "Example Org", output["maintainer"])
+	assert.Equal(t, "registry@example.com", output["contact"])
+	assert.Equal(t, "https://example.com/registry", output["homepage"])
+	assert.Equal(t, "Apache-2.0", output["license"])
+	assert.NotContains(t, output, "defaults")
+}
+
+func TestBuilder_InvalidRegistryConfig(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ConfigFile), []byte("defaults:\n  tier: Gold\n"), 0644))
+
+	_, err := NewBuilder(NewLoader(dir)).BuildRegistry()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid default tier "Gold"`)
+}
@@ -0,0 +1,83 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Severity is how serious a validation finding is
+type Severity string
+
+const (
+	// SeverityError is a finding that fails validation
+	SeverityError Severity = "error"
+	// SeverityWarning is a finding that doesn't fail validation
+	SeverityWarning Severity = "warning"
+)
+
+// FieldError is a validation failure of a single field of an entry
+type FieldError struct {
+	Entry string
+	// Field is the spec field the failure concerns, or "" if it concerns the entry as a whole
+	Field string
+	Err   error
+}
+
+// Error returns the error message, prefixed with the entry name
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("entry '%s': %v", e.Entry, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostic is a machine-readable validation finding, for editors and other tooling
+type Diagnostic struct {
+	Entry    string   `json:"entry"`
+	Field    string   `json:"field"`
+	Message  string   `json:"message"`
+	Severity Severity `json:"severity"`
+}
+
+// Diagnostics returns an error diagnostic for each failure in err, an error loading or
+// validating the named entry. Failures that aren't tied to a field are reported against
+// the entry as a whole with the full error message.
+func Diagnostics(entry string, err error) []Diagnostic {
+	fieldErrs := fieldErrors(err)
+	if len(fieldErrs) == 0 {
+		return []Diagnostic{{Entry: entry, Message: err.Error(), Severity: SeverityError}}
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		diagnostics = append(diagnostics, Diagnostic{
+			Entry:    entry,
+			Field:    fieldErr.Field,
+			Message:  fieldErr.Err.Error(),
+			Severity: SeverityError,
+		})
+	}
+	return diagnostics
+}
+
+// fieldErrors returns the field errors in the tree of errors wrapped by err. The tree is
+// walked by hand because errors.As only finds the first.
+func fieldErrors(err error) []*FieldError {
+	switch e := err.(type) {
+	case *FieldError:
+		return []*FieldError{e}
+	case interface{ Unwrap() []error }:
+		var all []*FieldError
+		for _, inner := range e.Unwrap() {
+			all = append(all, fieldErrors(inner)...)
+		}
+		return all
+	}
+
+	if inner := errors.Unwrap(err); inner != nil {
+		return fieldErrors(inner)
+	}
+	return nil
+}
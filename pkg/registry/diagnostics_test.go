@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		err  error
+		want []Diagnostic
+	}{
+		{
+			name: "joined field errors",
+			err: &EntryError{Path: "registry/example/spec.yaml", Err: fmt.Errorf("validation failed: %w", errors.Join(
+				&FieldError{Entry: "example", Field: "description", Err: errors.New("description is required")},
+				&FieldError{Entry: "example", Field: "tools", Err: errors.New("at least one tool must be specified")},
+			))},
+			want: []Diagnostic{
+				{Entry: "example", Field: "description", Message: "description is required", Severity: SeverityError},
+				{Entry: "example", Field: "tools", Message: "at least one tool must be specified", Severity: SeverityError},
+			},
+		},
+		{
+			name: "entry error",
+			err:  &EntryError{Path: "registry/example/spec.yaml", Err: errors.New("yaml: line 1: did not find expected node content")},
+			want: []Diagnostic{{
+				Entry:    "example",
+				Message:  "failed to load registry/example/spec.yaml: yaml: line 1: did not find expected node content",
+				Severity: SeverityError,
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, Diagnostics("example", tt.err))
+		})
+	}
+}
+
+func TestValidateEntryFields_ReportsEveryField(t *testing.T) {
+	t.Parallel()
+
+	entry := &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{Transport: "carrier-pigeon"},
+			Image:              "test/example:1.0.0",
+		},
+		Homepage: "http://example.com",
+	}
+	err := NewSchemaValidator().ValidateEntryFields(entry, "example")
+
+	var fields []string
+	for _, diagnostic := range Diagnostics("example", err) {
+		fields = append(fields, diagnostic.Field)
+	}
+	assert.Equal(t, []string{"description", "transport", "tools", "homepage"}, fields)
+	assert.Contains(t, err.Error(), "entry 'example': description is required")
+}
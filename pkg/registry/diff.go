@@ -0,0 +1,159 @@
+package registry
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+)
+
+// ChangeKind describes how a server changed between two builds
+type ChangeKind string
+
+const (
+	// ChangeAdded means the server only exists in the new build
+	ChangeAdded ChangeKind = "added"
+	// ChangeRemoved means the server only exists in the old build
+	ChangeRemoved ChangeKind = "removed"
+	// ChangeModified means the server exists in both builds with different content
+	ChangeModified ChangeKind = "modified"
+)
+
+// typeField is the pseudo-field reported when a server moves between image and remote servers
+const typeField = "type"
+
+// FieldChange is the old and new value of a single top-level field of a server, as it
+// appears in the built JSON. Old is nil for added fields and New is nil for removed ones.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old,omitempty"`
+	New   any    `json:"new,omitempty"`
+}
+
+// ServerChange describes a server whose built content differs between two builds
+type ServerChange struct {
+	Name string     `json:"name"`
+	Kind ChangeKind `json:"kind"`
+	// Fields holds the per-field deltas of modified servers, sorted by field name
+	Fields []FieldChange `json:"fields,omitempty"`
+}
+
+// FieldNames returns the names of the changed fields
+func (c ServerChange) FieldNames() []string {
+	names := make([]string, 0, len(c.Fields))
+	for _, field := range c.Fields {
+		names = append(names, field.Field)
+	}
+	return names
+}
+
+// RegistryDiff is the structured difference between two built registries
+type RegistryDiff struct {
+	// Servers holds every added, removed, or modified server, sorted by name
+	Servers []ServerChange `json:"servers"`
+}
+
+// Empty returns true if the registries have the same servers with the same content
+func (d RegistryDiff) Empty() bool {
+	return len(d.Servers) == 0
+}
+
+// Count returns the number of servers with the given kind of change
+func (d RegistryDiff) Count(kind ChangeKind) int {
+	count := 0
+	for _, change := range d.Servers {
+		if change.Kind == kind {
+			count++
+		}
+	}
+	return count
+}
+
+// Diff compares two built registries server by server. Servers are compared on their
+// built JSON, so only changes that reach the published registry are reported.
+// Registry-level fields such as last_updated are ignored.
+func Diff(a, b *toolhiveRegistry.Registry) RegistryDiff {
+	oldServers := diffableServers(a)
+	newServers := diffableServers(b)
+
+	names := make(map[string]bool)
+	for name := range oldServers {
+		names[name] = true
+	}
+	for name := range newServers {
+		names[name] = true
+	}
+
+	diff := RegistryDiff{Servers: []ServerChange{}}
+	for _, name := range sortedKeys(names) {
+		oldServer, inOld := oldServers[name]
+		newServer, inNew := newServers[name]
+		switch {
+		case !inOld:
+			diff.Servers = append(diff.Servers, ServerChange{Name: name, Kind: ChangeAdded})
+		case !inNew:
+			diff.Servers = append(diff.Servers, ServerChange{Name: name, Kind: ChangeRemoved})
+		default:
+			if fields := diffFields(oldServer, newServer); len(fields) > 0 {
+				diff.Servers = append(diff.Servers, ServerChange{Name: name, Kind: ChangeModified, Fields: fields})
+			}
+		}
+	}
+
+	return diff
+}
+
+// diffableServers returns every server of a registry as its built JSON fields, keyed by name.
+// Remote servers get a type field so moving between image and remote shows up as a change.
+func diffableServers(registry *toolhiveRegistry.Registry) map[string]map[string]any {
+	servers := make(map[string]map[string]any)
+	if registry == nil {
+		return servers
+	}
+
+	for name, server := range registry.Servers {
+		fields := jsonFields(server)
+		fields[typeField] = "container"
+		servers[name] = fields
+	}
+	for name, server := range registry.RemoteServers {
+		fields := jsonFields(server)
+		fields[typeField] = "remote"
+		servers[name] = fields
+	}
+
+	return servers
+}
+
+// jsonFields returns the top-level fields of a value's JSON encoding.
+// Registry servers are plain data, so encoding them can't fail.
+func jsonFields(v any) map[string]any {
+	fields := make(map[string]any)
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// diffFields returns the fields whose values differ between two servers, sorted by field name
+func diffFields(oldFields, newFields map[string]any) []FieldChange {
+	var changes []FieldChange
+	for field, oldValue := range oldFields {
+		if newValue, ok := newFields[field]; !ok || !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, FieldChange{Field: field, Old: oldValue, New: newFields[field]})
+		}
+	}
+	for field, newValue := range newFields {
+		if _, ok := oldFields[field]; !ok {
+			changes = append(changes, FieldChange{Field: field, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func diffTestImage(description string, tools ...string) *toolhiveRegistry.ImageMetadata {
+	return &toolhiveRegistry.ImageMetadata{
+		BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+			Description: description,
+			Transport:   "stdio",
+			Tools:       tools,
+		},
+		Image: "test/image:1.0",
+	}
+}
+
+func diffTestRemote(description string) *toolhiveRegistry.RemoteServerMetadata {
+	return &toolhiveRegistry.RemoteServerMetadata{
+		BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+			Description: description,
+			Transport:   "streamable-http",
+			Tools:       []string{"tool1"},
+		},
+		URL: "https://api.example.com/mcp",
+	}
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		a    *toolhiveRegistry.Registry
+		b    *toolhiveRegistry.Registry
+		want []ServerChange
+	}{
+		{
+			name: "identical registries",
+			a: &toolhiveRegistry.Registry{
+				Servers:       map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("Image", "tool1")},
+				RemoteServers: map[string]*toolhiveRegistry.RemoteServerMetadata{"remote": diffTestRemote("Remote")},
+			},
+			b: &toolhiveRegistry.Registry{
+				Servers:       map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("Image", "tool1")},
+				RemoteServers: map[string]*toolhiveRegistry.RemoteServerMetadata{"remote": diffTestRemote("Remote")},
+			},
+			want: []ServerChange{},
+		},
+		{
+			name: "registry-level fields are ignored",
+			a:    &toolhiveRegistry.Registry{Version: "1.0.0", LastUpdated: "2025-01-01T00:00:00Z"},
+			b:    &toolhiveRegistry.Registry{Version: "1.0.0", LastUpdated: "2025-02-01T00:00:00Z"},
+			want: []ServerChange{},
+		},
+		{
+			name: "added servers",
+			a:    &toolhiveRegistry.Registry{},
+			b: &toolhiveRegistry.Registry{
+				Servers:       map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("Image", "tool1")},
+				RemoteServers: map[string]*toolhiveRegistry.RemoteServerMetadata{"remote": diffTestRemote("Remote")},
+			},
+			want: []ServerChange{
+				{Name: "image", Kind: ChangeAdded},
+				{Name: "remote", Kind: ChangeAdded},
+			},
+		},
+		{
+			name: "removed servers",
+			a: &toolhiveRegistry.Registry{
+				Servers:       map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("Image", "tool1")},
+				RemoteServers: map[string]*toolhiveRegistry.RemoteServerMetadata{"remote": diffTestRemote("Remote")},
+			},
+			b: &toolhiveRegistry.Registry{},
+			want: []ServerChange{
+				{Name: "image", Kind: ChangeRemoved},
+				{Name: "remote", Kind: ChangeRemoved},
+			},
+		},
+		{
+			name: "changed fields",
+			a: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("Old", "tool1")},
+			},
+			b: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("New", "tool1", "tool2")},
+			},
+			want: []ServerChange{
+				{Name: "image", Kind: ChangeModified, Fields: []FieldChange{
+					{Field: "description", Old: "Old", New: "New"},
+					{Field: "tools", Old: []any{"tool1"}, New: []any{"tool1", "tool2"}},
+				}},
+			},
+		},
+		{
+			name: "added and removed fields",
+			a: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{"image": func() *toolhiveRegistry.ImageMetadata {
+					server := diffTestImage("Image", "tool1")
+					server.Tags = []string{"old-tag"}
+					return server
+				}()},
+			},
+			b: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{"image": func() *toolhiveRegistry.ImageMetadata {
+					server := diffTestImage("Image", "tool1")
+					server.RepositoryURL = "https://github.com/example/image"
+					return server
+				}()},
+			},
+			want: []ServerChange{
+				{Name: "image", Kind: ChangeModified, Fields: []FieldChange{
+					{Field: "repository_url", New: "https://github.com/example/image"},
+					{Field: "tags", Old: []any{"old-tag"}},
+				}},
+			},
+		},
+		{
+			name: "nil registries",
+			a:    nil,
+			b: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{"image": diffTestImage("Image", "tool1")},
+			},
+			want: []ServerChange{{Name: "image", Kind: ChangeAdded}},
+		},
+		{
+			name: "changes are sorted by name",
+			a: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{
+					"charlie": diffTestImage("Charlie", "tool1"),
+					"alpha":   diffTestImage("Alpha", "tool1"),
+				},
+			},
+			b: &toolhiveRegistry.Registry{
+				Servers: map[string]*toolhiveRegistry.ImageMetadata{
+					"bravo":   diffTestImage("Bravo", "tool1"),
+					"charlie": diffTestImage("Charlie", "tool2"),
+				},
+			},
+			want: []ServerChange{
+				{Name: "alpha", Kind: ChangeRemoved},
+				{Name: "bravo", Kind: ChangeAdded},
+				{Name: "charlie", Kind: ChangeModified, Fields: []FieldChange{
+					{Field: "tools", Old: []any{"tool1"}, New: []any{"tool2"}},
+				}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			diff := Diff(tt.a, tt.b)
+			assert.Equal(t, tt.want, diff.Servers)
+			assert.Equal(t, len(tt.want) == 0, diff.Empty())
+		})
+	}
+}
+
+func TestRegistryDiff_Count(t *testing.T) {
+	t.Parallel()
+
+	diff := RegistryDiff{Servers: []ServerChange{
+		{Name: "a", Kind: ChangeAdded},
+		{Name: "b", Kind: ChangeAdded},
+		{Name: "c", Kind: ChangeModified, Fields: []FieldChange{{Field: "description"}, {Field: "tools"}}},
+	}}
+
+	assert.Equal(t, 2, diff.Count(ChangeAdded))
+	assert.Equal(t, 0, diff.Count(ChangeRemoved))
+	assert.Equal(t, 1, diff.Count(ChangeModified))
+	assert.Equal(t, []string{"description", "tools"}, diff.Servers[2].FieldNames())
+	assert.Empty(t, diff.Servers[0].FieldNames())
+}
+
+func TestDiff_TypeChange(t *testing.T) {
+	t.Parallel()
+
+	diff := Diff(
+		&toolhiveRegistry.Registry{
+			Servers: map[string]*toolhiveRegistry.ImageMetadata{"server": diffTestImage("Server", "tool1")},
+		},
+		&toolhiveRegistry.Registry{
+			RemoteServers: map[string]*toolhiveRegistry.RemoteServerMetadata{"server": diffTestRemote("Server")},
+		},
+	)
+
+	assert.Len(t, diff.Servers, 1)
+	change := diff.Servers[0]
+	assert.Equal(t, ChangeModified, change.Kind)
+	assert.Contains(t, change.Fields, FieldChange{Field: "type", Old: "container", New: "remote"})
+	assert.Contains(t, change.Fields, FieldChange{Field: "image", Old: "test/image:1.0"})
+	assert.Contains(t, change.Fields, FieldChange{Field: "url", New: "https://api.example.com/mcp"})
+	assert.NotContains(t, change.FieldNames(), "description")
+}
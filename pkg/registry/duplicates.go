@@ -0,0 +1,177 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// DuplicateKind describes why two entries are considered possible duplicates
+type DuplicateKind string
+
+const (
+	// DuplicateImage means both entries use the same image, ignoring tag and digest
+	DuplicateImage DuplicateKind = "same-image"
+	// DuplicateRepository means both entries point at the same source repository
+	DuplicateRepository DuplicateKind = "same-repository"
+	// DuplicateName means the entry names are nearly identical
+	DuplicateName DuplicateKind = "similar-name"
+)
+
+// minNameLengthForDistance avoids flagging short, unrelated names such as "git" and "time"
+const minNameLengthForDistance = 6
+
+// longNameLength is the length from which names may differ by two characters rather than one
+const longNameLength = 10
+
+// DuplicateFinding describes a pair of entries that may be duplicates
+type DuplicateFinding struct {
+	Kind    DuplicateKind `json:"kind"`
+	Entries []string      `json:"entries"`
+	Detail  string        `json:"detail"`
+}
+
+// String returns a human-readable description of the finding
+func (f DuplicateFinding) String() string {
+	return fmt.Sprintf("[%s] %s: %s", f.Kind, strings.Join(f.Entries, ", "), f.Detail)
+}
+
+// FindDuplicates looks for entries that share an image, a repository URL, or a nearly identical name
+func FindDuplicates(entries map[string]*types.RegistryEntry) []DuplicateFinding {
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var findings []DuplicateFinding
+	findings = append(findings, groupByKey(names, entries, DuplicateImage, imageKey)...)
+	findings = append(findings, groupByKey(names, entries, DuplicateRepository, repositoryKey)...)
+
+	// Compare every pair of names
+	for i := 0; i < len(names); i++ {
+		for j := i + 1; j < len(names); j++ {
+			if detail, ok := similarNames(names[i], names[j]); ok {
+				findings = append(findings, DuplicateFinding{
+					Kind:    DuplicateName,
+					Entries: []string{names[i], names[j]},
+					Detail:  detail,
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// groupByKey reports every group of two or more entries sharing the same non-empty key
+func groupByKey(
+	names []string,
+	entries map[string]*types.RegistryEntry,
+	kind DuplicateKind,
+	key func(*types.RegistryEntry) string,
+) []DuplicateFinding {
+	groups := make(map[string][]string)
+	var keys []string
+	for _, name := range names {
+		k := key(entries[name])
+		if k == "" {
+			continue
+		}
+		if _, ok := groups[k]; !ok {
+			keys = append(keys, k)
+		}
+		groups[k] = append(groups[k], name)
+	}
+
+	var findings []DuplicateFinding
+	for _, k := range keys {
+		if len(groups[k]) > 1 {
+			findings = append(findings, DuplicateFinding{
+				Kind:    kind,
+				Entries: groups[k],
+				Detail:  k,
+			})
+		}
+	}
+	return findings
+}
+
+// imageKey returns the image repository without tag or digest
+func imageKey(entry *types.RegistryEntry) string {
+	ref, err := entry.ImageReference()
+	if err != nil {
+		return ""
+	}
+	return ref.Name()
+}
+
+// repositoryKey returns a normalized repository URL
+func repositoryKey(entry *types.RegistryEntry) string {
+	url := strings.ToLower(strings.TrimSpace(entry.GetRepositoryURL()))
+	url = strings.TrimSuffix(url, "/")
+	url = strings.TrimSuffix(url, ".git")
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "www.")
+	return url
+}
+
+// similarNames reports whether two entry names are nearly identical
+func similarNames(a, b string) (string, bool) {
+	na, nb := normalizeName(a), normalizeName(b)
+	if na != "" && na == nb {
+		return fmt.Sprintf("names normalize to %q", na), true
+	}
+
+	shortest := min(len(na), len(nb))
+	if shortest >= minNameLengthForDistance {
+		maxDistance := 1
+		if shortest >= longNameLength {
+			maxDistance = 2
+		}
+		if d := levenshtein(na, nb); d <= maxDistance {
+			return fmt.Sprintf("names differ by %d character(s)", d), true
+		}
+	}
+
+	return "", false
+}
+
+// normalizeName strips separators and common MCP naming affixes
+func normalizeName(name string) string {
+	name = strings.ToLower(name)
+	for _, affix := range []string{"mcp-server-", "-mcp-server", "mcp-", "-mcp", "-server"} {
+		if strings.HasPrefix(affix, "-") {
+			name = strings.TrimSuffix(name, affix)
+		} else {
+			name = strings.TrimPrefix(name, affix)
+		}
+	}
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(name)
+}
+
+// levenshtein returns the edit distance between two strings
+func levenshtein(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func newImageEntry(image, repositoryURL string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description:   "Test server",
+				Transport:     "stdio",
+				Tools:         []string{"test-tool"},
+				RepositoryURL: repositoryURL,
+			},
+			Image: image,
+		},
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		entries map[string]*types.RegistryEntry
+		want    []DuplicateFinding
+	}{
+		{
+			name: "no duplicates",
+			entries: map[string]*types.RegistryEntry{
+				"fetch": newImageEntry("mcp/fetch:1.0", "https://github.com/example/fetch"),
+				"git":   newImageEntry("mcp/git:1.0", "https://github.com/example/git"),
+				"time":  newImageEntry("mcp/time:1.0", ""),
+			},
+		},
+		{
+			name: "same image with different tags",
+			entries: map[string]*types.RegistryEntry{
+				"fetch":       newImageEntry("mcp/fetch:1.0", ""),
+				"web-fetcher": newImageEntry("docker.io/mcp/fetch:2.0", ""),
+			},
+			want: []DuplicateFinding{
+				{Kind: DuplicateImage, Entries: []string{"fetch", "web-fetcher"}, Detail: "docker.io/mcp/fetch"},
+			},
+		},
+		{
+			name: "same repository url",
+			entries: map[string]*types.RegistryEntry{
+				"notion":       newImageEntry("mcp/notion:1.0", "https://github.com/makenotion/notion-mcp-server"),
+				"notion-tools": newImageEntry("ghcr.io/example/notion:1.0", "https://github.com/makenotion/notion-mcp-server.git"),
+			},
+			want: []DuplicateFinding{
+				{
+					Kind:    DuplicateRepository,
+					Entries: []string{"notion", "notion-tools"},
+					Detail:  "github.com/makenotion/notion-mcp-server",
+				},
+			},
+		},
+		{
+			name: "names differing only by mcp affix",
+			entries: map[string]*types.RegistryEntry{
+				"graphlit":            newImageEntry("mcp/graphlit:1.0", ""),
+				"graphlit-mcp-server": newImageEntry("ghcr.io/graphlit/mcp:1.0", ""),
+			},
+			want: []DuplicateFinding{
+				{
+					Kind:    DuplicateName,
+					Entries: []string{"graphlit", "graphlit-mcp-server"},
+					Detail:  `names normalize to "graphlit"`,
+				},
+			},
+		},
+		{
+			name: "names with a typo",
+			entries: map[string]*types.RegistryEntry{
+				"firecrawl": newImageEntry("mcp/firecrawl:1.0", ""),
+				"firecrowl": newImageEntry("ghcr.io/example/firecrowl:1.0", ""),
+			},
+			want: []DuplicateFinding{
+				{
+					Kind:    DuplicateName,
+					Entries: []string{"firecrawl", "firecrowl"},
+					Detail:  "names differ by 1 character(s)",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, FindDuplicates(tt.entries))
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// envVarNamePattern matches the env var names that are safe to pass to a container: upper-case
+// letters, digits and underscores, not starting with a digit
+var envVarNamePattern = regexp.MustCompile(`^[A-Z_][A-Z0-9_]*$`)
+
+// EnvVarNameIssue lists the env vars of an entry whose names don't follow shell conventions
+type EnvVarNameIssue struct {
+	Name    string   `json:"name"`
+	EnvVars []string `json:"env_vars"`
+}
+
+// String returns a human-readable description of the issue
+func (i EnvVarNameIssue) String() string {
+	return fmt.Sprintf("%s: env var names must match %s: %s", i.Name, envVarNamePattern, strings.Join(i.EnvVars, ", "))
+}
+
+// InvalidEnvVarNames returns the names of the env vars of an entry that don't match
+// ^[A-Z_][A-Z0-9_]*$, in the order they are declared
+func InvalidEnvVarNames(entry *types.RegistryEntry) []string {
+	var invalid []string
+	for _, envVar := range entry.GetEnvVars() {
+		if envVar != nil && !envVarNamePattern.MatchString(envVar.Name) {
+			invalid = append(invalid, envVar.Name)
+		}
+	}
+	return invalid
+}
+
+// CheckEnvVarNames returns an issue for every entry with env var names that don't follow
+// shell conventions, sorted by entry name
+func CheckEnvVarNames(entries map[string]*types.RegistryEntry) []EnvVarNameIssue {
+	var issues []EnvVarNameIssue
+	for _, name := range sortedKeys(entries) {
+		if invalid := InvalidEnvVarNames(entries[name]); len(invalid) > 0 {
+			issues = append(issues, EnvVarNameIssue{Name: name, EnvVars: invalid})
+		}
+	}
+	return issues
+}
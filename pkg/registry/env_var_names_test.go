@@ -0,0 +1,120 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// envVarEntry returns an image or remote entry declaring env vars with the given names
+func envVarEntry(remote bool, names ...string) *types.RegistryEntry {
+	envVars := make([]*toolhiveRegistry.EnvVar, 0, len(names))
+	for _, name := range names {
+		envVars = append(envVars, &toolhiveRegistry.EnvVar{Name: name, Description: "Test variable"})
+	}
+
+	base := toolhiveRegistry.BaseServerMetadata{
+		Description: "Test server",
+		Tier:        "Community",
+		Status:      "Active",
+		Tools:       []string{"test-tool"},
+	}
+	if remote {
+		base.Transport = "streamable-http"
+		return &types.RegistryEntry{RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: base,
+			URL:                "https://api.example.com/mcp",
+			EnvVars:            envVars,
+		}}
+	}
+	base.Transport = "stdio"
+	return &types.RegistryEntry{ImageMetadata: &toolhiveRegistry.ImageMetadata{
+		BaseServerMetadata: base,
+		Image:              "test/server:1.0.0",
+		EnvVars:            envVars,
+	}}
+}
+
+func TestInvalidEnvVarNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		envVars []string
+		want    []string
+	}{
+		{name: "none"},
+		{name: "valid", envVars: []string{"API_KEY", "_PRIVATE", "GITHUB_TOKEN_V2", "X"}},
+		{name: "lower case", envVars: []string{"api_key", "API_KEY"}, want: []string{"api_key"}},
+		{name: "mixed case", envVars: []string{"ApiKey"}, want: []string{"ApiKey"}},
+		{name: "dashes", envVars: []string{"API-KEY", "SERVER_URL"}, want: []string{"API-KEY"}},
+		{name: "leading digit", envVars: []string{"1PASSWORD_TOKEN"}, want: []string{"1PASSWORD_TOKEN"}},
+		{name: "spaces and empty", envVars: []string{"API KEY", ""}, want: []string{"API KEY", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, InvalidEnvVarNames(envVarEntry(false, tt.envVars...)), "image")
+			assert.Equal(t, tt.want, InvalidEnvVarNames(envVarEntry(true, tt.envVars...)), "remote")
+		})
+	}
+
+	assert.Empty(t, InvalidEnvVarNames(&types.RegistryEntry{}))
+}
+
+func TestCheckEnvVarNames(t *testing.T) {
+	t.Parallel()
+
+	issues := CheckEnvVarNames(map[string]*types.RegistryEntry{
+		"valid":  envVarEntry(false, "API_KEY"),
+		"remote": envVarEntry(true, "api-key", "API_URL", "region"),
+		"image":  envVarEntry(false, "github-token"),
+	})
+	assert.Equal(t, []EnvVarNameIssue{
+		{Name: "image", EnvVars: []string{"github-token"}},
+		{Name: "remote", EnvVars: []string{"api-key", "region"}},
+	}, issues)
+	assert.Equal(t, "remote: env var names must match ^[A-Z_][A-Z0-9_]*$: api-key, region", issues[1].String())
+}
+
+func TestSchemaValidator_StrictEnv(t *testing.T) {
+	t.Parallel()
+
+	for _, remote := range []bool{false, true} {
+		validator := NewSchemaValidator()
+		assert.NoError(t, validator.ValidateEntryFields(envVarEntry(remote, "api-key"), "test-entry"),
+			"invalid names are only warnings by default")
+
+		validator.SetStrictEnv(true)
+		assert.NoError(t, validator.ValidateEntryFields(envVarEntry(remote, "API_KEY"), "test-entry"))
+		err := validator.ValidateEntryFields(envVarEntry(remote, "api-key", "API_URL", "Region"), "test-entry")
+		require.Error(t, err)
+		assert.Equal(t, "entry 'test-entry': env var names must match ^[A-Z_][A-Z0-9_]*$: api-key, Region", err.Error())
+	}
+
+	// The loader passes its setting on to the validator
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "lower/spec.yaml", `description: Server with a lower-case env var
+transport: stdio
+image: test/lower:1.0.0
+tier: Community
+status: Active
+tools:
+  - tool1
+env_vars:
+  - name: api_key
+    description: API key
+`)
+	assert.NoError(t, NewLoader(registryDir).LoadAll())
+	loader := NewLoader(registryDir)
+	loader.SetStrictEnv(true)
+	err := loader.LoadAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "api_key")
+}
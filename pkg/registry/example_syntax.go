@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/kballard/go-shellquote"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// danglingOperators are shell operators a command can't end with
+var danglingOperators = []string{"|", "||", "&&"}
+
+// ExampleSyntaxIssue describes an example whose sample isn't valid shell syntax
+type ExampleSyntaxIssue struct {
+	Name    string `json:"name"`
+	Example string `json:"example"`
+	Reason  string `json:"reason"`
+}
+
+// String returns a human-readable description of the issue
+func (i ExampleSyntaxIssue) String() string {
+	return fmt.Sprintf("%s: example %q: %s", i.Name, i.Example, i.Reason)
+}
+
+// CheckExampleSyntax parses the sample of every example as shell words and reports
+// samples with unbalanced quotes or a dangling operator. Multi-line samples that read
+// as prose rather than commands are skipped.
+func CheckExampleSyntax(entries map[string]*types.RegistryEntry) []ExampleSyntaxIssue {
+	var issues []ExampleSyntaxIssue
+	for _, name := range sortedKeys(entries) {
+		for _, example := range entries[name].Examples {
+			if reason := CheckSampleSyntax(example.Sample); reason != "" {
+				issues = append(issues, ExampleSyntaxIssue{Name: name, Example: example.Name, Reason: reason})
+			}
+		}
+	}
+	return issues
+}
+
+// CheckSampleSyntax returns why a sample isn't valid shell syntax, or "" if it is,
+// if it's empty or if it's multi-line prose
+func CheckSampleSyntax(sample string) string {
+	if isProseSample(sample) {
+		return ""
+	}
+
+	// Comments are free text, so an apostrophe in one isn't an unbalanced quote
+	var lines []string
+	for _, line := range strings.Split(sample, "\n") {
+		if !strings.HasPrefix(strings.TrimSpace(line), "#") {
+			lines = append(lines, line)
+		}
+	}
+
+	words, err := shellquote.Split(strings.Join(lines, "\n"))
+	switch {
+	case errors.Is(err, shellquote.UnterminatedSingleQuoteError):
+		return "unbalanced single quote"
+	case errors.Is(err, shellquote.UnterminatedDoubleQuoteError):
+		return "unbalanced double quote"
+	case errors.Is(err, shellquote.UnterminatedEscapeError):
+		return "ends with a dangling backslash"
+	case err != nil:
+		return err.Error()
+	case len(words) == 0:
+		return ""
+	case slices.Contains(danglingOperators, words[0]):
+		return fmt.Sprintf("starts with %q", words[0])
+	case slices.Contains(danglingOperators, words[len(words)-1]):
+		return fmt.Sprintf("ends with %q", words[len(words)-1])
+	}
+	return ""
+}
+
+// isProseSample returns true for multi-line samples where at least half of the lines
+// read like sentences rather than commands
+func isProseSample(sample string) bool {
+	var lines, sentences int
+	for _, line := range strings.Split(sample, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		lines++
+		if isSentence(line) {
+			sentences++
+		}
+	}
+	return lines > 1 && sentences*2 >= lines
+}
+
+// isSentence returns true if a line starts with a capital letter, has at least three
+// words and ends with sentence punctuation
+func isSentence(line string) bool {
+	first := []rune(line)[0]
+	return unicode.IsUpper(first) &&
+		len(strings.Fields(line)) >= 3 &&
+		strings.ContainsAny(line[len(line)-1:], ".!?:")
+}
@@ -0,0 +1,66 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestCheckSampleSyntax(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		sample string
+		want   string
+	}{
+		{name: "empty", sample: ""},
+		{name: "well-formed command", sample: `thv run --env API_KEY="my key" github -- --flag 'single quoted'`},
+		{name: "multi-line command", sample: "thv run \\\n  --env TOKEN=abc \\\n  github | jq '.tools'\n"},
+		{name: "apostrophe in a comment", sample: "# Don't forget the token\nthv run github\n"},
+		{name: "quoted operator", sample: `echo "a |"`},
+		{name: "unbalanced single quote", sample: `thv run github --query 'repos`, want: "unbalanced single quote"},
+		{name: "unbalanced double quote", sample: `thv run --env KEY="value github`, want: "unbalanced double quote"},
+		{name: "dangling backslash", sample: `thv run github \`, want: "ends with a dangling backslash"},
+		{name: "dangling pipe", sample: "thv run github |", want: `ends with "|"`},
+		{name: "leading operator", sample: "&& thv run github", want: `starts with "&&"`},
+		{
+			name: "prose is skipped",
+			sample: `Ask the assistant: "What's in my inbox today?"
+It will list the unread messages.
+Then ask it to summarize them.`,
+		},
+		{name: "single-line prose is checked", sample: "What's in my inbox today?", want: "unbalanced single quote"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, CheckSampleSyntax(tt.sample))
+		})
+	}
+}
+
+func TestCheckExampleSyntax(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string]*types.RegistryEntry{
+		"github": {Examples: []types.Example{
+			{Name: "Run", Sample: "thv run github"},
+			{Name: "Query", Sample: `thv run github --query "repos`},
+		}},
+		"fetch": {Examples: []types.Example{
+			{Name: "Pipe", Sample: "thv run fetch ||"},
+		}},
+		"plain": {},
+	}
+
+	issues := CheckExampleSyntax(entries)
+	assert.Equal(t, []ExampleSyntaxIssue{
+		{Name: "fetch", Example: "Pipe", Reason: `ends with "||"`},
+		{Name: "github", Example: "Query", Reason: "unbalanced double quote"},
+	}, issues)
+	assert.Equal(t, `github: example "Query": unbalanced double quote`, issues[1].String())
+}
@@ -0,0 +1,184 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ExportDiff compares the entries loaded from disk with an incoming registry.json, such as one
+// received from an external contributor. Added servers are only in the incoming registry and
+// removed servers only on disk. Both sides are compared as built, so defaults filled in by the
+// build don't show up as changes.
+func ExportDiff(loader *Loader, incoming *toolhiveRegistry.Registry) (RegistryDiff, error) {
+	builder := NewBuilder(loader)
+	config, err := builder.Config()
+	if err != nil {
+		return RegistryDiff{}, err
+	}
+	built, err := builder.BuildRegistry()
+	if err != nil {
+		return RegistryDiff{}, fmt.Errorf("failed to build registry: %w", err)
+	}
+	return Diff(built, processRegistry(incoming, config.Defaults)), nil
+}
+
+// ExportSpecPath returns the spec file a server of an ExportDiff maps to: the file the entry
+// was loaded from, or a spec.yaml in a new directory named after the server
+func ExportSpecPath(loader *Loader, name string) string {
+	if path := loader.SourcePath(name); path != "" {
+		return path
+	}
+	return filepath.Join(loader.registryPath, name, "spec.yaml")
+}
+
+// ApplyExportDiff writes the changes of an ExportDiff to the registry directory. Added servers
+// get a new entry directory and removed ones have theirs deleted. Modified servers only have
+// their changed fields rewritten, keeping comments and the fields only the registry reads,
+// unless they change between image and remote servers, in which case the metadata is replaced.
+// Written metadata has the defaults of registry.yaml filled in, as in the built registry.
+func ApplyExportDiff(loader *Loader, diff RegistryDiff, incoming *toolhiveRegistry.Registry) error {
+	config, err := NewBuilder(loader).Config()
+	if err != nil {
+		return err
+	}
+	incoming = processRegistry(incoming, config.Defaults)
+
+	for _, change := range diff.Servers {
+		specPath := ExportSpecPath(loader, change.Name)
+
+		switch change.Kind {
+		case ChangeAdded:
+			err = writeExportedEntry(specPath, &types.RegistryEntry{}, incoming, change.Name)
+		case ChangeRemoved:
+			err = os.RemoveAll(filepath.Dir(specPath))
+		case ChangeModified:
+			if slices.ContainsFunc(change.Fields, func(field FieldChange) bool { return field.Field == typeField }) {
+				err = writeExportedEntry(specPath, loader.GetEntries()[change.Name], incoming, change.Name)
+			} else {
+				err = applyFieldChanges(specPath, change.Fields)
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to apply changes to %s: %w", change.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// processRegistry returns a copy of a registry with its servers normalized the way BuildRegistry
+// normalizes the loaded entries
+func processRegistry(registry *toolhiveRegistry.Registry, defaults Defaults) *toolhiveRegistry.Registry {
+	result := &toolhiveRegistry.Registry{
+		Servers:       make(map[string]*toolhiveRegistry.ImageMetadata),
+		RemoteServers: make(map[string]*toolhiveRegistry.RemoteServerMetadata),
+	}
+	if registry == nil {
+		return result
+	}
+
+	for name, server := range registry.Servers {
+		result.Servers[name] = processImageMetadata(server, defaults)
+	}
+	for name, server := range registry.RemoteServers {
+		result.RemoteServers[name] = processRemoteMetadata(server, defaults)
+	}
+	return result
+}
+
+// writeExportedEntry writes an entry with the metadata of the named incoming server, keeping
+// the entry's registry-only fields, as a formatted spec file
+func writeExportedEntry(
+	specPath string, entry *types.RegistryEntry, incoming *toolhiveRegistry.Registry, name string,
+) error {
+	exported := *entry
+	exported.ImageMetadata = incoming.Servers[name]
+	exported.RemoteServerMetadata = incoming.RemoteServers[name]
+
+	data, err := yaml.Marshal(&exported)
+	if err != nil {
+		return fmt.Errorf("failed to marshal YAML: %w", err)
+	}
+	formatted, err := FormatSpec(data)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(specPath), 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(specPath, formatted, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
+
+// applyFieldChanges sets the new values of changed top-level fields in a spec file and
+// removes the fields that were removed, leaving the rest of the file untouched
+func applyFieldChanges(specPath string, fields []FieldChange) error {
+	data, err := types.ReadSpecFile(specPath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("expected a mapping at the document root")
+	}
+	mapping := doc.Content[0]
+
+	for _, field := range fields {
+		index := -1
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == field.Field {
+				index = i
+				break
+			}
+		}
+
+		if field.New == nil {
+			if index >= 0 {
+				mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+			}
+			continue
+		}
+
+		var value yaml.Node
+		if err := value.Encode(field.New); err != nil {
+			return fmt.Errorf("failed to encode %s: %w", field.Field, err)
+		}
+		if index < 0 {
+			mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: field.Field}, &value)
+			continue
+		}
+		old := mapping.Content[index+1]
+		value.HeadComment, value.LineComment, value.FootComment = old.HeadComment, old.LineComment, old.FootComment
+		mapping.Content[index+1] = &value
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	if err := os.WriteFile(specPath, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
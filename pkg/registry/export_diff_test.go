@@ -0,0 +1,208 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exportDiffFixture writes a registry tree with an unchanged, a curated and a stale entry
+func exportDiffFixture(t *testing.T) string {
+	t.Helper()
+
+	registryDir := t.TempDir()
+	for name, spec := range map[string]string{
+		"kept": `image: test/kept:1.0.0
+description: Kept server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - kept_tool
+`,
+		"curated": `# Curated by hand
+description: Curated server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - search # The only tool so far
+image: test/curated:1.0.0
+# Local additions
+license: MIT
+homepage: https://curated.example.com
+`,
+		"stale": `image: test/stale:1.0.0
+description: Stale server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - stale_tool
+`,
+	} {
+		writeRegistryFile(t, registryDir, filepath.Join(name, "spec.yaml"), spec)
+	}
+	return registryDir
+}
+
+// exportDiffIncoming is a registry.json with the kept entry unchanged, the curated one
+// updated, a new remote server, and no stale entry
+const exportDiffIncoming = `{
+  "version": "1.0.0",
+  "last_updated": "2025-01-01T00:00:00Z",
+  "servers": {
+    "kept": {
+      "image": "test/kept:1.0.0",
+      "description": "Kept server",
+      "transport": "stdio",
+      "tier": "Community",
+      "status": "Active",
+      "tools": ["kept_tool"]
+    },
+    "curated": {
+      "name": "curated",
+      "image": "test/curated:2.0.0",
+      "description": "Curated server",
+      "transport": "stdio",
+      "tier": "Community",
+      "status": "Active",
+      "tools": ["fetch", "search"]
+    }
+  },
+  "remote_servers": {
+    "hosted": {
+      "url": "https://api.example.com/mcp",
+      "description": "Hosted server",
+      "transport": "streamable-http",
+      "tier": "Community",
+      "status": "Active",
+      "tools": ["query"]
+    }
+  }
+}`
+
+func TestExportDiff(t *testing.T) {
+	t.Parallel()
+
+	registryDir := exportDiffFixture(t)
+	var incoming toolhiveRegistry.Registry
+	require.NoError(t, json.Unmarshal([]byte(exportDiffIncoming), &incoming))
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+
+	diff, err := ExportDiff(loader, &incoming)
+	require.NoError(t, err)
+	assert.Equal(t, []ServerChange{
+		{Name: "curated", Kind: ChangeModified, Fields: []FieldChange{
+			{Field: "image", Old: "test/curated:1.0.0", New: "test/curated:2.0.0"},
+			{Field: "tools", Old: []any{"search"}, New: []any{"fetch", "search"}},
+		}},
+		{Name: "hosted", Kind: ChangeAdded},
+		{Name: "stale", Kind: ChangeRemoved},
+	}, diff.Servers)
+
+	assert.Equal(t, filepath.Join(registryDir, "curated", "spec.yaml"), ExportSpecPath(loader, "curated"))
+	assert.Equal(t, filepath.Join(registryDir, "hosted", "spec.yaml"), ExportSpecPath(loader, "hosted"))
+
+	// Computing the diff doesn't write anything
+	assert.NoDirExists(t, filepath.Join(registryDir, "hosted"))
+	assert.FileExists(t, filepath.Join(registryDir, "stale", "spec.yaml"))
+}
+
+func TestApplyExportDiff(t *testing.T) {
+	t.Parallel()
+
+	registryDir := exportDiffFixture(t)
+	var incoming toolhiveRegistry.Registry
+	require.NoError(t, json.Unmarshal([]byte(exportDiffIncoming), &incoming))
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	diff, err := ExportDiff(loader, &incoming)
+	require.NoError(t, err)
+	require.NoError(t, ApplyExportDiff(loader, diff, &incoming))
+
+	// Only the changed fields of the curated entry are rewritten
+	data, err := os.ReadFile(filepath.Join(registryDir, "curated", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `# Curated by hand
+description: Curated server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - fetch
+  - search
+image: test/curated:2.0.0
+# Local additions
+license: MIT
+homepage: https://curated.example.com
+`, string(data))
+
+	// The new server gets its own entry, and the stale one is removed
+	data, err = os.ReadFile(filepath.Join(registryDir, "hosted", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `description: Hosted server
+tier: Community
+status: Active
+transport: streamable-http
+tools:
+  - query
+url: https://api.example.com/mcp
+`, string(data))
+	assert.NoDirExists(t, filepath.Join(registryDir, "stale"))
+
+	// The registry now matches the incoming one
+	reloaded := NewLoader(registryDir)
+	require.NoError(t, reloaded.LoadAll())
+	diff, err = ExportDiff(reloaded, &incoming)
+	require.NoError(t, err)
+	assert.True(t, diff.Empty(), "unexpected changes: %+v", diff.Servers)
+}
+
+func TestApplyExportDiff_TypeChange(t *testing.T) {
+	t.Parallel()
+
+	registryDir := exportDiffFixture(t)
+	incoming := toolhiveRegistry.Registry{
+		RemoteServers: map[string]*toolhiveRegistry.RemoteServerMetadata{
+			"curated": {
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Curated server, now hosted",
+					Tier:        "Community",
+					Status:      "Active",
+					Transport:   "sse",
+					Tools:       []string{"search"},
+				},
+				URL: "https://curated.example.com/sse",
+			},
+		},
+	}
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	diff, err := ExportDiff(loader, &incoming)
+	require.NoError(t, err)
+	require.NoError(t, ApplyExportDiff(loader, diff, &incoming))
+
+	// The metadata is replaced, and the registry-only fields are kept
+	data, err := os.ReadFile(filepath.Join(registryDir, "curated", "spec.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, `description: Curated server, now hosted
+tier: Community
+status: Active
+transport: sse
+tools:
+  - search
+url: https://curated.example.com/sse
+license: MIT
+homepage: https://curated.example.com
+`, string(data))
+}
@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// specKeyOrder is the canonical order of the top-level fields of a spec: the toolhive server
+// metadata, then the image or remote fields, then the fields only the registry reads.
+// Fields it doesn't list follow in their original order.
+var specKeyOrder = []string{
+	"name", "description", "tier", "status", "transport", "tools", "metadata", "repository_url", "tags",
+	"custom_metadata",
+	"image", "url", "target_port", "permissions", "headers", "oauth_config", "env_vars", "args", "docker_tags",
+	"provenance",
+	"examples", "license", "homepage", "documentation_url", "stars_source", "pulls_source", "deprecated",
+	"enabled", "platforms", "tool_discovery",
+}
+
+// FormatSpec re-emits a spec document with its top-level fields in canonical order, 2-space
+// indentation, block-style lists and maps, and scalars only quoted where YAML requires it.
+// Comments are kept with the fields they precede, and header comments stay at the top.
+func FormatSpec(data []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(types.NormalizeSpecData(data), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping at the document root")
+	}
+
+	sortSpecKeys(doc.Content[0])
+	normalizeStyle(&doc)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// FormatSpecFile formats a spec file with FormatSpec, rewriting it in place if write is set.
+// It returns whether the file wasn't already formatted.
+func FormatSpecFile(path string, write bool) (bool, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the caller
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	formatted, err := FormatSpec(data)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(data, formatted) {
+		return false, nil
+	}
+
+	if write {
+		if err := os.WriteFile(path, formatted, 0600); err != nil {
+			return false, fmt.Errorf("failed to write file: %w", err)
+		}
+	}
+	return true, nil
+}
+
+// sortSpecKeys reorders the key/value pairs of a spec mapping into specKeyOrder
+func sortSpecKeys(mapping *yaml.Node) {
+	rank := make(map[string]int, len(specKeyOrder))
+	for i, key := range specKeyOrder {
+		rank[key] = i
+	}
+
+	var known, unknown [][2]*yaml.Node
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		pair := [2]*yaml.Node{mapping.Content[i], mapping.Content[i+1]}
+		if _, ok := rank[pair[0].Value]; ok {
+			known = append(known, pair)
+		} else {
+			unknown = append(unknown, pair)
+		}
+	}
+
+	slices.SortStableFunc(known, func(a, b [2]*yaml.Node) int { return rank[a[0].Value] - rank[b[0].Value] })
+
+	content := make([]*yaml.Node, 0, len(mapping.Content))
+	for _, pair := range append(known, unknown...) {
+		content = append(content, pair[0], pair[1])
+	}
+	mapping.Content = content
+}
+
+// normalizeStyle switches every list and map to block style and drops quotes from scalars.
+// The encoder quotes the scalars that would otherwise read back as another type.
+func normalizeStyle(node *yaml.Node) {
+	node.Style &^= yaml.FlowStyle | yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle
+	for _, child := range node.Content {
+		normalizeStyle(child)
+	}
+}
@@ -0,0 +1,237 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestFormatSpec(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{
+			name: "canonical key order",
+			spec: `image: test/server:1.0.0
+tools: [search]
+transport: stdio
+license: MIT
+description: Test server
+name: server
+`,
+			want: `name: server
+description: Test server
+transport: stdio
+tools:
+  - search
+image: test/server:1.0.0
+license: MIT
+`,
+		},
+		{
+			name: "indentation",
+			spec: `description: Test server
+url: https://api.example.com/mcp
+transport: sse
+tools:
+    - search
+headers:
+    -   name: Authorization
+        description: Bearer token
+        required: true
+`,
+			want: `description: Test server
+transport: sse
+tools:
+  - search
+url: https://api.example.com/mcp
+headers:
+  - name: Authorization
+    description: Bearer token
+    required: true
+`,
+		},
+		{
+			name: "quoting",
+			spec: `description: "Test server"
+image: 'test/server:1.0.0'
+transport: stdio
+tools: ["search"]
+env_vars:
+  - name: "PORT"
+    description: Port
+    default: "8080"
+  - name: DEBUG
+    description: 'Enable debugging: verbose'
+    default: 'true'
+`,
+			want: `description: Test server
+transport: stdio
+tools:
+  - search
+image: test/server:1.0.0
+env_vars:
+  - name: PORT
+    description: Port
+    default: "8080"
+  - name: DEBUG
+    description: 'Enable debugging: verbose'
+    default: "true"
+`,
+		},
+		{
+			name: "unknown fields keep their order after known fields",
+			spec: `author: someone
+image: test/server:1.0.0
+maintainer: someone else
+description: Test server
+`,
+			want: `description: Test server
+image: test/server:1.0.0
+author: someone
+maintainer: someone else
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatted, err := FormatSpec([]byte(tt.spec))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(formatted))
+
+			// Formatting is idempotent
+			again, err := FormatSpec(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(again))
+
+			// The formatted spec declares the same entry
+			var before, after types.RegistryEntry
+			if yaml.Unmarshal([]byte(tt.spec), &before) == nil {
+				require.NoError(t, yaml.Unmarshal(formatted, &after))
+				assert.Equal(t, before, after)
+			}
+		})
+	}
+}
+
+func TestFormatSpec_Comments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		spec string
+		want string
+	}{
+		{
+			name: "header attached to the first field",
+			spec: `# server MCP Server Registry Entry
+# ---
+name: server
+image: test/server:1.0.0 # pinned
+description: Test server
+`,
+			want: `# server MCP Server Registry Entry
+# ---
+name: server
+description: Test server
+image: test/server:1.0.0 # pinned
+`,
+		},
+		{
+			name: "header separated by a blank line",
+			spec: `# Maintained by the platform team
+
+image: test/server:1.0.0
+description: Test server
+`,
+			want: `# Maintained by the platform team
+
+description: Test server
+image: test/server:1.0.0
+`,
+		},
+		{
+			name: "field comments move with their fields",
+			spec: `# Docker/OCI image reference (REQUIRED)
+image: test/server:1.0.0
+# One-line description (REQUIRED)
+description: Test server
+tools:
+  # The only tool
+  - search
+`,
+			want: `# One-line description (REQUIRED)
+description: Test server
+tools:
+  # The only tool
+  - search
+# Docker/OCI image reference (REQUIRED)
+image: test/server:1.0.0
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			formatted, err := FormatSpec([]byte(tt.spec))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(formatted))
+
+			again, err := FormatSpec(formatted)
+			require.NoError(t, err)
+			assert.Equal(t, string(formatted), string(again))
+		})
+	}
+}
+
+func TestFormatSpec_Invalid(t *testing.T) {
+	t.Parallel()
+
+	_, err := FormatSpec([]byte("- not\n- a mapping\n"))
+	assert.ErrorContains(t, err, "expected a mapping")
+
+	_, err = FormatSpec([]byte("description: [unclosed\n"))
+	assert.ErrorContains(t, err, "failed to parse YAML")
+}
+
+func TestFormatSpecFile(t *testing.T) {
+	t.Parallel()
+
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	original := "image: test/server:1.0.0\r\ndescription: Test server\r\n"
+	require.NoError(t, os.WriteFile(specPath, []byte(original), 0600))
+
+	// Checking reports the file without rewriting it
+	changed, err := FormatSpecFile(specPath, false)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+
+	changed, err = FormatSpecFile(specPath, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	data, err = os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, "description: Test server\nimage: test/server:1.0.0\n", string(data))
+
+	changed, err = FormatSpecFile(specPath, false)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
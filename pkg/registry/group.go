@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// GroupFilename is the file in a category directory whose fields are defaults for the entries below it
+const GroupFilename = "group.yaml"
+
+// groupDefaults returns the defaults the group files of the directories above an entry give it,
+// up to the registry root. A nearer group file takes precedence over one further up.
+// Specs outside the registry directory get no defaults.
+func (l *Loader) groupDefaults(specPath string) ([]types.EntryDefaults, error) {
+	root, err := filepath.Abs(l.registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve registry path: %w", err)
+	}
+	entryDir, err := filepath.Abs(filepath.Dir(specPath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve spec path: %w", err)
+	}
+	if rel, err := filepath.Rel(root, entryDir); err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return nil, nil
+	}
+
+	var groups []types.EntryDefaults
+	for dir := filepath.Dir(entryDir); ; dir = filepath.Dir(dir) {
+		defaults, ok, err := l.cachedGroupFile(filepath.Join(dir, GroupFilename))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			groups = append(groups, defaults)
+		}
+		if dir == root {
+			return groups, nil
+		}
+	}
+}
+
+// groupFile is the result of reading a group file
+type groupFile struct {
+	defaults types.EntryDefaults
+	ok       bool
+	err      error
+}
+
+// cachedGroupFile returns the defaults of a group file like loadGroupFile, reading each file only once
+func (l *Loader) cachedGroupFile(path string) (types.EntryDefaults, bool, error) {
+	l.groupMu.Lock()
+	defer l.groupMu.Unlock()
+
+	file, cached := l.groupFiles[path]
+	if !cached {
+		file.defaults, file.ok, file.err = loadGroupFile(path)
+		if l.groupFiles == nil {
+			l.groupFiles = make(map[string]groupFile)
+		}
+		l.groupFiles[path] = file
+	}
+	return file.defaults, file.ok, file.err
+}
+
+// loadGroupFile reads a group file, returning false if it doesn't exist. Unknown fields are
+// rejected, so a typo doesn't silently drop a default.
+func loadGroupFile(path string) (types.EntryDefaults, bool, error) {
+	var defaults types.EntryDefaults
+	data, err := os.ReadFile(path) // #nosec G304 - path is constructed from known directory structure
+	if errors.Is(err, os.ErrNotExist) {
+		return defaults, false, nil
+	}
+	if err != nil {
+		return defaults, false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&defaults); err != nil && !errors.Is(err, io.EOF) {
+		return defaults, false, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return defaults, true, nil
+}
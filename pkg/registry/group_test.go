@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_GroupDefaults(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "databases/group.yaml", `tags:
+  - database
+tier: Official
+license: Apache-2.0
+status: Active
+`)
+	writeRegistryFile(t, registryDir, "databases/embedded/group.yaml", "tier: Community\n")
+	writeRegistryFile(t, registryDir, "databases/postgres/spec.yaml", `description: Inherits everything
+image: test/postgres:1.0.0
+transport: stdio
+tools:
+  - query
+`)
+	writeRegistryFile(t, registryDir, "databases/mysql/spec.yaml", `description: Overrides some fields
+image: test/mysql:1.0.0
+transport: stdio
+tags:
+  - mysql
+status: Deprecated
+license: MIT
+tools:
+  - query
+`)
+	writeRegistryFile(t, registryDir, "databases/embedded/sqlite/spec.yaml", `description: Nearer group wins
+image: test/sqlite:1.0.0
+transport: stdio
+tools:
+  - query
+`)
+	writeRegistryFile(t, registryDir, "fetch/spec.yaml", `description: Outside the group
+image: test/fetch:1.0.0
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - fetch
+`)
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	entries := loader.GetEntries()
+
+	tests := []struct {
+		name    string
+		tags    []string
+		tier    string
+		status  string
+		license string
+	}{
+		{name: "postgres", tags: []string{"database"}, tier: "Official", status: "Active", license: "Apache-2.0"},
+		{name: "mysql", tags: []string{"mysql"}, tier: "Official", status: "Deprecated", license: "MIT"},
+		{name: "sqlite", tags: []string{"database"}, tier: "Community", status: "Active", license: "Apache-2.0"},
+		{name: "fetch", tier: "Community", status: "Active"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := entries[tt.name]
+			require.NotNil(t, entry)
+			assert.Equal(t, tt.tags, entry.GetTags())
+			assert.Equal(t, tt.tier, entry.GetTier())
+			assert.Equal(t, tt.status, entry.GetStatus())
+			assert.Equal(t, tt.license, entry.License)
+		})
+	}
+}
+
+func TestLoader_GroupDefaultsUnknownField(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "databases/group.yaml", "tag: database\n")
+	writeRegistryFile(t, registryDir, "databases/postgres/spec.yaml", `description: Postgres
+image: test/postgres:1.0.0
+transport: stdio
+tools:
+  - query
+`)
+
+	err := NewLoader(registryDir).LoadAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "field tag not found")
+}
+
+func TestLoader_GroupDefaultsReadOnce(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "databases/group.yaml", "tier: Official\nstatus: Active\n")
+	for _, name := range []string{"postgres", "mysql"} {
+		writeRegistryFile(t, registryDir, "databases/"+name+"/spec.yaml", `description: Database server
+image: test/`+name+`:1.0.0
+transport: stdio
+tools:
+  - query
+`)
+	}
+
+	// Group files are read once per loader, so the entries of a category share what was read first
+	loader := NewLoader(registryDir)
+	_, err := loader.LoadEntryWithName(filepath.Join(registryDir, "databases", "postgres", "spec.yaml"), "postgres")
+	require.NoError(t, err)
+	writeRegistryFile(t, registryDir, "databases/group.yaml", "tier: Community\nstatus: Active\n")
+	entry, err := loader.LoadEntryWithName(filepath.Join(registryDir, "databases", "mysql", "spec.yaml"), "mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "Official", entry.GetTier())
+}
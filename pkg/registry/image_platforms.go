@@ -0,0 +1,261 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// manifestMediaTypes are the manifest formats accepted when fetching an image manifest
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// dockerHubRegistryHost is the host serving the registry API for docker.io images
+const dockerHubRegistryHost = "registry-1.docker.io"
+
+// manifest holds the fields of an image index/manifest list or a single image manifest
+// needed to determine the platforms an image provides
+type manifest struct {
+	Manifests []struct {
+		Platform *struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant,omitempty"`
+		} `json:"platform,omitempty"`
+	} `json:"manifests,omitempty"`
+	Config *struct {
+		Digest string `json:"digest"`
+	} `json:"config,omitempty"`
+}
+
+// PlatformChecker checks the platforms provided by container images using the
+// OCI distribution API, with anonymous token authentication
+type PlatformChecker struct {
+	client *http.Client
+	scheme string
+}
+
+// NewPlatformChecker creates a platform checker with the given per-request timeout
+func NewPlatformChecker(timeout time.Duration) *PlatformChecker {
+	return &PlatformChecker{
+		client: &http.Client{Timeout: timeout},
+		scheme: "https",
+	}
+}
+
+// MissingPlatforms returns the platforms an image entry declares that its image doesn't provide
+func (c *PlatformChecker) MissingPlatforms(ctx context.Context, entry *types.RegistryEntry) ([]string, error) {
+	if !entry.IsImage() {
+		return nil, fmt.Errorf("entry is not an image-based server")
+	}
+	if len(entry.Platforms) == 0 {
+		return nil, nil
+	}
+
+	provided, err := c.ImagePlatforms(ctx, entry.Image)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, platform := range entry.Platforms {
+		if !slices.Contains(provided, platform) {
+			missing = append(missing, platform)
+		}
+	}
+	return missing, nil
+}
+
+// ImagePlatforms returns the sorted platforms an image provides. Multi-platform images
+// list them in their manifest list; single-platform images declare theirs in the image config.
+func (c *PlatformChecker) ImagePlatforms(ctx context.Context, image string) ([]string, error) {
+	ref, err := types.ParseImageReference(image)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := ref.Digest
+	if reference == "" {
+		reference = ref.Tag
+	}
+	if reference == "" {
+		reference = "latest"
+	}
+
+	var m manifest
+	if err := c.getJSON(ctx, ref, "manifests/"+reference, &m); err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest for %s: %w", image, err)
+	}
+
+	var platforms []string
+	switch {
+	case len(m.Manifests) > 0:
+		for _, descriptor := range m.Manifests {
+			// Attestation manifests have no platform or an "unknown" one
+			if descriptor.Platform == nil || descriptor.Platform.OS == "unknown" {
+				continue
+			}
+			platforms = append(platforms,
+				types.FormatPlatform(descriptor.Platform.OS, descriptor.Platform.Architecture, descriptor.Platform.Variant))
+		}
+	case m.Config != nil:
+		var config struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+			Variant      string `json:"variant,omitempty"`
+		}
+		if err := c.getJSON(ctx, ref, "blobs/"+m.Config.Digest, &config); err != nil {
+			return nil, fmt.Errorf("failed to fetch image config for %s: %w", image, err)
+		}
+		platforms = append(platforms, types.FormatPlatform(config.OS, config.Architecture, config.Variant))
+	default:
+		return nil, fmt.Errorf("manifest for %s has neither platforms nor a config", image)
+	}
+
+	sort.Strings(platforms)
+	return platforms, nil
+}
+
+// getJSON fetches a path under the image's repository from the registry API and decodes it.
+// A 401 with a Bearer challenge is answered with an anonymous token and retried once.
+func (c *PlatformChecker) getJSON(ctx context.Context, ref *types.ImageReference, path string, v any) error {
+	host := ref.Registry
+	if ref.IsDockerHub() {
+		host = dockerHubRegistryHost
+	}
+	endpoint := fmt.Sprintf("%s://%s/v2/%s/%s", c.scheme, host, ref.Repository, path)
+
+	resp, err := c.get(ctx, endpoint, "")
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		token, err := c.anonymousToken(ctx, challenge)
+		if err != nil {
+			return err
+		}
+		if resp, err = c.get(ctx, endpoint, token); err != nil {
+			return err
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("registry returned %s", resp.Status)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+	return nil
+}
+
+// get sends a GET request accepting any manifest format, with an optional bearer token
+func (c *PlatformChecker) get(ctx context.Context, endpoint, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
+// anonymousToken requests a pull token from the realm of a Bearer challenge
+func (c *PlatformChecker) anonymousToken(ctx context.Context, challenge string) (string, error) {
+	params := parseBearerChallenge(challenge)
+	realm := params["realm"]
+	if realm == "" {
+		return "", fmt.Errorf("registry requires authentication without a bearer realm")
+	}
+
+	tokenURL, err := url.Parse(realm)
+	if err != nil {
+		return "", fmt.Errorf("invalid bearer realm %q: %w", realm, err)
+	}
+	query := tokenURL.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	tokenURL.RawQuery = query.Encode()
+
+	resp, err := c.get(ctx, tokenURL.String(), "")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("token request returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse token response: %w", err)
+	}
+	if token.Token != "" {
+		return token.Token, nil
+	}
+	return token.AccessToken, nil
+}
+
+// parseBearerChallenge parses the parameters of a `Bearer key="value",...` WWW-Authenticate header
+func parseBearerChallenge(challenge string) map[string]string {
+	params := make(map[string]string)
+	scheme, rest, found := strings.Cut(challenge, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return params
+	}
+
+	for rest != "" {
+		key, remainder, ok := strings.Cut(rest, "=")
+		if !ok {
+			break
+		}
+		remainder = strings.TrimSpace(remainder)
+
+		// Values are usually quoted and may then contain commas (e.g. multiple scopes)
+		var value string
+		if strings.HasPrefix(remainder, `"`) {
+			end := strings.Index(remainder[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, rest = remainder[1:end+1], remainder[end+2:]
+		} else {
+			value, rest, _ = strings.Cut(remainder, ",")
+		}
+
+		params[strings.TrimSpace(key)] = value
+		rest = strings.TrimPrefix(strings.TrimSpace(rest), ",")
+	}
+	return params
+}
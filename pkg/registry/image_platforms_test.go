@@ -0,0 +1,123 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// newFakeOCIRegistry serves a multi-arch image (example/multi), a single-platform
+// image (example/single) and requires an anonymous bearer token like Docker Hub does
+func newFakeOCIRegistry(t *testing.T) (*PlatformChecker, string) {
+	t.Helper()
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			assert.Equal(t, "registry.test", r.URL.Query().Get("service"))
+			fmt.Fprint(w, `{"token": "anonymous"}`)
+			return
+		}
+
+		if r.Header.Get("Authorization") != "Bearer anonymous" {
+			w.Header().Set("WWW-Authenticate",
+				fmt.Sprintf(`Bearer realm="%s/token",service="registry.test",scope="repository:example:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+
+		switch r.URL.Path {
+		case "/v2/example/multi/manifests/1.0.0":
+			fmt.Fprint(w, `{
+  "mediaType": "application/vnd.oci.image.index.v1+json",
+  "manifests": [
+    {"digest": "sha256:aa", "platform": {"os": "linux", "architecture": "amd64"}},
+    {"digest": "sha256:bb", "platform": {"os": "linux", "architecture": "arm", "variant": "v7"}},
+    {"digest": "sha256:cc", "platform": {"os": "unknown", "architecture": "unknown"}}
+  ]
+}`)
+		case "/v2/example/single/manifests/latest":
+			fmt.Fprint(w, `{"mediaType": "application/vnd.oci.image.manifest.v1+json", "config": {"digest": "sha256:config"}}`)
+		case "/v2/example/single/blobs/sha256:config":
+			fmt.Fprint(w, `{"os": "linux", "architecture": "amd64"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	checker := NewPlatformChecker(5 * time.Second)
+	checker.scheme = "http"
+	return checker, strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestPlatformChecker_ImagePlatforms(t *testing.T) {
+	t.Parallel()
+
+	checker, host := newFakeOCIRegistry(t)
+	ctx := context.Background()
+
+	platforms, err := checker.ImagePlatforms(ctx, host+"/example/multi:1.0.0")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux/amd64", "linux/arm/v7"}, platforms)
+
+	platforms, err = checker.ImagePlatforms(ctx, host+"/example/single")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"linux/amd64"}, platforms)
+
+	_, err = checker.ImagePlatforms(ctx, host+"/example/missing:1.0.0")
+	assert.ErrorContains(t, err, "404")
+}
+
+func TestPlatformChecker_MissingPlatforms(t *testing.T) {
+	t.Parallel()
+
+	checker, host := newFakeOCIRegistry(t)
+
+	tests := []struct {
+		name      string
+		image     string
+		platforms []string
+		want      []string
+	}{
+		{name: "all declared platforms provided", image: "/example/multi:1.0.0", platforms: []string{"linux/amd64", "linux/arm/v7"}},
+		{name: "declared platform missing", image: "/example/multi:1.0.0", platforms: []string{"linux/amd64", "linux/arm64"}, want: []string{"linux/arm64"}},
+		{name: "single platform image", image: "/example/single", platforms: []string{"linux/arm64"}, want: []string{"linux/arm64"}},
+		{name: "no declared platforms", image: "/example/missing:1.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			entry := &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{Image: host + tt.image},
+				Platforms:     tt.platforms,
+			}
+			missing, err := checker.MissingPlatforms(context.Background(), entry)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, missing)
+		})
+	}
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, map[string]string{
+		"realm":   "https://auth.docker.io/token",
+		"service": "registry.docker.io",
+		"scope":   "repository:mcp/fetch:pull,push",
+	}, parseBearerChallenge(`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:mcp/fetch:pull,push"`))
+	assert.Empty(t, parseBearerChallenge(`Basic realm="registry"`))
+}
@@ -0,0 +1,104 @@
+package registry
+
+import (
+	_ "embed" // for the bundled known transports
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+//go:embed known_transports.yaml
+var defaultKnownTransports []byte
+
+// KnownTransports maps image names (without tag or digest) to the transports they support
+type KnownTransports map[string][]string
+
+// TransportMismatch describes an entry whose declared transport isn't supported by its image
+type TransportMismatch struct {
+	Name      string   `json:"name"`
+	Image     string   `json:"image"`
+	Transport string   `json:"transport"`
+	Supported []string `json:"supported"`
+}
+
+// String returns a human-readable description of the mismatch
+func (m TransportMismatch) String() string {
+	return fmt.Sprintf("%s: transport %q is not supported by image %s (known transports: %s)",
+		m.Name, m.Transport, m.Image, strings.Join(m.Supported, ", "))
+}
+
+// DefaultKnownTransports returns the known transports bundled with the registry builder
+func DefaultKnownTransports() (KnownTransports, error) {
+	return parseKnownTransports(defaultKnownTransports)
+}
+
+// LoadKnownTransports reads known transports from a YAML file in the same format as the bundled mapping
+func LoadKnownTransports(path string) (KnownTransports, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read known transports: %w", err)
+	}
+	return parseKnownTransports(data)
+}
+
+// parseKnownTransports parses a known transports file, normalizing image names
+func parseKnownTransports(data []byte) (KnownTransports, error) {
+	var file struct {
+		Images map[string][]string `yaml:"images"`
+	}
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse known transports: %w", err)
+	}
+
+	known := make(KnownTransports, len(file.Images))
+	for image, transports := range file.Images {
+		ref, err := types.ParseImageReference(image)
+		if err != nil {
+			return nil, fmt.Errorf("invalid image in known transports: %w", err)
+		}
+		known[ref.Name()] = transports
+	}
+	return known, nil
+}
+
+// Merge adds the mappings from other, replacing any existing mapping for the same image
+func (k KnownTransports) Merge(other KnownTransports) {
+	for image, transports := range other {
+		k[image] = transports
+	}
+}
+
+// CheckTransports returns the image entries whose declared transport contradicts
+// the known transports of their image. Images without a known mapping are skipped.
+func CheckTransports(entries map[string]*types.RegistryEntry, known KnownTransports) []TransportMismatch {
+	var mismatches []TransportMismatch
+	for _, name := range sortedKeys(entries) {
+		entry := entries[name]
+		if !entry.IsImage() {
+			continue
+		}
+
+		ref, err := entry.ImageReference()
+		if err != nil {
+			continue
+		}
+
+		supported, ok := known[ref.Name()]
+		if !ok || slices.Contains(supported, entry.GetTransport()) {
+			continue
+		}
+
+		mismatches = append(mismatches, TransportMismatch{
+			Name:      name,
+			Image:     entry.Image,
+			Transport: entry.GetTransport(),
+			Supported: supported,
+		})
+	}
+	return mismatches
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func newTransportEntry(image, transport string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description: "Test server",
+				Transport:   transport,
+				Tools:       []string{"test-tool"},
+			},
+			Image: image,
+		},
+	}
+}
+
+func TestCheckTransports(t *testing.T) {
+	t.Parallel()
+
+	known := KnownTransports{
+		"docker.io/mcp/time":          {"stdio"},
+		"ghcr.io/example/dual-server": {"stdio", "streamable-http"},
+	}
+
+	tests := []struct {
+		name  string
+		entry *types.RegistryEntry
+		want  []TransportMismatch
+	}{
+		{
+			name:  "known mismatch",
+			entry: newTransportEntry("mcp/time:latest", "sse"),
+			want: []TransportMismatch{{
+				Name:      "test-server",
+				Image:     "mcp/time:latest",
+				Transport: "sse",
+				Supported: []string{"stdio"},
+			}},
+		},
+		{
+			name:  "known match",
+			entry: newTransportEntry("docker.io/mcp/time:latest", "stdio"),
+		},
+		{
+			name:  "one of several supported transports",
+			entry: newTransportEntry("ghcr.io/example/dual-server:1.0.0", "streamable-http"),
+		},
+		{
+			name:  "unknown image",
+			entry: newTransportEntry("ghcr.io/example/unknown:1.0.0", "sse"),
+		},
+		{
+			name:  "remote servers are skipped",
+			entry: newRemoteEntry("https://example.com/mcp", "sse"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			entries := map[string]*types.RegistryEntry{"test-server": tt.entry}
+			assert.Equal(t, tt.want, CheckTransports(entries, known))
+		})
+	}
+}
+
+func TestKnownTransports_DefaultAndMerge(t *testing.T) {
+	t.Parallel()
+
+	known, err := DefaultKnownTransports()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stdio"}, known["docker.io/mcp/time"])
+
+	// Extra mappings use the same format, accept shorthand image names, and override defaults
+	path := filepath.Join(t.TempDir(), "known-transports.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`images:
+  mcp/time:
+    - stdio
+    - sse
+  ghcr.io/example/server:
+    - streamable-http
+`), 0644))
+
+	extra, err := LoadKnownTransports(path)
+	require.NoError(t, err)
+	known.Merge(extra)
+
+	assert.Equal(t, []string{"stdio", "sse"}, known["docker.io/mcp/time"])
+	assert.Equal(t, []string{"streamable-http"}, known["ghcr.io/example/server"])
+}
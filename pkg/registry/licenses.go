@@ -0,0 +1,181 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed" // for the bundled SPDX license list
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+//go:embed spdx_licenses.txt
+var spdxLicenseList []byte
+
+// Special SPDX values for servers without a license or whose license wasn't determined
+const (
+	LicenseNone        = "NONE"
+	LicenseNoAssertion = "NOASSERTION"
+)
+
+// licenseAliases maps the comparison keys of common near-misses that don't
+// normalize to an SPDX identifier on their own to their canonical form
+var licenseAliases = map[string]string{
+	"apache":     "Apache-2.0",
+	"apache2":    "Apache-2.0",
+	"apachev2":   "Apache-2.0",
+	"asl2":       "Apache-2.0",
+	"gpl2":       "GPL-2.0-only",
+	"gplv2":      "GPL-2.0-only",
+	"gpl20":      "GPL-2.0-only",
+	"gpl3":       "GPL-3.0-only",
+	"gplv3":      "GPL-3.0-only",
+	"gpl30":      "GPL-3.0-only",
+	"agpl3":      "AGPL-3.0-only",
+	"agplv3":     "AGPL-3.0-only",
+	"agpl30":     "AGPL-3.0-only",
+	"lgpl21":     "LGPL-2.1-only",
+	"lgpl3":      "LGPL-3.0-only",
+	"lgplv3":     "LGPL-3.0-only",
+	"lgpl30":     "LGPL-3.0-only",
+	"mpl2":       "MPL-2.0",
+	"bsd2":       "BSD-2-Clause",
+	"bsd3":       "BSD-3-Clause",
+	"cc0":        "CC0-1.0",
+	"unlicensed": "Unlicense",
+}
+
+// licenseNoise matches words that are dropped when comparing licenses
+var licenseNoise = regexp.MustCompile(`\b(licen[cs]e|version)\b`)
+
+// licenseOperator splits SPDX expressions such as "MIT OR Apache-2.0" into their operands
+var licenseOperator = regexp.MustCompile(`(?i)\s+(AND|OR)\s+`)
+
+// spdxLicenses returns the bundled SPDX identifiers keyed by their comparison key
+var spdxLicenses = sync.OnceValue(func() map[string]string {
+	licenses := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(spdxLicenseList))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		licenses[licenseKey(line)] = line
+	}
+	for _, special := range []string{LicenseNone, LicenseNoAssertion} {
+		licenses[licenseKey(special)] = special
+	}
+	return licenses
+})
+
+// LicenseIssue describes a license that isn't a valid SPDX identifier and its suggested canonical form
+type LicenseIssue struct {
+	Name       string `json:"name,omitempty"`
+	License    string `json:"license"`
+	Suggestion string `json:"suggestion"`
+}
+
+// String returns a human-readable description of the issue
+func (i LicenseIssue) String() string {
+	message := fmt.Sprintf("license %q is not a valid SPDX identifier", i.License)
+	if i.Suggestion != "" {
+		message += fmt.Sprintf(" (suggested: %q)", i.Suggestion)
+	}
+	if i.Name != "" {
+		return i.Name + ": " + message
+	}
+	return message
+}
+
+// NormalizeLicense returns the canonical SPDX form of a license, or an empty string if
+// it can't be matched to a bundled identifier. Matching ignores case, punctuation and the
+// words "license" and "version", and knows common near-misses such as "Apache2" and "GPLv3". Simple
+// expressions combining identifiers with AND or OR are normalized operand by operand.
+func NormalizeLicense(license string) string {
+	operands := licenseOperator.Split(strings.TrimSpace(license), -1)
+	operators := licenseOperator.FindAllStringSubmatch(license, -1)
+
+	var b strings.Builder
+	for i, operand := range operands {
+		canonical := normalizeLicenseID(operand)
+		if canonical == "" {
+			return ""
+		}
+		if i > 0 {
+			b.WriteString(" " + strings.ToUpper(operators[i-1][1]) + " ")
+		}
+		b.WriteString(canonical)
+	}
+	return b.String()
+}
+
+// CheckLicense returns an issue if a license is set but isn't in canonical SPDX form
+func CheckLicense(license string) *LicenseIssue {
+	if license == "" {
+		return nil
+	}
+	normalized := NormalizeLicense(license)
+	if normalized == license {
+		return nil
+	}
+	return &LicenseIssue{License: license, Suggestion: normalized}
+}
+
+// CheckLicenses returns an issue for every entry whose license isn't in canonical SPDX form,
+// sorted by entry name
+func CheckLicenses(entries map[string]*types.RegistryEntry) []LicenseIssue {
+	var issues []LicenseIssue
+	for _, name := range sortedKeys(entries) {
+		if issue := CheckLicense(entries[name].License); issue != nil {
+			issue.Name = name
+			issues = append(issues, *issue)
+		}
+	}
+	return issues
+}
+
+// CheckSpecLicense reports the license issue in a spec file without modifying it
+func CheckSpecLicense(path string) (*LicenseIssue, error) {
+	return normalizeSpecLicense(path, false)
+}
+
+// FixSpecLicense rewrites the license in a spec file to its canonical SPDX form in place,
+// preserving comments and the rest of the document. Licenses without a suggestion are
+// left untouched. It returns the issue found, if any.
+func FixSpecLicense(path string) (*LicenseIssue, error) {
+	return normalizeSpecLicense(path, true)
+}
+
+// normalizeSpecLicense finds a license issue in a spec file and, if write is set, fixes it in place
+func normalizeSpecLicense(path string, write bool) (*LicenseIssue, error) {
+	license, err := normalizeSpecScalar(path, "license", write, NormalizeLicense)
+	if err != nil {
+		return nil, err
+	}
+	return CheckLicense(license), nil
+}
+
+// normalizeLicenseID returns the canonical form of a single license identifier, or an empty string
+func normalizeLicenseID(id string) string {
+	key := licenseKey(id)
+	if canonical, ok := spdxLicenses()[key]; ok {
+		return canonical
+	}
+	return licenseAliases[key]
+}
+
+// licenseKey returns the key licenses are compared by: lowercase letters and
+// digits only, without the words "license", "licence" or "version"
+func licenseKey(license string) string {
+	license = licenseNoise.ReplaceAllString(strings.ToLower(license), "")
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, license)
+}
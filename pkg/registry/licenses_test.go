@@ -0,0 +1,220 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestCheckLicense(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		license string
+		want    *LicenseIssue
+	}{
+		// Valid
+		{license: ""},
+		{license: "MIT"},
+		{license: "Apache-2.0"},
+		{license: "BSD-3-Clause"},
+		{license: "Unlicense"},
+		{license: "NONE"},
+		{license: "NOASSERTION"},
+		{license: "MIT OR Apache-2.0"},
+		// Near misses
+		{license: "MIT License", want: &LicenseIssue{License: "MIT License", Suggestion: "MIT"}},
+		{license: "mit", want: &LicenseIssue{License: "mit", Suggestion: "MIT"}},
+		{license: "Apache2", want: &LicenseIssue{License: "Apache2", Suggestion: "Apache-2.0"}},
+		{license: "Apache 2.0", want: &LicenseIssue{License: "Apache 2.0", Suggestion: "Apache-2.0"}},
+		{
+			license: "Apache License, Version 2.0",
+			want:    &LicenseIssue{License: "Apache License, Version 2.0", Suggestion: "Apache-2.0"},
+		},
+		{license: "GPLv3", want: &LicenseIssue{License: "GPLv3", Suggestion: "GPL-3.0-only"}},
+		{license: "BSD 3-Clause", want: &LicenseIssue{License: "BSD 3-Clause", Suggestion: "BSD-3-Clause"}},
+		{license: "none", want: &LicenseIssue{License: "none", Suggestion: "NONE"}},
+		{license: "The Unlicense", want: &LicenseIssue{License: "The Unlicense", Suggestion: ""}},
+		{
+			license: "mit or apache 2.0",
+			want:    &LicenseIssue{License: "mit or apache 2.0", Suggestion: "MIT OR Apache-2.0"},
+		},
+		// Invalid
+		{license: "Proprietary", want: &LicenseIssue{License: "Proprietary"}},
+		{license: "MIT OR Proprietary", want: &LicenseIssue{License: "MIT OR Proprietary"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.license, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, CheckLicense(tt.license))
+		})
+	}
+}
+
+func TestLicenseIssue_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `license "mit" is not a valid SPDX identifier (suggested: "MIT")`,
+		LicenseIssue{License: "mit", Suggestion: "MIT"}.String())
+	assert.Equal(t, `license "Proprietary" is not a valid SPDX identifier`,
+		LicenseIssue{License: "Proprietary"}.String())
+	assert.Equal(t, `fetch: license "mit" is not a valid SPDX identifier (suggested: "MIT")`,
+		LicenseIssue{Name: "fetch", License: "mit", Suggestion: "MIT"}.String())
+}
+
+func licensedEntry(license string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description: "Test server",
+				Tier:        "Community",
+				Status:      "Active",
+				Transport:   "stdio",
+				Tools:       []string{"test-tool"},
+			},
+			Image: "test/image:1.0.0",
+		},
+		License: license,
+	}
+}
+
+func TestCheckLicenses(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string]*types.RegistryEntry{
+		"exact":        licensedEntry("Apache-2.0"),
+		"case-variant": licensedEntry("mit"),
+		"unknown":      licensedEntry("Proprietary"),
+		"unlicensed":   licensedEntry(""),
+	}
+	assert.Equal(t, []LicenseIssue{
+		{Name: "case-variant", License: "mit", Suggestion: "MIT"},
+		{Name: "unknown", License: "Proprietary"},
+	}, CheckLicenses(entries))
+}
+
+func TestLoader_StrictLicense(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		license string
+		strict  bool
+		errMsg  string
+	}{
+		{name: "exact", license: "MIT"},
+		{name: "exact strict", license: "MIT", strict: true},
+		{name: "case variant", license: "apache-2.0"},
+		{
+			name:    "case variant strict",
+			license: "apache-2.0",
+			strict:  true,
+			errMsg:  `license "apache-2.0" is not a valid SPDX identifier (suggested: "Apache-2.0")`,
+		},
+		{name: "near miss strict", license: "MIT License", strict: true, errMsg: `(suggested: "MIT")`},
+		{name: "unknown", license: "Proprietary"},
+		{name: "unknown strict", license: "Proprietary", strict: true, errMsg: "use an SPDX identifier, NONE or NOASSERTION"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loader := NewLoader("")
+			loader.SetStrictLicense(tt.strict)
+			err := loader.validateEntry(licensedEntry(tt.license), "test-entry")
+			if tt.errMsg == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.errMsg)
+		})
+	}
+}
+
+func TestFixSpecLicense(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		spec      string
+		wantIssue *LicenseIssue
+		wantSpec  string
+	}{
+		{
+			name: "near miss is normalized",
+			spec: `# Header comment
+name: test
+license: "MIT License" # from the README
+tools:
+  - tool1
+`,
+			wantIssue: &LicenseIssue{License: "MIT License", Suggestion: "MIT"},
+			wantSpec: `# Header comment
+name: test
+license: MIT # from the README
+tools:
+  - tool1
+`,
+		},
+		{
+			name: "invalid license is left untouched",
+			spec: `name: test
+license: Proprietary
+`,
+			wantIssue: &LicenseIssue{License: "Proprietary"},
+			wantSpec: `name: test
+license: Proprietary
+`,
+		},
+		{
+			name: "valid license is left untouched",
+			spec: `name: test
+license: Apache-2.0
+`,
+			wantSpec: `name: test
+license: Apache-2.0
+`,
+		},
+		{
+			name: "missing license is fine",
+			spec: `name: test
+`,
+			wantSpec: `name: test
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			specPath := filepath.Join(t.TempDir(), "spec.yaml")
+			require.NoError(t, os.WriteFile(specPath, []byte(tt.spec), 0644))
+
+			checked, err := CheckSpecLicense(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIssue, checked)
+
+			data, err := os.ReadFile(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.spec, string(data), "check must not modify the file")
+
+			fixed, err := FixSpecLicense(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIssue, fixed)
+
+			data, err = os.ReadFile(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSpec, string(data))
+		})
+	}
+}
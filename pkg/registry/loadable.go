@@ -0,0 +1,118 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+)
+
+// VerifyLoadableFile reads a built registry file and verifies it with VerifyLoadable
+func VerifyLoadableFile(path string, expected *toolhiveRegistry.Registry) error {
+	data, err := os.ReadFile(path) // #nosec G304 - path is the file we just wrote
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return VerifyLoadable(data, expected)
+}
+
+// VerifyLoadable checks that built registry JSON loads the way a toolhive client loads it.
+// It unmarshals the data into toolhive's Registry type, checks that every server is
+// resolvable and has its required fields, and, if expected is non-nil, that the
+// same set of servers survives the round-trip.
+func VerifyLoadable(data []byte, expected *toolhiveRegistry.Registry) error {
+	var loaded toolhiveRegistry.Registry
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		return fmt.Errorf("registry does not unmarshal into toolhive format: %w", err)
+	}
+
+	var errs []error
+	if loaded.Version == "" {
+		errs = append(errs, fmt.Errorf("registry version is empty"))
+	}
+
+	for _, name := range sortedKeys(loaded.Servers) {
+		server := loaded.Servers[name]
+		if server == nil {
+			errs = append(errs, fmt.Errorf("server %q is null", name))
+			continue
+		}
+		if server.Image == "" {
+			errs = append(errs, fmt.Errorf("server %q has no image", name))
+		}
+		errs = append(errs, checkBaseMetadata(name, &server.BaseServerMetadata)...)
+	}
+
+	for _, name := range sortedKeys(loaded.RemoteServers) {
+		server := loaded.RemoteServers[name]
+		if server == nil {
+			errs = append(errs, fmt.Errorf("remote server %q is null", name))
+			continue
+		}
+		if server.URL == "" {
+			errs = append(errs, fmt.Errorf("remote server %q has no url", name))
+		}
+		errs = append(errs, checkBaseMetadata(name, &server.BaseServerMetadata)...)
+	}
+
+	if expected != nil {
+		errs = append(errs, compareServerNames("server", sortedKeys(expected.Servers), sortedKeys(loaded.Servers))...)
+		errs = append(errs, compareServerNames("remote server",
+			sortedKeys(expected.RemoteServers), sortedKeys(loaded.RemoteServers))...)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkBaseMetadata checks the fields every server needs to be usable by a client
+func checkBaseMetadata(name string, metadata *toolhiveRegistry.BaseServerMetadata) []error {
+	var errs []error
+	if metadata.Description == "" {
+		errs = append(errs, fmt.Errorf("server %q has no description", name))
+	}
+	if metadata.Transport == "" {
+		errs = append(errs, fmt.Errorf("server %q has no transport", name))
+	}
+	if metadata.Tools == nil {
+		errs = append(errs, fmt.Errorf("server %q has no tools list", name))
+	}
+	return errs
+}
+
+// compareServerNames reports servers that were lost or gained in the round-trip
+func compareServerNames(kind string, expected, loaded []string) []error {
+	loadedSet := make(map[string]bool, len(loaded))
+	for _, name := range loaded {
+		loadedSet[name] = true
+	}
+	expectedSet := make(map[string]bool, len(expected))
+	for _, name := range expected {
+		expectedSet[name] = true
+	}
+
+	var errs []error
+	for _, name := range expected {
+		if !loadedSet[name] {
+			errs = append(errs, fmt.Errorf("%s %q is missing after round-trip", kind, name))
+		}
+	}
+	for _, name := range loaded {
+		if !expectedSet[name] {
+			errs = append(errs, fmt.Errorf("unexpected %s %q after round-trip", kind, name))
+		}
+	}
+	return errs
+}
+
+// sortedKeys returns the keys of a map in sorted order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,69 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLoadable(t *testing.T) {
+	t.Parallel()
+
+	expected := &toolhiveRegistry.Registry{
+		Servers: map[string]*toolhiveRegistry.ImageMetadata{"fetch": {}},
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		errMsg  string
+		wantErr bool
+	}{
+		{
+			name: "valid registry",
+			data: `{"version":"1.0.0","last_updated":"2025-01-01T00:00:00Z","servers":{
+				"fetch":{"description":"Fetch","transport":"stdio","image":"mcp/fetch:1.0","tools":["fetch"]}}}`,
+		},
+		{
+			name:    "server is null",
+			data:    `{"version":"1.0.0","servers":{"fetch":null}}`,
+			wantErr: true,
+			errMsg:  `server "fetch" is null`,
+		},
+		{
+			name: "tools has the wrong type",
+			data: `{"version":"1.0.0","servers":{
+				"fetch":{"description":"Fetch","transport":"stdio","image":"mcp/fetch:1.0","tools":"fetch"}}}`,
+			wantErr: true,
+			errMsg:  "does not unmarshal into toolhive format",
+		},
+		{
+			name: "server key is misspelled so the server is lost",
+			data: `{"version":"1.0.0","servers":{
+				"fetch ":{"description":"Fetch","transport":"stdio","image":"mcp/fetch:1.0","tools":["fetch"]}}}`,
+			wantErr: true,
+			errMsg:  `server "fetch" is missing after round-trip`,
+		},
+		{
+			name: "missing tools list",
+			data: `{"version":"1.0.0","servers":{
+				"fetch":{"description":"Fetch","transport":"stdio","image":"mcp/fetch:1.0"}}}`,
+			wantErr: true,
+			errMsg:  `server "fetch" has no tools list`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := VerifyLoadable([]byte(tt.data), expected)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -3,12 +3,19 @@ package registry
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/stacklok/toolhive/pkg/permissions"
@@ -18,72 +25,472 @@ import (
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+// ErrEntryNotFound is returned when a requested entry doesn't exist in the registry
+var ErrEntryNotFound = errors.New("entry not found")
+
+// errorLine extracts the line number YAML parse errors refer to
+var errorLine = regexp.MustCompile(`line (\d+)`)
+
+// EntryError is returned when a spec file fails to load or validate
+type EntryError struct {
+	Path string
+	Err  error
+}
+
+// Error returns the error message, prefixed with the spec file path
+func (e *EntryError) Error() string {
+	return fmt.Sprintf("failed to load %s: %v", e.Path, e.Err)
+}
+
+// Unwrap returns the underlying error
+func (e *EntryError) Unwrap() error {
+	return e.Err
+}
+
+// Line returns the line of the spec file the error refers to, or 0 if it isn't known
+func (e *EntryError) Line() int {
+	match := errorLine.FindStringSubmatch(e.Err.Error())
+	if match == nil {
+		return 0
+	}
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+// NamePolicy controls where the names of entries come from
+type NamePolicy string
+
+const (
+	// NamePolicyDefault names entries after their directory unless the spec sets a name field
+	NamePolicyDefault NamePolicy = ""
+	// NamePolicyDir names entries after their directory and rejects specs with a name field
+	NamePolicyDir NamePolicy = "dir"
+	// NamePolicyField names entries after their name field and rejects specs without one
+	NamePolicyField NamePolicy = "field"
+)
+
+// ParseNamePolicy parses a name policy, where "" is the default policy
+func ParseNamePolicy(policy string) (NamePolicy, error) {
+	switch NamePolicy(policy) {
+	case NamePolicyDefault, NamePolicyDir, NamePolicyField:
+		return NamePolicy(policy), nil
+	}
+	return "", fmt.Errorf("unknown entry name policy %q (supported: dir, field)", policy)
+}
+
+// DefaultSpecFilenames are the spec file names the loader recognizes unless WithSpecFilename is given
+var DefaultSpecFilenames = []string{"spec.yaml", "spec.yml"}
+
 // Loader handles loading registry entries from YAML files
 type Loader struct {
-	registryPath string
-	entries      map[string]*types.RegistryEntry
+	registryPath   string
+	specFilenames  []string
+	entries        map[string]*types.RegistryEntry
+	sources        map[string]string
+	skipDisabled   bool
+	namePolicy     NamePolicy
+	cache          *ValidationCache
+	transports     []string
+	workers        int
+	categoryPrefix bool
+	allowLatest    bool
+	allowInsecure  bool
+	strictEnv      bool
+	strictLicense  bool
+	rules          []ValidationRule
+
+	// mu guards entries and sources, which concurrent loads write to
+	mu sync.Mutex
+
+	// configOnce guards reading registry.yaml, which every entry loaded takes its defaults from
+	configOnce sync.Once
+	config     *Config
+	configErr  error
+
+	// groupMu guards groupFiles, the group files read so far by path, which the entries of a
+	// category share
+	groupMu    sync.Mutex
+	groupFiles map[string]groupFile
+}
+
+// LoaderOption configures a Loader created by NewLoader
+type LoaderOption func(*Loader)
+
+// WithSpecFilename sets the names a spec file may have in an entry directory, replacing
+// DefaultSpecFilenames. A directory with more than one of them fails to load.
+func WithSpecFilename(names ...string) LoaderOption {
+	return func(l *Loader) {
+		l.specFilenames = names
+	}
 }
 
 // NewLoader creates a new registry loader
-func NewLoader(registryPath string) *Loader {
-	return &Loader{
-		registryPath: registryPath,
-		entries:      make(map[string]*types.RegistryEntry),
+func NewLoader(registryPath string, opts ...LoaderOption) *Loader {
+	loader := &Loader{
+		registryPath:  registryPath,
+		specFilenames: DefaultSpecFilenames,
+		entries:       make(map[string]*types.RegistryEntry),
+		sources:       make(map[string]string),
+		workers:       runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(loader)
 	}
+	return loader
 }
 
-// LoadAll loads all registry entries from the registry directory
+// LoadAll loads all registry entries from the registry directory. The specs are parsed and
+// validated concurrently; if any fail, the error of the first in path order is returned.
 func (l *Loader) LoadAll() error {
-	// Walk through the registry directory
-	err := filepath.Walk(l.registryPath, func(path string, info os.FileInfo, err error) error {
+	specPaths, err := l.specPaths()
+	if err != nil {
+		return err
+	}
+
+	entries := make([]*types.RegistryEntry, len(specPaths))
+	errs := make([]error, len(specPaths))
+	workers := max(1, min(l.workers, len(specPaths)))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				specPath := specPaths[i]
+				entries[i], errs[i] = l.loadNamedEntry(specPath, l.dirName(specPath))
+			}
+		}()
+	}
+
+	for i := range specPaths {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
 		if err != nil {
 			return err
 		}
+	}
+
+	// Names are claimed in path order, so a duplicate is always reported against the same spec.
+	// Excluded entries claim their names too, so filters can't hide a duplicate.
+	claimed := make(map[string]string, len(entries))
+	for i, entry := range entries {
+		if err := claimName(claimed, entry.GetName(), specPaths[i]); err != nil {
+			return err
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, entry := range entries {
+		if l.includes(entry) {
+			l.entries[entry.GetName()] = entry
+		}
+	}
+
+	return nil
+}
 
-		// Skip if not a directory or if it's the root directory
-		if !info.IsDir() || path == l.registryPath {
+// claimName records that the entry in specPath has the given name, failing if an entry in
+// another spec already has it, for example because its name field matches a directory name
+func claimName(claimed map[string]string, name, specPath string) error {
+	if other, ok := claimed[name]; ok {
+		return fmt.Errorf("entry name %q is used by both %s and %s", name, other, specPath)
+	}
+	claimed[name] = specPath
+	return nil
+}
+
+// specPaths returns the spec file of every entry directory in the registry, sorted by path.
+// Directories without a spec file are categories, whose subdirectories are searched for
+// entries in turn. Hidden directories are skipped. It fails if two entry directories
+// would give their entries the same name, or if a directory has more than one spec file.
+func (l *Loader) specPaths() ([]string, error) {
+	var specPaths []string
+	err := filepath.WalkDir(l.registryPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || path == l.registryPath {
 			return nil
 		}
+		if strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
 
-		// Get the relative path from registry root
-		relPath, err := filepath.Rel(l.registryPath, path)
+		// An entry directory's subdirectories belong to the entry
+		specPath, err := l.findSpec(path)
 		if err != nil {
 			return err
 		}
+		if specPath != "" {
+			specPaths = append(specPaths, specPath)
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry directory: %w", err)
+	}
+	sort.Strings(specPaths)
 
-		// Skip hidden directories and nested directories
-		if strings.HasPrefix(info.Name(), ".") || strings.Contains(relPath, string(os.PathSeparator)) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
-			return nil
+	dirs := make(map[string]string, len(specPaths))
+	for _, specPath := range specPaths {
+		if err := claimName(dirs, l.dirName(specPath), specPath); err != nil {
+			return nil, err
 		}
+	}
 
-		// Try to load spec.yaml from this directory
-		specPath := filepath.Join(path, "spec.yaml")
-		if _, err := os.Stat(specPath); err == nil {
-			// Use directory name as the entry name
-			entryName := info.Name()
+	return specPaths, nil
+}
 
-			entry, err := l.LoadEntryWithName(specPath, entryName)
-			if err != nil {
-				return fmt.Errorf("failed to load %s: %w", specPath, err)
-			}
+// findSpec returns the spec file in dir, or "" if it has none. It fails if dir has more
+// than one of the accepted spec file names, since it's unclear which one is the entry.
+func (l *Loader) findSpec(dir string) (string, error) {
+	var found []string
+	for _, name := range l.specFilenames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			found = append(found, name)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return "", nil
+	case 1:
+		return filepath.Join(dir, found[0]), nil
+	}
+	return "", fmt.Errorf("%s has more than one spec file (%s), keep only one", dir, strings.Join(found, ", "))
+}
+
+// dirName returns the name an entry gets from its directory: the directory's own name, or
+// its path within the registry joined with dashes if category prefixes are enabled
+func (l *Loader) dirName(specPath string) string {
+	dir := filepath.Dir(specPath)
+	if !l.categoryPrefix {
+		return filepath.Base(dir)
+	}
+	rel, err := filepath.Rel(l.registryPath, dir)
+	if err != nil {
+		return filepath.Base(dir)
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", "-")
+}
+
+// SetCategoryPrefix controls whether entries in category subdirectories are named after their
+// category path as well as their directory, e.g. databases-postgres for databases/postgres
+func (l *Loader) SetCategoryPrefix(prefix bool) {
+	l.categoryPrefix = prefix
+}
+
+// DirSpecs maps the name each entry gets from its directory to its spec file, without loading
+// the entries. Names set by name fields aren't taken into account.
+func (l *Loader) DirSpecs() (map[string]string, error) {
+	specPaths, err := l.specPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	specs := make(map[string]string, len(specPaths))
+	for _, specPath := range specPaths {
+		specs[l.dirName(specPath)] = specPath
+	}
+	return specs, nil
+}
+
+// SetWorkers sets how many specs LoadAll parses and validates at once. It defaults to GOMAXPROCS.
+func (l *Loader) SetWorkers(workers int) {
+	l.workers = workers
+}
+
+// SetSkipDisabled controls whether LoadAll and ValidateEach leave out entries
+// with `enabled: false`. Skipped entries are still parsed and validated, so an
+// invalid disabled entry is reported either way.
+func (l *Loader) SetSkipDisabled(skip bool) {
+	l.skipDisabled = skip
+}
+
+// SetTransportFilter makes LoadAll and ValidateEach leave out entries whose transport isn't
+// one of transports. Like disabled entries, filtered entries are still parsed and validated.
+// No transports means entries of every transport are loaded.
+func (l *Loader) SetTransportFilter(transports ...string) {
+	l.transports = transports
+}
+
+// includes returns true if a loaded entry passes the disabled and transport filters
+func (l *Loader) includes(entry *types.RegistryEntry) bool {
+	if l.skipDisabled && !entry.IsEnabled() {
+		return false
+	}
+	return len(l.transports) == 0 || slices.Contains(l.transports, entry.GetTransport())
+}
+
+// SetNamePolicy controls where the names of loaded entries come from. Entries that break
+// the policy fail to load.
+func (l *Loader) SetNamePolicy(policy NamePolicy) {
+	l.namePolicy = policy
+}
+
+// SetAllowLatest controls whether entries whose image is only pinned to the latest tag are valid
+func (l *Loader) SetAllowLatest(allow bool) {
+	l.allowLatest = allow
+}
+
+// SetAllowInsecureURL controls whether remote servers with http URLs are valid
+func (l *Loader) SetAllowInsecureURL(allow bool) {
+	l.allowInsecure = allow
+}
+
+// SetStrictEnv controls whether entries with env var names that don't follow shell conventions are invalid
+func (l *Loader) SetStrictEnv(strict bool) {
+	l.strictEnv = strict
+}
+
+// SetStrictLicense controls whether entries with a license that isn't in canonical SPDX form are invalid
+func (l *Loader) SetStrictLicense(strict bool) {
+	l.strictLicense = strict
+}
+
+// AddRule adds rules that entries must pass after schema validation
+func (l *Loader) AddRule(rules ...ValidationRule) {
+	l.rules = append(l.rules, rules...)
+}
 
-			// Override with explicit name if set in the spec
-			if entry.GetName() != "" && entry.GetName() != entryName {
-				entryName = entry.GetName()
-			} else {
-				entry.SetName(entryName)
+// SetValidationCache makes the loader skip validating entries that last passed with the same
+// content, and record the result of validating the others
+func (l *Loader) SetValidationCache(cache *ValidationCache) {
+	l.cache = cache
+}
+
+// LoadByName loads and validates a single entry by name without loading the whole registry.
+// The entry is looked up in the spec file of registry/<name> first; if that doesn't exist or
+// declares a different name, the entry directory in a category or the spec whose name
+// field overrides to name is used.
+// It returns an error wrapping ErrEntryNotFound if no entry has the name.
+func (l *Loader) LoadByName(name string) (*types.RegistryEntry, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return nil, fmt.Errorf("invalid entry name %q", name)
+	}
+
+	// Fast path: the entry lives in a directory with the same name
+	specPath, err := l.findSpec(filepath.Join(l.registryPath, name))
+	if err != nil {
+		return nil, err
+	}
+	if specPath != "" {
+		entry, err := l.loadNamedEntry(specPath, name)
+		if err != nil {
+			return nil, err
+		}
+		if entry.GetName() == name {
+			return entry, nil
+		}
+	}
+
+	// Slow path: the entry is in a category, or another directory overrides its name to the one requested
+	specPaths, err := l.specPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candidate := range specPaths {
+		if candidate == specPath {
+			continue
+		}
+
+		// Under the dir policy, name fields are never looked at
+		dirName := l.dirName(candidate)
+		if l.namePolicy == NamePolicyDir {
+			if dirName == name {
+				return l.loadNamedEntry(candidate, dirName)
 			}
+			continue
+		}
+
+		if declared := declaredName(candidate); declared == name || (declared == "" && dirName == name) {
+			return l.loadNamedEntry(candidate, dirName)
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, name)
+}
 
-			l.entries[entryName] = entry
+// LoadNamed loads and validates the named entries with LoadByName, adding them to the loader
+// without loading the rest of the registry. Named entries are added even if the loader's
+// filters would exclude them, since they were asked for explicitly.
+func (l *Loader) LoadNamed(names ...string) error {
+	for _, name := range names {
+		entry, err := l.LoadByName(name)
+		if err != nil {
+			return err
 		}
 
+		l.mu.Lock()
+		l.entries[entry.GetName()] = entry
+		l.mu.Unlock()
+	}
+	return nil
+}
+
+// loadNamedEntry loads and validates a spec, naming it after its directory unless the spec overrides the name
+// or the name policy requires otherwise
+func (l *Loader) loadNamedEntry(specPath, dirName string) (*types.RegistryEntry, error) {
+	entry, err := l.LoadEntryWithName(specPath, dirName)
+	if err != nil {
+		return nil, &EntryError{Path: specPath, Err: err}
+	}
+
+	// Validation fills in the name, so the policy is checked against the file itself
+	if err := l.checkNamePolicy(specPath, dirName); err != nil {
+		return nil, &EntryError{Path: specPath, Err: err}
+	}
+
+	// Override with explicit name if set in the spec
+	if entry.GetName() == "" {
+		entry.SetName(dirName)
+	}
+	l.mu.Lock()
+	l.sources[entry.GetName()] = specPath
+	l.mu.Unlock()
+
+	return entry, nil
+}
+
+// checkNamePolicy checks the name field of a spec against the loader's name policy
+func (l *Loader) checkNamePolicy(specPath, dirName string) error {
+	if l.namePolicy == NamePolicyDefault {
 		return nil
-	})
+	}
 
-	return err
+	name := declaredName(specPath)
+	switch {
+	case l.namePolicy == NamePolicyDir && name != "":
+		return fmt.Errorf("name field %q is not allowed, entry names come from their directory (%q)", name, dirName)
+	case l.namePolicy == NamePolicyField && name == "":
+		return errors.New("name field is required, entry names come from the spec")
+	}
+	return nil
+}
+
+// declaredName returns the name field of a spec file, or "" if it has none or can't be read
+func declaredName(specPath string) string {
+	data, err := types.ReadSpecFile(specPath)
+	if err != nil {
+		return ""
+	}
+
+	var spec struct {
+		Name string `yaml:"name"`
+	}
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return ""
+	}
+	return spec.Name
 }
 
 // LoadEntry loads a single registry entry from a YAML file without validation
@@ -92,7 +499,9 @@ func (l *Loader) LoadEntry(path string) (*types.RegistryEntry, error) {
 	return l.LoadEntryWithName(path, "")
 }
 
-// LoadEntryWithName loads a single registry entry from a YAML file with validation
+// LoadEntryWithName loads a single registry entry from a YAML file with validation. Fields the
+// entry doesn't set are filled in from the group.yaml files of the categories it's in, then
+// the tier and status from the defaults of registry.yaml.
 func (l *Loader) LoadEntryWithName(path string, name string) (*types.RegistryEntry, error) {
 	file, err := os.Open(path) // #nosec G304 - path is constructed from known directory structure
 	if err != nil {
@@ -104,12 +513,31 @@ func (l *Loader) LoadEntryWithName(path string, name string) (*types.RegistryEnt
 	if err != nil {
 		return nil, fmt.Errorf("failed to read file: %w", err)
 	}
+	data = types.NormalizeSpecData(data)
 
 	var entry types.RegistryEntry
 	if err := yaml.Unmarshal(data, &entry); err != nil {
 		return nil, fmt.Errorf("failed to parse YAML: %w", err)
 	}
 
+	// The entry's own fields take precedence over its category's group files, and those over
+	// registry.yaml. Defaults are applied before validation, so entries can rely on them for
+	// required fields.
+	groups, err := l.groupDefaults(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, defaults := range groups {
+		entry.ApplyDefaults(defaults)
+	}
+	config, err := l.registryConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config != nil {
+		config.Defaults.apply(&entry)
+	}
+
 	// Validate with the actual name if provided
 	if err := l.validateEntry(&entry, name); err != nil {
 		return nil, fmt.Errorf("validation failed: %w", err)
@@ -118,12 +546,56 @@ func (l *Loader) LoadEntryWithName(path string, name string) (*types.RegistryEnt
 	return &entry, nil
 }
 
-// validateEntry validates a registry entry using comprehensive schema-based validation
-func (*Loader) validateEntry(entry *types.RegistryEntry, name string) error {
+// registryConfig returns the registry's registry.yaml, read on first use, or nil if the
+// registry has none
+func (l *Loader) registryConfig() (*Config, error) {
+	l.configOnce.Do(func() {
+		if l.registryPath == "" {
+			return
+		}
+		if _, err := os.Stat(filepath.Join(l.registryPath, ConfigFile)); err != nil {
+			return
+		}
+		l.config, l.configErr = LoadConfig(l.registryPath)
+	})
+	return l.config, l.configErr
+}
+
+// latestAllowed reports whether the allow_latest list of registry.yaml names the entry
+func (l *Loader) latestAllowed(name string) bool {
+	config, err := l.registryConfig()
+	return err == nil && config != nil && slices.Contains(config.AllowLatest, name)
+}
+
+// validateEntry validates a registry entry using comprehensive schema-based validation,
+// unless the validation cache has a pass for the same content
+func (l *Loader) validateEntry(entry *types.RegistryEntry, name string) error {
 	// Use the new schema validator for comprehensive validation
 	validator := NewSchemaValidator()
+	allowLatest := l.allowLatest || l.latestAllowed(name)
+	validator.SetAllowLatest(allowLatest)
+	validator.SetAllowInsecureURL(l.allowInsecure)
+	validator.SetStrictEnv(l.strictEnv)
+	validator.SetStrictLicense(l.strictLicense)
+	validator.AddRule(l.rules...)
+
+	// Entries only let through by registry.yaml aren't cached, so they fail again once taken off its list
+	if l.cache == nil || name == "" || allowLatest != l.allowLatest {
+		return validator.ValidateComplete(entry, name)
+	}
 
-	return validator.ValidateComplete(entry, name)
+	// The hash is taken before validating, which fills in the name
+	hash, err := entry.ContentHash()
+	if err != nil {
+		return validator.ValidateComplete(entry, name)
+	}
+	if l.cache.Passed(name, hash) {
+		return nil
+	}
+
+	err = validator.ValidateComplete(entry, name)
+	l.cache.Record(name, hash, err)
+	return err
 }
 
 // GetEntries returns all loaded entries
@@ -131,6 +603,13 @@ func (l *Loader) GetEntries() map[string]*types.RegistryEntry {
 	return l.entries
 }
 
+// SourcePath returns the spec file an entry was loaded from, or "" if no entry with the name was loaded
+func (l *Loader) SourcePath(name string) string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sources[name]
+}
+
 // GetSortedEntries returns entries sorted by name
 func (l *Loader) GetSortedEntries() []*types.RegistryEntry {
 	var entries []*types.RegistryEntry
@@ -145,23 +624,66 @@ func (l *Loader) GetSortedEntries() []*types.RegistryEntry {
 	return entries
 }
 
-// Builder builds the final registry JSON from loaded entries
+// Builder builds the final registry JSON from loaded entries.
+// It can be embedded by other Go programs: BuildRegistry returns the built registry
+// and MarshalJSON its JSON output, neither of which touches the disk.
 type Builder struct {
-	loader *Loader
+	loader    *Loader
+	schemaURL string
+	// config is the registry-wide metadata, read from registry.yaml on first use unless set
+	config *Config
+	// now returns the time recorded as the registry's last_updated
+	now func() time.Time
 }
 
 // NewBuilder creates a new registry builder
 func NewBuilder(loader *Loader) *Builder {
 	return &Builder{
-		loader: loader,
+		loader:    loader,
+		schemaURL: DefaultSchemaURL,
+		now:       time.Now,
 	}
 }
 
-// Build creates the final registry structure compatible with toolhive
-func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
+// SetSchemaURL sets the schema declared in the output's $schema field.
+// The same schema is used by ValidateAgainstSchema so output and validation never disagree.
+func (b *Builder) SetSchemaURL(schemaURL string) {
+	if schemaURL == "" {
+		schemaURL = DefaultSchemaURL
+	}
+	b.schemaURL = schemaURL
+}
+
+// SetConfig sets the registry-wide metadata instead of reading it from the registry's registry.yaml
+func (b *Builder) SetConfig(config *Config) {
+	b.config = config
+}
+
+// Config returns the registry-wide metadata, reading it from the registry's registry.yaml
+// the first time it's needed
+func (b *Builder) Config() (*Config, error) {
+	if b.config == nil {
+		config, err := LoadConfig(b.loader.registryPath)
+		if err != nil {
+			return nil, err
+		}
+		b.config = config
+	}
+	return b.config, nil
+}
+
+// BuildRegistry returns the registry built from the loaded entries in the toolhive
+// format, with servers keyed by name and the defaults of registry.yaml applied. It doesn't
+// include the registry builder's extended fields; use MarshalJSON for the full output.
+func (b *Builder) BuildRegistry() (*toolhiveRegistry.Registry, error) {
+	config, err := b.Config()
+	if err != nil {
+		return nil, err
+	}
+
 	registry := &toolhiveRegistry.Registry{
 		Version:       "1.0.0",
-		LastUpdated:   time.Now().UTC().Format(time.RFC3339),
+		LastUpdated:   b.now().UTC().Format(time.RFC3339),
 		Servers:       make(map[string]*toolhiveRegistry.ImageMetadata),
 		RemoteServers: make(map[string]*toolhiveRegistry.RemoteServerMetadata),
 	}
@@ -179,11 +701,11 @@ func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
 
 		if entry.IsImage() {
 			// Process image-based server
-			metadata := b.processImageMetadata(entry.ImageMetadata)
+			metadata := processImageMetadata(entry.ImageMetadata, config.Defaults)
 			registry.Servers[name] = metadata
 		} else if entry.IsRemote() {
 			// Process remote server
-			metadata := b.processRemoteMetadata(entry.RemoteServerMetadata)
+			metadata := processRemoteMetadata(entry.RemoteServerMetadata, config.Defaults)
 			registry.RemoteServers[name] = metadata
 		}
 	}
@@ -192,20 +714,23 @@ func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
 }
 
 // processImageMetadata processes and normalizes ImageMetadata
-func (*Builder) processImageMetadata(metadata *toolhiveRegistry.ImageMetadata) *toolhiveRegistry.ImageMetadata {
+func processImageMetadata(metadata *toolhiveRegistry.ImageMetadata, defaults Defaults) *toolhiveRegistry.ImageMetadata {
 	// Create a copy of the ImageMetadata
 	result := *metadata
 
 	// Don't set the name field - the key serves as the name
 	result.Name = ""
 
+	// Write the canonical transport even for entries that weren't normalized on load
+	result.Transport, _ = types.NormalizeTransport(result.Transport)
+
 	// Set defaults if not specified
 	if result.Tier == "" {
-		result.Tier = "Community"
+		result.Tier = defaults.Tier
 	}
 
 	if result.Status == "" {
-		result.Status = "Active"
+		result.Status = defaults.Status
 	}
 
 	// Initialize empty slices if nil to match JSON output
@@ -251,20 +776,25 @@ func (*Builder) processImageMetadata(metadata *toolhiveRegistry.ImageMetadata) *
 }
 
 // processRemoteMetadata processes and normalizes RemoteServerMetadata
-func (*Builder) processRemoteMetadata(metadata *toolhiveRegistry.RemoteServerMetadata) *toolhiveRegistry.RemoteServerMetadata {
+func processRemoteMetadata(
+	metadata *toolhiveRegistry.RemoteServerMetadata, defaults Defaults,
+) *toolhiveRegistry.RemoteServerMetadata {
 	// Create a copy of the RemoteServerMetadata
 	result := *metadata
 
 	// Don't set the name field - the key serves as the name
 	result.Name = ""
 
+	// Write the canonical transport even for entries that weren't normalized on load
+	result.Transport, _ = types.NormalizeTransport(result.Transport)
+
 	// Set defaults if not specified
 	if result.Tier == "" {
-		result.Tier = "Community"
+		result.Tier = defaults.Tier
 	}
 
 	if result.Status == "" {
-		result.Status = "Active"
+		result.Status = defaults.Status
 	}
 
 	// Initialize empty slices if nil to match JSON output
@@ -287,35 +817,47 @@ func (*Builder) processRemoteMetadata(metadata *toolhiveRegistry.RemoteServerMet
 	return &result
 }
 
-// WriteJSON writes the registry to a JSON file
-func (b *Builder) WriteJSON(path string) error {
-	registry, err := b.Build()
+// Build is an alias of BuildRegistry.
+//
+// Deprecated: use BuildRegistry.
+func (b *Builder) Build() (*toolhiveRegistry.Registry, error) {
+	return b.BuildRegistry()
+}
+
+// MarshalJSON returns the built registry as written to registry.json: indented JSON
+// wrapped with the $schema and including the publication metadata and extended fields
+func (b *Builder) MarshalJSON() ([]byte, error) {
+	registry, err := b.BuildRegistry()
 	if err != nil {
-		return fmt.Errorf("failed to build registry: %w", err)
+		return nil, fmt.Errorf("failed to build registry: %w", err)
 	}
 
-	// Create the directory if it doesn't exist
-	dir := filepath.Dir(path)
-	if err := os.MkdirAll(dir, 0750); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+	// BuildRegistry has read the config already
+	config, err := b.Config()
+	if err != nil {
+		return nil, err
 	}
 
-	// Create a wrapper struct that includes the schema field
-	type registryWithSchema struct {
-		Schema string `json:"$schema"`
-		*toolhiveRegistry.Registry
+	// Wrap the registry with the schema, publication metadata and extended fields
+	data, err := json.MarshalIndent(b.buildOutput(registry, b.schemaURL, config), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 
-	// Wrap the registry with the schema
-	wrappedRegistry := registryWithSchema{
-		Schema:   "https://raw.githubusercontent.com/stacklok/toolhive/main/pkg/registry/data/schema.json",
-		Registry: registry,
-	}
+	return data, nil
+}
 
-	// Marshal to JSON with indentation
-	data, err := json.MarshalIndent(wrappedRegistry, "", "  ")
+// WriteJSON writes the output of MarshalJSON to a file, creating its directory if needed
+func (b *Builder) WriteJSON(path string) error {
+	data, err := b.MarshalJSON()
 	if err != nil {
-		return fmt.Errorf("failed to marshal JSON: %w", err)
+		return err
+	}
+
+	// Create the directory if it doesn't exist
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
 	}
 
 	// Write to file
@@ -328,13 +870,13 @@ func (b *Builder) WriteJSON(path string) error {
 
 // ValidateAgainstSchema validates the built registry against the toolhive schema
 func (b *Builder) ValidateAgainstSchema() error {
-	registry, err := b.Build()
+	registry, err := b.BuildRegistry()
 	if err != nil {
 		return fmt.Errorf("failed to build registry: %w", err)
 	}
 
-	// Use the comprehensive schema validator
-	validator := NewSchemaValidator()
+	// Validate against the same schema the output declares
+	validator := NewSchemaValidatorWithURL(b.schemaURL)
 
 	if err := validator.ValidateRegistry(registry); err != nil {
 		return fmt.Errorf("registry validation failed: %w", err)
@@ -1,9 +1,16 @@
 package registry
 
 import (
+	"encoding/json"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
 	"github.com/stretchr/testify/assert"
@@ -12,6 +19,14 @@ import (
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+// writeRegistryFile writes a file at a path relative to the registry directory, creating its directories
+func writeRegistryFile(t testing.TB, registryDir, path, content string) {
+	t.Helper()
+	path = filepath.Join(registryDir, path)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
 func TestLoader_LoadEntry(t *testing.T) {
 	t.Parallel()
 	// Create a temporary directory
@@ -20,7 +35,7 @@ func TestLoader_LoadEntry(t *testing.T) {
 	// Create a test YAML file with raw YAML to avoid marshaling issues
 	yamlData := []byte(`name: test-server
 description: Test MCP server
-image: test/image:latest
+image: test/image:1.0.0
 transport: stdio
 tier: Community
 status: Active
@@ -44,7 +59,7 @@ tags:
 	assert.NotNil(t, entry)
 	assert.Equal(t, "test-server", entry.GetName())
 	assert.True(t, entry.IsImage())
-	assert.Equal(t, "test/image:latest", entry.Image)
+	assert.Equal(t, "test/image:1.0.0", entry.Image)
 	assert.Equal(t, "Test MCP server", entry.GetDescription())
 	assert.Equal(t, "stdio", entry.GetTransport())
 	assert.Len(t, entry.GetTools(), 2)
@@ -71,7 +86,7 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Status:      "Active",
 						Tools:       []string{"test-tool"},
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: false,
@@ -88,7 +103,23 @@ func TestLoader_ValidateEntry(t *testing.T) {
 				},
 			},
 			wantErr: true,
-			errMsg:  "description is required",
+			errMsg:  "has image metadata but no image",
+		},
+		{
+			name: "image entry with leftover empty remote metadata",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{},
+			},
+			wantErr: true,
+			errMsg:  "entry 'test-entry': image entry has empty remote server metadata, which must be nil",
 		},
 		{
 			name: "missing description",
@@ -98,7 +129,7 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Transport: "stdio",
 						Tools:     []string{"test-tool"},
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: true,
@@ -112,7 +143,7 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Description: "Test server",
 						Tools:       []string{"test-tool"},
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: true,
@@ -126,7 +157,7 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Description: "Test server",
 						Transport:   "stdio",
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: true,
@@ -141,11 +172,11 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Transport:   "invalid",
 						Tools:       []string{"test-tool"},
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: true,
-			errMsg:  "schema validation failed",
+			errMsg:  `unknown transport "invalid"`,
 		},
 		{
 			name: "invalid tier",
@@ -157,7 +188,7 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Tier:        "InvalidTier",
 						Tools:       []string{"test-tool"},
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: true,
@@ -173,12 +204,297 @@ func TestLoader_ValidateEntry(t *testing.T) {
 						Status:      "InvalidStatus",
 						Tools:       []string{"test-tool"},
 					},
-					Image: "test/image:latest",
+					Image: "test/image:1.0.0",
 				},
 			},
 			wantErr: true,
 			errMsg:  "schema validation failed",
 		},
+		{
+			name: "args reference declared env var",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        "Official",
+						Status:      "Active",
+						Tools:       []string{"test-tool"},
+					},
+					Image:   "test/image:1.0.0",
+					Args:    []string{"--port", "${PORT}"},
+					EnvVars: []*toolhiveRegistry.EnvVar{{Name: "PORT", Description: "Port to listen on"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "args reference undeclared env var",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+					Args:  []string{"--token=$API_TOKEN"},
+				},
+			},
+			wantErr: true,
+			errMsg:  "references undeclared env var API_TOKEN",
+		},
+		{
+			name: "args with a literal dollar",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        "Official",
+						Status:      "Active",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+					Args:  []string{"--price=cost:$5", "a$$", "${"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "tool discovery args reference discovery env",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        "Official",
+						Status:      "Active",
+						Tools:       []string{"test-tool"},
+					},
+					Image:   "test/image:1.0.0",
+					EnvVars: []*toolhiveRegistry.EnvVar{{Name: "PORT", Description: "Port to listen on"}},
+				},
+				ToolDiscovery: &types.ToolDiscovery{
+					Args: []string{"--port=$PORT", "--mode=${MODE}"},
+					Env:  map[string]string{"MODE": "discovery"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "tool discovery args reference undeclared env var",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				ToolDiscovery: &types.ToolDiscovery{Args: []string{"--token=${API_TOKEN}"}},
+			},
+			wantErr: true,
+			errMsg:  "tool_discovery arg \"--token=${API_TOKEN}\" references undeclared env var API_TOKEN",
+		},
+		{
+			name: "malformed tag",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+						Tags:        []string{"database", "Machine Learning"},
+					},
+					Image: "test/image:1.0.0",
+				},
+			},
+			wantErr: true,
+			errMsg:  `tag "Machine Learning" is malformed (suggested: "machine-learning")`,
+		},
+		{
+			name: "valid homepage and documentation url",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        "Official",
+						Status:      "Active",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				Homepage:         "https://example.com",
+				DocumentationURL: "https://docs.example.com/mcp",
+			},
+			wantErr: false,
+		},
+		{
+			name: "homepage is not https",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				Homepage: "http://example.com",
+			},
+			wantErr: true,
+			errMsg:  "invalid homepage",
+		},
+		{
+			name: "deprecated with a reason",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        "Official",
+						Status:      "Deprecated",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				Deprecated: &types.DeprecationInfo{Reason: "Superseded", ReplacedBy: "other-server"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "deprecated without a reason",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				Deprecated: &types.DeprecationInfo{ReplacedBy: "other-server"},
+			},
+			wantErr: true,
+			errMsg:  "deprecated.reason is required",
+		},
+		{
+			name: "documentation url is relative",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				DocumentationURL: "/docs/mcp",
+			},
+			wantErr: true,
+			errMsg:  "invalid documentation_url",
+		},
+		{
+			name: "valid metadata sources",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tier:        "Official",
+						Status:      "Active",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				StarsSource: "https://github.com/example/monorepo",
+				PullsSource: "docker.io/example/mirror:1.0.0",
+			},
+			wantErr: false,
+		},
+		{
+			name: "stars source is not a repository",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				StarsSource: "https://github.com/example/monorepo/tree/main/servers/test",
+			},
+			wantErr: true,
+			errMsg:  "invalid stars_source",
+		},
+		{
+			name: "pulls source is not an image reference",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				PullsSource: "Not An Image",
+			},
+			wantErr: true,
+			errMsg:  "invalid pulls_source",
+		},
+		{
+			name: "unknown platform",
+			entry: &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: "test/image:1.0.0",
+				},
+				Platforms: []string{"linux/amd64", "linux/x86"},
+			},
+			wantErr: true,
+			errMsg:  `invalid platforms: unknown platform "linux/x86"`,
+		},
+		{
+			name: "platforms on remote server",
+			entry: &types.RegistryEntry{
+				RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "streamable-http",
+						Tools:       []string{"test-tool"},
+					},
+					URL: "https://api.example.com/mcp",
+				},
+				Platforms: []string{"linux/amd64"},
+			},
+			wantErr: true,
+			errMsg:  "platforms can only be declared for image-based servers",
+		},
+		{
+			name: "tool discovery on remote server",
+			entry: &types.RegistryEntry{
+				RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Transport:   "streamable-http",
+						Tools:       []string{"test-tool"},
+					},
+					URL: "https://api.example.com/mcp",
+				},
+				ToolDiscovery: &types.ToolDiscovery{Args: []string{"--list-tools"}},
+			},
+			wantErr: true,
+			errMsg:  "tool_discovery can only be declared for image-based servers",
+		},
 	}
 
 	for _, tt := range tests {
@@ -197,38 +513,204 @@ func TestLoader_ValidateEntry(t *testing.T) {
 	}
 }
 
-func TestLoader_LoadAll(t *testing.T) {
+func TestSchemaValidator_ImagePinning(t *testing.T) {
 	t.Parallel()
-	// Create a temporary directory structure
-	tmpDir := t.TempDir()
-
-	// Create multiple test entries with raw YAML to avoid marshaling issues
-	server1YAML := `name: server1
-description: Test server 1
-transport: stdio
-image: test/server1:latest
-tier: Community
-status: Active
-tools:
-  - tool1`
 
-	server2YAML := `name: server2
-description: Test server 2
-transport: sse
-image: test/server2:latest
-tier: Community
-status: Active
-tools:
-  - tool2`
-
-	entries := map[string]string{
-		"server1": server1YAML,
-		"server2": server2YAML,
+	tests := []struct {
+		name        string
+		image       string
+		allowLatest bool
+		wantErr     string
+	}{
+		{name: "tagged", image: "ghcr.io/org/server:1.2.3"},
+		{name: "digest pinned", image: "ghcr.io/org/server@sha256:" + strings.Repeat("a", 64)},
+		{name: "tag and digest", image: "ghcr.io/org/server:1.2.3@sha256:" + strings.Repeat("a", 64)},
+		{name: "untagged", image: "ghcr.io/org/server", wantErr: `image "ghcr.io/org/server" has no tag or digest`},
+		{name: "untagged even with allow latest", image: "org/server", allowLatest: true, wantErr: "has no tag or digest"},
+		{name: "latest", image: "ghcr.io/org/server:latest", wantErr: "uses the latest tag"},
+		{name: "latest allowed", image: "ghcr.io/org/server:latest", allowLatest: true},
+		{name: "latest pinned by digest", image: "ghcr.io/org/server:latest@sha256:" + strings.Repeat("a", 64)},
+		{name: "invalid reference", image: "ghcr.io/Org/Server:1.0", wantErr: "invalid image reference"},
 	}
 
-	// Create directories and spec files
-	for name, yamlContent := range entries {
-		dir := filepath.Join(tmpDir, name)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &types.RegistryEntry{
+				ImageMetadata: &toolhiveRegistry.ImageMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Tier:        "Official",
+						Status:      "Active",
+						Transport:   "stdio",
+						Tools:       []string{"test-tool"},
+					},
+					Image: tt.image,
+				},
+			}
+
+			validator := NewSchemaValidator()
+			validator.SetAllowLatest(tt.allowLatest)
+			err := validator.ValidateEntryFields(entry, "test-entry")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "entry 'test-entry'")
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// The loader passes its setting on to the validator
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "latest/spec.yaml",
+		"description: Server pinned to latest\ntransport: stdio\nimage: test/latest:latest\ntier: Community\nstatus: Active\ntools:\n  - tool1\n")
+	assert.Error(t, NewLoader(registryDir).LoadAll())
+	loader := NewLoader(registryDir)
+	loader.SetAllowLatest(true)
+	assert.NoError(t, loader.LoadAll())
+
+	// Entries listed under allow_latest in registry.yaml pass, other ones still fail
+	writeRegistryFile(t, registryDir, "other/spec.yaml",
+		"description: Other server\ntransport: stdio\nimage: test/other:latest\ntier: Community\nstatus: Active\ntools:\n  - tool1\n")
+	writeRegistryFile(t, registryDir, ConfigFile, "allow_latest:\n  - latest\n")
+	loader = NewLoader(registryDir)
+	_, err := loader.LoadByName("latest")
+	require.NoError(t, err)
+	_, err = loader.LoadByName("other")
+	assert.ErrorContains(t, err, "uses the latest tag")
+}
+
+func TestSchemaValidator_RemoteURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		url           string
+		allowInsecure bool
+		wantErr       string
+	}{
+		{name: "https", url: "https://api.example.com/mcp"},
+		{name: "https with port", url: "https://api.example.com:8443/mcp"},
+		{name: "http", url: "http://api.example.com/mcp", wantErr: "uses http"},
+		{name: "http allowed", url: "http://localhost:8080/mcp", allowInsecure: true},
+		{name: "bare hostname", url: "api.example.com/mcp", wantErr: "must be an absolute https URL"},
+		{name: "ftp scheme", url: "ftp://files.example.com/mcp", wantErr: "must be an absolute https URL"},
+		{name: "ftp even with allow insecure", url: "ftp://files.example.com/mcp", allowInsecure: true, wantErr: "must be an absolute"},
+		{name: "missing host", url: "https:///mcp", wantErr: "has no host"},
+		{name: "port without host", url: "https://:8443/mcp", wantErr: "has no host"},
+		{name: "unparseable", url: "https://api.example.com/%zz", wantErr: "failed to parse URL"},
+		{name: "control character", url: "https://api.example.com/\x7f", wantErr: "failed to parse URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := &types.RegistryEntry{
+				RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+						Description: "Test server",
+						Tier:        "Official",
+						Status:      "Active",
+						Transport:   "streamable-http",
+						Tools:       []string{"test-tool"},
+					},
+					URL: tt.url,
+				},
+			}
+
+			validator := NewSchemaValidator()
+			validator.SetAllowInsecureURL(tt.allowInsecure)
+			err := validator.ValidateEntryFields(entry, "test-entry")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "entry 'test-entry': invalid url")
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+
+	// The loader passes its setting on to the validator
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "insecure/spec.yaml",
+		"description: Server served over http\ntransport: sse\nurl: http://localhost:8080/sse\ntier: Community\nstatus: Active\ntools:\n  - tool1\n")
+	assert.Error(t, NewLoader(registryDir).LoadAll())
+	loader := NewLoader(registryDir)
+	loader.SetAllowInsecureURL(true)
+	assert.NoError(t, loader.LoadAll())
+}
+
+func TestValidateStarsSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{name: "unset", source: ""},
+		{name: "github repository", source: "https://github.com/example/monorepo"},
+		{name: "github repository with .git", source: "https://github.com/example/monorepo.git"},
+		{name: "gitlab project in a subgroup", source: "https://gitlab.com/group/sub/project"},
+		{name: "github enterprise repository", source: "https://github.example.com/team/server"},
+		{name: "self-hosted gitlab project", source: "https://gitlab.example.com/group/sub/project"},
+		{name: "path into a github repository", source: "https://github.com/example/monorepo/tree/main", wantErr: "must be a repository URL"},
+		{name: "github owner only", source: "https://github.com/example", wantErr: "must be a repository URL"},
+		{name: "host only", source: "https://gitlab.example.com", wantErr: "must be a repository URL"},
+		{name: "http", source: "http://github.com/example/monorepo", wantErr: "must be an absolute https URL"},
+		{name: "ssh", source: "git@github.com:example/monorepo.git", wantErr: "failed to parse URL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateStarsSource(tt.source)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestLoader_LoadAll(t *testing.T) {
+	t.Parallel()
+	// Create a temporary directory structure
+	tmpDir := t.TempDir()
+
+	// Create multiple test entries with raw YAML to avoid marshaling issues
+	server1YAML := `name: server1
+description: Test server 1
+transport: stdio
+image: test/server1:1.0.0
+tier: Community
+status: Active
+tools:
+  - tool1`
+
+	server2YAML := `name: server2
+description: Test server 2
+transport: sse
+image: test/server2:1.0.0
+tier: Community
+status: Active
+tools:
+  - tool2`
+
+	entries := map[string]string{
+		"server1": server1YAML,
+		"server2": server2YAML,
+	}
+
+	// Create directories and spec files
+	for name, yamlContent := range entries {
+		dir := filepath.Join(tmpDir, name)
 		err := os.MkdirAll(dir, 0755)
 		require.NoError(t, err)
 
@@ -251,7 +733,286 @@ tools:
 	assert.Len(t, sortedEntries, 2)
 }
 
-func TestBuilder_Build(t *testing.T) {
+func TestLoader_LoadEntryBOMAndCRLF(t *testing.T) {
+	t.Parallel()
+
+	content := "\xEF\xBB\xBF# Windows spec\r\n" +
+		"image: test/image:1.0.0\r\n" +
+		"description: Test server\r\n" +
+		"transport: stdio\r\n" +
+		"tier: Community\r\n" +
+		"status: Active\r\n" +
+		"tags:\r\n" +
+		"  - Database\r\n" +
+		"tools:\r\n" +
+		"  - tool1\r\n"
+
+	dir := filepath.Join(t.TempDir(), "windows")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	specPath := filepath.Join(dir, "spec.yaml")
+	require.NoError(t, os.WriteFile(specPath, []byte(content), 0644))
+
+	// The malformed tag fails validation, so fix it first; the rewrite produces a clean file
+	issues, err := FixSpecTags(specPath)
+	require.NoError(t, err)
+	assert.Len(t, issues, 1)
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "\r")
+	assert.NotContains(t, string(data), "\xEF\xBB\xBF")
+	assert.Contains(t, string(data), "# Windows spec\n")
+
+	entry, err := NewLoader(filepath.Dir(dir)).LoadByName("windows")
+	require.NoError(t, err)
+	assert.Equal(t, "test/image:1.0.0", entry.Image)
+	assert.Equal(t, "Test server", entry.GetDescription())
+	assert.Equal(t, []string{"tool1"}, entry.GetTools())
+}
+
+func TestLoader_LoadEntryWithBOM(t *testing.T) {
+	t.Parallel()
+
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	content := "\xEF\xBB\xBFimage: test/image:1.0.0\r\ndescription: Test server\r\ntransport: stdio\r\n" +
+		"tier: Community\r\nstatus: Active\r\ntools:\r\n  - tool1\r\n"
+	require.NoError(t, os.WriteFile(specPath, []byte(content), 0644))
+
+	entry, err := NewLoader("").LoadEntryWithName(specPath, "windows")
+	require.NoError(t, err)
+	assert.Equal(t, "test/image:1.0.0", entry.Image)
+}
+
+func TestLoader_LoadByName(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	specs := map[string]string{
+		// Named after its directory
+		"plain": `description: Plain server
+transport: stdio
+tier: Community
+status: Active
+image: test/plain:1.0.0
+tools:
+  - tool1`,
+		// Overrides its name
+		"old-dir": `name: renamed
+description: Renamed server
+transport: stdio
+tier: Community
+status: Active
+image: test/renamed:1.0.0
+tools:
+  - tool1`,
+		"broken": `description: Broken server
+transport: stdio
+tier: Community
+status: Active
+tools:
+  - tool1`,
+	}
+
+	for dir, content := range specs {
+		require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, dir), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, dir, "spec.yaml"), []byte(content), 0644))
+	}
+
+	tests := []struct {
+		name        string
+		entryName   string
+		wantDesc    string
+		errMsg      string
+		errNotFound bool
+	}{
+		{name: "present", entryName: "plain", wantDesc: "Plain server"},
+		{name: "name override", entryName: "renamed", wantDesc: "Renamed server"},
+		{name: "directory of a renamed entry", entryName: "old-dir", errNotFound: true},
+		{name: "absent", entryName: "missing", errNotFound: true},
+		{name: "invalid spec", entryName: "broken", errMsg: "failed to load"},
+		{name: "path traversal", entryName: "../plain", errMsg: "invalid entry name"},
+	}
+
+	loader := NewLoader(tmpDir)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			entry, err := loader.LoadByName(tt.entryName)
+			switch {
+			case tt.errNotFound:
+				require.Error(t, err)
+				assert.ErrorIs(t, err, ErrEntryNotFound)
+				assert.Contains(t, err.Error(), tt.entryName)
+			case tt.errMsg != "":
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.entryName, entry.GetName())
+				assert.Equal(t, tt.wantDesc, entry.GetDescription())
+			}
+		})
+	}
+
+	// LoadByName doesn't populate the loader's entries
+	assert.Empty(t, loader.GetEntries())
+}
+
+func TestLoader_NamePolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		policy    NamePolicy
+		nameField string
+		wantName  string
+		errMsg    string
+	}{
+		{name: "default uses the directory", policy: NamePolicyDefault, wantName: "server-dir"},
+		{name: "default lets the field override", policy: NamePolicyDefault, nameField: "renamed", wantName: "renamed"},
+		{name: "dir uses the directory", policy: NamePolicyDir, wantName: "server-dir"},
+		{name: "dir rejects a name field", policy: NamePolicyDir, nameField: "renamed",
+			errMsg: `name field "renamed" is not allowed, entry names come from their directory ("server-dir")`},
+		{name: "dir rejects a name field matching the directory", policy: NamePolicyDir, nameField: "server-dir",
+			errMsg: "is not allowed"},
+		{name: "field uses the name field", policy: NamePolicyField, nameField: "renamed", wantName: "renamed"},
+		{name: "field requires a name field", policy: NamePolicyField, errMsg: "name field is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			registryDir := t.TempDir()
+			spec := "description: Test server\ntransport: stdio\ntier: Community\nstatus: Active\nimage: test/image:1.0.0\ntools:\n  - tool1\n"
+			if tt.nameField != "" {
+				spec = "name: " + tt.nameField + "\n" + spec
+			}
+			specPath := filepath.Join(registryDir, "server-dir", "spec.yaml")
+			require.NoError(t, os.MkdirAll(filepath.Dir(specPath), 0755))
+			require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+
+			loader := NewLoader(registryDir)
+			loader.SetNamePolicy(tt.policy)
+			err := loader.LoadAll()
+			if tt.errMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.errMsg)
+				var entryErr *EntryError
+				require.ErrorAs(t, err, &entryErr)
+				assert.Equal(t, specPath, entryErr.Path)
+				return
+			}
+			require.NoError(t, err)
+			assert.Contains(t, loader.GetEntries(), tt.wantName)
+
+			entry, err := loader.LoadByName(tt.wantName)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantName, entry.GetName())
+		})
+	}
+}
+
+func TestLoader_TransportFilter(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	for name, spec := range map[string]string{
+		"stdio-server":  "description: Stdio server\ntransport: stdio\ntier: Community\nstatus: Active\nimage: test/stdio:1.0.0\ntools:\n  - tool1\n",
+		"sse-server":    "description: SSE server\ntransport: sse\ntier: Community\nstatus: Active\nimage: test/sse:1.0.0\ntarget_port: 8080\ntools:\n  - tool1\n",
+		"remote-server": "description: Remote server\ntransport: streamable-http\ntier: Community\nstatus: Active\nurl: https://api.example.com/mcp\ntools:\n  - tool1\n",
+	} {
+		require.NoError(t, os.MkdirAll(filepath.Join(registryDir, name), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, name, "spec.yaml"), []byte(spec), 0644))
+	}
+
+	tests := []struct {
+		name       string
+		transports []string
+		want       []string
+	}{
+		{name: "no filter", want: []string{"remote-server", "sse-server", "stdio-server"}},
+		{name: "stdio", transports: []string{"stdio"}, want: []string{"stdio-server"}},
+		{name: "remote transports", transports: []string{"sse", "streamable-http"}, want: []string{"remote-server", "sse-server"}},
+		{name: "stdio and sse", transports: []string{"sse", "stdio"}, want: []string{"sse-server", "stdio-server"}},
+		{name: "no match", transports: []string{"websocket"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loader := NewLoader(registryDir)
+			loader.SetTransportFilter(tt.transports...)
+			require.NoError(t, loader.LoadAll())
+			assert.ElementsMatch(t, tt.want, slices.Collect(maps.Keys(loader.GetEntries())))
+
+			loader = NewLoader(registryDir)
+			loader.SetTransportFilter(tt.transports...)
+			report, err := loader.ValidateEach()
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.want), report.Passed)
+		})
+	}
+}
+
+func TestLoader_LoadNamed(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	for _, name := range []string{"first", "second"} {
+		writeRegistryFile(t, registryDir, filepath.Join(name, "spec.yaml"), `description: Test server
+transport: stdio
+tier: Community
+status: Active
+image: test/`+name+`:1.0.0
+tools:
+  - tool1`)
+	}
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadNamed("second"))
+	entries := loader.GetEntries()
+	assert.Len(t, entries, 1)
+	assert.Contains(t, entries, "second")
+
+	err := loader.LoadNamed("missing")
+	assert.ErrorIs(t, err, ErrEntryNotFound)
+}
+
+func TestLoader_NamePolicyDirIgnoresOverrides(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "old-dir"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "old-dir", "spec.yaml"), []byte(`name: renamed
+description: Renamed server
+transport: stdio
+tier: Community
+status: Active
+image: test/renamed:1.0.0
+tools:
+  - tool1`), 0644))
+
+	// The dir policy never looks up entries by their name field
+	loader := NewLoader(registryDir)
+	loader.SetNamePolicy(NamePolicyDir)
+	_, err := loader.LoadByName("renamed")
+	assert.ErrorIs(t, err, ErrEntryNotFound)
+}
+
+func TestParseNamePolicy(t *testing.T) {
+	t.Parallel()
+
+	for _, policy := range []string{"", "dir", "field"} {
+		parsed, err := ParseNamePolicy(policy)
+		require.NoError(t, err)
+		assert.Equal(t, NamePolicy(policy), parsed)
+	}
+
+	_, err := ParseNamePolicy("directory")
+	assert.EqualError(t, err, `unknown entry name policy "directory" (supported: dir, field)`)
+}
+
+func TestBuilder_BuildRegistry(t *testing.T) {
 	t.Parallel()
 	loader := NewLoader("")
 	loader.entries = map[string]*types.RegistryEntry{
@@ -265,14 +1026,14 @@ func TestBuilder_Build(t *testing.T) {
 					Status:      "Active",
 					Tools:       []string{"test-tool"},
 				},
-				Image: "test/image:latest",
+				Image: "test/image:1.0.0",
 			},
 		},
 	}
 
 	// Create builder and build
 	builder := NewBuilder(loader)
-	registry, err := builder.Build()
+	registry, err := builder.BuildRegistry()
 
 	assert.NoError(t, err)
 	assert.NotNil(t, registry)
@@ -294,7 +1055,7 @@ func TestBuilder_ValidateAgainstSchema(t *testing.T) {
 					Status:      "Active",
 					Tools:       []string{"test-tool"},
 				},
-				Image: "test/image:latest",
+				Image: "test/image:1.0.0",
 			},
 		},
 	}
@@ -312,7 +1073,7 @@ func TestBuilder_ValidateAgainstSchema(t *testing.T) {
 					Transport: "stdio",
 					Tools:     []string{"test-tool"},
 				},
-				Image: "test/image:latest",
+				Image: "test/image:1.0.0",
 			},
 		},
 	}
@@ -320,3 +1081,500 @@ func TestBuilder_ValidateAgainstSchema(t *testing.T) {
 	err = builder.ValidateAgainstSchema()
 	assert.Error(t, err)
 }
+
+func TestBuilder_CustomSchemaURL(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"test-server": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Test server",
+					Transport:   "stdio",
+					Tools:       []string{"test-tool"},
+				},
+				Image: "test/image:1.0.0",
+			},
+		},
+	}
+
+	// A schema that only accepts registries with a version of 2.0.0
+	schemaPath := filepath.Join(tmpDir, "schema.json")
+	schema := `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "required": ["version"],
+  "properties": {"version": {"const": "2.0.0"}}
+}`
+	require.NoError(t, os.WriteFile(schemaPath, []byte(schema), 0644))
+
+	builder := NewBuilder(loader)
+	require.NoError(t, builder.ValidateAgainstSchema())
+
+	// The custom schema is used for validation
+	builder.SetSchemaURL(schemaPath)
+	err := builder.ValidateAgainstSchema()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "registry schema validation failed")
+
+	// The custom schema is declared in the output
+	outputPath := filepath.Join(tmpDir, "registry.json")
+	require.NoError(t, builder.WriteJSON(outputPath))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var output struct {
+		Schema string `json:"$schema"`
+	}
+	require.NoError(t, json.Unmarshal(data, &output))
+	assert.Equal(t, schemaPath, output.Schema)
+}
+
+func TestBuilder_WriteJSONIncludesLinks(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"test-server": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Test server",
+					Transport:   "stdio",
+					Tools:       []string{"test-tool"},
+				},
+				Image: "test/image:1.0.0",
+			},
+			Homepage:         "https://example.com",
+			DocumentationURL: "https://docs.example.com/mcp",
+			Platforms:        []string{"linux/amd64"},
+			Deprecated:       &types.DeprecationInfo{Reason: "Superseded", ReplacedBy: "plain-server"},
+		},
+		"plain-server": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Plain server",
+					Transport:   "stdio",
+					Tools:       []string{"test-tool"},
+				},
+				Image: "test/plain:1.0.0",
+			},
+		},
+	}
+
+	outputPath := filepath.Join(tmpDir, "registry.json")
+	require.NoError(t, NewBuilder(loader).WriteJSON(outputPath))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+
+	var output struct {
+		Servers map[string]map[string]any `json:"servers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &output))
+
+	assert.Equal(t, "https://example.com", output.Servers["test-server"]["homepage"])
+	assert.Equal(t, "https://docs.example.com/mcp", output.Servers["test-server"]["documentation_url"])
+	assert.Equal(t, "test/image:1.0.0", output.Servers["test-server"]["image"])
+	assert.Equal(t, []any{"linux/amd64"}, output.Servers["test-server"]["platforms"])
+	assert.Equal(t, map[string]any{"reason": "Superseded", "replaced_by": "plain-server"},
+		output.Servers["test-server"]["deprecated"])
+	assert.NotContains(t, output.Servers["plain-server"], "homepage")
+	assert.NotContains(t, output.Servers["plain-server"], "deprecated")
+	assert.NotContains(t, output.Servers["plain-server"], "documentation_url")
+	assert.NotContains(t, output.Servers["plain-server"], "platforms")
+}
+
+func TestLoader_SourcePathAndEntryError(t *testing.T) {
+	t.Parallel()
+
+	// The spec overrides its directory name
+	registryDir := t.TempDir()
+	specPath := filepath.Join(registryDir, "dir-name", "spec.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(specPath), 0755))
+	require.NoError(t, os.WriteFile(specPath, []byte(`name: declared
+description: Renamed server
+transport: stdio
+image: test/renamed:1.0.0
+tier: Community
+status: Active
+tools:
+  - tool1
+`), 0644))
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	assert.Equal(t, specPath, loader.SourcePath("declared"))
+	assert.Empty(t, loader.SourcePath("dir-name"))
+	assert.Equal(t, 4, SpecFieldLine(specPath, "image"))
+	assert.Zero(t, SpecFieldLine(specPath, "license"))
+
+	brokenPath := filepath.Join(registryDir, "broken", "spec.yaml")
+	require.NoError(t, os.MkdirAll(filepath.Dir(brokenPath), 0755))
+	require.NoError(t, os.WriteFile(brokenPath, []byte("description: Broken\ntools:\n  - tool1\n   bad: indent\n"), 0644))
+
+	err := NewLoader(registryDir).LoadAll()
+	var entryErr *EntryError
+	require.ErrorAs(t, err, &entryErr)
+	assert.Equal(t, brokenPath, entryErr.Path)
+	assert.Positive(t, entryErr.Line())
+	assert.Contains(t, err.Error(), "failed to load "+brokenPath)
+
+	noLine := &EntryError{Path: brokenPath, Err: assert.AnError}
+	assert.Zero(t, noLine.Line())
+}
+
+func TestBuilder_MarshalJSONMatchesWriteJSON(t *testing.T) {
+	t.Parallel()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"test-server": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Test server",
+					Transport:   "stdio",
+					Tools:       []string{"test-tool"},
+				},
+				Image: "test/image:1.0.0",
+			},
+			Homepage: "https://example.com",
+		},
+	}
+
+	builder := NewBuilder(loader)
+	builder.SetSchemaURL("https://example.com/schema.json")
+	builder.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+
+	data, err := builder.MarshalJSON()
+	require.NoError(t, err)
+
+	outputPath := filepath.Join(t.TempDir(), "registry.json")
+	require.NoError(t, builder.WriteJSON(outputPath))
+	written, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, string(written), string(data))
+
+	var output map[string]any
+	require.NoError(t, json.Unmarshal(data, &output))
+	assert.Equal(t, "https://example.com/schema.json", output["$schema"])
+	assert.Equal(t, "2025-01-02T03:04:05Z", output["last_updated"])
+
+	registry, err := builder.BuildRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "2025-01-02T03:04:05Z", registry.LastUpdated)
+	assert.Contains(t, registry.Servers, "test-server")
+}
+
+// writeLoadTestRegistry writes a registry with count valid entries and returns its directory
+func writeLoadTestRegistry(t testing.TB, count int) string {
+	t.Helper()
+
+	registryDir := t.TempDir()
+	for i := range count {
+		name := fmt.Sprintf("server-%03d", i)
+		writeRegistryFile(t, registryDir, filepath.Join(name, "spec.yaml"), fmt.Sprintf(
+			"description: Test server %d\ntransport: stdio\nimage: test/%s:1.0.0\ntier: Community\nstatus: Active\ntools:\n  - tool_%d\n",
+			i, name, i))
+	}
+	return registryDir
+}
+
+func TestLoader_LoadAllConcurrent(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeLoadTestRegistry(t, 100)
+	// Hidden directories and directories without a spec are skipped
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, ".hidden"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, ".hidden", "spec.yaml"), []byte("invalid: ["), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "empty"), 0755))
+
+	for _, workers := range []int{0, 1, 8, 1000} {
+		t.Run(fmt.Sprintf("%d workers", workers), func(t *testing.T) {
+			t.Parallel()
+
+			loader := NewLoader(registryDir)
+			loader.SetWorkers(workers)
+			require.NoError(t, loader.LoadAll())
+
+			entries := loader.GetEntries()
+			require.Len(t, entries, 100)
+			for i := range 100 {
+				name := fmt.Sprintf("server-%03d", i)
+				require.Contains(t, entries, name)
+				assert.Equal(t, fmt.Sprintf("Test server %d", i), entries[name].GetDescription())
+				assert.Equal(t, filepath.Join(registryDir, name, "spec.yaml"), loader.SourcePath(name))
+			}
+		})
+	}
+}
+
+func TestLoader_LoadAllFirstErrorInPathOrder(t *testing.T) {
+	t.Parallel()
+
+	registryDir := writeLoadTestRegistry(t, 50)
+	for _, name := range []string{"server-040", "server-007", "server-023"} {
+		require.NoError(t, os.WriteFile(filepath.Join(registryDir, name, "spec.yaml"), []byte("description: [broken\n"), 0644))
+	}
+
+	// Whichever spec fails first, the error of the first in path order is returned
+	for range 10 {
+		err := NewLoader(registryDir).LoadAll()
+		var entryErr *EntryError
+		require.ErrorAs(t, err, &entryErr)
+		assert.Equal(t, filepath.Join(registryDir, "server-007", "spec.yaml"), entryErr.Path)
+	}
+}
+
+func BenchmarkLoader_LoadAll(b *testing.B) {
+	registryDir := writeLoadTestRegistry(b, 300)
+	for _, workers := range []int{1, 4, runtime.GOMAXPROCS(0)} {
+		b.Run(fmt.Sprintf("%d workers", workers), func(b *testing.B) {
+			for b.Loop() {
+				loader := NewLoader(registryDir)
+				loader.SetWorkers(workers)
+				require.NoError(b, loader.LoadAll())
+			}
+		})
+	}
+}
+
+// writeNestedSpec writes a minimal image spec to registryDir/relDir/spec.yaml
+func writeNestedSpec(t *testing.T, registryDir, relDir, extra string) string {
+	t.Helper()
+
+	specPath := filepath.Join(filepath.FromSlash(relDir), "spec.yaml")
+	writeRegistryFile(t, registryDir, specPath, extra+"description: Server in "+relDir+
+		"\ntransport: stdio\nimage: test/server:1.0.0\ntier: Community\nstatus: Active\ntools:\n  - tool1\n")
+	return filepath.Join(registryDir, specPath)
+}
+
+func TestLoader_NestedCategories(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeNestedSpec(t, registryDir, "fetch", "")
+	writeNestedSpec(t, registryDir, "databases/postgres", "")
+	writeNestedSpec(t, registryDir, "databases/nosql/mongodb", "")
+	writeNestedSpec(t, registryDir, "cloud/aws/pricing", "")
+	// Directories inside an entry belong to the entry, and hidden categories are skipped
+	writeNestedSpec(t, registryDir, "fetch/examples/extra", "")
+	writeNestedSpec(t, registryDir, ".archive/old", "")
+
+	tests := []struct {
+		name           string
+		categoryPrefix bool
+		want           map[string]string
+	}{
+		{
+			name: "leaf directory names",
+			want: map[string]string{
+				"fetch":    "fetch",
+				"postgres": "databases/postgres",
+				"mongodb":  "databases/nosql/mongodb",
+				"pricing":  "cloud/aws/pricing",
+			},
+		},
+		{
+			name:           "category prefixed names",
+			categoryPrefix: true,
+			want: map[string]string{
+				"fetch":                   "fetch",
+				"databases-postgres":      "databases/postgres",
+				"databases-nosql-mongodb": "databases/nosql/mongodb",
+				"cloud-aws-pricing":       "cloud/aws/pricing",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			loader := NewLoader(registryDir)
+			loader.SetCategoryPrefix(tt.categoryPrefix)
+			require.NoError(t, loader.LoadAll())
+			require.Len(t, loader.GetEntries(), len(tt.want))
+			for name, dir := range tt.want {
+				wantPath := filepath.Join(registryDir, filepath.FromSlash(dir), "spec.yaml")
+				assert.Equal(t, wantPath, loader.SourcePath(name), name)
+			}
+
+			specs, err := loader.DirSpecs()
+			require.NoError(t, err)
+			require.Len(t, specs, len(tt.want))
+			for name, dir := range tt.want {
+				assert.Equal(t, filepath.Join(registryDir, filepath.FromSlash(dir), "spec.yaml"), specs[name])
+			}
+
+			report, err := NewLoader(registryDir).ValidateEach()
+			require.NoError(t, err)
+			assert.Equal(t, 4, report.Passed)
+
+			// Nested entries can be loaded by name too
+			for name := range tt.want {
+				byName := NewLoader(registryDir)
+				byName.SetCategoryPrefix(tt.categoryPrefix)
+				entry, err := byName.LoadByName(name)
+				require.NoError(t, err)
+				assert.Equal(t, name, entry.GetName())
+			}
+		})
+	}
+}
+
+func TestLoader_NestedCategoryCollision(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	first := writeNestedSpec(t, registryDir, "databases/postgres", "")
+	second := writeNestedSpec(t, registryDir, "legacy/postgres", "")
+
+	err := NewLoader(registryDir).LoadAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `entry name "postgres" is used by both `+first+" and "+second)
+
+	_, err = NewLoader(registryDir).ValidateEach()
+	require.Error(t, err)
+
+	// Category prefixes tell them apart
+	loader := NewLoader(registryDir)
+	loader.SetCategoryPrefix(true)
+	require.NoError(t, loader.LoadAll())
+	assert.Contains(t, loader.GetEntries(), "databases-postgres")
+	assert.Contains(t, loader.GetEntries(), "legacy-postgres")
+}
+
+func TestLoader_DuplicateNames(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		specs map[string]string
+	}{
+		{
+			name: "name field matches another directory",
+			specs: map[string]string{
+				"github":        "",
+				"github-server": "name: github\n",
+			},
+		},
+		{
+			name: "two name fields",
+			specs: map[string]string{
+				"github-official": "name: github\n",
+				"github-server":   "name: github\n",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registryDir := t.TempDir()
+			var specPaths []string
+			for dir, extra := range tt.specs {
+				specPaths = append(specPaths, writeNestedSpec(t, registryDir, dir, extra))
+			}
+			slices.Sort(specPaths)
+
+			err := NewLoader(registryDir).LoadAll()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `entry name "github" is used by both `+specPaths[0]+" and "+specPaths[1])
+
+			// Entries excluded from the registry still claim their names
+			loader := NewLoader(registryDir)
+			loader.SetTransportFilter("sse")
+			assert.Error(t, loader.LoadAll())
+		})
+	}
+}
+
+func TestLoader_SpecFilenames(t *testing.T) {
+	t.Parallel()
+
+	// writeAs writes a valid spec for an entry under the given file name
+	writeAs := func(t *testing.T, registryDir, name, filename string) {
+		t.Helper()
+		writeRegistryFile(t, registryDir, filepath.Join(name, filename), "description: Server "+name+
+			"\ntransport: stdio\nimage: test/"+name+":1.0.0\ntier: Community\nstatus: Active\ntools:\n  - tool1\n")
+	}
+
+	tests := []struct {
+		name      string
+		files     map[string][]string
+		filenames []string
+		want      map[string]string
+		wantErr   string
+		// conflict is the entry with more than one spec file
+		conflict string
+	}{
+		{
+			name:  "yaml and yml",
+			files: map[string][]string{"fetch": {"spec.yaml"}, "github": {"spec.yml"}},
+			want:  map[string]string{"fetch": "spec.yaml", "github": "spec.yml"},
+		},
+		{
+			name:      "custom names",
+			files:     map[string][]string{"fetch": {"server.yaml"}, "github": {"spec.yaml"}},
+			filenames: []string{"server.yaml"},
+			want:      map[string]string{"fetch": "server.yaml"},
+		},
+		{
+			name:     "both variants",
+			files:    map[string][]string{"fetch": {"spec.yaml"}, "github": {"spec.yaml", "spec.yml"}},
+			wantErr:  "has more than one spec file (spec.yaml, spec.yml)",
+			conflict: "github",
+		},
+		{
+			name:      "both custom names",
+			files:     map[string][]string{"fetch": {"server.yaml", "spec.yaml"}},
+			filenames: []string{"spec.yaml", "server.yaml"},
+			wantErr:   "has more than one spec file (spec.yaml, server.yaml)",
+			conflict:  "fetch",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			registryDir := t.TempDir()
+			for name, filenames := range tt.files {
+				for _, filename := range filenames {
+					writeAs(t, registryDir, name, filename)
+				}
+			}
+
+			var opts []LoaderOption
+			if tt.filenames != nil {
+				opts = append(opts, WithSpecFilename(tt.filenames...))
+			}
+
+			loader := NewLoader(registryDir, opts...)
+			err := loader.LoadAll()
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				_, err = NewLoader(registryDir, opts...).LoadByName(tt.conflict)
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			require.Len(t, loader.GetEntries(), len(tt.want))
+			for name, filename := range tt.want {
+				assert.Equal(t, filepath.Join(registryDir, name, filename), loader.SourcePath(name))
+
+				entry, err := NewLoader(registryDir, opts...).LoadByName(name)
+				require.NoError(t, err)
+				assert.Equal(t, name, entry.GetName())
+			}
+		})
+	}
+}
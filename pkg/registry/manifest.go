@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ManifestFile is the name of the file describing the built registry, written next to it
+const ManifestFile = "manifest.json"
+
+// DigestSuffix is appended to the path of a built registry to name the file holding its
+// SHA-256, in the "<hash>  <file name>" format of sha256sum so it can be checked with sha256sum -c
+const DigestSuffix = ".sha256"
+
+// Manifest describes a built registry for downstream caches
+type Manifest struct {
+	// EntryCount is the number of servers, image-based and remote, in the registry
+	EntryCount int `json:"entry_count"`
+	// BuiltAt is the registry's last_updated time
+	BuiltAt string `json:"built_at"`
+	// ContentDigest is the digest of the registry's content as returned by ContentDigest
+	ContentDigest string `json:"content_digest"`
+}
+
+// ContentDigest returns the hex SHA-256 of the canonical JSON of a built registry: compact, with
+// object keys sorted and without last_updated, so rebuilding unchanged entries yields the
+// same digest regardless of when or how the file was written
+func ContentDigest(data []byte) (string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var registry map[string]any
+	if err := decoder.Decode(&registry); err != nil {
+		return "", fmt.Errorf("failed to parse registry: %w", err)
+	}
+	delete(registry, "last_updated")
+
+	// Maps are marshaled with sorted keys, which makes the output canonical
+	canonical, err := json.Marshal(registry)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal registry: %w", err)
+	}
+	sum := sha256.Sum256(canonical)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// WriteManifest writes the SHA-256 of the built registry file at path to <path>.sha256, and a
+// Manifest with the digest of its content to manifest.json in the same directory
+func WriteManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the caller
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	contentDigest, err := ContentDigest(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var registry struct {
+		LastUpdated   string                     `json:"last_updated"`
+		Servers       map[string]json.RawMessage `json:"servers"`
+		RemoteServers map[string]json.RawMessage `json:"remote_servers"`
+	}
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse registry: %w", err)
+	}
+	manifest := &Manifest{
+		EntryCount:    len(registry.Servers) + len(registry.RemoteServers),
+		BuiltAt:       registry.LastUpdated,
+		ContentDigest: contentDigest,
+	}
+
+	sum := sha256.Sum256(data)
+	fileDigest := fmt.Sprintf("%s  %s\n", hex.EncodeToString(sum[:]), filepath.Base(path))
+	if err := os.WriteFile(path+DigestSuffix, []byte(fileDigest), 0600); err != nil {
+		return nil, fmt.Errorf("failed to write digest: %w", err)
+	}
+	if err := writeJSONFile(filepath.Join(filepath.Dir(path), ManifestFile), manifest); err != nil {
+		return nil, fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func buildWithManifest(t *testing.T, entries map[string]*types.RegistryEntry, now time.Time) (*Manifest, string) {
+	t.Helper()
+	outputPath := filepath.Join(t.TempDir(), "registry.json")
+
+	loader := NewLoader("")
+	loader.entries = entries
+	builder := NewBuilder(loader)
+	builder.now = func() time.Time { return now }
+	require.NoError(t, builder.WriteJSON(outputPath))
+
+	manifest, err := WriteManifest(outputPath)
+	require.NoError(t, err)
+	return manifest, outputPath
+}
+
+func TestWriteManifest_ReproducibleDigest(t *testing.T) {
+	t.Parallel()
+
+	entries := map[string]*types.RegistryEntry{
+		"community-image": tierImageEntry("Community"),
+		"official-image":  tierImageEntry("Official"),
+	}
+	first, firstPath := buildWithManifest(t, entries, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	second, _ := buildWithManifest(t, entries, time.Date(2026, 2, 1, 12, 30, 0, 0, time.UTC))
+
+	assert.Equal(t, first.ContentDigest, second.ContentDigest)
+	assert.Len(t, first.ContentDigest, 64)
+	assert.Equal(t, 2, first.EntryCount)
+	assert.Equal(t, "2026-01-01T00:00:00Z", first.BuiltAt)
+	assert.Equal(t, "2026-02-01T12:30:00Z", second.BuiltAt)
+
+	// The digest file holds the hash of the file itself, in the format of sha256sum
+	registryData, err := os.ReadFile(firstPath)
+	require.NoError(t, err)
+	sum := sha256.Sum256(registryData)
+	data, err := os.ReadFile(firstPath + DigestSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, hex.EncodeToString(sum[:])+"  registry.json\n", string(data))
+
+	// The manifest file agrees with the returned manifest
+	data, err = os.ReadFile(filepath.Join(filepath.Dir(firstPath), ManifestFile))
+	require.NoError(t, err)
+	var written Manifest
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, *first, written)
+
+	// Changing an entry changes the digest
+	entries["community-image"] = tierImageEntry("Community")
+	entries["community-image"].Image = "test/image:2.0.0"
+	changed, _ := buildWithManifest(t, entries, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	assert.NotEqual(t, first.ContentDigest, changed.ContentDigest)
+}
+
+func TestContentDigest_Canonical(t *testing.T) {
+	t.Parallel()
+
+	want, err := ContentDigest([]byte(`{"version":"1.0.0","last_updated":"2026-01-01T00:00:00Z","servers":{"a":{"tools":["x"],"stars":10}}}`))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "indented", data: "{\n  \"version\": \"1.0.0\",\n  \"last_updated\": \"2026-01-01T00:00:00Z\",\n" +
+			"  \"servers\": {\n    \"a\": {\n      \"tools\": [\"x\"],\n      \"stars\": 10\n    }\n  }\n}\n"},
+		{name: "keys reordered", data: `{"servers":{"a":{"stars":10,"tools":["x"]}},"version":"1.0.0","last_updated":"2026-01-01T00:00:00Z"}`},
+		{name: "other last_updated", data: `{"version":"1.0.0","last_updated":"2027-06-30T00:00:00Z","servers":{"a":{"tools":["x"],"stars":10}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ContentDigest([]byte(tt.data))
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+		})
+	}
+
+	other, err := ContentDigest([]byte(`{"version":"1.0.0","servers":{"a":{"tools":["x"],"stars":11}}}`))
+	require.NoError(t, err)
+	assert.NotEqual(t, want, other)
+
+	_, err = ContentDigest([]byte(strings.Repeat("{", 3)))
+	assert.Error(t, err)
+}
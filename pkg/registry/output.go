@@ -0,0 +1,108 @@
+package registry
+
+import (
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// entryLinks holds registry-specific links that the toolhive types don't carry
+type entryLinks struct {
+	Homepage         string `json:"homepage,omitempty"`
+	DocumentationURL string `json:"documentation_url,omitempty"`
+}
+
+// entryExtensions holds registry-specific fields that apply to every server
+type entryExtensions struct {
+	Deprecated *types.DeprecationInfo `json:"deprecated,omitempty"`
+}
+
+// imageExtensions holds registry-specific fields that only apply to image servers
+type imageExtensions struct {
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+// imageServerOutput is an image server as written to the output, with extended fields
+type imageServerOutput struct {
+	*toolhiveRegistry.ImageMetadata
+	entryLinks
+	entryExtensions
+	imageExtensions
+}
+
+// remoteServerOutput is a remote server as written to the output, with extended fields
+type remoteServerOutput struct {
+	*toolhiveRegistry.RemoteServerMetadata
+	entryLinks
+	entryExtensions
+}
+
+// registryOutput is the registry as written to registry.json
+type registryOutput struct {
+	Schema      string `json:"$schema"`
+	Version     string `json:"version"`
+	LastUpdated string `json:"last_updated"`
+	Publication
+	Servers       map[string]imageServerOutput  `json:"servers"`
+	RemoteServers map[string]remoteServerOutput `json:"remote_servers,omitempty"`
+}
+
+// linksFor returns the extended links of a loaded entry
+func (b *Builder) linksFor(name string) entryLinks {
+	entry, ok := b.loader.GetEntries()[name]
+	if !ok {
+		return entryLinks{}
+	}
+	return entryLinks{
+		Homepage:         entry.Homepage,
+		DocumentationURL: entry.DocumentationURL,
+	}
+}
+
+// extensionsFor returns the extended fields of a loaded entry that apply to every server
+func (b *Builder) extensionsFor(name string) entryExtensions {
+	entry, ok := b.loader.GetEntries()[name]
+	if !ok {
+		return entryExtensions{}
+	}
+	return entryExtensions{Deprecated: entry.Deprecated}
+}
+
+// imageOutput wraps a built image server with its extended fields
+func (b *Builder) imageOutput(name string, server *toolhiveRegistry.ImageMetadata) imageServerOutput {
+	output := imageServerOutput{ImageMetadata: server, entryLinks: b.linksFor(name), entryExtensions: b.extensionsFor(name)}
+	if entry, ok := b.loader.GetEntries()[name]; ok {
+		output.Platforms = entry.Platforms
+	}
+	return output
+}
+
+// remoteOutput wraps a built remote server with its extended fields
+func (b *Builder) remoteOutput(name string, server *toolhiveRegistry.RemoteServerMetadata) remoteServerOutput {
+	return remoteServerOutput{RemoteServerMetadata: server, entryLinks: b.linksFor(name), entryExtensions: b.extensionsFor(name)}
+}
+
+// buildOutput converts a built registry into its output form with the given $schema
+// and the publication metadata of the registry config
+func (b *Builder) buildOutput(registry *toolhiveRegistry.Registry, schemaURL string, config *Config) *registryOutput {
+	output := &registryOutput{
+		Schema:      schemaURL,
+		Version:     registry.Version,
+		LastUpdated: registry.LastUpdated,
+		Publication: config.Publication,
+		Servers:     make(map[string]imageServerOutput, len(registry.Servers)),
+	}
+
+	for name, server := range registry.Servers {
+		output.Servers[name] = b.imageOutput(name, server)
+	}
+
+	if len(registry.RemoteServers) > 0 {
+		output.RemoteServers = make(map[string]remoteServerOutput, len(registry.RemoteServers))
+		for name, server := range registry.RemoteServers {
+			output.RemoteServers[name] = b.remoteOutput(name, server)
+		}
+	}
+
+	return output
+}
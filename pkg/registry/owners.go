@@ -0,0 +1,115 @@
+package registry
+
+import (
+	_ "embed" // for the bundled owner exceptions
+	"fmt"
+	"net/url"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+//go:embed owner_exceptions.yaml
+var defaultOwnerExceptions []byte
+
+// OwnerExceptions lists the cases where an image and its repository_url are
+// expected to have different owners
+type OwnerExceptions struct {
+	// Namespaces are fully qualified image name prefixes (registry and optional path)
+	// that publish servers built from other projects. Images under them are never checked.
+	Namespaces []string `yaml:"namespaces"`
+	// Entries maps entry names to the reason their owners legitimately differ
+	Entries map[string]string `yaml:"entries"`
+}
+
+// OwnerMismatch describes an entry whose image doesn't appear to belong to its repository's owner
+type OwnerMismatch struct {
+	Name          string `json:"name"`
+	Image         string `json:"image"`
+	RepositoryURL string `json:"repository_url"`
+	Owner         string `json:"owner"`
+}
+
+// String returns a human-readable description of the mismatch
+func (m OwnerMismatch) String() string {
+	return fmt.Sprintf("%s: image %s does not belong to repository owner %q (%s)",
+		m.Name, m.Image, m.Owner, m.RepositoryURL)
+}
+
+// DefaultOwnerExceptions returns the owner exceptions bundled with the registry builder
+func DefaultOwnerExceptions() (*OwnerExceptions, error) {
+	var exceptions OwnerExceptions
+	if err := yaml.Unmarshal(defaultOwnerExceptions, &exceptions); err != nil {
+		return nil, fmt.Errorf("failed to parse owner exceptions: %w", err)
+	}
+
+	return &exceptions, nil
+}
+
+// CheckImageOwners returns the image entries whose repository_url owner doesn't
+// appear anywhere in their image's repository path. The comparison is a
+// case-insensitive heuristic aimed at copy-paste errors; entries without a
+// repository_url or with an unparsable image are skipped.
+func CheckImageOwners(entries map[string]*types.RegistryEntry, exceptions *OwnerExceptions) []OwnerMismatch {
+	var mismatches []OwnerMismatch
+	for _, name := range sortedKeys(entries) {
+		entry := entries[name]
+		if !entry.IsImage() || entry.ImageMetadata.RepositoryURL == "" {
+			continue
+		}
+		if _, ok := exceptions.Entries[name]; ok {
+			continue
+		}
+
+		ref, err := entry.ImageReference()
+		if err != nil || exceptions.coversImage(ref) {
+			continue
+		}
+
+		owner := repositoryOwner(entry.ImageMetadata.RepositoryURL)
+		if owner == "" || imageHasOwner(ref, owner) {
+			continue
+		}
+
+		mismatches = append(mismatches, OwnerMismatch{
+			Name:          name,
+			Image:         entry.Image,
+			RepositoryURL: entry.ImageMetadata.RepositoryURL,
+			Owner:         owner,
+		})
+	}
+	return mismatches
+}
+
+// coversImage returns true if the image is published under an excepted namespace
+func (e *OwnerExceptions) coversImage(ref *types.ImageReference) bool {
+	for _, namespace := range e.Namespaces {
+		if strings.HasPrefix(ref.Name()+"/", namespace+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryOwner returns the owner (first path segment) of a repository URL, or "" if it has none
+func repositoryOwner(repositoryURL string) string {
+	parsed, err := url.Parse(repositoryURL)
+	if err != nil {
+		return ""
+	}
+	owner, _, _ := strings.Cut(strings.Trim(parsed.Path, "/"), "/")
+	return owner
+}
+
+// imageHasOwner returns true if owner is one of the segments of the image's repository path.
+// Any segment counts since some registries put an account alias before the organization.
+func imageHasOwner(ref *types.ImageReference, owner string) bool {
+	for _, segment := range strings.Split(ref.Repository, "/") {
+		if strings.EqualFold(segment, owner) {
+			return true
+		}
+	}
+	return false
+}
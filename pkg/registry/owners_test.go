@@ -0,0 +1,122 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func ownerTestEntry(image, repositoryURL string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{RepositoryURL: repositoryURL},
+			Image:              image,
+		},
+	}
+}
+
+func TestCheckImageOwners(t *testing.T) {
+	t.Parallel()
+
+	exceptions := &OwnerExceptions{
+		Namespaces: []string{"docker.io/mcp"},
+		Entries:    map[string]string{"forked": "community fork"},
+	}
+
+	tests := []struct {
+		name      string
+		entryName string
+		entry     *types.RegistryEntry
+		mismatch  bool
+	}{
+		{
+			name:  "matching owner",
+			entry: ownerTestEntry("ghcr.io/example/server:1.0.0", "https://github.com/example/server"),
+		},
+		{
+			name:  "owner differs only in case",
+			entry: ownerTestEntry("ghcr.io/stackloklabs/tool/server:1.0.0", "https://github.com/StacklokLabs/tool"),
+		},
+		{
+			name:  "owner after a registry alias",
+			entry: ownerTestEntry("public.ecr.aws/a1b2c3/example/server:1.0.0", "https://github.com/example/server"),
+		},
+		{
+			name:     "mismatched owner",
+			entry:    ownerTestEntry("ghcr.io/other-org/server:1.0.0", "https://github.com/example/server"),
+			mismatch: true,
+		},
+		{
+			name:     "docker hub shorthand",
+			entry:    ownerTestEntry("other-org/server:1.0.0", "https://github.com/example/server"),
+			mismatch: true,
+		},
+		{
+			name:      "exempted entry",
+			entryName: "forked",
+			entry:     ownerTestEntry("ghcr.io/other-org/server:1.0.0", "https://github.com/example/server"),
+		},
+		{
+			name:  "exempted namespace",
+			entry: ownerTestEntry("docker.io/mcp/server:latest", "https://github.com/example/server"),
+		},
+		{
+			name:  "namespace prefix must match whole segments",
+			entry: ownerTestEntry("docker.io/mcpx/server:latest", "https://github.com/example/server"),
+			// "mcpx" is neither the excepted namespace nor the owner
+			mismatch: true,
+		},
+		{
+			name:  "no repository url",
+			entry: ownerTestEntry("ghcr.io/other-org/server:1.0.0", ""),
+		},
+		{
+			name: "remote entry",
+			entry: &types.RegistryEntry{
+				RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+					BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{RepositoryURL: "https://github.com/example/server"},
+					URL:                "https://api.other.com/mcp",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			name := tt.entryName
+			if name == "" {
+				name = "server"
+			}
+
+			mismatches := CheckImageOwners(map[string]*types.RegistryEntry{name: tt.entry}, exceptions)
+			if !tt.mismatch {
+				assert.Empty(t, mismatches)
+				return
+			}
+			require.Len(t, mismatches, 1)
+			assert.Equal(t, "server", mismatches[0].Name)
+			assert.Equal(t, "example", mismatches[0].Owner)
+			assert.Contains(t, mismatches[0].String(), `does not belong to repository owner "example"`)
+		})
+	}
+}
+
+func TestDefaultOwnerExceptions(t *testing.T) {
+	t.Parallel()
+
+	exceptions, err := DefaultOwnerExceptions()
+	require.NoError(t, err)
+	assert.Contains(t, exceptions.Namespaces, "ghcr.io/stacklok/dockyard")
+	assert.NotEmpty(t, exceptions.Entries)
+
+	// Servers repackaged under an excepted namespace aren't flagged
+	mismatches := CheckImageOwners(map[string]*types.RegistryEntry{
+		"context7": ownerTestEntry("ghcr.io/stacklok/dockyard/npx/context7:1.0.14", "https://github.com/upstash/context7"),
+	}, exceptions)
+	assert.Empty(t, mismatches)
+}
@@ -0,0 +1,124 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// PerEntryDir is the directory, relative to the output directory, that holds per-entry files
+const PerEntryDir = "servers"
+
+// EntryIndex lists the per-entry files written by WritePerEntryJSON
+type EntryIndex struct {
+	Servers []EntryIndexItem `json:"servers"`
+}
+
+// EntryIndexItem describes a single per-entry file
+type EntryIndexItem struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	Path string `json:"path"`
+}
+
+// WritePerEntryJSON writes each server's toolhive JSON, with its name embedded,
+// to <outputDir>/servers/<name>.json along with a servers/index.json listing them
+func (b *Builder) WritePerEntryJSON(outputDir string) error {
+	registry, err := b.BuildRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to build registry: %w", err)
+	}
+
+	dir := filepath.Join(outputDir, PerEntryDir)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	index := EntryIndex{Servers: []EntryIndexItem{}}
+
+	// Entries are written in sorted order so the index is deterministic
+	for _, name := range sortedKeys(registry.Servers) {
+		server := *registry.Servers[name]
+		server.Name = name
+		if err := writeEntryFile(dir, name, b.imageOutput(name, &server)); err != nil {
+			return err
+		}
+		index.Servers = append(index.Servers, EntryIndexItem{Name: name, Type: "container", Path: name + ".json"})
+	}
+
+	for _, name := range sortedKeys(registry.RemoteServers) {
+		server := *registry.RemoteServers[name]
+		server.Name = name
+		if err := writeEntryFile(dir, name, b.remoteOutput(name, &server)); err != nil {
+			return err
+		}
+		index.Servers = append(index.Servers, EntryIndexItem{Name: name, Type: "remote", Path: name + ".json"})
+	}
+
+	return writeJSONFile(filepath.Join(dir, "index.json"), index)
+}
+
+// EntryOutput returns the per-entry projection of a loaded entry: its built toolhive JSON
+// with the name embedded and extended fields, as written by WritePerEntryJSON
+func (b *Builder) EntryOutput(entry *types.RegistryEntry) (any, error) {
+	config, err := b.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	name := entry.GetName()
+	switch {
+	case entry.IsImage():
+		server := processImageMetadata(entry.ImageMetadata, config.Defaults)
+		server.Name = name
+		return b.imageOutput(name, server), nil
+	case entry.IsRemote():
+		server := processRemoteMetadata(entry.RemoteServerMetadata, config.Defaults)
+		server.Name = name
+		return b.remoteOutput(name, server), nil
+	default:
+		return nil, fmt.Errorf("entry %s is neither an image nor a remote server", name)
+	}
+}
+
+// WriteNDJSON writes the per-entry projection of each entry to w as newline-delimited
+// JSON. Each entry is written as soon as it's projected rather than buffering the whole list.
+func (b *Builder) WriteNDJSON(w io.Writer, entries []*types.RegistryEntry) error {
+	encoder := json.NewEncoder(w)
+	for _, entry := range entries {
+		output, err := b.EntryOutput(entry)
+		if err != nil {
+			return err
+		}
+		if err := encoder.Encode(output); err != nil {
+			return fmt.Errorf("failed to write entry %s: %w", entry.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// writeEntryFile writes a single server's JSON file
+func writeEntryFile(dir, name string, server any) error {
+	if err := writeJSONFile(filepath.Join(dir, name+".json"), server); err != nil {
+		return fmt.Errorf("failed to write entry %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeJSONFile marshals a value with indentation and writes it to path
+func writeJSONFile(path string, value any) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestBuilder_WritePerEntryJSON(t *testing.T) {
+	t.Parallel()
+	tmpDir := t.TempDir()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"fetch": newImageEntry("mcp/fetch:1.0", "https://github.com/example/fetch"),
+		"git":   newImageEntry("mcp/git:1.0", ""),
+		"remote": {
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Remote server",
+					Transport:   "sse",
+					Tools:       []string{"remote-tool"},
+				},
+				URL: "https://example.com/sse",
+			},
+		},
+	}
+
+	builder := NewBuilder(loader)
+	require.NoError(t, builder.WritePerEntryJSON(tmpDir))
+
+	combined, err := builder.BuildRegistry()
+	require.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, PerEntryDir)
+
+	// Each entry matches the combined output, with its name embedded
+	for name, server := range combined.Servers {
+		withName := *server
+		withName.Name = name
+		assertEntryFile(t, filepath.Join(dir, name+".json"), &withName)
+	}
+	for name, server := range combined.RemoteServers {
+		withName := *server
+		withName.Name = name
+		assertEntryFile(t, filepath.Join(dir, name+".json"), &withName)
+	}
+
+	// The index lists every entry in a deterministic order
+	data, err := os.ReadFile(filepath.Join(dir, "index.json"))
+	require.NoError(t, err)
+	var index EntryIndex
+	require.NoError(t, json.Unmarshal(data, &index))
+	assert.Equal(t, []EntryIndexItem{
+		{Name: "fetch", Type: "container", Path: "fetch.json"},
+		{Name: "git", Type: "container", Path: "git.json"},
+		{Name: "remote", Type: "remote", Path: "remote.json"},
+	}, index.Servers)
+}
+
+func assertEntryFile(t *testing.T, path string, expected any) {
+	t.Helper()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	want, err := json.Marshal(expected)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(want), string(data))
+}
+
+// countingWriter counts the writes made to it
+type countingWriter struct {
+	bytes.Buffer
+	writes int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Buffer.Write(p)
+}
+
+func TestBuilder_WriteNDJSON(t *testing.T) {
+	t.Parallel()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"fetch": newImageEntry("mcp/fetch:1.0", "https://github.com/example/fetch"),
+		"git":   newImageEntry("mcp/git:1.0", ""),
+		"remote": {
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Remote server",
+					Transport:   "sse",
+					Tools:       []string{"remote-tool"},
+				},
+				URL: "https://example.com/sse",
+			},
+		},
+	}
+	for name, entry := range loader.entries {
+		entry.SetName(name)
+	}
+	loader.entries["fetch"].Homepage = "https://fetch.example.com"
+
+	var out countingWriter
+	entries := loader.GetSortedEntries()
+	require.NoError(t, NewBuilder(loader).WriteNDJSON(&out, entries))
+
+	// Each entry is written on its own as it's produced
+	assert.Equal(t, len(entries), out.writes)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	require.Len(t, lines, len(entries))
+
+	var names []string
+	for _, line := range lines {
+		require.True(t, json.Valid([]byte(line)), "line is not valid JSON: %s", line)
+		var server map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &server))
+		names = append(names, server["name"].(string))
+	}
+	assert.Equal(t, []string{"fetch", "git", "remote"}, names)
+	assert.Contains(t, lines[0], `"homepage":"https://fetch.example.com"`)
+	assert.Contains(t, lines[2], `"url":"https://example.com/sse"`)
+}
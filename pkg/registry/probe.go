@@ -0,0 +1,182 @@
+package registry
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ProbeStatus describes the outcome of probing a remote server
+type ProbeStatus string
+
+const (
+	// ProbeReachable means the server responded and completed the MCP handshake
+	ProbeReachable ProbeStatus = "reachable"
+	// ProbeAuthRequired means the server responded but requires authentication
+	ProbeAuthRequired ProbeStatus = "auth-required"
+	// ProbeUnreachable means no HTTP response could be obtained from the server
+	ProbeUnreachable ProbeStatus = "unreachable"
+	// ProbeHandshakeFailed means the server responded but not as an MCP server
+	ProbeHandshakeFailed ProbeStatus = "handshake-failed"
+)
+
+// ProbeResult holds the result of probing a single remote server
+type ProbeResult struct {
+	Name   string      `json:"name"`
+	URL    string      `json:"url"`
+	Status ProbeStatus `json:"status"`
+	Detail string      `json:"detail,omitempty"`
+}
+
+// OK returns true if the probe result should be considered a pass.
+// Servers that require authentication are reachable and therefore pass.
+func (r ProbeResult) OK() bool {
+	return r.Status == ProbeReachable || r.Status == ProbeAuthRequired
+}
+
+// RemoteProber performs lightweight connectivity checks against remote MCP servers
+type RemoteProber struct {
+	client *http.Client
+}
+
+// NewRemoteProber creates a new remote prober with the given per-request timeout
+func NewRemoteProber(timeout time.Duration) *RemoteProber {
+	return &RemoteProber{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// initializeRequest is the JSON-RPC initialize request sent to streamable-http servers
+const initializeRequest = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{` +
+	`"protocolVersion":"2025-03-26","capabilities":{},` +
+	`"clientInfo":{"name":"registry-builder","version":"probe"}}}`
+
+// Probe checks a remote entry, respecting its declared transport
+func (p *RemoteProber) Probe(ctx context.Context, name string, entry *types.RegistryEntry) ProbeResult {
+	result := ProbeResult{Name: name}
+	if !entry.IsRemote() {
+		result.Status = ProbeHandshakeFailed
+		result.Detail = "entry is not a remote server"
+		return result
+	}
+	result.URL = entry.URL
+
+	switch entry.GetTransport() {
+	case "sse":
+		result.Status, result.Detail = p.probeSSE(ctx, entry.URL)
+	default:
+		result.Status, result.Detail = p.probeStreamableHTTP(ctx, entry.URL)
+	}
+
+	return result
+}
+
+// probeSSE opens the SSE stream and waits for the endpoint event
+func (p *RemoteProber) probeSSE(ctx context.Context, url string) (ProbeStatus, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return ProbeUnreachable, fmt.Sprintf("failed to create request: %v", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ProbeUnreachable, err.Error()
+	}
+	defer resp.Body.Close()
+
+	if status, detail, done := classifyResponse(resp); done {
+		return status, detail
+	}
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return ProbeHandshakeFailed, fmt.Sprintf("unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	// The first event on an MCP SSE stream announces the message endpoint
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "event: endpoint" || line == "event:endpoint" {
+			return ProbeReachable, ""
+		}
+	}
+
+	return ProbeHandshakeFailed, "stream closed before endpoint event was received"
+}
+
+// probeStreamableHTTP sends an initialize request and checks for a JSON-RPC result
+func (p *RemoteProber) probeStreamableHTTP(ctx context.Context, url string) (ProbeStatus, string) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBufferString(initializeRequest))
+	if err != nil {
+		return ProbeUnreachable, fmt.Sprintf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return ProbeUnreachable, err.Error()
+	}
+	defer resp.Body.Close()
+
+	if status, detail, done := classifyResponse(resp); done {
+		return status, detail
+	}
+
+	// Limit how much we read; the initialize response is small
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return ProbeHandshakeFailed, fmt.Sprintf("failed to read response: %v", err)
+	}
+
+	// Event-stream responses carry the JSON-RPC message in a data line
+	payload := body
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		payload = nil
+		for _, line := range strings.Split(string(body), "\n") {
+			if data, ok := strings.CutPrefix(strings.TrimSpace(line), "data:"); ok {
+				payload = []byte(strings.TrimSpace(data))
+				break
+			}
+		}
+	}
+
+	var rpc struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(payload, &rpc); err != nil {
+		return ProbeHandshakeFailed, fmt.Sprintf("invalid JSON-RPC response: %v", err)
+	}
+	if rpc.Error != nil {
+		return ProbeHandshakeFailed, fmt.Sprintf("initialize returned error: %s", rpc.Error.Message)
+	}
+	if len(rpc.Result) == 0 {
+		return ProbeHandshakeFailed, "initialize response has no result"
+	}
+
+	return ProbeReachable, ""
+}
+
+// classifyResponse handles status codes common to all transports.
+// It returns done=true when the status code alone determines the outcome.
+func classifyResponse(resp *http.Response) (ProbeStatus, string, bool) {
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden:
+		return ProbeAuthRequired, resp.Status, true
+	case resp.StatusCode != http.StatusOK:
+		return ProbeHandshakeFailed, fmt.Sprintf("server returned %s", resp.Status), true
+	}
+	return "", "", false
+}
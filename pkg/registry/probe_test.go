@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func newRemoteEntry(url, transport string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description: "Test remote server",
+				Transport:   transport,
+				Tools:       []string{"test-tool"},
+			},
+			URL: url,
+		},
+	}
+}
+
+func TestRemoteProber_Probe(t *testing.T) {
+	t.Parallel()
+
+	sseServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "event: endpoint\ndata: /messages?session=1\n\n")
+	}))
+	t.Cleanup(sseServer.Close)
+
+	notMCPServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprint(w, "<html></html>")
+	}))
+	t.Cleanup(notMCPServer.Close)
+
+	authServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(authServer.Close)
+
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-03-26"}}`)
+	}))
+	t.Cleanup(httpServer.Close)
+
+	// A closed server gives us an address nothing is listening on
+	closedServer := httptest.NewServer(http.NotFoundHandler())
+	closedURL := closedServer.URL
+	closedServer.Close()
+
+	tests := []struct {
+		name   string
+		entry  *types.RegistryEntry
+		status ProbeStatus
+		ok     bool
+	}{
+		{
+			name:   "sse handshake succeeds",
+			entry:  newRemoteEntry(sseServer.URL, "sse"),
+			status: ProbeReachable,
+			ok:     true,
+		},
+		{
+			name:   "sse endpoint is not an event stream",
+			entry:  newRemoteEntry(notMCPServer.URL, "sse"),
+			status: ProbeHandshakeFailed,
+		},
+		{
+			name:   "auth required is a pass",
+			entry:  newRemoteEntry(authServer.URL, "sse"),
+			status: ProbeAuthRequired,
+			ok:     true,
+		},
+		{
+			name:   "streamable-http initialize succeeds",
+			entry:  newRemoteEntry(httpServer.URL, "streamable-http"),
+			status: ProbeReachable,
+			ok:     true,
+		},
+		{
+			name:   "unreachable server",
+			entry:  newRemoteEntry(closedURL, "sse"),
+			status: ProbeUnreachable,
+		},
+	}
+
+	prober := NewRemoteProber(2 * time.Second)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result := prober.Probe(context.Background(), "test-remote", tt.entry)
+			assert.Equal(t, tt.status, result.Status, result.Detail)
+			assert.Equal(t, tt.ok, result.OK())
+		})
+	}
+}
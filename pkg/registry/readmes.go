@@ -0,0 +1,134 @@
+package registry
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/readme"
+)
+
+// readmeFile is the name of the README file of an entry directory
+const readmeFile = "README.md"
+
+// ReadmeIssue describes a README.md that no longer matches the spec it documents
+type ReadmeIssue struct {
+	Name string `json:"name"`
+	Path string `json:"path"`
+	// Orphaned is set when the README should be removed, either because the entry
+	// no longer needs one or because its directory has no spec.yaml
+	Orphaned bool `json:"orphaned"`
+
+	// content is the regenerated README, for stale READMEs
+	content string
+}
+
+// String returns a human-readable description of the issue
+func (i ReadmeIssue) String() string {
+	if i.Orphaned {
+		return fmt.Sprintf("%s: %s is no longer needed", i.Name, i.Path)
+	}
+	return fmt.Sprintf("%s: %s is out of date with its spec", i.Name, i.Path)
+}
+
+// CheckReadmes compares the README.md of every entry loaded by the loader with the one
+// generated from its spec. READMEs of entries below the README threshold and READMEs in
+// directories without a spec.yaml (e.g. left behind by a rename) are reported as orphaned.
+// Entries without a README and remote servers are not checked.
+func CheckReadmes(loader *Loader) ([]ReadmeIssue, error) {
+	var issues []ReadmeIssue
+
+	for _, entry := range loader.GetSortedEntries() {
+		if entry.ImageMetadata == nil {
+			continue
+		}
+		name := entry.GetName()
+		path := filepath.Join(filepath.Dir(loader.SourcePath(name)), readmeFile)
+
+		data, err := os.ReadFile(path) // #nosec G304 - path is constructed from known directory structure
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+
+		if !readme.Needed(entry.ImageMetadata) {
+			issues = append(issues, ReadmeIssue{Name: name, Path: path, Orphaned: true})
+			continue
+		}
+
+		content := readme.Generate(name, entry.ImageMetadata, readme.Links{
+			Homepage:         entry.Homepage,
+			DocumentationURL: entry.DocumentationURL,
+		})
+		if string(data) != content {
+			issues = append(issues, ReadmeIssue{Name: name, Path: path, content: content})
+		}
+	}
+
+	orphans, err := loader.speclessReadmes()
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, orphans...)
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Path < issues[j].Path })
+	return issues, nil
+}
+
+// speclessReadmes returns the READMEs of entry directories that have no spec file
+func (l *Loader) speclessReadmes() ([]ReadmeIssue, error) {
+	dirEntries, err := os.ReadDir(l.registryPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry directory: %w", err)
+	}
+
+	var issues []ReadmeIssue
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.IsDir() || strings.HasPrefix(dirEntry.Name(), ".") {
+			continue
+		}
+
+		dir := filepath.Join(l.registryPath, dirEntry.Name())
+		if specPath, err := l.findSpec(dir); err != nil || specPath != "" || isCategoryDir(dir) {
+			continue
+		}
+		path := filepath.Join(dir, readmeFile)
+		if _, err := os.Stat(path); err == nil {
+			issues = append(issues, ReadmeIssue{Name: dirEntry.Name(), Path: path, Orphaned: true})
+		}
+	}
+	return issues, nil
+}
+
+// isCategoryDir returns true if a directory has subdirectories, which makes it a category
+// of entries rather than an entry that lost its spec
+func isCategoryDir(dir string) bool {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	return slices.ContainsFunc(dirEntries, func(d os.DirEntry) bool {
+		return d.IsDir() && !strings.HasPrefix(d.Name(), ".")
+	})
+}
+
+// FixReadme removes an orphaned README or regenerates a stale one
+func FixReadme(issue ReadmeIssue) error {
+	if issue.Orphaned {
+		if err := os.Remove(issue.Path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", issue.Path, err)
+		}
+		return nil
+	}
+
+	if err := os.WriteFile(issue.Path, []byte(issue.content), 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", issue.Path, err)
+	}
+	return nil
+}
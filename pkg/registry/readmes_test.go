@@ -0,0 +1,121 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/readme"
+)
+
+// readmeTestSpec returns an image spec with the given number of tools
+func readmeTestSpec(tools int) string {
+	var spec strings.Builder
+	spec.WriteString(`image: example/server:1.0.0
+description: Example server
+transport: stdio
+tier: Community
+status: Active
+tools:
+`)
+	for i := range tools {
+		fmt.Fprintf(&spec, "  - tool_%d\n", i)
+	}
+	return spec.String()
+}
+
+func writeReadmeTestEntry(t *testing.T, registryDir, name, spec, readme string) string {
+	t.Helper()
+	dir := filepath.Join(registryDir, name)
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	if spec != "" {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "spec.yaml"), []byte(spec), 0644))
+	}
+	path := filepath.Join(dir, readmeFile)
+	if readme != "" {
+		require.NoError(t, os.WriteFile(path, []byte(readme), 0644))
+	}
+	return path
+}
+
+func TestCheckReadmes(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	stale := writeReadmeTestEntry(t, registryDir, "stale", readmeTestSpec(12), "# old-name\n")
+	shrunk := writeReadmeTestEntry(t, registryDir, "shrunk", readmeTestSpec(2), "# shrunk\n")
+	renamed := writeReadmeTestEntry(t, registryDir, "renamed", "", "# renamed\n")
+	writeReadmeTestEntry(t, registryDir, "no-readme", readmeTestSpec(12), "")
+	upToDate := writeReadmeTestEntry(t, registryDir, "up-to-date", readmeTestSpec(12), "")
+	// A category's README describes the category, so it's not orphaned
+	writeReadmeTestEntry(t, registryDir, "databases", "", "# Databases\n")
+	writeReadmeTestEntry(t, registryDir, "databases/postgres", readmeTestSpec(2), "")
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+
+	entry := loader.GetEntries()["up-to-date"]
+	content := readme.Generate("up-to-date", entry.ImageMetadata, readme.Links{})
+	require.NoError(t, os.WriteFile(upToDate, []byte(content), 0644))
+
+	issues, err := CheckReadmes(loader)
+	require.NoError(t, err)
+	assert.Equal(t, []ReadmeIssue{
+		{Name: "renamed", Path: renamed, Orphaned: true},
+		{Name: "shrunk", Path: shrunk, Orphaned: true},
+		{Name: "stale", Path: stale},
+	}, stripReadmeContent(issues))
+}
+
+func TestFixReadme(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	stale := writeReadmeTestEntry(t, registryDir, "stale", readmeTestSpec(12), "# old-name\n")
+	shrunk := writeReadmeTestEntry(t, registryDir, "shrunk", readmeTestSpec(2), "# shrunk\n")
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+
+	issues, err := CheckReadmes(loader)
+	require.NoError(t, err)
+	require.Len(t, issues, 2)
+	for _, issue := range issues {
+		require.NoError(t, FixReadme(issue))
+	}
+
+	// The stale README is regenerated from the spec
+	data, err := os.ReadFile(stale)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "# stale\n\nExample server\n"))
+	assert.Contains(t, string(data), "This server provides 12 tools")
+
+	// The README of the entry that fell below the threshold is removed
+	assert.NoFileExists(t, shrunk)
+
+	issues, err = CheckReadmes(loader)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestReadmeIssue_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "fetch: registry/fetch/README.md is out of date with its spec",
+		ReadmeIssue{Name: "fetch", Path: "registry/fetch/README.md"}.String())
+	assert.Equal(t, "fetch: registry/fetch/README.md is no longer needed",
+		ReadmeIssue{Name: "fetch", Path: "registry/fetch/README.md", Orphaned: true}.String())
+}
+
+// stripReadmeContent clears the regenerated content of issues so they can be compared
+func stripReadmeContent(issues []ReadmeIssue) []ReadmeIssue {
+	for i := range issues {
+		issues[i].content = ""
+	}
+	return issues
+}
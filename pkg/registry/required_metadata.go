@@ -0,0 +1,26 @@
+package registry
+
+import (
+	"sort"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// FindMissingMetadata returns the sorted names of image entries that were never
+// enriched with stars/pulls metadata. An entry counts as enriched when its
+// metadata has non-zero stars or a last_updated timestamp, which regup always sets.
+// Remote entries are skipped since they have no image to fetch pulls for.
+func FindMissingMetadata(entries map[string]*types.RegistryEntry) []string {
+	var missing []string
+	for name, entry := range entries {
+		if !entry.IsImage() {
+			continue
+		}
+		metadata := entry.ImageMetadata.Metadata
+		if metadata == nil || (metadata.Stars == 0 && metadata.LastUpdated == "") {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
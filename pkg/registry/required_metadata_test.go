@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestFindMissingMetadata(t *testing.T) {
+	t.Parallel()
+
+	imageEntry := func(metadata *toolhiveRegistry.Metadata) *types.RegistryEntry {
+		return &types.RegistryEntry{
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{Metadata: metadata},
+				Image:              "test/image:latest",
+			},
+		}
+	}
+
+	entries := map[string]*types.RegistryEntry{
+		"enriched":      imageEntry(&toolhiveRegistry.Metadata{Stars: 10, Pulls: 100, LastUpdated: "2025-01-01T00:00:00Z"}),
+		"no-stars":      imageEntry(&toolhiveRegistry.Metadata{LastUpdated: "2025-01-01T00:00:00Z"}),
+		"stars-only":    imageEntry(&toolhiveRegistry.Metadata{Stars: 3}),
+		"no-metadata":   imageEntry(nil),
+		"zero-metadata": imageEntry(&toolhiveRegistry.Metadata{Pulls: 5}),
+		"remote": {
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{URL: "https://api.example.com/mcp"},
+		},
+	}
+
+	assert.Equal(t, []string{"no-metadata", "zero-metadata"}, FindMissingMetadata(entries))
+	assert.Empty(t, FindMissingMetadata(map[string]*types.RegistryEntry{"enriched": entries["enriched"]}))
+}
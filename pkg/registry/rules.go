@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// ValidationRule is a policy check run on each entry after schema validation, for
+// requirements a registry has beyond what the format itself demands
+type ValidationRule interface {
+	// Validate returns an error if the named entry breaks the rule. Returning a *FieldError
+	// ties the failure to a field in diagnostics.
+	Validate(entry *types.RegistryEntry, name string) error
+}
+
+// RuleFunc adapts a function to a ValidationRule
+type RuleFunc func(entry *types.RegistryEntry, name string) error
+
+// Validate calls f
+func (f RuleFunc) Validate(entry *types.RegistryEntry, name string) error {
+	return f(entry, name)
+}
+
+// RequireRepositoryURL returns a rule that rejects entries of the given tiers without a
+// repository_url. With no tiers, it applies to every entry.
+func RequireRepositoryURL(tiers ...string) ValidationRule {
+	return RuleFunc(func(entry *types.RegistryEntry, name string) error {
+		if len(tiers) > 0 && !slices.Contains(tiers, entry.GetTier()) {
+			return nil
+		}
+		if entry.GetRepositoryURL() != "" {
+			return nil
+		}
+		return &FieldError{Entry: name, Field: "repository_url",
+			Err: fmt.Errorf("repository_url is required for %s entries", entry.GetTier())}
+	})
+}
+
+// AllowImageRegistries returns a rule that rejects image-based entries whose image isn't
+// hosted on one of the given registries, such as ghcr.io or docker.io
+func AllowImageRegistries(registries ...string) ValidationRule {
+	return RuleFunc(func(entry *types.RegistryEntry, name string) error {
+		if !entry.IsImage() {
+			return nil
+		}
+		ref, err := entry.ImageReference()
+		if err != nil {
+			// Schema validation reports the malformed image
+			return nil
+		}
+		if slices.Contains(registries, ref.Registry) {
+			return nil
+		}
+		return &FieldError{Entry: name, Field: "image",
+			Err: fmt.Errorf("image registry %s is not allowed (allowed: %s)", ref.Registry, strings.Join(registries, ", "))}
+	})
+}
+
+// builtinRules builds the built-in rules by name from their comma-separated arguments
+var builtinRules = map[string]func(args []string) (ValidationRule, error){
+	"require-repository-url": func(args []string) (ValidationRule, error) {
+		for _, tier := range args {
+			if !slices.Contains(tierOrder, tier) {
+				return nil, fmt.Errorf("invalid tier %q (supported: %s)", tier, strings.Join(tierOrder, ", "))
+			}
+		}
+		return RequireRepositoryURL(args...), nil
+	},
+	"allow-image-registries": func(args []string) (ValidationRule, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("at least one registry is required")
+		}
+		return AllowImageRegistries(args...), nil
+	},
+}
+
+// BuiltinRuleNames returns the names ParseRule accepts, sorted
+func BuiltinRuleNames() []string {
+	names := make([]string, 0, len(builtinRules))
+	for name := range builtinRules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// ParseRule returns the built-in rule a name=arg1,arg2 spec describes, for example
+// require-repository-url=Official or allow-image-registries=ghcr.io,docker.io
+func ParseRule(spec string) (ValidationRule, error) {
+	name, value, _ := strings.Cut(spec, "=")
+	build, ok := builtinRules[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown rule %q (supported: %s)", name, strings.Join(BuiltinRuleNames(), ", "))
+	}
+
+	var args []string
+	for _, arg := range strings.Split(value, ",") {
+		if arg = strings.TrimSpace(arg); arg != "" {
+			args = append(args, arg)
+		}
+	}
+	rule, err := build(args)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule %s: %w", name, err)
+	}
+	return rule, nil
+}
@@ -0,0 +1,230 @@
+package registry
+
+import (
+	"errors"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// officialLicenseRule is a custom rule that rejects Official entries without a license
+var officialLicenseRule = RuleFunc(func(entry *types.RegistryEntry, name string) error {
+	if entry.GetTier() == "Official" && entry.License == "" {
+		return &FieldError{Entry: name, Field: "license", Err: errors.New("license is required for Official entries")}
+	}
+	return nil
+})
+
+func ruleEntry(tier, license, image, repositoryURL string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description:   "Example server",
+				Tier:          tier,
+				Status:        "Active",
+				Transport:     "stdio",
+				Tools:         []string{"tool1"},
+				RepositoryURL: repositoryURL,
+			},
+			Image: image,
+		},
+		License: license,
+	}
+}
+
+func TestSchemaValidator_CustomRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		entry   *types.RegistryEntry
+		wantErr string
+	}{
+		{name: "official with license", entry: ruleEntry("Official", "MIT", "test/example:1.0.0", "")},
+		{
+			name:    "official without license",
+			entry:   ruleEntry("Official", "", "test/example:1.0.0", ""),
+			wantErr: "entry 'example': license is required for Official entries",
+		},
+		{name: "community without license", entry: ruleEntry("Community", "", "test/example:1.0.0", "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			validator := NewSchemaValidator()
+			validator.AddRule(officialLicenseRule)
+			err := validator.ValidateComplete(tt.entry, "example")
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Equal(t, tt.wantErr, err.Error())
+			assert.Equal(t, []Diagnostic{{
+				Entry: "example", Field: "license", Message: "license is required for Official entries", Severity: SeverityError,
+			}}, Diagnostics("example", err))
+		})
+	}
+}
+
+func TestSchemaValidator_RulesReportEveryFailure(t *testing.T) {
+	t.Parallel()
+
+	validator := NewSchemaValidator()
+	validator.AddRule(officialLicenseRule, RequireRepositoryURL("Official"), AllowImageRegistries("ghcr.io"))
+	err := validator.ValidateComplete(ruleEntry("Official", "", "test/example:1.0.0", ""), "example")
+
+	var fields []string
+	for _, diagnostic := range Diagnostics("example", err) {
+		fields = append(fields, diagnostic.Field)
+	}
+	assert.Equal(t, []string{"license", "repository_url", "image"}, fields)
+}
+
+func TestSchemaValidator_RulesSkippedForInvalidEntry(t *testing.T) {
+	t.Parallel()
+
+	called := false
+	validator := NewSchemaValidator()
+	validator.AddRule(RuleFunc(func(*types.RegistryEntry, string) error {
+		called = true
+		return nil
+	}))
+	entry := ruleEntry("Official", "MIT", "test/example:1.0.0", "")
+	entry.ImageMetadata.Description = ""
+
+	assert.Error(t, validator.ValidateComplete(entry, "example"))
+	assert.False(t, called)
+}
+
+func TestBuiltinRules(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		rule    ValidationRule
+		entry   *types.RegistryEntry
+		wantErr string
+	}{
+		{
+			name:    "repository url missing",
+			rule:    RequireRepositoryURL(),
+			entry:   ruleEntry("Community", "", "test/example:1.0.0", ""),
+			wantErr: "repository_url is required for Community entries",
+		},
+		{
+			name:  "repository url set",
+			rule:  RequireRepositoryURL(),
+			entry: ruleEntry("Community", "", "test/example:1.0.0", "https://github.com/example/example"),
+		},
+		{
+			name:  "repository url missing outside tiers",
+			rule:  RequireRepositoryURL("Official"),
+			entry: ruleEntry("Community", "", "test/example:1.0.0", ""),
+		},
+		{
+			name:  "allowed registry",
+			rule:  AllowImageRegistries("ghcr.io"),
+			entry: ruleEntry("Community", "", "ghcr.io/example/example:1.0.0", ""),
+		},
+		{
+			name:  "docker hub short name",
+			rule:  AllowImageRegistries("docker.io"),
+			entry: ruleEntry("Community", "", "example/example:1.0.0", ""),
+		},
+		{
+			name:    "registry not allowed",
+			rule:    AllowImageRegistries("ghcr.io", "quay.io"),
+			entry:   ruleEntry("Community", "", "docker.io/example/example:1.0.0", ""),
+			wantErr: "image registry docker.io is not allowed (allowed: ghcr.io, quay.io)",
+		},
+		{
+			name: "remote server",
+			rule: AllowImageRegistries("ghcr.io"),
+			entry: &types.RegistryEntry{RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				URL: "https://mcp.example.com",
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tt.rule.Validate(tt.entry, "example")
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var fieldErr *FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			assert.Equal(t, tt.wantErr, fieldErr.Err.Error())
+		})
+	}
+}
+
+func TestParseRule(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		spec    string
+		wantErr string
+	}{
+		{spec: "require-repository-url"},
+		{spec: "require-repository-url=Official, Community"},
+		{spec: "allow-image-registries=ghcr.io,docker.io"},
+		{spec: "require-repository-url=Gold", wantErr: `invalid rule require-repository-url: invalid tier "Gold"`},
+		{spec: "allow-image-registries", wantErr: "invalid rule allow-image-registries: at least one registry is required"},
+		{spec: "require-license", wantErr: `unknown rule "require-license" (supported: allow-image-registries, require-repository-url)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Parallel()
+
+			rule, err := ParseRule(tt.spec)
+			if tt.wantErr == "" {
+				require.NoError(t, err)
+				assert.NotNil(t, rule)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoader_AddRule(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	writeRegistryFile(t, registryDir, "licensed/spec.yaml", `description: Licensed server
+image: test/licensed:1.0.0
+transport: stdio
+tier: Official
+status: Active
+license: Apache-2.0
+tools:
+  - tool1
+`)
+	writeRegistryFile(t, registryDir, "unlicensed/spec.yaml", `description: Unlicensed server
+image: test/unlicensed:1.0.0
+transport: stdio
+tier: Official
+status: Active
+tools:
+  - tool1
+`)
+
+	loader := NewLoader(registryDir)
+	loader.AddRule(officialLicenseRule)
+	err := loader.LoadAll()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "entry 'unlicensed': license is required for Official entries")
+	assert.NotContains(t, err.Error(), "entry 'licensed'")
+}
@@ -2,20 +2,57 @@
 package registry
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
 
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	_ "github.com/santhosh-tekuri/jsonschema/v5/httploader" // enables loading schemas over http(s)
 	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
 
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+// DefaultSchemaURL is the schema declared by the ToolHive registry format.
+// Validation against this schema uses the copy embedded in the toolhive library.
+const DefaultSchemaURL = "https://raw.githubusercontent.com/stacklok/toolhive/main/pkg/registry/data/schema.json"
+
+// envReferencePattern matches ${VAR} (first group) and $VAR (second group) references in args.
+// Anything else with a $, such as $$ or $5, is a literal.
+var envReferencePattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
 // SchemaValidator provides comprehensive schema-based validation using the toolhive library
-type SchemaValidator struct{}
+type SchemaValidator struct {
+	// schemaURL is the schema complete registries are validated against
+	schemaURL string
+	// allowLatest accepts images pinned only to the latest tag
+	allowLatest bool
+	// allowInsecureURL accepts remote server URLs using http
+	allowInsecureURL bool
+	// strictEnv rejects env var names that don't follow shell conventions
+	strictEnv bool
+	// strictLicense rejects licenses that aren't in canonical SPDX form
+	strictLicense bool
+	// rules are run on entries that pass schema validation, in order
+	rules []ValidationRule
+}
 
 // NewSchemaValidator creates a new schema validator
 func NewSchemaValidator() *SchemaValidator {
-	return &SchemaValidator{}
+	return &SchemaValidator{schemaURL: DefaultSchemaURL}
+}
+
+// NewSchemaValidatorWithURL creates a schema validator that validates complete
+// registries against the schema at the given URL or file path
+func NewSchemaValidatorWithURL(schemaURL string) *SchemaValidator {
+	if schemaURL == "" {
+		schemaURL = DefaultSchemaURL
+	}
+	return &SchemaValidator{schemaURL: schemaURL}
 }
 
 // ValidateEntry validates a single registry entry using the toolhive schema
@@ -40,22 +77,46 @@ func (v *SchemaValidator) ValidateEntry(entry *types.RegistryEntry, name string)
 	return nil
 }
 
-// ValidateRegistry validates a complete registry using the toolhive schema
-func (*SchemaValidator) ValidateRegistry(registry *toolhiveRegistry.Registry) error {
+// ValidateRegistry validates a complete registry using the validator's schema
+func (v *SchemaValidator) ValidateRegistry(registry *toolhiveRegistry.Registry) error {
 	// Serialize to JSON for schema validation
 	registryJSON, err := json.Marshal(registry)
 	if err != nil {
 		return fmt.Errorf("failed to marshal registry for validation: %w", err)
 	}
 
-	// Use toolhive's schema validation
-	if err := toolhiveRegistry.ValidateRegistrySchema(registryJSON); err != nil {
+	// Use toolhive's embedded schema when targeting the default schema
+	if v.schemaURL == DefaultSchemaURL {
+		if err := toolhiveRegistry.ValidateRegistrySchema(registryJSON); err != nil {
+			return fmt.Errorf("registry schema validation failed: %w", err)
+		}
+		return nil
+	}
+
+	if err := validateAgainstSchemaURL(v.schemaURL, registryJSON); err != nil {
 		return fmt.Errorf("registry schema validation failed: %w", err)
 	}
 
 	return nil
 }
 
+// validateAgainstSchemaURL validates a JSON document against the schema at the given URL or file path
+func validateAgainstSchemaURL(schemaURL string, data []byte) error {
+	schema, err := jsonschema.Compile(schemaURL)
+	if err != nil {
+		return fmt.Errorf("failed to load schema %s: %w", schemaURL, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var doc interface{}
+	if err := decoder.Decode(&doc); err != nil {
+		return fmt.Errorf("failed to decode document for validation: %w", err)
+	}
+
+	return schema.Validate(doc)
+}
+
 // convertToToolhiveRegistry converts our RegistryEntry to a minimal toolhive Registry for validation
 func (*SchemaValidator) convertToToolhiveRegistry(entry *types.RegistryEntry, name string) (*toolhiveRegistry.Registry, error) {
 	registry := &toolhiveRegistry.Registry{
@@ -84,53 +145,281 @@ func (*SchemaValidator) convertToToolhiveRegistry(entry *types.RegistryEntry, na
 	return registry, nil
 }
 
-// ValidateEntryFields performs additional field-level validation beyond schema validation
-func (*SchemaValidator) ValidateEntryFields(entry *types.RegistryEntry, name string) error {
-	// Basic type validation
-	if entry.ImageMetadata == nil && entry.RemoteServerMetadata == nil {
-		return fmt.Errorf("entry '%s' must be either an image or remote server", name)
+// SetAllowLatest controls whether images tagged latest without a digest are accepted.
+// Images without any tag or digest are always rejected.
+func (v *SchemaValidator) SetAllowLatest(allow bool) {
+	v.allowLatest = allow
+}
+
+// SetAllowInsecureURL controls whether remote servers may use http URLs rather than https
+func (v *SchemaValidator) SetAllowInsecureURL(allow bool) {
+	v.allowInsecureURL = allow
+}
+
+// SetStrictEnv controls whether env var names that don't match ^[A-Z_][A-Z0-9_]*$ are errors.
+// Otherwise CheckEnvVarNames reports them as warnings.
+func (v *SchemaValidator) SetStrictEnv(strict bool) {
+	v.strictEnv = strict
+}
+
+// SetStrictLicense controls whether entries with a license that isn't in canonical SPDX form are invalid
+func (v *SchemaValidator) SetStrictLicense(strict bool) {
+	v.strictLicense = strict
+}
+
+// AddRule adds rules that ValidateComplete runs on entries that pass schema validation
+func (v *SchemaValidator) AddRule(rules ...ValidationRule) {
+	v.rules = append(v.rules, rules...)
+}
+
+// ValidateEntryFields performs additional field-level validation beyond schema validation.
+// Every failing field is reported, as a *FieldError joined with the others.
+func (v *SchemaValidator) ValidateEntryFields(entry *types.RegistryEntry, name string) error {
+	var errs []error
+	fail := func(field string, err error) {
+		errs = append(errs, &FieldError{Entry: name, Field: field, Err: err})
 	}
 
-	if entry.ImageMetadata != nil && entry.RemoteServerMetadata != nil {
-		return fmt.Errorf("entry '%s' cannot be both image and remote server", name)
+	// Basic type validation
+	if err := entry.ValidateServerType(); err != nil {
+		fail("", err)
 	}
 
 	// Image-specific validation
 	if entry.IsImage() {
 		if entry.Image == "" {
-			return fmt.Errorf("entry '%s': image field is required for image-based servers", name)
+			fail("image", errors.New("image field is required for image-based servers"))
+		} else if err := validateImagePinned(entry.Image, v.allowLatest); err != nil {
+			fail("image", err)
+		}
+
+		if err := validateArgEnvReferences(entry); err != nil {
+			fail("args", err)
+		}
+		if err := validateToolDiscoveryEnvReferences(entry); err != nil {
+			fail("tool_discovery.args", err)
 		}
 	}
 
 	// Remote-specific validation
 	if entry.IsRemote() {
 		if entry.URL == "" {
-			return fmt.Errorf("entry '%s': url field is required for remote servers", name)
+			fail("url", errors.New("url field is required for remote servers"))
+		} else if err := validateRemoteURL(entry.URL, v.allowInsecureURL); err != nil {
+			fail("url", fmt.Errorf("invalid url: %w", err))
 		}
 
 		// Remote servers cannot use stdio transport
 		if entry.GetTransport() == "stdio" {
-			return fmt.Errorf("entry '%s': remote servers cannot use stdio transport (use sse or streamable-http)", name)
+			fail("transport", errors.New("remote servers cannot use stdio transport (use sse or streamable-http)"))
 		}
 	}
 
 	// Common field validation
 	if entry.GetDescription() == "" {
-		return fmt.Errorf("entry '%s': description is required", name)
+		fail("description", errors.New("description is required"))
 	}
 
 	if entry.GetTransport() == "" {
-		return fmt.Errorf("entry '%s': transport is required", name)
+		fail("transport", errors.New("transport is required"))
+	} else if _, ok := types.NormalizeTransport(entry.GetTransport()); !ok {
+		fail("transport", fmt.Errorf("unknown transport %q (supported: %s)",
+			entry.GetTransport(), strings.Join(types.Transports, ", ")))
+	}
+
+	if v.strictEnv {
+		if invalid := InvalidEnvVarNames(entry); len(invalid) > 0 {
+			fail("env_vars", fmt.Errorf("env var names must match %s: %s", envVarNamePattern, strings.Join(invalid, ", ")))
+		}
 	}
 
 	if len(entry.GetTools()) == 0 {
-		return fmt.Errorf("entry '%s': at least one tool must be specified", name)
+		fail("tools", errors.New("at least one tool must be specified"))
+	}
+
+	if issues := CheckTags(entry.GetTags()); len(issues) > 0 {
+		fail("tags", fmt.Errorf("%s (run 'registry-builder lint --fix' to normalize tags)", issues[0]))
+	}
+
+	if v.strictLicense {
+		if issue := CheckLicense(entry.License); issue != nil {
+			if issue.Suggestion != "" {
+				fail("license", fmt.Errorf("%s (run 'registry-builder lint --fix' to normalize it)", issue))
+			} else {
+				fail("license", fmt.Errorf("%s (use an SPDX identifier, %s or %s)", issue, LicenseNone, LicenseNoAssertion))
+			}
+		}
+	}
+
+	if err := validateLinkURL(entry.Homepage); err != nil {
+		fail("homepage", fmt.Errorf("invalid homepage: %w", err))
+	}
+
+	if err := validateLinkURL(entry.DocumentationURL); err != nil {
+		fail("documentation_url", fmt.Errorf("invalid documentation_url: %w", err))
+	}
+
+	if err := validateStarsSource(entry.StarsSource); err != nil {
+		fail("stars_source", fmt.Errorf("invalid stars_source: %w", err))
+	}
+
+	if len(entry.Platforms) > 0 && !entry.IsImage() {
+		fail("platforms", errors.New("platforms can only be declared for image-based servers"))
+	} else if err := types.ValidatePlatforms(entry.Platforms); err != nil {
+		fail("platforms", fmt.Errorf("invalid platforms: %w", err))
+	}
+
+	if entry.ToolDiscovery != nil && !entry.IsImage() {
+		fail("tool_discovery", errors.New("tool_discovery can only be declared for image-based servers"))
+	}
+
+	if entry.PullsSource != "" {
+		if _, err := types.ParseImageReference(entry.PullsSource); err != nil {
+			fail("pulls_source", fmt.Errorf("invalid pulls_source: %w", err))
+		}
+	}
+
+	if entry.Deprecated != nil && strings.TrimSpace(entry.Deprecated.Reason) == "" {
+		fail("deprecated", errors.New("deprecated.reason is required"))
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateStarsSource checks that an optional stars source is the https URL of a repository,
+// on any host so GitHub Enterprise and self-hosted GitLab instances work too. Stars are
+// counted per repository, so a github.com URL can't point into one.
+func validateStarsSource(source string) error {
+	if source == "" {
+		return nil
 	}
 
+	if err := validateLinkURL(source); err != nil {
+		return err
+	}
+
+	host, path, ok := types.SplitRepositoryURL(source)
+	if !ok || (strings.EqualFold(host, types.GitHubHost) && strings.Count(path, "/") != 1) {
+		return fmt.Errorf("%q must be a repository URL, such as https://%s/<owner>/<repo> or https://%s/<group>/<project>",
+			source, types.GitHubHost, types.GitLabHost)
+	}
+
+	return nil
+}
+
+// validateLinkURL checks that an optional link is an absolute https URL
+func validateLinkURL(link string) error {
+	if link == "" {
+		return nil
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL %q: %w", link, err)
+	}
+
+	if parsed.Scheme != "https" || parsed.Host == "" {
+		return fmt.Errorf("%q must be an absolute https URL", link)
+	}
+
+	return nil
+}
+
+// validateRemoteURL checks that a remote server URL is an absolute https URL with a host.
+// With allowInsecure, http is accepted too.
+func validateRemoteURL(remoteURL string, allowInsecure bool) error {
+	parsed, err := url.Parse(remoteURL)
+	if err != nil {
+		return fmt.Errorf("failed to parse URL %q: %w", remoteURL, err)
+	}
+
+	switch {
+	case parsed.Scheme == "http" && !allowInsecure:
+		return fmt.Errorf("%q uses http (use https, or pass --allow-insecure-url)", remoteURL)
+	case parsed.Scheme != "https" && parsed.Scheme != "http":
+		return fmt.Errorf("%q must be an absolute https URL", remoteURL)
+	case parsed.Host == "" || parsed.Hostname() == "":
+		return fmt.Errorf("%q has no host", remoteURL)
+	}
+	return nil
+}
+
+// validateImagePinned checks that an image reference has a tag or digest, so builds are
+// reproducible. The latest tag is only accepted with allowLatest, unless a digest pins it.
+func validateImagePinned(image string, allowLatest bool) error {
+	ref, err := types.ParseImageReference(image)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case ref.Digest != "":
+		return nil
+	case ref.Tag == "":
+		return fmt.Errorf("image %q has no tag or digest (pin it with :<version> or @sha256:<digest>)", image)
+	case ref.Tag == "latest" && !allowLatest:
+		return fmt.Errorf("image %q uses the latest tag (pin a version or digest, or list the entry under allow_latest "+
+			"in registry.yaml)", image)
+	}
 	return nil
 }
 
-// ValidateComplete performs both schema validation and field validation
+// validateArgEnvReferences checks that env vars referenced in args are declared in env_vars
+func validateArgEnvReferences(entry *types.RegistryEntry) error {
+	declared := make(map[string]bool)
+	for _, envVar := range entry.ImageMetadata.EnvVars {
+		if envVar != nil {
+			declared[envVar.Name] = true
+		}
+	}
+
+	for _, arg := range entry.ImageMetadata.Args {
+		if name := undeclaredEnvReference(arg, declared); name != "" {
+			return fmt.Errorf("arg %q references undeclared env var %s", arg, name)
+		}
+	}
+
+	return nil
+}
+
+// validateToolDiscoveryEnvReferences checks that env vars referenced in tool_discovery.args are
+// declared in env_vars or set by tool_discovery.env
+func validateToolDiscoveryEnvReferences(entry *types.RegistryEntry) error {
+	if entry.ToolDiscovery == nil {
+		return nil
+	}
+
+	declared := make(map[string]bool)
+	for _, envVar := range entry.ImageMetadata.EnvVars {
+		if envVar != nil {
+			declared[envVar.Name] = true
+		}
+	}
+	for name := range entry.ToolDiscovery.Env {
+		declared[name] = true
+	}
+
+	for _, arg := range entry.ToolDiscovery.Args {
+		if name := undeclaredEnvReference(arg, declared); name != "" {
+			return fmt.Errorf("tool_discovery arg %q references undeclared env var %s", arg, name)
+		}
+	}
+
+	return nil
+}
+
+// undeclaredEnvReference returns the first env var arg references that isn't declared, or ""
+func undeclaredEnvReference(arg string, declared map[string]bool) string {
+	for _, match := range envReferencePattern.FindAllStringSubmatch(arg, -1) {
+		name := match[1] + match[2]
+		if !declared[name] {
+			return name
+		}
+	}
+	return ""
+}
+
+// ValidateComplete performs field validation, schema validation and then the added rules
 func (v *SchemaValidator) ValidateComplete(entry *types.RegistryEntry, name string) error {
 	// First perform field validation
 	if err := v.ValidateEntryFields(entry, name); err != nil {
@@ -138,5 +427,16 @@ func (v *SchemaValidator) ValidateComplete(entry *types.RegistryEntry, name stri
 	}
 
 	// Then perform schema validation
-	return v.ValidateEntry(entry, name)
+	if err := v.ValidateEntry(entry, name); err != nil {
+		return err
+	}
+
+	// Finally run the configured rules, reporting every one the entry breaks
+	var errs []error
+	for _, rule := range v.rules {
+		if err := rule.Validate(entry, name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
 }
@@ -0,0 +1,171 @@
+package registry
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// DefaultSourceTimeout bounds how long downloading a registry tarball may take
+const DefaultSourceTimeout = 5 * time.Minute
+
+// DefaultMaxSourceSize bounds the size of a downloaded registry tarball, and separately
+// the size of its content once decompressed
+const DefaultMaxSourceSize = 256 << 20
+
+// SourceFetcher fetches a registry from a git repository or a tarball URL into a temporary
+// directory, so it can be loaded without cloning the repository first
+type SourceFetcher struct {
+	client *http.Client
+	// maxSize bounds the bytes downloaded, and the bytes extracted, from a tarball
+	maxSize int64
+}
+
+// NewSourceFetcher creates a SourceFetcher whose downloads time out after timeout
+func NewSourceFetcher(timeout time.Duration) *SourceFetcher {
+	return &SourceFetcher{
+		client:  &http.Client{Timeout: timeout},
+		maxSize: DefaultMaxSourceSize,
+	}
+}
+
+// SetMaxSize sets how many bytes a tarball may have, both as downloaded and once decompressed
+func (f *SourceFetcher) SetMaxSize(size int64) {
+	f.maxSize = size
+}
+
+// IsRemoteSource returns true if source is a git or tarball URL rather than a local path
+func IsRemoteSource(source string) bool {
+	return isGitSource(source) || isTarballSource(source)
+}
+
+// Fetch fetches source into a temporary directory and returns the directory along with a
+// function that removes it. A git source is a repository URL ending in .git or using the
+// git://, ssh:// or file:// scheme, optionally followed by #ref to check out a branch or tag.
+// A tarball source is an http(s) URL of a tar or gzipped tar archive; when the archive
+// holds a single top-level directory, as GitHub's do, that directory is returned.
+func (f *SourceFetcher) Fetch(ctx context.Context, source string) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "registry-source-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	cleanup := func() { _ = os.RemoveAll(tmpDir) }
+
+	var dir string
+	switch {
+	case isGitSource(source):
+		dir, err = f.fetchGit(ctx, source, tmpDir)
+	case isTarballSource(source):
+		dir, err = f.fetchTarball(ctx, source, tmpDir)
+	default:
+		err = fmt.Errorf("unsupported registry source %q: expected a git URL or an http(s) tarball URL", source)
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return dir, cleanup, nil
+}
+
+// fetchGit shallow clones a git repository into dir
+func (*SourceFetcher) fetchGit(ctx context.Context, source, dir string) (string, error) {
+	repoURL, ref, _ := strings.Cut(source, "#")
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if ref != "" {
+		args = append(args, "--branch", ref)
+	}
+	args = append(args, "--", repoURL, "repo")
+
+	if _, err := gitOutputContext(ctx, dir, args...); err != nil {
+		return "", fmt.Errorf("failed to clone %s: %w", repoURL, err)
+	}
+	return filepath.Join(dir, "repo"), nil
+}
+
+// fetchTarball downloads and extracts a tar or gzipped tar archive into dir
+func (f *SourceFetcher) fetchTarball(ctx context.Context, source, dir string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("failed to download %s: unexpected status %d", source, resp.StatusCode)
+	}
+
+	// Gzipped archives are recognized by their magic bytes, as URLs don't always say
+	body := bufio.NewReader(newSizeLimitedReader(resp.Body, f.maxSize, "download"))
+	var archive io.Reader = body
+	if magic, err := body.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return "", fmt.Errorf("failed to decompress %s: %w", source, err)
+		}
+		defer gz.Close()
+		archive = newSizeLimitedReader(gz, f.maxSize, "decompressed archive")
+	}
+
+	if err := extractTar(archive, dir); err != nil {
+		return "", fmt.Errorf("failed to extract %s: %w", source, err)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", dir, err)
+	}
+	if len(entries) == 1 && entries[0].IsDir() {
+		return filepath.Join(dir, entries[0].Name()), nil
+	}
+	return dir, nil
+}
+
+// sizeLimitedReader reads from a reader until more than limit bytes have been read, then fails
+type sizeLimitedReader struct {
+	r     io.Reader
+	read  int64
+	limit int64
+	what  string
+}
+
+// newSizeLimitedReader returns a reader that fails once more than limit bytes of what are read.
+// At most limit+1 bytes are read from r.
+func newSizeLimitedReader(r io.Reader, limit int64, what string) *sizeLimitedReader {
+	return &sizeLimitedReader{r: io.LimitReader(r, limit+1), limit: limit, what: what}
+}
+
+// Read reads from the underlying reader, failing if the limit is exceeded
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.read > l.limit {
+		return n, fmt.Errorf("%s exceeds the limit of %d bytes", l.what, l.limit)
+	}
+	return n, err
+}
+
+// isGitSource returns true if source looks like a git repository URL
+func isGitSource(source string) bool {
+	repoURL, _, _ := strings.Cut(source, "#")
+	for _, scheme := range []string{"git://", "ssh://", "file://", "git@"} {
+		if strings.HasPrefix(repoURL, scheme) {
+			return true
+		}
+	}
+	return strings.HasSuffix(repoURL, ".git") && strings.Contains(repoURL, "://")
+}
+
+// isTarballSource returns true if source is an http(s) URL that isn't a git repository
+func isTarballSource(source string) bool {
+	return !isGitSource(source) && (strings.HasPrefix(source, "https://") || strings.HasPrefix(source, "http://"))
+}
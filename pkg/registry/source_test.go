@@ -0,0 +1,211 @@
+package registry
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadSourceEntries(t *testing.T, registryDir string) []string {
+	t.Helper()
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+	var names []string
+	for name := range loader.GetEntries() {
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestSourceFetcher_Git(t *testing.T) {
+	t.Parallel()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := t.TempDir()
+	writeSpec(t, filepath.Join(repoDir, "registry"), "server1", "First server")
+	runGit(t, repoDir, "init", "-q")
+	runGit(t, repoDir, "add", "-A")
+	runGit(t, repoDir, "commit", "-q", "-m", "initial")
+	runGit(t, repoDir, "tag", "v1")
+	writeSpec(t, filepath.Join(repoDir, "registry"), "server2", "Second server")
+	runGit(t, repoDir, "add", "-A")
+	runGit(t, repoDir, "commit", "-q", "-m", "second")
+
+	bareDir := filepath.Join(t.TempDir(), "registry.git")
+	runGit(t, repoDir, "clone", "-q", "--bare", repoDir, bareDir)
+
+	fetcher := NewSourceFetcher(time.Minute)
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{name: "default branch", source: "file://" + bareDir, want: []string{"server1", "server2"}},
+		{name: "tag", source: "file://" + bareDir + "#v1", want: []string{"server1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup, err := fetcher.Fetch(context.Background(), tt.source)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, tt.want, loadSourceEntries(t, filepath.Join(dir, "registry")))
+
+			cleanup()
+			_, err = os.Stat(dir)
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+
+	_, _, err := fetcher.Fetch(context.Background(), "file://"+bareDir+"#does-not-exist")
+	assert.Error(t, err)
+}
+
+func writeTarball(t *testing.T, files map[string]string, gzipped bool) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var gz *gzip.Writer
+	tw := tar.NewWriter(&buf)
+	if gzipped {
+		gz = gzip.NewWriter(&buf)
+		tw = tar.NewWriter(gz)
+	}
+
+	for name, content := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Name: name, Mode: 0600, Size: int64(len(content)), Typeflag: tar.TypeReg,
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	if gz != nil {
+		require.NoError(t, gz.Close())
+	}
+	return buf.Bytes()
+}
+
+func TestSourceFetcher_Tarball(t *testing.T) {
+	t.Parallel()
+
+	spec := "description: First server\nimage: test/server1:1.0\ntransport: stdio\ntier: Community\nstatus: Active\ntools:\n  - tool1\n"
+	archives := map[string][]byte{
+		// GitHub's tarballs hold a single top-level directory
+		"/github.tar.gz": writeTarball(t, map[string]string{"toolhive-registry-abc123/registry/server1/spec.yaml": spec}, true),
+		"/plain.tar": writeTarball(t, map[string]string{
+			"README.md":                  "# Registry\n",
+			"registry/server1/spec.yaml": spec,
+		}, false),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		archive, ok := archives[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(archive)
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher := NewSourceFetcher(time.Minute)
+	for _, path := range []string{"/github.tar.gz", "/plain.tar"} {
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup, err := fetcher.Fetch(context.Background(), server.URL+path)
+			require.NoError(t, err)
+			defer cleanup()
+			assert.Equal(t, []string{"server1"}, loadSourceEntries(t, filepath.Join(dir, "registry")))
+		})
+	}
+
+	t.Run("missing", func(t *testing.T) {
+		t.Parallel()
+
+		_, _, err := fetcher.Fetch(context.Background(), server.URL+"/missing.tar.gz")
+		assert.ErrorContains(t, err, "unexpected status 404")
+	})
+}
+
+func TestSourceFetcher_TarballSizeLimit(t *testing.T) {
+	t.Parallel()
+
+	// The bomb compresses a megabyte of zeros into a download well under the limit
+	archives := map[string][]byte{
+		"/large.tar":   writeTarball(t, map[string]string{"registry/large.txt": strings.Repeat("x", 64<<10)}, false),
+		"/bomb.tar.gz": writeTarball(t, map[string]string{"registry/bomb.txt": strings.Repeat("\x00", 1<<20)}, true),
+	}
+	require.Less(t, len(archives["/bomb.tar.gz"]), 32<<10)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(archives[r.URL.Path])
+	}))
+	t.Cleanup(server.Close)
+
+	fetcher := NewSourceFetcher(time.Minute)
+	fetcher.SetMaxSize(32 << 10)
+
+	tests := []struct {
+		path    string
+		wantErr string
+	}{
+		{path: "/large.tar", wantErr: "download exceeds the limit of 32768 bytes"},
+		{path: "/bomb.tar.gz", wantErr: "decompressed archive exceeds the limit of 32768 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			t.Parallel()
+
+			_, _, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+
+	// Archives within the limit are fetched
+	dir, cleanup, err := NewSourceFetcher(time.Minute).Fetch(context.Background(), server.URL+"/bomb.tar.gz")
+	require.NoError(t, err)
+	defer cleanup()
+	assert.FileExists(t, filepath.Join(dir, "bomb.txt"))
+}
+
+func TestIsRemoteSource(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source string
+		want   bool
+	}{
+		{source: "https://github.com/stacklok/toolhive-registry.git", want: true},
+		{source: "https://github.com/stacklok/toolhive-registry.git#v1.0.0", want: true},
+		{source: "git@github.com:stacklok/toolhive-registry.git", want: true},
+		{source: "file:///srv/registry.git", want: true},
+		{source: "https://example.com/registry.tar.gz", want: true},
+		{source: "./registry", want: false},
+		{source: "/srv/registry", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, IsRemoteSource(tt.source))
+		})
+	}
+
+	_, _, err := NewSourceFetcher(time.Minute).Fetch(context.Background(), "./registry")
+	assert.ErrorContains(t, err, "unsupported registry source")
+}
@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+)
+
+// SplitByTier splits the build output into one registry file per tier
+const SplitByTier = "tier"
+
+// TierFilename returns the name of the file that holds the entries of a tier when the
+// build output is split by tier, such as registry-official.json
+func TierFilename(tier string) string {
+	return "registry-" + strings.ToLower(tier) + ".json"
+}
+
+// WriteTierJSON writes the entries of each tier to <outputDir>/registry-<tier>.json, in the
+// same form as WriteJSON, and returns the paths written. A file is written for every
+// standard tier even if no entry has it, so consumers can rely on it existing.
+func (b *Builder) WriteTierJSON(outputDir string) ([]string, error) {
+	registry, err := b.BuildRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build registry: %w", err)
+	}
+	config, err := b.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := os.MkdirAll(outputDir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	var paths []string
+	for _, tier := range registryTiers(registry) {
+		path := filepath.Join(outputDir, TierFilename(tier))
+		if err := writeJSONFile(path, b.buildOutput(filterTier(registry, tier), b.schemaURL, config)); err != nil {
+			return nil, fmt.Errorf("failed to write %s tier: %w", tier, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// registryTiers returns the standard tiers followed by any other tiers of the built
+// servers, in catalog order
+func registryTiers(registry *toolhiveRegistry.Registry) []string {
+	tiers := slices.Clone(tierOrder)
+	for _, server := range registry.Servers {
+		if !slices.Contains(tiers, server.Tier) {
+			tiers = append(tiers, server.Tier)
+		}
+	}
+	for _, server := range registry.RemoteServers {
+		if !slices.Contains(tiers, server.Tier) {
+			tiers = append(tiers, server.Tier)
+		}
+	}
+	slices.Sort(tiers[len(tierOrder):])
+	return tiers
+}
+
+// filterTier returns a copy of the built registry with only the servers of a tier
+func filterTier(registry *toolhiveRegistry.Registry, tier string) *toolhiveRegistry.Registry {
+	filtered := &toolhiveRegistry.Registry{
+		Version:       registry.Version,
+		LastUpdated:   registry.LastUpdated,
+		Servers:       make(map[string]*toolhiveRegistry.ImageMetadata),
+		RemoteServers: make(map[string]*toolhiveRegistry.RemoteServerMetadata),
+	}
+	for name, server := range registry.Servers {
+		if server.Tier == tier {
+			filtered.Servers[name] = server
+		}
+	}
+	for name, server := range registry.RemoteServers {
+		if server.Tier == tier {
+			filtered.RemoteServers[name] = server
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,110 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func tierImageEntry(tier string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description: "Test server",
+				Tier:        tier,
+				Transport:   "stdio",
+				Tools:       []string{"test-tool"},
+			},
+			Image: "test/image:1.0.0",
+		},
+	}
+}
+
+func TestBuilder_WriteTierJSON(t *testing.T) {
+	t.Parallel()
+	outputDir := t.TempDir()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"official-image": tierImageEntry("Official"),
+		"official-remote": {
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Remote server",
+					Tier:        "Official",
+					Transport:   "streamable-http",
+					Tools:       []string{"test-tool"},
+				},
+				URL: "https://mcp.example.com",
+			},
+		},
+		"community-image": tierImageEntry("Community"),
+		// Entries without a tier get the default tier
+		"untiered-image":     tierImageEntry(""),
+		"experimental-image": tierImageEntry("Experimental"),
+	}
+
+	paths, err := NewBuilder(loader).WriteTierJSON(outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(outputDir, "registry-official.json"),
+		filepath.Join(outputDir, "registry-community.json"),
+		filepath.Join(outputDir, "registry-experimental.json"),
+	}, paths)
+
+	tests := []struct {
+		file          string
+		servers       []string
+		remoteServers []string
+	}{
+		{file: "registry-official.json", servers: []string{"official-image"}, remoteServers: []string{"official-remote"}},
+		{file: "registry-community.json", servers: []string{"community-image", "untiered-image"}},
+		{file: "registry-experimental.json", servers: []string{"experimental-image"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			t.Parallel()
+
+			data, err := os.ReadFile(filepath.Join(outputDir, tt.file))
+			require.NoError(t, err)
+			var output struct {
+				Schema        string                    `json:"$schema"`
+				Servers       map[string]map[string]any `json:"servers"`
+				RemoteServers map[string]map[string]any `json:"remote_servers"`
+			}
+			require.NoError(t, json.Unmarshal(data, &output))
+
+			assert.Equal(t, DefaultSchemaURL, output.Schema)
+			assert.ElementsMatch(t, tt.servers, sortedKeys(output.Servers))
+			assert.ElementsMatch(t, tt.remoteServers, sortedKeys(output.RemoteServers))
+		})
+	}
+}
+
+func TestBuilder_WriteTierJSONWritesEmptyStandardTiers(t *testing.T) {
+	t.Parallel()
+	outputDir := t.TempDir()
+
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{"community-image": tierImageEntry("Community")}
+
+	_, err := NewBuilder(loader).WriteTierJSON(outputDir)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(filepath.Join(outputDir, "registry-official.json"))
+	require.NoError(t, err)
+	var output struct {
+		Servers map[string]any `json:"servers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &output))
+	assert.NotNil(t, output.Servers)
+	assert.Empty(t, output.Servers)
+}
@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"sort"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// RegistryStats summarizes the composition of a registry
+type RegistryStats struct {
+	Total int `json:"total"`
+	// Tools is the number of tools declared across all entries
+	Tools      int            `json:"tools"`
+	Types      map[string]int `json:"types"`
+	Tiers      map[string]int `json:"tiers"`
+	Statuses   map[string]int `json:"statuses"`
+	Transports map[string]int `json:"transports"`
+	Tags       map[string]int `json:"tags"`
+	// MissingRepositoryURL and MissingLicense count the entries without those fields
+	MissingRepositoryURL int `json:"missing_repository_url"`
+	MissingLicense       int `json:"missing_license"`
+}
+
+// StatCount is the number of entries with a value, such as a tier or a tag
+type StatCount struct {
+	Value string
+	Count int
+}
+
+// ComputeStats counts the entries by type, tier, status, transport and tag. Entries without
+// a tier or status count as Community and Active, as in the catalog, and transports are
+// counted under their canonical names.
+func ComputeStats(entries map[string]*types.RegistryEntry) RegistryStats {
+	stats := RegistryStats{
+		Total:      len(entries),
+		Types:      map[string]int{"container": 0, "remote": 0},
+		Tiers:      map[string]int{},
+		Statuses:   map[string]int{},
+		Transports: map[string]int{},
+		Tags:       map[string]int{},
+	}
+
+	for _, entry := range entries {
+		if entry.IsRemote() {
+			stats.Types["remote"]++
+		} else {
+			stats.Types["container"]++
+		}
+
+		tier := entry.GetTier()
+		if tier == "" {
+			tier = "Community"
+		}
+		stats.Tiers[tier]++
+
+		status := entry.GetStatus()
+		if status == "" {
+			status = "Active"
+		}
+		stats.Statuses[status]++
+
+		transport, _ := types.NormalizeTransport(entry.GetTransport())
+		stats.Transports[transport]++
+
+		for _, tag := range entry.GetTags() {
+			stats.Tags[tag]++
+		}
+
+		stats.Tools += len(entry.GetTools())
+		if entry.GetRepositoryURL() == "" {
+			stats.MissingRepositoryURL++
+		}
+		if entry.License == "" {
+			stats.MissingLicense++
+		}
+	}
+
+	return stats
+}
+
+// SortedCounts returns the values of a breakdown from the most to the least common,
+// with ties sorted by value
+func SortedCounts(counts map[string]int) []StatCount {
+	sorted := make([]StatCount, 0, len(counts))
+	for _, value := range sortedKeys(counts) {
+		sorted = append(sorted, StatCount{Value: value, Count: counts[value]})
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Count > sorted[j].Count
+	})
+	return sorted
+}
@@ -0,0 +1,89 @@
+package registry
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	for name, spec := range map[string]string{
+		"github": `image: ghcr.io/github/github-mcp-server:1.0.0
+description: GitHub integration
+transport: stdio
+tier: Official
+status: Active
+repository_url: https://github.com/github/github-mcp-server
+license: MIT
+tags: [git, scm]
+tools: [create_issue, list_issues, search_code]
+`,
+		"gitlab": `image: test/gitlab:1.0.0
+description: GitLab integration
+transport: stdio
+tier: Community
+status: Active
+license: Apache-2.0
+tags: [git]
+tools: [list_projects]
+`,
+		"notion": `url: https://mcp.notion.com/mcp
+description: Notion workspace
+transport: http
+tier: Official
+status: Active
+repository_url: https://github.com/makenotion/notion-mcp-server
+tags: [docs]
+tools: [search, fetch]
+`,
+		"legacy": `url: https://legacy.example.com/sse
+description: Legacy server
+transport: sse
+tier: Community
+status: Deprecated
+tools: [query]
+`,
+	} {
+		writeRegistryFile(t, registryDir, filepath.Join(name, "spec.yaml"), spec)
+	}
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+
+	assert.Equal(t, RegistryStats{
+		Total:                4,
+		Tools:                7,
+		Types:                map[string]int{"container": 2, "remote": 2},
+		Tiers:                map[string]int{"Official": 2, "Community": 2},
+		Statuses:             map[string]int{"Active": 3, "Deprecated": 1},
+		Transports:           map[string]int{"stdio": 2, "streamable-http": 1, "sse": 1},
+		Tags:                 map[string]int{"git": 2, "scm": 1, "docs": 1},
+		MissingRepositoryURL: 2,
+		MissingLicense:       2,
+	}, ComputeStats(loader.GetEntries()))
+}
+
+func TestComputeStats_Empty(t *testing.T) {
+	t.Parallel()
+
+	stats := ComputeStats(nil)
+	assert.Equal(t, 0, stats.Total)
+	assert.Equal(t, map[string]int{"container": 0, "remote": 0}, stats.Types)
+	assert.Empty(t, stats.Tiers)
+}
+
+func TestSortedCounts(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []StatCount{
+		{Value: "git", Count: 3},
+		{Value: "docs", Count: 1},
+		{Value: "scm", Count: 1},
+	}, SortedCounts(map[string]int{"scm": 1, "git": 3, "docs": 1}))
+	assert.Empty(t, SortedCounts(nil))
+}
@@ -0,0 +1,194 @@
+package registry
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// TagIssue describes a malformed tag and its suggested normalized form
+type TagIssue struct {
+	Tag        string `json:"tag"`
+	Suggestion string `json:"suggestion"`
+}
+
+// String returns a human-readable description of the issue
+func (i TagIssue) String() string {
+	if i.Suggestion == "" {
+		return fmt.Sprintf("tag %q is malformed and should be removed", i.Tag)
+	}
+	return fmt.Sprintf("tag %q is malformed (suggested: %q)", i.Tag, i.Suggestion)
+}
+
+// NormalizeTag returns the normalized form of a tag: lowercase, with surrounding
+// whitespace and punctuation removed and internal whitespace replaced by hyphens
+func NormalizeTag(tag string) string {
+	tag = strings.ToLower(tag)
+	tag = strings.Join(strings.Fields(tag), "-")
+	return strings.TrimFunc(tag, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// CheckTags returns an issue for every tag that differs from its normalized form
+func CheckTags(tags []string) []TagIssue {
+	var issues []TagIssue
+	for _, tag := range tags {
+		if normalized := NormalizeTag(tag); normalized != tag {
+			issues = append(issues, TagIssue{Tag: tag, Suggestion: normalized})
+		}
+	}
+	return issues
+}
+
+// CheckSpecTags reports the tag issues in a spec file without modifying it.
+// Unlike loading the entry, this works even when the entry fails validation.
+func CheckSpecTags(path string) ([]TagIssue, error) {
+	return normalizeSpecTags(path, false)
+}
+
+// FixSpecTags normalizes the tags in a spec file in place using the YAML node API,
+// preserving comments and the rest of the document. Tags that normalize to an empty
+// string or to a duplicate of an earlier tag are dropped. It returns the issues fixed.
+func FixSpecTags(path string) ([]TagIssue, error) {
+	return normalizeSpecTags(path, true)
+}
+
+// normalizeSpecTags finds tag issues in a spec file and, if write is set, fixes them in place
+func normalizeSpecTags(path string, write bool) ([]TagIssue, error) {
+	doc, err := readSpecNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tagsNode := specField(doc, "tags")
+	if tagsNode == nil || tagsNode.Kind != yaml.SequenceNode {
+		return nil, nil
+	}
+
+	var issues []TagIssue
+	seen := make(map[string]bool)
+	content := make([]*yaml.Node, 0, len(tagsNode.Content))
+	for _, item := range tagsNode.Content {
+		normalized := NormalizeTag(item.Value)
+		if normalized != item.Value {
+			issues = append(issues, TagIssue{Tag: item.Value, Suggestion: normalized})
+		}
+		if normalized == "" || seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		item.Value = normalized
+		item.Style = 0
+		content = append(content, item)
+	}
+
+	if !write || (len(issues) == 0 && len(content) == len(tagsNode.Content)) {
+		return issues, nil
+	}
+	tagsNode.Content = content
+
+	if err := writeSpecNode(path, doc); err != nil {
+		return nil, err
+	}
+
+	return issues, nil
+}
+
+// normalizeSpecScalar returns the value of a top-level scalar field of a spec file, or ""
+// if it isn't set. If write is set and normalize returns a different, non-empty value, the
+// field is rewritten to it in place, preserving comments and the rest of the document.
+func normalizeSpecScalar(path, field string, write bool, normalize func(string) string) (string, error) {
+	doc, err := readSpecNode(path)
+	if err != nil {
+		return "", err
+	}
+
+	node := specField(doc, field)
+	if node == nil || node.Kind != yaml.ScalarNode {
+		return "", nil
+	}
+
+	value := node.Value
+	normalized := normalize(value)
+	if !write || normalized == "" || normalized == value {
+		return value, nil
+	}
+
+	node.Value = normalized
+	node.Style = 0
+	if err := writeSpecNode(path, doc); err != nil {
+		return "", err
+	}
+
+	return value, nil
+}
+
+// readSpecNode parses a spec file into a YAML document node whose root is a mapping
+func readSpecNode(path string) (*yaml.Node, error) {
+	data, err := types.ReadSpecFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping at the document root")
+	}
+
+	return &doc, nil
+}
+
+// specField returns the value node of a top-level field in a spec document, or nil if it isn't set
+func specField(doc *yaml.Node, field string) *yaml.Node {
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == field {
+			return root.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SpecFieldLine returns the line of a top-level field in a spec file, or 0 if the
+// file can't be parsed or doesn't set the field
+func SpecFieldLine(path, field string) int {
+	doc, err := readSpecNode(path)
+	if err != nil {
+		return 0
+	}
+
+	root := doc.Content[0]
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == field {
+			return root.Content[i].Line
+		}
+	}
+	return 0
+}
+
+// writeSpecNode encodes a spec document back to its file, preserving comments
+func writeSpecNode(path string, doc *yaml.Node) error {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "database", want: "database"},
+		{tag: "convert_time", want: "convert_time"},
+		{tag: "machine learning", want: "machine-learning"},
+		{tag: "  spaced  ", want: "spaced"},
+		{tag: "GitHub", want: "github"},
+		{tag: "Cloud Storage", want: "cloud-storage"},
+		{tag: "-leading", want: "leading"},
+		{tag: "trailing.", want: "trailing"},
+		{tag: "#hashtag!", want: "hashtag"},
+		{tag: "---", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, NormalizeTag(tt.tag))
+		})
+	}
+}
+
+func TestCheckTags(t *testing.T) {
+	t.Parallel()
+
+	issues := CheckTags([]string{"database", "Machine Learning", "api"})
+	assert.Equal(t, []TagIssue{{Tag: "Machine Learning", Suggestion: "machine-learning"}}, issues)
+	assert.Empty(t, CheckTags([]string{"database", "api"}))
+}
+
+func TestFixSpecTags(t *testing.T) {
+	t.Parallel()
+
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	original := `# Header comment
+name: test
+description: Test server
+tags:
+  - Database
+  - machine learning
+  - database
+  - "---"
+  - api # keep this comment
+tools:
+  - tool1
+`
+	require.NoError(t, os.WriteFile(specPath, []byte(original), 0644))
+
+	issues, err := FixSpecTags(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, []TagIssue{
+		{Tag: "Database", Suggestion: "database"},
+		{Tag: "machine learning", Suggestion: "machine-learning"},
+		{Tag: "---", Suggestion: ""},
+	}, issues)
+
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, `# Header comment
+name: test
+description: Test server
+tags:
+  - database
+  - machine-learning
+  - api # keep this comment
+tools:
+  - tool1
+`, string(data))
+
+	// A second run finds nothing to fix
+	issues, err = FixSpecTags(specPath)
+	require.NoError(t, err)
+	assert.Empty(t, issues)
+}
+
+func TestCheckSpecTags_DoesNotModify(t *testing.T) {
+	t.Parallel()
+
+	specPath := filepath.Join(t.TempDir(), "spec.yaml")
+	original := "name: test\ntags:\n  - Database\n"
+	require.NoError(t, os.WriteFile(specPath, []byte(original), 0644))
+
+	issues, err := CheckSpecTags(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, []TagIssue{{Tag: "Database", Suggestion: "database"}}, issues)
+
+	data, err := os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(data))
+}
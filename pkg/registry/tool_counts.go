@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// minCategorySize is the number of entries a category needs before its
+// median tool count is considered meaningful for low-count detection
+const minCategorySize = 3
+
+// lowToolCountDivisor flags entries with fewer tools than the category median divided by this value
+const lowToolCountDivisor = 4
+
+// ToolCountIssue describes an entry whose tool count looks suspicious
+type ToolCountIssue struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	Reason  string `json:"reason"`
+	IsError bool   `json:"is_error"`
+}
+
+// String returns a human-readable description of the issue
+func (i ToolCountIssue) String() string {
+	return fmt.Sprintf("%s: %d tools (%s)", i.Name, i.Count, i.Reason)
+}
+
+// CheckToolCounts checks the tool count of every entry.
+// Entries with more than maxTools tools are reported as errors (maxTools <= 0 disables the check).
+// Entries with an unusually low count compared to other entries in the same
+// category (their first tag) are reported as warnings.
+func CheckToolCounts(entries map[string]*types.RegistryEntry, maxTools int) []ToolCountIssue {
+	var issues []ToolCountIssue
+
+	// Group tool counts by category
+	categoryCounts := make(map[string][]int)
+	for _, entry := range entries {
+		if category := entryCategory(entry); category != "" {
+			categoryCounts[category] = append(categoryCounts[category], len(entry.GetTools()))
+		}
+	}
+
+	medians := make(map[string]int)
+	for category, counts := range categoryCounts {
+		if len(counts) >= minCategorySize {
+			medians[category] = median(counts)
+		}
+	}
+
+	// Check entries in alphabetical order so output is stable
+	var names []string
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		entry := entries[name]
+		count := len(entry.GetTools())
+
+		if maxTools > 0 && count > maxTools {
+			issues = append(issues, ToolCountIssue{
+				Name:    name,
+				Count:   count,
+				Reason:  fmt.Sprintf("exceeds maximum of %d", maxTools),
+				IsError: true,
+			})
+			continue
+		}
+
+		category := entryCategory(entry)
+		if m, ok := medians[category]; ok && count > 0 && count < m/lowToolCountDivisor {
+			issues = append(issues, ToolCountIssue{
+				Name:   name,
+				Count:  count,
+				Reason: fmt.Sprintf("unusually low for category %q (median %d)", category, m),
+			})
+		}
+	}
+
+	return issues
+}
+
+// entryCategory returns the category used to compare tool counts, which is the entry's first tag
+func entryCategory(entry *types.RegistryEntry) string {
+	tags := entry.GetTags()
+	if len(tags) == 0 {
+		return ""
+	}
+	return tags[0]
+}
+
+// median returns the median of the given values
+func median(values []int) int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	return sorted[len(sorted)/2]
+}
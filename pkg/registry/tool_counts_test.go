@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"fmt"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func newEntryWithTools(count int, tags ...string) *types.RegistryEntry {
+	tools := make([]string, count)
+	for i := range tools {
+		tools[i] = fmt.Sprintf("tool%d", i)
+	}
+	return &types.RegistryEntry{
+		ImageMetadata: &toolhiveRegistry.ImageMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+				Description: "Test server",
+				Transport:   "stdio",
+				Tools:       tools,
+				Tags:        tags,
+			},
+			Image: "test/image:latest",
+		},
+	}
+}
+
+func TestCheckToolCounts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		entries  map[string]*types.RegistryEntry
+		maxTools int
+		want     []ToolCountIssue
+	}{
+		{
+			name: "normal counts",
+			entries: map[string]*types.RegistryEntry{
+				"a": newEntryWithTools(5),
+				"b": newEntryWithTools(50),
+			},
+			maxTools: 100,
+		},
+		{
+			name: "over limit",
+			entries: map[string]*types.RegistryEntry{
+				"small": newEntryWithTools(5),
+				"huge":  newEntryWithTools(2000),
+			},
+			maxTools: 100,
+			want: []ToolCountIssue{
+				{Name: "huge", Count: 2000, Reason: "exceeds maximum of 100", IsError: true},
+			},
+		},
+		{
+			name: "limit disabled",
+			entries: map[string]*types.RegistryEntry{
+				"huge": newEntryWithTools(2000),
+			},
+			maxTools: 0,
+		},
+		{
+			name: "unusually low for category",
+			entries: map[string]*types.RegistryEntry{
+				"db1": newEntryWithTools(20, "database"),
+				"db2": newEntryWithTools(24, "database"),
+				"db3": newEntryWithTools(2, "database"),
+			},
+			want: []ToolCountIssue{
+				{Name: "db3", Count: 2, Reason: `unusually low for category "database" (median 20)`},
+			},
+		},
+		{
+			name: "small category is not compared",
+			entries: map[string]*types.RegistryEntry{
+				"db1": newEntryWithTools(20, "database"),
+				"db2": newEntryWithTools(1, "database"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, CheckToolCounts(tt.entries, tt.maxTools))
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// TransportIssue describes a transport that isn't written in its canonical form
+type TransportIssue struct {
+	Name       string `json:"name,omitempty"`
+	Transport  string `json:"transport"`
+	Suggestion string `json:"suggestion"`
+}
+
+// String returns a human-readable description of the issue
+func (i TransportIssue) String() string {
+	var message string
+	if i.Suggestion == "" {
+		message = fmt.Sprintf("transport %q is unknown (supported: %s)", i.Transport, strings.Join(types.Transports, ", "))
+	} else {
+		message = fmt.Sprintf("transport %q is not canonical (suggested: %q)", i.Transport, i.Suggestion)
+	}
+	if i.Name != "" {
+		return i.Name + ": " + message
+	}
+	return message
+}
+
+// CheckTransport returns an issue if a transport is set but isn't a canonical transport name
+func CheckTransport(transport string) *TransportIssue {
+	if transport == "" {
+		return nil
+	}
+	canonical, ok := types.NormalizeTransport(transport)
+	switch {
+	case !ok:
+		return &TransportIssue{Transport: transport}
+	case canonical != transport:
+		return &TransportIssue{Transport: transport, Suggestion: canonical}
+	}
+	return nil
+}
+
+// CheckTransportAliases returns an issue for every loaded entry whose spec declares its
+// transport with an alias or non-canonical casing that was normalized on load
+func CheckTransportAliases(entries map[string]*types.RegistryEntry) []TransportIssue {
+	var issues []TransportIssue
+	for _, name := range sortedKeys(entries) {
+		entry := entries[name]
+		if entry.DeclaredTransport == "" {
+			continue
+		}
+		issues = append(issues, TransportIssue{
+			Name:       name,
+			Transport:  entry.DeclaredTransport,
+			Suggestion: entry.GetTransport(),
+		})
+	}
+	return issues
+}
+
+// CheckSpecTransport reports the transport issue in a spec file without modifying it
+func CheckSpecTransport(path string) (*TransportIssue, error) {
+	return normalizeSpecTransport(path, false)
+}
+
+// FixSpecTransport rewrites the transport in a spec file to its canonical name in place,
+// preserving comments and the rest of the document. Unknown transports are left untouched.
+// It returns the issue found, if any.
+func FixSpecTransport(path string) (*TransportIssue, error) {
+	return normalizeSpecTransport(path, true)
+}
+
+// normalizeSpecTransport finds a transport issue in a spec file and, if write is set, fixes it in place
+func normalizeSpecTransport(path string, write bool) (*TransportIssue, error) {
+	transport, err := normalizeSpecScalar(path, "transport", write, func(transport string) string {
+		if canonical, ok := types.NormalizeTransport(transport); ok {
+			return canonical
+		}
+		return ""
+	})
+	if err != nil {
+		return nil, err
+	}
+	return CheckTransport(transport), nil
+}
@@ -0,0 +1,157 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func TestCheckTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		transport string
+		want      *TransportIssue
+	}{
+		{transport: ""},
+		{transport: "stdio"},
+		{transport: "sse"},
+		{transport: "streamable-http"},
+		{transport: "SSE", want: &TransportIssue{Transport: "SSE", Suggestion: "sse"}},
+		{transport: "Stdio", want: &TransportIssue{Transport: "Stdio", Suggestion: "stdio"}},
+		{transport: "http", want: &TransportIssue{Transport: "http", Suggestion: "streamable-http"}},
+		{transport: "streamable_http", want: &TransportIssue{Transport: "streamable_http", Suggestion: "streamable-http"}},
+		{transport: "streamablehttp", want: &TransportIssue{Transport: "streamablehttp", Suggestion: "streamable-http"}},
+		{transport: "streamable", want: &TransportIssue{Transport: "streamable", Suggestion: "streamable-http"}},
+		{transport: "server-sent-events", want: &TransportIssue{Transport: "server-sent-events", Suggestion: "sse"}},
+		{transport: "websocket", want: &TransportIssue{Transport: "websocket"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transport, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, CheckTransport(tt.transport))
+		})
+	}
+}
+
+func TestTransportIssue_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, `fetch: transport "http" is not canonical (suggested: "streamable-http")`,
+		TransportIssue{Name: "fetch", Transport: "http", Suggestion: "streamable-http"}.String())
+	assert.Equal(t, `transport "websocket" is unknown (supported: stdio, sse, streamable-http)`,
+		TransportIssue{Transport: "websocket"}.String())
+}
+
+func TestTransportAliasesAreNormalizedOnLoad(t *testing.T) {
+	t.Parallel()
+
+	registryDir := t.TempDir()
+	for name, transport := range map[string]string{"aliased": "http", "canonical": "streamable-http"} {
+		writeRegistryFile(t, registryDir, name+"/spec.yaml", `description: Test server
+transport: `+transport+`
+url: https://example.com/mcp
+tier: Community
+status: Active
+tools:
+  - tool1
+`)
+	}
+
+	loader := NewLoader(registryDir)
+	require.NoError(t, loader.LoadAll())
+
+	assert.Equal(t, []TransportIssue{{Name: "aliased", Transport: "http", Suggestion: "streamable-http"}},
+		CheckTransportAliases(loader.GetEntries()))
+
+	built, err := NewBuilder(loader).BuildRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "streamable-http", built.RemoteServers["aliased"].Transport)
+	assert.Equal(t, "streamable-http", built.RemoteServers["canonical"].Transport)
+}
+
+func TestBuilder_NormalizesTransport(t *testing.T) {
+	t.Parallel()
+
+	// Entries that weren't loaded from YAML are normalized when built
+	loader := NewLoader("")
+	loader.entries = map[string]*types.RegistryEntry{
+		"shouty": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Test server",
+					Transport:   "SSE",
+					Tools:       []string{"tool1"},
+				},
+				Image: "test/image:latest",
+			},
+		},
+	}
+
+	built, err := NewBuilder(loader).BuildRegistry()
+	require.NoError(t, err)
+	assert.Equal(t, "sse", built.Servers["shouty"].Transport)
+}
+
+func TestFixSpecTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		spec      string
+		wantIssue *TransportIssue
+		wantSpec  string
+	}{
+		{
+			name:      "alias is rewritten",
+			spec:      "# Header\ntransport: streamable_http # the only one\ntools:\n  - tool1\n",
+			wantIssue: &TransportIssue{Transport: "streamable_http", Suggestion: "streamable-http"},
+			wantSpec:  "# Header\ntransport: streamable-http # the only one\ntools:\n  - tool1\n",
+		},
+		{
+			name:      "casing is rewritten",
+			spec:      "transport: \"SSE\"\n",
+			wantIssue: &TransportIssue{Transport: "SSE", Suggestion: "sse"},
+			wantSpec:  "transport: sse\n",
+		},
+		{
+			name:      "unknown transport is left untouched",
+			spec:      "transport: websocket\n",
+			wantIssue: &TransportIssue{Transport: "websocket"},
+			wantSpec:  "transport: websocket\n",
+		},
+		{
+			name:     "canonical transport is left untouched",
+			spec:     "transport: stdio\n",
+			wantSpec: "transport: stdio\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			specPath := filepath.Join(t.TempDir(), "spec.yaml")
+			require.NoError(t, os.WriteFile(specPath, []byte(tt.spec), 0644))
+
+			checked, err := CheckSpecTransport(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIssue, checked)
+
+			fixed, err := FixSpecTransport(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantIssue, fixed)
+
+			data, err := os.ReadFile(specPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantSpec, string(data))
+		})
+	}
+}
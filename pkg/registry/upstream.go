@@ -0,0 +1,194 @@
+package registry
+
+import (
+	_ "embed" // for the bundled upstream server schema
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// UpstreamSchemaURL is the schema of a server in the upstream MCP registry format, as converted
+// to by the toolhive library. Validation against it uses the copy bundled with the registry builder.
+const UpstreamSchemaURL = "https://modelcontextprotocol.io/schemas/draft/2025-07-09/server.json"
+
+// UpstreamFile is the name of the registry written in the upstream MCP registry format
+const UpstreamFile = "mcp-registry.json"
+
+//go:embed upstream_server_schema.json
+var upstreamServerSchema string
+
+// upstreamOutput is the registry as written to mcp-registry.json
+type upstreamOutput struct {
+	Servers []*toolhiveRegistry.UpstreamServerDetail `json:"servers"`
+}
+
+// UpstreamValidator validates servers converted to the upstream MCP registry format against
+// the upstream server schema, the way SchemaValidator does for the toolhive format
+type UpstreamValidator struct {
+	schema *jsonschema.Schema
+}
+
+// NewUpstreamValidator creates a validator for the bundled upstream server schema
+func NewUpstreamValidator() (*UpstreamValidator, error) {
+	compiler := jsonschema.NewCompiler()
+	compiler.AssertFormat = true
+	if err := compiler.AddResource(UpstreamSchemaURL, strings.NewReader(upstreamServerSchema)); err != nil {
+		return nil, fmt.Errorf("failed to add upstream schema: %w", err)
+	}
+	schema, err := compiler.Compile(UpstreamSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile upstream schema: %w", err)
+	}
+	return &UpstreamValidator{schema: schema}, nil
+}
+
+// ValidateEntry converts a single registry entry to the upstream format and validates it
+func (v *UpstreamValidator) ValidateEntry(entry *types.RegistryEntry, name string) error {
+	var server toolhiveRegistry.ServerMetadata
+	switch {
+	case entry.IsImage():
+		metadata := *entry.ImageMetadata
+		server = &metadata
+	case entry.IsRemote():
+		metadata := *entry.RemoteServerMetadata
+		server = &metadata
+	default:
+		return fmt.Errorf("entry must be either image-based or remote server")
+	}
+
+	detail, err := convertToUpstream(name, server)
+	if err != nil {
+		return err
+	}
+	return v.ValidateServer(detail)
+}
+
+// ValidateServer validates a server in the upstream format against the upstream schema
+func (v *UpstreamValidator) ValidateServer(server *toolhiveRegistry.UpstreamServerDetail) error {
+	data, err := json.Marshal(server)
+	if err != nil {
+		return fmt.Errorf("failed to marshal server for validation: %w", err)
+	}
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to decode server for validation: %w", err)
+	}
+
+	if err := v.schema.Validate(doc); err != nil {
+		return fmt.Errorf("upstream schema validation failed for server '%s': %w", server.Server.Name, err)
+	}
+	return nil
+}
+
+// ValidateServers validates every server, reporting all that fail
+func (v *UpstreamValidator) ValidateServers(servers []*toolhiveRegistry.UpstreamServerDetail) error {
+	var errs []error
+	for _, server := range servers {
+		if err := v.ValidateServer(server); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// convertToUpstream converts a built server to the upstream format under its registry name
+func convertToUpstream(name string, server toolhiveRegistry.ServerMetadata) (*toolhiveRegistry.UpstreamServerDetail, error) {
+	// The built servers are keyed by name, and the converter only recognizes lower-case statuses
+	switch metadata := server.(type) {
+	case *toolhiveRegistry.ImageMetadata:
+		metadata.Name = name
+		metadata.Status = strings.ToLower(metadata.Status)
+	case *toolhiveRegistry.RemoteServerMetadata:
+		metadata.Name = name
+		metadata.Status = strings.ToLower(metadata.Status)
+	}
+
+	detail, err := toolhiveRegistry.ConvertToolhiveToUpstream(server)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %s to the upstream format: %w", name, err)
+	}
+	return detail, nil
+}
+
+// BuildUpstream returns the built servers converted to the upstream MCP registry format, sorted by name
+func (b *Builder) BuildUpstream() ([]*toolhiveRegistry.UpstreamServerDetail, error) {
+	registry, err := b.BuildRegistry()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build registry: %w", err)
+	}
+
+	servers := make(map[string]toolhiveRegistry.ServerMetadata, len(registry.Servers)+len(registry.RemoteServers))
+	for name, server := range registry.Servers {
+		metadata := *server
+		servers[name] = &metadata
+	}
+	for name, server := range registry.RemoteServers {
+		metadata := *server
+		servers[name] = &metadata
+	}
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	details := make([]*toolhiveRegistry.UpstreamServerDetail, 0, len(names))
+	for _, name := range names {
+		detail, err := convertToUpstream(name, servers[name])
+		if err != nil {
+			return nil, err
+		}
+		details = append(details, detail)
+	}
+	return details, nil
+}
+
+// ValidateAgainstUpstreamSchema validates the built servers, in the upstream MCP registry
+// format, against the upstream server schema
+func (b *Builder) ValidateAgainstUpstreamSchema() error {
+	servers, err := b.BuildUpstream()
+	if err != nil {
+		return err
+	}
+
+	validator, err := NewUpstreamValidator()
+	if err != nil {
+		return err
+	}
+	if err := validator.ValidateServers(servers); err != nil {
+		return fmt.Errorf("registry validation failed: %w", err)
+	}
+	return nil
+}
+
+// WriteUpstreamJSON writes the built servers in the upstream MCP registry format to a file,
+// creating its directory if needed
+func (b *Builder) WriteUpstreamJSON(path string) error {
+	servers, err := b.BuildUpstream()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(upstreamOutput{Servers: servers}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	return nil
+}
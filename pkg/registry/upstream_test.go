@@ -0,0 +1,180 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func upstreamTestEntries() map[string]*types.RegistryEntry {
+	return map[string]*types.RegistryEntry{
+		"fetch": {
+			ImageMetadata: &toolhiveRegistry.ImageMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description:   "Fetches web pages",
+					Tier:          "Official",
+					Status:        "Deprecated",
+					Transport:     "stdio",
+					Tools:         []string{"fetch"},
+					RepositoryURL: "https://github.com/example/fetch",
+				},
+				Image: "ghcr.io/example/fetch:1.2.0",
+				Args:  []string{"--verbose"},
+				EnvVars: []*toolhiveRegistry.EnvVar{
+					{Name: "API_KEY", Description: "API key for the service", Required: true, Secret: true},
+				},
+			},
+		},
+		"hosted": {
+			RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+				BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{
+					Description: "Hosted server",
+					Tier:        "Community",
+					Status:      "Active",
+					Transport:   "streamable-http",
+					Tools:       []string{"search"},
+				},
+				URL: "https://api.example.com/mcp",
+				Headers: []*toolhiveRegistry.Header{
+					{Name: "X-API-Key", Description: "API key header", Required: true, Secret: true},
+				},
+			},
+		},
+	}
+}
+
+func TestUpstreamValidator_ValidateEntry(t *testing.T) {
+	t.Parallel()
+
+	validator, err := NewUpstreamValidator()
+	require.NoError(t, err)
+
+	for name, entry := range upstreamTestEntries() {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			assert.NoError(t, validator.ValidateEntry(entry, name))
+			// The entry itself is left as loaded
+			assert.Empty(t, entry.GetName())
+		})
+	}
+
+	assert.Error(t, validator.ValidateEntry(&types.RegistryEntry{}, "empty"))
+}
+
+func TestUpstreamValidator_ValidateServer(t *testing.T) {
+	t.Parallel()
+
+	validator, err := NewUpstreamValidator()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		modify  func(*toolhiveRegistry.UpstreamServerDetail)
+		wantErr string
+	}{
+		{
+			name:   "valid",
+			modify: func(*toolhiveRegistry.UpstreamServerDetail) {},
+		},
+		{
+			name: "unknown transport type",
+			modify: func(detail *toolhiveRegistry.UpstreamServerDetail) {
+				detail.Server.Remotes[0].TransportType = "websocket"
+			},
+			wantErr: "transport_type",
+		},
+		{
+			name: "unknown status",
+			modify: func(detail *toolhiveRegistry.UpstreamServerDetail) {
+				detail.Server.Status = "retired"
+			},
+			wantErr: "status",
+		},
+		{
+			name: "invalid remote url",
+			modify: func(detail *toolhiveRegistry.UpstreamServerDetail) {
+				detail.Server.Remotes[0].URL = "not a url"
+			},
+			wantErr: "url",
+		},
+		{
+			name: "missing version",
+			modify: func(detail *toolhiveRegistry.UpstreamServerDetail) {
+				detail.Server.VersionDetail.Version = ""
+			},
+			wantErr: "version",
+		},
+		{
+			name: "header without a name",
+			modify: func(detail *toolhiveRegistry.UpstreamServerDetail) {
+				detail.Server.Remotes[0].Headers[0].Name = ""
+			},
+			wantErr: "headers",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := upstreamTestEntries()["hosted"]
+			metadata := *entry.RemoteServerMetadata
+			detail, err := convertToUpstream("hosted", &metadata)
+			require.NoError(t, err)
+			tt.modify(detail)
+
+			err = validator.ValidateServer(detail)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "upstream schema validation failed for server 'hosted'")
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestBuilder_WriteUpstreamJSON(t *testing.T) {
+	t.Parallel()
+
+	loader := NewLoader("")
+	loader.entries = upstreamTestEntries()
+	builder := NewBuilder(loader)
+	require.NoError(t, builder.ValidateAgainstUpstreamSchema())
+
+	path := filepath.Join(t.TempDir(), "build", UpstreamFile)
+	require.NoError(t, builder.WriteUpstreamJSON(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var output struct {
+		Servers []*toolhiveRegistry.UpstreamServerDetail `json:"servers"`
+	}
+	require.NoError(t, json.Unmarshal(data, &output))
+	require.Len(t, output.Servers, 2)
+
+	image := output.Servers[0].Server
+	assert.Equal(t, "fetch", image.Name)
+	assert.Equal(t, toolhiveRegistry.UpstreamServerStatusDeprecated, image.Status)
+	require.Len(t, image.Packages, 1)
+	assert.Equal(t, "docker", image.Packages[0].RegistryName)
+	assert.Equal(t, "ghcr.io/example/fetch", image.Packages[0].Name)
+	assert.Equal(t, "1.2.0", image.Packages[0].Version)
+	assert.Equal(t, "https://github.com/example/fetch", image.Repository.URL)
+	assert.Equal(t, "Official", output.Servers[0].XPublisher.XDevToolhive.Tier)
+
+	remote := output.Servers[1].Server
+	assert.Equal(t, "hosted", remote.Name)
+	assert.Equal(t, toolhiveRegistry.UpstreamServerStatusActive, remote.Status)
+	require.Len(t, remote.Remotes, 1)
+	assert.Equal(t, toolhiveRegistry.UpstreamTransportTypeStreamable, remote.Remotes[0].TransportType)
+	assert.Equal(t, "https://api.example.com/mcp", remote.Remotes[0].URL)
+}
@@ -0,0 +1,160 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+)
+
+// ValidationCacheFile is the name of the validation cache in the cache directory
+const ValidationCacheFile = "validation-cache.json"
+
+// validationCacheVersion is bumped when the cache format or the validation rules
+// change in a way the schema version doesn't capture
+const validationCacheVersion = 1
+
+// toolhiveModule is the module whose embedded schema entries are validated against
+const toolhiveModule = "github.com/stacklok/toolhive"
+
+// CachedValidation is the last validation result of an entry
+type CachedValidation struct {
+	// Hash is the ContentHash of the entry that was validated
+	Hash  string `json:"hash"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+}
+
+// validationCacheData is the on-disk format of the validation cache
+type validationCacheData struct {
+	Version int                         `json:"version"`
+	Schema  string                      `json:"schema"`
+	Entries map[string]CachedValidation `json:"entries"`
+}
+
+// ValidationCache records validation results keyed by entry name and content hash, so
+// entries that haven't changed since they last passed aren't validated again
+type ValidationCache struct {
+	path   string
+	schema string
+
+	mu      sync.Mutex
+	entries map[string]CachedValidation
+	hits    int
+}
+
+// SchemaVersion identifies the schema entries are validated against: the schema URL
+// and the version of the toolhive library that embeds it
+func SchemaVersion() string {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == toolhiveModule {
+				version = dep.Version
+				if dep.Replace != nil {
+					version = dep.Replace.Path + "@" + dep.Replace.Version
+				}
+			}
+		}
+	}
+	return DefaultSchemaURL + "@" + version
+}
+
+// OpenValidationCache opens the validation cache in dir for the given schema version.
+// A missing or unreadable cache, or one written for another schema version, starts empty.
+func OpenValidationCache(dir, schema string) (*ValidationCache, error) {
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	cache := &ValidationCache{
+		path:    filepath.Join(dir, ValidationCacheFile),
+		schema:  schema,
+		entries: make(map[string]CachedValidation),
+	}
+
+	data, err := os.ReadFile(cache.path) // #nosec G304 - path is the cache file in the configured cache directory
+	if errors.Is(err, os.ErrNotExist) {
+		return cache, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read validation cache: %w", err)
+	}
+
+	// A corrupt cache only costs a full validation, so it's discarded rather than reported
+	var stored validationCacheData
+	if json.Unmarshal(data, &stored) == nil &&
+		stored.Version == validationCacheVersion && stored.Schema == schema && stored.Entries != nil {
+		cache.entries = stored.Entries
+	}
+
+	return cache, nil
+}
+
+// Passed returns true if the entry last passed validation with the same content hash
+func (c *ValidationCache) Passed(name, hash string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.entries[name]
+	if !ok || !cached.Valid || cached.Hash != hash {
+		return false
+	}
+	c.hits++
+	return true
+}
+
+// Record stores the result of validating an entry with the given content hash
+func (c *ValidationCache) Record(name, hash string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := CachedValidation{Hash: hash, Valid: err == nil}
+	if err != nil {
+		result.Error = err.Error()
+	}
+	c.entries[name] = result
+}
+
+// Hits returns how many entries were skipped because they last passed with the same content
+func (c *ValidationCache) Hits() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hits
+}
+
+// Save writes the cache. It's written to a temporary file that replaces the cache, so
+// runs sharing the cache directory never read a partially written cache.
+func (c *ValidationCache) Save() error {
+	c.mu.Lock()
+	data, err := json.MarshalIndent(validationCacheData{
+		Version: validationCacheVersion,
+		Schema:  c.schema,
+		Entries: c.entries,
+	}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation cache: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(c.path), ValidationCacheFile+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create validation cache: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write validation cache: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write validation cache: %w", err)
+	}
+	if err := os.Rename(file.Name(), c.path); err != nil {
+		return fmt.Errorf("failed to replace validation cache: %w", err)
+	}
+	return nil
+}
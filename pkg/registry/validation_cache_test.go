@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// loadWithCache loads the registry with the validation cache in cacheDir and saves the cache,
+// returning how many entries the cache let the loader skip and the load error
+func loadWithCache(t *testing.T, registryDir, cacheDir, schema string) (int, error) {
+	t.Helper()
+
+	cache, err := OpenValidationCache(cacheDir, schema)
+	require.NoError(t, err)
+	loader := NewLoader(registryDir)
+	loader.SetValidationCache(cache)
+	loadErr := loader.LoadAll()
+	require.NoError(t, cache.Save())
+	return cache.Hits(), loadErr
+}
+
+// specHash returns the content hash of the spec of a registry entry
+func specHash(t *testing.T, registryDir, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(registryDir, name, "spec.yaml"))
+	require.NoError(t, err)
+	var entry types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal(types.NormalizeSpecData(data), &entry))
+	hash, err := entry.ContentHash()
+	require.NoError(t, err)
+	return hash
+}
+
+func TestValidationCache_SkipsUnchangedEntries(t *testing.T) {
+	t.Parallel()
+
+	registryDir, cacheDir := t.TempDir(), t.TempDir()
+	writeSpec(t, registryDir, "server1", "First server")
+	writeSpec(t, registryDir, "server2", "Second server")
+
+	// The first run validates everything
+	hits, err := loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.NoError(t, err)
+	assert.Equal(t, 0, hits)
+
+	// Unchanged entries are skipped
+	hits, err = loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, hits)
+
+	// A modified entry is revalidated
+	writeSpec(t, registryDir, "server2", "Second server, updated")
+	hits, err = loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	// A new schema version discards the cache
+	hits, err = loadWithCache(t, registryDir, cacheDir, "schema-2")
+	require.NoError(t, err)
+	assert.Equal(t, 0, hits)
+	hits, err = loadWithCache(t, registryDir, cacheDir, "schema-2")
+	require.NoError(t, err)
+	assert.Equal(t, 2, hits)
+}
+
+func TestValidationCache_CachedPassSkipsValidation(t *testing.T) {
+	t.Parallel()
+
+	registryDir, cacheDir := t.TempDir(), t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(registryDir, "server1"), 0755))
+	spec := "description: Server with an invalid tier\nimage: test/server1:1.0\ntransport: stdio\ntier: Gold\n"
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "server1", "spec.yaml"), []byte(spec), 0644))
+
+	// Failures are recorded but never skipped
+	_, err := loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.Error(t, err)
+	hits, err := loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.Error(t, err)
+	assert.Equal(t, 0, hits)
+
+	// A cached pass for the same content is trusted without validating
+	cache, err := OpenValidationCache(cacheDir, "schema-1")
+	require.NoError(t, err)
+	cache.Record("server1", specHash(t, registryDir, "server1"), nil)
+	require.NoError(t, cache.Save())
+
+	hits, err = loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.NoError(t, err)
+	assert.Equal(t, 1, hits)
+
+	// Changing the entry forces it to be validated again
+	spec = "description: Server with an invalid tier, updated\nimage: test/server1:1.0\ntransport: stdio\ntier: Gold\n"
+	require.NoError(t, os.WriteFile(filepath.Join(registryDir, "server1", "spec.yaml"), []byte(spec), 0644))
+	hits, err = loadWithCache(t, registryDir, cacheDir, "schema-1")
+	require.Error(t, err)
+	assert.Equal(t, 0, hits)
+}
+
+func TestOpenValidationCache(t *testing.T) {
+	t.Parallel()
+
+	// The cache directory is created if needed
+	cacheDir := filepath.Join(t.TempDir(), "nested", "cache")
+	cache, err := OpenValidationCache(cacheDir, "schema-1")
+	require.NoError(t, err)
+	cache.Record("server1", "abc", nil)
+	require.NoError(t, cache.Save())
+
+	cache, err = OpenValidationCache(cacheDir, "schema-1")
+	require.NoError(t, err)
+	assert.True(t, cache.Passed("server1", "abc"))
+	assert.False(t, cache.Passed("server1", "def"))
+	assert.False(t, cache.Passed("server2", "abc"))
+
+	// Only the cache file is left behind
+	files, err := os.ReadDir(cacheDir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	assert.Equal(t, ValidationCacheFile, files[0].Name())
+
+	// A corrupt cache starts empty and is replaced on save
+	require.NoError(t, os.WriteFile(filepath.Join(cacheDir, ValidationCacheFile), []byte("{not json"), 0600))
+	cache, err = OpenValidationCache(cacheDir, "schema-1")
+	require.NoError(t, err)
+	assert.False(t, cache.Passed("server1", "abc"))
+	require.NoError(t, cache.Save())
+}
@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"errors"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// EntryResult is the validation outcome of a single registry entry
+type EntryResult struct {
+	Name  string `json:"name"`
+	Path  string `json:"path,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Valid bool   `json:"valid"`
+	Error string `json:"error,omitempty"`
+	// Diagnostics breaks the error down into each failing field
+	Diagnostics []Diagnostic `json:"diagnostics,omitempty"`
+}
+
+// ValidationReport is a machine-readable summary of validating the whole registry
+type ValidationReport struct {
+	Valid   bool           `json:"valid"`
+	Total   int            `json:"total"`
+	Passed  int            `json:"passed"`
+	Failed  int            `json:"failed"`
+	Types   map[string]int `json:"types"`
+	Entries []EntryResult  `json:"entries"`
+	// Errors holds registry-level failures that aren't tied to a single entry
+	Errors []string `json:"errors,omitempty"`
+	// Warnings holds the findings of the registry-wide checks that don't fail validation
+	Warnings []Diagnostic `json:"warnings,omitempty"`
+}
+
+// AddError records a registry-level failure and marks the report invalid
+func (r *ValidationReport) AddError(err error) {
+	r.Errors = append(r.Errors, err.Error())
+	r.Valid = false
+}
+
+// ValidateEach loads and validates every entry individually, so that one invalid
+// entry doesn't hide the results of the others. Valid entries are added to the
+// loader as LoadAll would; invalid ones are recorded in the report.
+func (l *Loader) ValidateEach() (*ValidationReport, error) {
+	specPaths, err := l.specPaths()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ValidationReport{
+		Types:   map[string]int{"container": 0, "remote": 0},
+		Entries: []EntryResult{},
+	}
+
+	claimed := make(map[string]string, len(specPaths))
+	for _, specPath := range specPaths {
+		dirName := l.dirName(specPath)
+		entry, err := l.loadNamedEntry(specPath, dirName)
+		if err == nil {
+			err = claimName(claimed, entry.GetName(), specPath)
+		}
+		if err != nil {
+			report.Entries = append(report.Entries, EntryResult{
+				Name: dirName, Path: specPath, Error: err.Error(), Diagnostics: Diagnostics(dirName, err),
+			})
+			report.Failed++
+			continue
+		}
+
+		if !l.includes(entry) {
+			continue
+		}
+
+		l.addValidEntry(report, entry, specPath)
+	}
+
+	report.Total = report.Passed + report.Failed
+	report.Valid = report.Failed == 0
+
+	return report, nil
+}
+
+// ValidateNamed loads and validates only the named entries, like ValidateEach does for the
+// whole registry. It returns an error wrapping ErrEntryNotFound if a name doesn't exist.
+func (l *Loader) ValidateNamed(names ...string) (*ValidationReport, error) {
+	report := &ValidationReport{
+		Types:   map[string]int{"container": 0, "remote": 0},
+		Entries: []EntryResult{},
+	}
+
+	for _, name := range names {
+		entry, err := l.LoadByName(name)
+		if errors.Is(err, ErrEntryNotFound) {
+			return nil, err
+		}
+		if err != nil {
+			var entryErr *EntryError
+			result := EntryResult{Name: name, Error: err.Error(), Diagnostics: Diagnostics(name, err)}
+			if errors.As(err, &entryErr) {
+				result.Path = entryErr.Path
+			}
+			report.Entries = append(report.Entries, result)
+			report.Failed++
+			continue
+		}
+
+		l.addValidEntry(report, entry, l.SourcePath(entry.GetName()))
+	}
+
+	report.Total = report.Passed + report.Failed
+	report.Valid = report.Failed == 0
+
+	return report, nil
+}
+
+// addValidEntry records a valid entry in the report and adds it to the loader
+func (l *Loader) addValidEntry(report *ValidationReport, entry *types.RegistryEntry, specPath string) {
+	result := EntryResult{Name: entry.GetName(), Path: specPath, Valid: true}
+	if entry.IsRemote() {
+		result.Type = "remote"
+	} else {
+		result.Type = "container"
+	}
+	report.Types[result.Type]++
+	report.Entries = append(report.Entries, result)
+	report.Passed++
+
+	l.mu.Lock()
+	l.entries[entry.GetName()] = entry
+	l.mu.Unlock()
+}
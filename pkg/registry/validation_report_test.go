@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader_ValidateEach(t *testing.T) {
+	t.Parallel()
+
+	validSpecs := map[string]string{
+		"container": `description: Container server
+transport: stdio
+tier: Community
+status: Active
+image: test/container:1.0.0
+tools:
+  - tool1`,
+		"remote": `description: Remote server
+transport: sse
+tier: Community
+status: Active
+url: https://example.com/mcp
+tools:
+  - tool1`,
+	}
+
+	tests := []struct {
+		name     string
+		invalid  map[string]string
+		wantJSON string
+	}{
+		{
+			name: "all pass",
+			wantJSON: `{
+  "valid": true,
+  "total": 2,
+  "passed": 2,
+  "failed": 0,
+  "types": {"container": 1, "remote": 1},
+  "entries": [
+    {"name": "container", "path": "DIR/container/spec.yaml", "type": "container", "valid": true},
+    {"name": "remote", "path": "DIR/remote/spec.yaml", "type": "remote", "valid": true}
+  ]
+}`,
+		},
+		{
+			name: "one failure",
+			invalid: map[string]string{
+				"broken": `description: Broken server
+transport: stdio
+image: test/broken:1.0.0`,
+			},
+			wantJSON: `{
+  "valid": false,
+  "total": 3,
+  "passed": 2,
+  "failed": 1,
+  "types": {"container": 1, "remote": 1},
+  "entries": [
+    {
+      "name": "broken",
+      "path": "DIR/broken/spec.yaml",
+      "valid": false,
+      "error": "failed to load DIR/broken/spec.yaml: validation failed: entry 'broken': at least one tool must be specified",
+      "diagnostics": [
+        {"entry": "broken", "field": "tools", "message": "at least one tool must be specified", "severity": "error"}
+      ]
+    },
+    {"name": "container", "path": "DIR/container/spec.yaml", "type": "container", "valid": true},
+    {"name": "remote", "path": "DIR/remote/spec.yaml", "type": "remote", "valid": true}
+  ]
+}`,
+		},
+		{
+			name: "duplicate name",
+			invalid: map[string]string{
+				"hosted": `name: remote
+description: Server claiming another directory's name
+transport: sse
+url: https://example.com/hosted
+tier: Community
+status: Active
+tools:
+  - tool1`,
+			},
+			wantJSON: `{
+  "valid": false,
+  "total": 3,
+  "passed": 2,
+  "failed": 1,
+  "types": {"container": 1, "remote": 1},
+  "entries": [
+    {"name": "container", "path": "DIR/container/spec.yaml", "type": "container", "valid": true},
+    {"name": "remote", "path": "DIR/hosted/spec.yaml", "type": "remote", "valid": true},
+    {
+      "name": "remote",
+      "path": "DIR/remote/spec.yaml",
+      "valid": false,
+      "error": "entry name \"remote\" is used by both DIR/hosted/spec.yaml and DIR/remote/spec.yaml",
+      "diagnostics": [
+        {
+          "entry": "remote",
+          "field": "",
+          "message": "entry name \"remote\" is used by both DIR/hosted/spec.yaml and DIR/remote/spec.yaml",
+          "severity": "error"
+        }
+      ]
+    }
+  ]
+}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tmpDir := t.TempDir()
+			for _, specs := range []map[string]string{validSpecs, tt.invalid} {
+				for name, content := range specs {
+					require.NoError(t, os.MkdirAll(filepath.Join(tmpDir, name), 0755))
+					require.NoError(t, os.WriteFile(filepath.Join(tmpDir, name, "spec.yaml"), []byte(content), 0644))
+				}
+			}
+
+			loader := NewLoader(tmpDir)
+			report, err := loader.ValidateEach()
+			require.NoError(t, err)
+
+			data, err := json.Marshal(report)
+			require.NoError(t, err)
+			want := strings.ReplaceAll(tt.wantJSON, "DIR", tmpDir)
+			assert.JSONEq(t, want, string(data))
+
+			// Valid entries are available for further checks
+			assert.Len(t, loader.GetEntries(), 2)
+		})
+	}
+}
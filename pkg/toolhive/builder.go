@@ -1,7 +1,13 @@
 package toolhive
 
 import (
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
 
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
@@ -53,11 +59,136 @@ func (b *CommandBuilder) Build() []string {
 	return b.args
 }
 
+// DefaultSecretPlaceholder is the value passed for required secrets when nothing else is configured
+const DefaultSecretPlaceholder = "placeholder"
+
+// SecretEnvPrefix prefixes the environment variables that supply real secret values for tool
+// discovery, e.g. THV_SECRET_GITHUB_TOKEN for a GITHUB_TOKEN secret
+const SecretEnvPrefix = "THV_SECRET_"
+
+// RunCommandOptions controls how BuildRunCommandWithOptions fills in secret values
+type RunCommandOptions struct {
+	// SecretPlaceholder is passed for required secrets without a better value.
+	// Defaults to DefaultSecretPlaceholder.
+	SecretPlaceholder string
+
+	// LookupEnv, if set, is consulted for secret values under their own names so real
+	// values can be supplied for tool discovery (typically os.LookupEnv). It's also used
+	// instead of os.LookupEnv to read the SecretEnvPrefix variables.
+	LookupEnv func(string) (string, bool)
+
+	// Secrets holds real secret values by env var name, typically read with LoadSecretsFile.
+	// Values from the environment take precedence.
+	Secrets map[string]string
+
+	// EnvFile, if set, is passed to thv run with --env-file. Real secret values, as returned by
+	// SecretEnv, are never put on the command line, so they have to be written to this file.
+	EnvFile string
+}
+
 // BuildRunCommand builds the thv run command arguments from a spec
 func BuildRunCommand(spec *types.RegistryEntry, tempName, image string) []string {
+	return BuildRunCommandWithOptions(spec, tempName, image, RunCommandOptions{})
+}
+
+// secretValue picks the value for a secret env var, in order of preference: the
+// THV_SECRET_<NAME> environment variable, the <NAME> environment variable if LookupEnv
+// is set, the secrets file, the spec's tool_discovery_value hint, and the configured
+// placeholder. It returns an empty string if an optional secret has no real value, and
+// whether the value is a real one from the environment or the secrets file.
+func (o RunCommandOptions) secretValue(spec *types.RegistryEntry, name string, required bool) (string, bool) {
+	lookupEnv := o.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+	if value, ok := lookupEnv(SecretEnvPrefix + name); ok && value != "" {
+		return value, true
+	}
+	if o.LookupEnv != nil {
+		if value, ok := o.LookupEnv(name); ok && value != "" {
+			return value, true
+		}
+	}
+	if value := o.Secrets[name]; value != "" {
+		return value, true
+	}
+	if value := spec.ToolDiscoveryValues[name]; value != "" {
+		return value, false
+	}
+	if !required {
+		return "", false
+	}
+	if o.SecretPlaceholder != "" {
+		return o.SecretPlaceholder, false
+	}
+	return DefaultSecretPlaceholder, false
+}
+
+// SecretEnv returns the real secret values the spec's secret env vars get with opts, by name.
+// BuildRunCommandWithOptions leaves these off the command line; they belong in opts.EnvFile.
+func SecretEnv(spec *types.RegistryEntry, opts RunCommandOptions) map[string]string {
+	secrets := make(map[string]string)
+	if spec.ImageMetadata == nil {
+		return secrets
+	}
+
+	for _, envVar := range spec.ImageMetadata.EnvVars {
+		if envVar == nil || !envVar.Secret {
+			continue
+		}
+		if spec.ToolDiscovery != nil {
+			if _, ok := spec.ToolDiscovery.Env[envVar.Name]; ok {
+				continue
+			}
+		}
+		if value, isReal := opts.secretValue(spec, envVar.Name, envVar.Required); isReal {
+			secrets[envVar.Name] = value
+		}
+	}
+	return secrets
+}
+
+// WriteEnvFile writes values to a new temporary file of NAME=value lines, readable only by
+// the current user, for thv run --env-file. The caller removes the file.
+func WriteEnvFile(values map[string]string) (string, error) {
+	var content strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		if strings.ContainsAny(values[name], "\r\n") {
+			return "", fmt.Errorf("value of %s contains a line break, which an env file can't hold", name)
+		}
+		fmt.Fprintf(&content, "%s=%s\n", name, values[name])
+	}
+
+	file, err := os.CreateTemp("", "thv-env-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create env file: %w", err)
+	}
+	if _, err := file.WriteString(content.String()); err != nil {
+		_ = file.Close()
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		_ = os.Remove(file.Name())
+		return "", fmt.Errorf("failed to write env file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// BuildRunCommandWithOptions builds the thv run command arguments from a spec using the given options.
+// A tool_discovery block in the spec overrides the server's args and env.
+func BuildRunCommandWithOptions(spec *types.RegistryEntry, tempName, image string, opts RunCommandOptions) []string {
 	builder := NewCommandBuilder("run")
 	builder.AddFlag("--name", tempName)
 
+	// Values passed for env vars, used to expand references in args
+	envValues := make(map[string]string)
+
+	var discoveryEnv map[string]string
+	if spec.ToolDiscovery != nil {
+		discoveryEnv = spec.ToolDiscovery.Env
+	}
+
 	if spec.ImageMetadata != nil {
 		// Add transport
 		builder.AddFlag("--transport", spec.ImageMetadata.Transport)
@@ -65,25 +196,128 @@ func BuildRunCommand(spec *types.RegistryEntry, tempName, image string) []string
 		// Add environment variables
 		if spec.ImageMetadata.EnvVars != nil {
 			for _, envVar := range spec.ImageMetadata.EnvVars {
+				if _, ok := discoveryEnv[envVar.Name]; ok {
+					// Set from the discovery env below
+					continue
+				}
 				if envVar.Secret {
-					// For secrets, use placeholder values if required
-					if envVar.Required {
-						builder.AddEnvVar(envVar.Name, "placeholder")
+					// For secrets, use a placeholder value; real ones are passed in the env file
+					if value, isReal := opts.secretValue(spec, envVar.Name, envVar.Required); !isReal {
+						builder.AddEnvVar(envVar.Name, value)
 					}
 				} else if envVar.Default != "" {
 					builder.AddEnvVar(envVar.Name, envVar.Default)
+					envValues[envVar.Name] = envVar.Default
 				}
 			}
 		}
 
+		// Add the discovery env in a stable order
+		for _, name := range slices.Sorted(maps.Keys(discoveryEnv)) {
+			builder.AddEnvVar(name, discoveryEnv[name])
+			envValues[name] = discoveryEnv[name]
+		}
+
+		// Real secret values are read from the env file, so they stay off the command line
+		if opts.EnvFile != "" {
+			builder.AddFlag("--env-file", opts.EnvFile)
+		}
+
 		// Add permission profile
 		if spec.Permissions != nil && spec.Permissions.Network != nil {
 			builder.AddFlag("--permission-profile", "network")
 		}
 	}
 
-	// Add the image as the last positional argument
+	// Add the image as the positional argument
 	builder.AddPositional(image)
 
+	// Pass the server's own arguments after the separator, expanding
+	// references to env vars we set so the server sees concrete values
+	var args []string
+	if spec.ImageMetadata != nil {
+		args = spec.ImageMetadata.Args
+	}
+	if spec.ToolDiscovery != nil && spec.ToolDiscovery.Args != nil {
+		args = spec.ToolDiscovery.Args
+	}
+	if len(args) > 0 {
+		builder.AddPositional("--")
+		for _, arg := range args {
+			builder.AddPositional(expandEnvReferences(arg, envValues))
+		}
+	}
+
 	return builder.Build()
 }
+
+// ErrCredentialsRequired is returned when listing the tools of a remote server that uses OAuth
+// without an Authorization header to send
+var ErrCredentialsRequired = errors.New("remote server requires credentials")
+
+// ResolveRemoteHeaders returns the headers to send when listing the tools of a remote server,
+// by name. Headers declared with from_env are read from the environment (or opts.LookupEnv if
+// set); other headers use their default value. It fails if a required header has no value, and
+// with ErrCredentialsRequired if the server uses OAuth and no Authorization header is set.
+func ResolveRemoteHeaders(spec *types.RegistryEntry, opts RunCommandOptions) (map[string]string, error) {
+	if !spec.IsRemote() || spec.RemoteServerMetadata == nil {
+		return nil, fmt.Errorf("entry is not a remote server")
+	}
+
+	lookupEnv := opts.LookupEnv
+	if lookupEnv == nil {
+		lookupEnv = os.LookupEnv
+	}
+
+	headers := make(map[string]string)
+	for _, header := range spec.RemoteServerMetadata.Headers {
+		if header == nil {
+			continue
+		}
+
+		value := header.Default
+		if envName := spec.HeaderEnvVars[header.Name]; envName != "" {
+			if envValue, ok := lookupEnv(envName); ok && envValue != "" {
+				value = envValue
+			} else if header.Required {
+				return nil, fmt.Errorf("header %s requires environment variable %s to be set", header.Name, envName)
+			}
+		}
+
+		if value != "" {
+			headers[header.Name] = value
+		}
+	}
+
+	if spec.RemoteServerMetadata.OAuthConfig != nil && !hasAuthorizationHeader(headers) {
+		return nil, fmt.Errorf("%w: it uses OAuth and no Authorization header is set "+
+			"(declare an Authorization header with from_env and set that environment variable to a token)",
+			ErrCredentialsRequired)
+	}
+	return headers, nil
+}
+
+// hasAuthorizationHeader reports whether resolved headers include an Authorization header
+func hasAuthorizationHeader(headers map[string]string) bool {
+	for name := range headers {
+		if strings.EqualFold(name, "Authorization") {
+			return true
+		}
+	}
+	return false
+}
+
+// envReferencePattern matches ${VAR} (first group) and $VAR (second group) references in args
+var envReferencePattern = regexp.MustCompile(`\$(?:\{([A-Za-z_][A-Za-z0-9_]*)\}|([A-Za-z_][A-Za-z0-9_]*))`)
+
+// expandEnvReferences replaces $VAR and ${VAR} references with known values. References to
+// unknown variables and any other $, as in $$ or $5, are left exactly as written.
+func expandEnvReferences(arg string, values map[string]string) string {
+	return envReferencePattern.ReplaceAllStringFunc(arg, func(reference string) string {
+		match := envReferencePattern.FindStringSubmatch(reference)
+		if value, ok := values[match[1]+match[2]]; ok {
+			return value
+		}
+		return reference
+	})
+}
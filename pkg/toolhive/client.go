@@ -2,7 +2,10 @@
 package toolhive
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -12,14 +15,34 @@ import (
 	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
+// DefaultStartupTimeout is how long RunServer waits for a server to be running, unless
+// WithStartupTimeout overrides it
+const DefaultStartupTimeout = 60 * time.Second
+
+// startupPollInterval is how often RunServer checks whether a server is running
+const startupPollInterval = 500 * time.Millisecond
+
 // Client represents a ToolHive client
 type Client struct {
-	thvPath string
-	verbose bool
+	thvPath        string
+	verbose        bool
+	runOptions     RunCommandOptions
+	startupTimeout time.Duration
+	pollInterval   time.Duration
+}
+
+// ClientOption configures a Client created by NewClient
+type ClientOption func(*Client)
+
+// WithStartupTimeout sets how long RunServer waits for a server it started to be running
+func WithStartupTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.startupTimeout = timeout
+	}
 }
 
 // NewClient creates a new ToolHive client
-func NewClient(thvPath string, verbose bool) (*Client, error) {
+func NewClient(thvPath string, verbose bool, opts ...ClientOption) (*Client, error) {
 	// Find thv binary if not specified
 	if thvPath == "" {
 		var err error
@@ -29,20 +52,32 @@ func NewClient(thvPath string, verbose bool) (*Client, error) {
 		}
 	}
 
-	return &Client{
-		thvPath: thvPath,
-		verbose: verbose,
-	}, nil
+	client := &Client{
+		thvPath:        thvPath,
+		verbose:        verbose,
+		startupTimeout: DefaultStartupTimeout,
+		pollInterval:   startupPollInterval,
+	}
+	for _, opt := range opts {
+		opt(client)
+	}
+	return client, nil
+}
+
+// SetRunOptions sets the options used to build the thv run command in RunServer
+func (c *Client) SetRunOptions(opts RunCommandOptions) {
+	c.runOptions = opts
 }
 
-// RunServer starts an MCP server from a spec
+// RunServer starts an MCP server from a spec and waits until thv reports it as running.
+// A server that fails or doesn't start within the startup timeout is stopped and removed.
 func (c *Client) RunServer(spec *types.RegistryEntry, serverName string) (string, error) {
 	// Get the image from the spec
 	var image string
 	if spec.IsImage() && spec.ImageMetadata != nil {
 		image = spec.Image
 	} else if spec.IsRemote() {
-		return "", fmt.Errorf("remote servers cannot be run locally")
+		return "", fmt.Errorf("remote servers cannot be run locally, list their tools with ListRemoteTools")
 	} else {
 		return "", fmt.Errorf("no image found in spec file")
 	}
@@ -58,7 +93,17 @@ func (c *Client) RunServer(spec *types.RegistryEntry, serverName string) (string
 
 	// Build the run command
 	tempName := fmt.Sprintf("temp-%s-%d", serverName, time.Now().Unix())
-	runArgs := BuildRunCommand(spec, tempName, image)
+	runOptions := c.runOptions
+	if secrets := SecretEnv(spec, runOptions); len(secrets) > 0 {
+		envFile, err := WriteEnvFile(secrets)
+		if err != nil {
+			return "", err
+		}
+		// thv reads the file while starting the server, so it can go once RunServer returns
+		defer os.Remove(envFile)
+		runOptions.EnvFile = envFile
+	}
+	runArgs := BuildRunCommandWithOptions(spec, tempName, image, runOptions)
 
 	if c.verbose {
 		logger.Debugf("Running command: thv %s", strings.Join(runArgs, " "))
@@ -70,14 +115,104 @@ func (c *Client) RunServer(spec *types.RegistryEntry, serverName string) (string
 		return "", fmt.Errorf("failed to start MCP server: %w\nOutput: %s", err, string(runOutput))
 	}
 
-	// Give the server time to start
-	time.Sleep(5 * time.Second)
+	if err := c.waitForServer(tempName); err != nil {
+		c.cleanUpServer(tempName)
+		return "", err
+	}
 
 	return tempName, nil
 }
 
-// ListTools queries a running MCP server for its tools
+// workloadFailedStatuses are the statuses of a server that won't become running
+var workloadFailedStatuses = map[string]bool{"error": true, "stopped": true}
+
+// waitForServer polls thv until a server is running, it fails, or the startup timeout elapses
+func (c *Client) waitForServer(serverName string) error {
+	deadline := time.Now().Add(c.startupTimeout)
+	for {
+		status, err := c.ServerStatus(serverName)
+		switch {
+		case err == nil && status == "running":
+			return nil
+		case err == nil && workloadFailedStatuses[status]:
+			return fmt.Errorf("MCP server %s failed to start (status: %s)", serverName, status)
+		}
+
+		if !time.Now().Before(deadline) {
+			if err != nil {
+				return fmt.Errorf("MCP server %s wasn't running after %s: %w", serverName, c.startupTimeout, err)
+			}
+			if status == "" {
+				status = "not listed"
+			}
+			return fmt.Errorf("MCP server %s wasn't running after %s (status: %s)", serverName, c.startupTimeout, status)
+		}
+
+		if c.verbose {
+			logger.Debugf("Waiting for MCP server %s to start (status: %s)", serverName, status)
+		}
+		time.Sleep(c.pollInterval)
+	}
+}
+
+// cleanUpServer stops and removes a server that failed to start, logging any failure
+func (c *Client) cleanUpServer(serverName string) {
+	if err := c.StopServer(serverName); err != nil {
+		logger.Debugf("Failed to stop MCP server %s: %v", serverName, err)
+	}
+	if err := c.RemoveServer(serverName); err != nil {
+		logger.Debugf("Failed to remove MCP server %s: %v", serverName, err)
+	}
+}
+
+// ServerStatus returns the status thv list reports for a server (such as running or error),
+// or "" if it isn't listed
+func (c *Client) ServerStatus(serverName string) (string, error) {
+	listArgs := NewCommandBuilder("list").
+		AddBoolFlag("--all", true).
+		AddFlag("--format", "json").
+		Build()
+
+	listCmd := exec.Command(c.thvPath, listArgs...) // #nosec G204 - thvPath is validated in NewClient
+	output, err := listCmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("thv list failed: %w\nOutput: %s", err, string(output))
+	}
+
+	// Skip any warning messages before the JSON; no JSON means no servers
+	jsonStart := strings.Index(string(output), "[")
+	if jsonStart == -1 {
+		return "", nil
+	}
+
+	var workloads []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(output[jsonStart:], &workloads); err != nil {
+		return "", fmt.Errorf("failed to parse thv list output: %w", err)
+	}
+
+	for _, workload := range workloads {
+		if workload.Name == serverName {
+			return workload.Status, nil
+		}
+	}
+	return "", nil
+}
+
+// ListTools queries a running MCP server for its tool names
 func (c *Client) ListTools(serverName string) ([]string, error) {
+	tools, err := c.ListToolsDetailed(serverName)
+	if err != nil {
+		return nil, err
+	}
+	return ToolNames(tools), nil
+}
+
+// ListToolsDetailed queries a running MCP server for its tools, including their descriptions
+// and input schemas
+func (c *Client) ListToolsDetailed(serverName string) ([]Tool, error) {
 	listArgs := NewCommandBuilder("mcp").
 		AddPositional("list").
 		AddPositional("tools").
@@ -91,7 +226,64 @@ func (c *Client) ListTools(serverName string) ([]string, error) {
 		return nil, fmt.Errorf("thv mcp list failed: %w\nOutput: %s", err, string(output))
 	}
 
-	return ParseToolsJSON(string(output))
+	return ParseToolsDetailed(string(output))
+}
+
+// ListRemoteTools queries a remote MCP server for its tool names, sending the headers the spec
+// declares. Resolved header values are only sent to the server and never logged.
+func (c *Client) ListRemoteTools(spec *types.RegistryEntry) ([]string, error) {
+	tools, err := c.ListRemoteToolsDetailed(spec)
+	if err != nil {
+		return nil, err
+	}
+	return ToolNames(tools), nil
+}
+
+// ListRemoteToolsDetailed queries a remote MCP server for its tools like ListRemoteTools,
+// including their descriptions and input schemas
+func (c *Client) ListRemoteToolsDetailed(spec *types.RegistryEntry) ([]Tool, error) {
+	headers, err := ResolveRemoteHeaders(spec, c.runOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.verbose {
+		logger.Debugf("Listing tools from remote server: %s", spec.URL)
+	}
+
+	return listRemoteTools(context.Background(), spec, headers)
+}
+
+// FetchTools lists the tools of the server a spec describes. Image servers are run with
+// thv first, then stopped and removed once their tools are listed; remote servers are
+// already running, so their tools are listed directly.
+func (c *Client) FetchTools(spec *types.RegistryEntry, serverName string) ([]Tool, error) {
+	if spec.IsRemote() {
+		tools, err := c.ListRemoteToolsDetailed(spec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		return tools, nil
+	}
+
+	tempName, err := c.RunServer(spec, serverName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to run server: %w", err)
+	}
+	defer func() {
+		if err := c.StopServer(tempName); err != nil {
+			logger.Warnf("Failed to stop temporary server %s: %v", tempName, err)
+		}
+		if err := c.RemoveServer(tempName); err != nil {
+			logger.Warnf("Failed to remove temporary server %s: %v", tempName, err)
+		}
+	}()
+
+	tools, err := c.ListToolsDetailed(tempName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	return tools, nil
 }
 
 // StopServer stops a running MCP server
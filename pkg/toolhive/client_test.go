@@ -0,0 +1,307 @@
+package toolhive
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// remoteMCPServer starts an MCP server with the search and fetch tools over transport and
+// returns its URL and a function returning the last Authorization header it received
+func remoteMCPServer(t *testing.T, transport string) (string, func() string) {
+	t.Helper()
+
+	mcpServer := server.NewMCPServer("remote", "1.0.0", server.WithToolCapabilities(false))
+	for _, name := range []string{"search", "fetch"} {
+		mcpServer.AddTool(mcp.NewTool(name, mcp.WithDescription("The "+name+" tool")),
+			func(context.Context, mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+				return mcp.NewToolResultText(name), nil
+			})
+	}
+
+	var mu sync.Mutex
+	var authorization string
+	record := func(ctx context.Context, r *http.Request) context.Context {
+		mu.Lock()
+		defer mu.Unlock()
+		if value := r.Header.Get("Authorization"); value != "" {
+			authorization = value
+		}
+		return ctx
+	}
+	lastAuthorization := func() string {
+		mu.Lock()
+		defer mu.Unlock()
+		return authorization
+	}
+
+	var testServer *httptest.Server
+	var path string
+	switch transport {
+	case "sse":
+		testServer = server.NewTestServer(mcpServer, server.WithSSEContextFunc(record))
+		path = "/sse"
+	default:
+		testServer = server.NewTestStreamableHTTPServer(mcpServer, server.WithHTTPContextFunc(record))
+		path = "/mcp"
+	}
+	t.Cleanup(testServer.Close)
+	return testServer.URL + path, lastAuthorization
+}
+
+func TestClient_ListRemoteTools(t *testing.T) {
+	t.Parallel()
+
+	for _, transport := range []string{"sse", "streamable-http"} {
+		t.Run(transport, func(t *testing.T) {
+			t.Parallel()
+
+			url, lastAuthorization := remoteMCPServer(t, transport)
+			tmpDir := t.TempDir()
+			specPath := filepath.Join(tmpDir, "spec.yaml")
+			spec := `url: ` + url + `
+description: Example remote server
+transport: ` + transport + `
+tools:
+  - search
+headers:
+  - name: Authorization
+    description: Bearer token
+    required: true
+    secret: true
+    from_env: EXAMPLE_AUTH
+`
+			require.NoError(t, os.WriteFile(specPath, []byte(spec), 0644))
+
+			var entry types.RegistryEntry
+			require.NoError(t, yaml.Unmarshal([]byte(spec), &entry))
+
+			// Remote servers are listed without running thv
+			client, err := NewClient(filepath.Join(tmpDir, "missing-thv"), false)
+			require.NoError(t, err)
+			client.SetRunOptions(RunCommandOptions{LookupEnv: func(name string) (string, bool) {
+				if name == "EXAMPLE_AUTH" {
+					return "Bearer secret-token", true
+				}
+				return "", false
+			}})
+
+			tools, err := client.ListRemoteTools(&entry)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"fetch", "search"}, tools)
+
+			// The resolved header is sent to the server
+			assert.Equal(t, "Bearer secret-token", lastAuthorization())
+
+			// Updating the spec with the discovered tools doesn't leak the secret
+			require.NoError(t, UpdateSpecTools(specPath, tools))
+			data, err := os.ReadFile(specPath)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "secret-token")
+			assert.Contains(t, string(data), "from_env: EXAMPLE_AUTH")
+		})
+	}
+}
+
+// fakeStartingThv writes a thv stand-in whose run command starts a server that thv list
+// reports with startStatus, then with readyStatus once delay has elapsed. Run arguments
+// and stop and rm calls are recorded in the returned directory.
+func fakeStartingThv(t *testing.T, delay, startStatus, readyStatus string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake thv is a shell script")
+	}
+
+	dir := t.TempDir()
+	script := `#!/bin/sh
+dir="` + dir + `"
+case "$1" in
+run)
+  printf '%s\n' "$@" > "$dir/run"
+  while [ $# -gt 0 ]; do
+    if [ "$1" = "--name" ]; then echo "$2" > "$dir/name"; fi
+    if [ "$1" = "--env-file" ]; then echo "$2" > "$dir/env-file"; cp "$2" "$dir/env"; fi
+    shift
+  done
+  (sleep ` + delay + `; touch "$dir/ready") >/dev/null 2>&1 &
+  ;;
+list)
+  status="` + startStatus + `"
+  if [ -f "$dir/ready" ]; then status="` + readyStatus + `"; fi
+  echo "warning: a newer thv is available"
+  printf '[{"name":"other","status":"running"},{"name":"%s","status":"%s"}]\n' "$(cat "$dir/name")" "$status"
+  ;;
+mcp)
+  echo '{"tools":[{"name":"search"},{"name":"fetch"}]}'
+  ;;
+stop|rm)
+  echo "$2" >> "$dir/$1"
+  ;;
+esac
+`
+	path := filepath.Join(dir, "thv")
+	require.NoError(t, os.WriteFile(path, []byte(script), 0700))
+	return path, dir
+}
+
+func TestClient_RunServerWaitsForReadiness(t *testing.T) {
+	t.Parallel()
+
+	var entry types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal([]byte(`image: test/server:1.0.0
+description: Test server
+transport: stdio
+tools:
+  - search
+`), &entry))
+
+	tests := []struct {
+		name        string
+		readyStatus string
+		timeout     time.Duration
+		wantErr     string
+	}{
+		{name: "ready after a delay", readyStatus: "running", timeout: 10 * time.Second},
+		{name: "never ready", readyStatus: "starting", timeout: time.Second, wantErr: "wasn't running after 1s (status: starting)"},
+		{name: "fails to start", readyStatus: "error", timeout: 10 * time.Second, wantErr: "failed to start (status: error)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			thvPath, dir := fakeStartingThv(t, "0.3", "starting", tt.readyStatus)
+			client, err := NewClient(thvPath, false, WithStartupTimeout(tt.timeout))
+			require.NoError(t, err)
+			client.pollInterval = 50 * time.Millisecond
+
+			start := time.Now()
+			name, err := client.RunServer(&entry, "server")
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+
+				// The server that didn't start is cleaned up
+				for _, command := range []string{"stop", "rm"} {
+					data, err := os.ReadFile(filepath.Join(dir, command))
+					require.NoError(t, err)
+					assert.Contains(t, string(data), "temp-server-")
+				}
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(name, "temp-server-"))
+
+			// The server was polled until ready rather than waiting a fixed time
+			assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+			assert.Less(t, time.Since(start), 5*time.Second)
+			assert.NoFileExists(t, filepath.Join(dir, "stop"))
+		})
+	}
+}
+
+func TestClient_FetchTools(t *testing.T) {
+	t.Parallel()
+
+	var entry types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal([]byte(`image: test/server:1.0.0
+description: Test server
+transport: stdio
+tools:
+  - search
+`), &entry))
+
+	thvPath, dir := fakeStartingThv(t, "0", "starting", "running")
+	client, err := NewClient(thvPath, false)
+	require.NoError(t, err)
+	client.pollInterval = 50 * time.Millisecond
+
+	tools, err := client.FetchTools(&entry, "server")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"fetch", "search"}, ToolNames(tools))
+
+	// The temporary server is stopped and removed once its tools are listed
+	for _, command := range []string{"stop", "rm"} {
+		data, err := os.ReadFile(filepath.Join(dir, command))
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "temp-server-")
+	}
+}
+
+func TestClient_RunServerPassesSecrets(t *testing.T) {
+	t.Parallel()
+
+	var entry types.RegistryEntry
+	require.NoError(t, yaml.Unmarshal([]byte(`image: test/server:1.0.0
+description: Test server
+transport: stdio
+tools:
+  - search
+env_vars:
+  - name: API_TOKEN
+    description: API token
+    required: true
+    secret: true
+  - name: OTHER_TOKEN
+    description: Another token
+    required: true
+    secret: true
+`), &entry))
+
+	thvPath, dir := fakeStartingThv(t, "0", "starting", "running")
+	client, err := NewClient(thvPath, false)
+	require.NoError(t, err)
+	client.pollInterval = 50 * time.Millisecond
+	client.SetRunOptions(RunCommandOptions{Secrets: map[string]string{"API_TOKEN": "real-token"}})
+
+	_, err = client.RunServer(&entry, "server")
+	require.NoError(t, err)
+
+	// Secrets with a real value get it through the env file, the others fall back to the placeholder
+	data, err := os.ReadFile(filepath.Join(dir, "run"))
+	require.NoError(t, err)
+	args := strings.Split(strings.TrimSpace(string(data)), "\n")
+	assert.Contains(t, args, "OTHER_TOKEN=placeholder")
+	assert.NotContains(t, string(data), "real-token")
+
+	env, err := os.ReadFile(filepath.Join(dir, "env"))
+	require.NoError(t, err)
+	assert.Equal(t, "API_TOKEN=real-token\n", string(env))
+
+	// The env file is removed once the server is running
+	envFile, err := os.ReadFile(filepath.Join(dir, "env-file"))
+	require.NoError(t, err)
+	assert.NoFileExists(t, strings.TrimSpace(string(envFile)))
+}
+
+func TestClient_ServerStatus(t *testing.T) {
+	t.Parallel()
+
+	thvPath, dir := fakeStartingThv(t, "0", "starting", "running")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "name"), []byte("server\n"), 0600))
+	client, err := NewClient(thvPath, false)
+	require.NoError(t, err)
+
+	status, err := client.ServerStatus("server")
+	require.NoError(t, err)
+	assert.Equal(t, "starting", status)
+
+	status, err = client.ServerStatus("missing")
+	require.NoError(t, err)
+	assert.Empty(t, status)
+}
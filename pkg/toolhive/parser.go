@@ -23,13 +23,33 @@ type MCPListOutput struct {
 	Tools []Tool `json:"tools"`
 }
 
-// ParseToolsJSON parses JSON output from thv mcp list tools --format json
+// ParseToolsJSON parses JSON output from thv mcp list tools --format json into the tool names
 func ParseToolsJSON(output string) ([]string, error) {
+	tools, err := ParseToolsDetailed(output)
+	if err != nil {
+		return nil, err
+	}
+	return ToolNames(tools), nil
+}
+
+// ToolNames returns the names of tools, in order
+func ToolNames(tools []Tool) []string {
+	var names []string
+	for _, tool := range tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// ParseToolsDetailed parses JSON output from thv mcp list tools --format json into the full
+// tool definitions, including their descriptions and input schemas, sorted by name. Output
+// that isn't JSON is parsed with ParseToolsText, so only the names are set.
+func ParseToolsDetailed(output string) ([]Tool, error) {
 	// Find the JSON part (skip any warning messages before the JSON)
 	jsonStart := strings.Index(output, "{")
 	if jsonStart == -1 {
 		// No JSON found, try text parsing as fallback
-		return ParseToolsText(output)
+		return parseToolsTextDetailed(output)
 	}
 	jsonOutput := output[jsonStart:]
 
@@ -37,17 +57,28 @@ func ParseToolsJSON(output string) ([]string, error) {
 	if err := json.Unmarshal([]byte(jsonOutput), &result); err != nil {
 		logger.Debugf("Failed to parse JSON output: %v", err)
 		// Fallback to text parsing
-		return ParseToolsText(output)
-	}
-
-	var tools []string
-	for _, tool := range result.Tools {
-		tools = append(tools, tool.Name)
+		return parseToolsTextDetailed(output)
 	}
 
 	// Sort tools alphabetically
-	sort.Strings(tools)
+	sort.SliceStable(result.Tools, func(i, j int) bool {
+		return result.Tools[i].Name < result.Tools[j].Name
+	})
+
+	return result.Tools, nil
+}
 
+// parseToolsTextDetailed parses text output with ParseToolsText into tools with only a name
+func parseToolsTextDetailed(output string) ([]Tool, error) {
+	names, err := ParseToolsText(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []Tool
+	for _, name := range names {
+		tools = append(tools, Tool{Name: name})
+	}
 	return tools, nil
 }
 
@@ -0,0 +1,83 @@
+package toolhive
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// detailedToolsOutput is thv mcp list output with descriptions and nested input schemas,
+// preceded by a warning
+const detailedToolsOutput = `A new version of ToolHive is available
+{
+  "tools": [
+    {
+      "name": "search_issues",
+      "description": "Search issues across repositories",
+      "inputSchema": {
+        "type": "object",
+        "properties": {
+          "query": {"type": "string", "description": "Search query"},
+          "filters": {
+            "type": "object",
+            "properties": {
+              "labels": {"type": "array", "items": {"type": "string"}}
+            }
+          }
+        },
+        "required": ["query"]
+      },
+      "annotations": {"readOnlyHint": true}
+    },
+    {
+      "name": "create_issue",
+      "description": "Create an issue.\nThe issue is assigned to the caller."
+    }
+  ]
+}`
+
+func TestParseToolsDetailed(t *testing.T) {
+	t.Parallel()
+
+	tools, err := ParseToolsDetailed(detailedToolsOutput)
+	require.NoError(t, err)
+	require.Len(t, tools, 2)
+
+	// Tools are sorted by name
+	assert.Equal(t, "create_issue", tools[0].Name)
+	assert.Equal(t, "Create an issue.\nThe issue is assigned to the caller.", tools[0].Description)
+	assert.Nil(t, tools[0].InputSchema)
+
+	search := tools[1]
+	assert.Equal(t, "search_issues", search.Name)
+	assert.Equal(t, "Search issues across repositories", search.Description)
+	assert.Equal(t, []any{"query"}, search.InputSchema["required"])
+	properties := search.InputSchema["properties"].(map[string]any)
+	filters := properties["filters"].(map[string]any)["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "array", "items": map[string]any{"type": "string"}}, filters["labels"])
+	assert.Equal(t, map[string]any{"readOnlyHint": true}, search.Annotations)
+}
+
+func TestParseToolsDetailed_TextFallback(t *testing.T) {
+	t.Parallel()
+
+	tools, err := ParseToolsDetailed("TOOLS:\nNAME      DESCRIPTION\nsearch    Search things\nfetch     Fetch a page\n")
+	require.NoError(t, err)
+	assert.Equal(t, []Tool{{Name: "fetch"}, {Name: "search"}}, tools)
+
+	_, err = ParseToolsDetailed("no tools here")
+	assert.ErrorContains(t, err, "no TOOLS section")
+}
+
+func TestParseToolsJSON(t *testing.T) {
+	t.Parallel()
+
+	tools, err := ParseToolsJSON(detailedToolsOutput)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"create_issue", "search_issues"}, tools)
+
+	tools, err = ParseToolsJSON(`{"tools":[]}`)
+	require.NoError(t, err)
+	assert.Empty(t, tools)
+}
@@ -0,0 +1,88 @@
+package toolhive
+
+// Tool discovery is split between two paths. Image-based servers are run and listed with thv
+// (thv run, then thv mcp list --server <name>), since thv manages their containers. Remote
+// servers are already running, but thv mcp list can't send the headers they may require, so
+// their tools are listed in-process with an MCP client instead. Both return tools the same way.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+// remoteListTimeout bounds connecting to a remote server and listing its tools, matching the
+// default timeout of thv mcp list
+const remoteListTimeout = 30 * time.Second
+
+// listRemoteTools connects to the remote MCP server a spec describes, sending headers with
+// every request, and lists its tools sorted by name. Header values are only sent to the
+// server, so they never reach argv.
+func listRemoteTools(ctx context.Context, spec *types.RegistryEntry, headers map[string]string) ([]Tool, error) {
+	ctx, cancel := context.WithTimeout(ctx, remoteListTimeout)
+	defer cancel()
+
+	mcpClient, err := newRemoteClient(spec, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer mcpClient.Close()
+
+	if err := mcpClient.Start(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", spec.URL, err)
+	}
+
+	initRequest := mcp.InitializeRequest{}
+	initRequest.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	initRequest.Params.ClientInfo = mcp.Implementation{Name: "toolhive-registry", Version: "1.0.0"}
+	if _, err := mcpClient.Initialize(ctx, initRequest); err != nil {
+		return nil, fmt.Errorf("failed to initialize MCP session with %s: %w", spec.URL, err)
+	}
+
+	result, err := mcpClient.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools of %s: %w", spec.URL, err)
+	}
+
+	// Round-trip through JSON to get the same tool definitions thv mcp list prints
+	data, err := json.Marshal(result.Tools)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal tools: %w", err)
+	}
+	var tools []Tool
+	if err := json.Unmarshal(data, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse tools: %w", err)
+	}
+	sort.SliceStable(tools, func(i, j int) bool {
+		return tools[i].Name < tools[j].Name
+	})
+	return tools, nil
+}
+
+// newRemoteClient returns an MCP client for the remote server's transport that sends headers
+func newRemoteClient(spec *types.RegistryEntry, headers map[string]string) (*client.Client, error) {
+	switch spec.RemoteServerMetadata.Transport {
+	case "sse":
+		mcpClient, err := client.NewSSEMCPClient(spec.URL, client.WithHeaders(headers))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create SSE MCP client: %w", err)
+		}
+		return mcpClient, nil
+	case "streamable-http":
+		mcpClient, err := client.NewStreamableHttpClient(spec.URL, transport.WithHTTPHeaders(headers))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Streamable HTTP MCP client: %w", err)
+		}
+		return mcpClient, nil
+	default:
+		return nil, fmt.Errorf("unsupported remote transport %q", spec.RemoteServerMetadata.Transport)
+	}
+}
@@ -0,0 +1,65 @@
+package toolhive
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	toolhiveRegistry "github.com/stacklok/toolhive/pkg/registry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
+)
+
+func remoteEntry(url, transport string) *types.RegistryEntry {
+	return &types.RegistryEntry{
+		RemoteServerMetadata: &toolhiveRegistry.RemoteServerMetadata{
+			BaseServerMetadata: toolhiveRegistry.BaseServerMetadata{Transport: transport},
+			URL:                url,
+		},
+	}
+}
+
+func TestListRemoteTools(t *testing.T) {
+	t.Parallel()
+
+	// A server that rejects every request, like one missing its credentials
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+	}))
+	t.Cleanup(unauthorized.Close)
+
+	url, lastAuthorization := remoteMCPServer(t, "streamable-http")
+
+	tests := []struct {
+		name      string
+		url       string
+		transport string
+		want      []string
+		wantErr   string
+	}{
+		{name: "tools sorted by name", url: url, transport: "streamable-http", want: []string{"fetch", "search"}},
+		{name: "unsupported transport", url: url, transport: "stdio", wantErr: `unsupported remote transport "stdio"`},
+		{name: "rejected request", url: unauthorized.URL, transport: "streamable-http", wantErr: "failed to initialize MCP session"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			headers := map[string]string{"Authorization": "Bearer token"}
+			got, err := listRemoteTools(context.Background(), remoteEntry(tt.url, tt.transport), headers)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, ToolNames(got))
+			assert.Equal(t, "The fetch tool", got[0].Description)
+			assert.Equal(t, "Bearer token", lastAuthorization())
+		})
+	}
+}
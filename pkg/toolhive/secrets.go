@@ -0,0 +1,48 @@
+package toolhive
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadSecretsFile reads secret values for tool discovery from a file of NAME=value lines.
+// Blank lines and lines starting with # are skipped, and values may be wrapped in single
+// or double quotes.
+func LoadSecretsFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the user
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	secrets := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("secrets file %s line %d: expected NAME=value", path, lineNum)
+		}
+		secrets[name] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read secrets file: %w", err)
+	}
+
+	return secrets, nil
+}
+
+// unquote strips a matching pair of single or double quotes around a value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,66 @@
+package toolhive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSecretsFile(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+		wantErr string
+	}{
+		{
+			name: "values",
+			content: `# Secrets for tool discovery
+GITHUB_PERSONAL_ACCESS_TOKEN=ghp_example
+
+API_KEY = "key with spaces"
+OTHER_KEY='single=quoted'
+EMPTY=
+`,
+			want: map[string]string{
+				"GITHUB_PERSONAL_ACCESS_TOKEN": "ghp_example",
+				"API_KEY":                      "key with spaces",
+				"OTHER_KEY":                    "single=quoted",
+				"EMPTY":                        "",
+			},
+		},
+		{name: "empty file", content: "", want: map[string]string{}},
+		{name: "missing value", content: "API_KEY=key\nNOT_A_SECRET\n", wantErr: "line 2: expected NAME=value"},
+		{name: "missing name", content: "=value\n", wantErr: "line 1: expected NAME=value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "secrets.env")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0600))
+
+			secrets, err := LoadSecretsFile(path)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, secrets)
+		})
+	}
+}
+
+func TestLoadSecretsFile_Missing(t *testing.T) {
+	t.Parallel()
+
+	_, err := LoadSecretsFile(filepath.Join(t.TempDir(), "missing.env"))
+	assert.ErrorContains(t, err, "failed to read secrets file")
+}
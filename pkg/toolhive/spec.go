@@ -4,15 +4,66 @@ import (
 	"bytes"
 	"fmt"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/stacklok/toolhive-registry/pkg/types"
 )
 
-// UpdateSpecTools updates the tools field in a spec file
+// UpdateSpecTools updates the tools field in a spec file. Comments on tools that are kept,
+// such as descriptions written by UpdateSpecToolsWithDescriptions, are preserved.
 func UpdateSpecTools(path string, tools []string) error {
+	named := make([]Tool, 0, len(tools))
+	for _, tool := range tools {
+		named = append(named, Tool{Name: tool})
+	}
+	return updateSpecTools(path, named, false)
+}
+
+// UpdateSpecToolsWithDescriptions updates the tools field in a spec file, writing the first
+// line of each tool's description as a comment after its name. The tools field itself stays
+// a list of names, as the registry schema requires. Tools without a description keep their comment.
+func UpdateSpecToolsWithDescriptions(path string, tools []Tool) error {
+	return updateSpecTools(path, tools, true)
+}
+
+// updateSpecTools updates the tools field in a spec file, writing descriptions as comments if describe is set
+func updateSpecTools(path string, tools []Tool, describe bool) error {
+	toolsNode := &yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Content: make([]*yaml.Node, 0, len(tools)),
+	}
+
+	for _, tool := range tools {
+		item := &yaml.Node{Kind: yaml.ScalarNode, Value: tool.Name}
+		if describe {
+			item.LineComment = descriptionComment(tool.Description)
+		}
+		toolsNode.Content = append(toolsNode.Content, item)
+	}
+
+	if err := UpdateSpecField(path, "tools", toolsNode); err != nil {
+		return fmt.Errorf("failed to update tools: %w", err)
+	}
+	return nil
+}
+
+// UpdateSpecField sets a field in a spec file to value, preserving comments and the rest of
+// the document. key is a top-level field or a dotted path to a nested one, such as
+// metadata.last_updated; a missing field is added along with any mappings leading to it.
+// Comments on the old value are kept unless value has its own, and so are the line comments
+// of list items whose value is still in the list.
+func UpdateSpecField(path, key string, value *yaml.Node) error {
+	keys := strings.Split(key, ".")
+	if slices.Contains(keys, "") {
+		return fmt.Errorf("invalid field path %q", key)
+	}
+
 	// Read the original file
-	data, err := os.ReadFile(path) // #nosec G304 - path is controlled by application
+	data, err := types.ReadSpecFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
@@ -22,10 +73,12 @@ func UpdateSpecTools(path string, tools []string) error {
 	if err := yaml.Unmarshal(data, &doc); err != nil {
 		return fmt.Errorf("failed to parse YAML: %w", err)
 	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return fmt.Errorf("spec file is empty")
+	}
 
-	// Update the tools field
-	if err := updateToolsInNode(&doc, tools); err != nil {
-		return fmt.Errorf("failed to update tools: %w", err)
+	if err := setFieldInNode(doc.Content[0], keys, value); err != nil {
+		return fmt.Errorf("failed to update %s: %w", key, err)
 	}
 
 	// Marshal back preserving structure
@@ -40,104 +93,142 @@ func UpdateSpecTools(path string, tools []string) error {
 	return os.WriteFile(path, buf.Bytes(), 0600)
 }
 
-// updateToolsInNode updates the tools field in the YAML node tree
-func updateToolsInNode(node *yaml.Node, tools []string) error {
-	// Navigate to the document content
-	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
-		return updateToolsInNode(node.Content[0], tools)
-	}
-
+// setFieldInNode sets the field at the path keys in a mapping node to value, creating it
+// and any mappings leading to it if needed
+func setFieldInNode(node *yaml.Node, keys []string, value *yaml.Node) error {
 	if node.Kind != yaml.MappingNode {
 		return fmt.Errorf("expected mapping node, got %v", node.Kind)
 	}
 
-	// Find or create tools section
-	toolsIndex := -1
-	for i := 0; i < len(node.Content); i += 2 {
-		if node.Content[i].Value == "tools" {
-			toolsIndex = i
-			break
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != keys[0] {
+			continue
 		}
+		if len(keys) > 1 {
+			if err := setFieldInNode(node.Content[i+1], keys[1:], value); err != nil {
+				return fmt.Errorf("%s: %w", keys[0], err)
+			}
+			return nil
+		}
+		keepComments(node.Content[i+1], value)
+		node.Content[i+1] = value
+		return nil
 	}
 
-	// Create new tools array node
-	toolsNode := &yaml.Node{
-		Kind:    yaml.SequenceNode,
-		Content: make([]*yaml.Node, 0, len(tools)),
+	// Add the field, nested in new mappings for the rest of the path
+	child := value
+	for i := len(keys) - 1; i > 0; i-- {
+		child = &yaml.Node{
+			Kind:    yaml.MappingNode,
+			Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: keys[i]}, child},
+		}
 	}
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: keys[0]}, child)
+	return nil
+}
 
-	for _, tool := range tools {
-		toolsNode.Content = append(toolsNode.Content, &yaml.Node{
-			Kind:  yaml.ScalarNode,
-			Value: tool,
-		})
+// keepComments copies the comments of a node being replaced onto its replacement, unless the
+// replacement has its own. Line comments of list items are kept on the items with the same value.
+func keepComments(old, replacement *yaml.Node) {
+	if replacement.HeadComment == "" && replacement.LineComment == "" && replacement.FootComment == "" {
+		replacement.HeadComment = old.HeadComment
+		replacement.LineComment = old.LineComment
+		replacement.FootComment = old.FootComment
 	}
 
-	if toolsIndex >= 0 {
-		// Replace existing tools
-		node.Content[toolsIndex+1] = toolsNode
-	} else {
-		// Add new tools section
-		node.Content = append(node.Content,
-			&yaml.Node{Kind: yaml.ScalarNode, Value: "tools"},
-			toolsNode,
-		)
+	if old.Kind != yaml.SequenceNode || replacement.Kind != yaml.SequenceNode {
+		return
+	}
+	comments := make(map[string]string)
+	for _, item := range old.Content {
+		if item.Kind == yaml.ScalarNode && item.LineComment != "" {
+			comments[item.Value] = item.LineComment
+		}
 	}
+	for _, item := range replacement.Content {
+		if item.Kind == yaml.ScalarNode && item.LineComment == "" {
+			item.LineComment = comments[item.Value]
+		}
+	}
+}
 
-	return nil
+// descriptionComment returns the first line of a tool description as a YAML comment, or "" if it's empty
+func descriptionComment(description string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(description), "\n")
+	if line = strings.TrimSpace(line); line == "" {
+		return ""
+	}
+	return "# " + line
 }
 
-// AddWarningComment adds a warning comment to a spec file
+// AddWarningComment adds a warning comment to a spec file, after its leading comment lines and
+// blank lines and right before its first content line. Nothing is added if the file already
+// contains the warning.
 func AddWarningComment(path, warning, detail string) error {
 	// Read the original file
-	data, err := os.ReadFile(path) // #nosec G304 - path is controlled by application
+	data, err := types.ReadSpecFile(path)
 	if err != nil {
 		return fmt.Errorf("failed to read file: %w", err)
 	}
 
 	// Check if warning already exists
 	if bytes.Contains(data, []byte(warning)) {
-		// Warning already exists
 		return nil
 	}
 
-	// Add warning comment at the beginning (after any existing header comments)
-	lines := bytes.Split(data, []byte("\n"))
+	block := fmt.Sprintf("# WARNING: %s on %s\n# %s\n", warning, time.Now().Format("2006-01-02"), detail)
+
 	var output bytes.Buffer
-	warningAdded := false
+	added := false
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		if !added && len(trimmed) > 0 && !bytes.HasPrefix(trimmed, []byte("#")) {
+			output.WriteString(block)
+			added = true
+		}
+		output.Write(line)
+	}
 
-	for i, line := range lines {
-		// Write existing line
-		if i > 0 {
+	// A file without content gets the warning at the end
+	if !added {
+		if output.Len() > 0 && !bytes.HasSuffix(output.Bytes(), []byte("\n")) {
 			output.WriteByte('\n')
 		}
-		output.Write(line)
+		output.WriteString(block)
+	}
 
-		// Add warning after initial comments but before content
-		if !warningAdded && !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) && len(bytes.TrimSpace(line)) > 0 {
-			// Insert warning before this line
-			output.Reset()
-			if i > 0 {
-				// Write previous lines
-				for j := 0; j < i; j++ {
-					if j > 0 {
-						output.WriteByte('\n')
-					}
-					output.Write(lines[j])
-				}
-				output.WriteByte('\n')
-			}
+	// Write back to file
+	return os.WriteFile(path, output.Bytes(), 0600)
+}
 
-			// Add warning
-			output.WriteString(fmt.Sprintf("# WARNING: %s on %s\n", warning, time.Now().Format("2006-01-02")))
-			output.WriteString(fmt.Sprintf("# %s\n", detail))
+// RemoveWarningComment removes the warning blocks AddWarningComment added to a spec file for
+// warning: the "# WARNING: <warning> on <date>" line and the detail comment line after it.
+// The file is left untouched if it doesn't contain the warning.
+func RemoveWarningComment(path, warning string) error {
+	data, err := types.ReadSpecFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
 
-			// Write current line
-			output.Write(line)
-			warningAdded = true
+	prefix := []byte(fmt.Sprintf("# WARNING: %s on ", warning))
+	var output bytes.Buffer
+	removed, skipDetail := false, false
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		trimmed := bytes.TrimSpace(line)
+		switch {
+		case bytes.HasPrefix(trimmed, prefix):
+			removed, skipDetail = true, true
+			continue
+		case skipDetail && bytes.HasPrefix(trimmed, []byte("#")) && !bytes.HasPrefix(trimmed, []byte("# WARNING: ")):
+			skipDetail = false
+			continue
 		}
+		skipDetail = false
+		output.Write(line)
 	}
 
-	// Write back to file
+	if !removed {
+		return nil
+	}
 	return os.WriteFile(path, output.Bytes(), 0600)
 }
@@ -0,0 +1,344 @@
+package toolhive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// windowsSpec is a spec as saved by some Windows editors, with a BOM and CRLF line endings
+const windowsSpec = "\xEF\xBB\xBF# Test server\r\n" +
+	"image: test/image:latest\r\n" +
+	"description: Test server\r\n" +
+	"transport: stdio\r\n" +
+	"tools:\r\n" +
+	"  - old_tool\r\n"
+
+func writeWindowsSpec(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func assertCleanSpec(t *testing.T, data []byte) {
+	t.Helper()
+	assert.False(t, strings.HasPrefix(string(data), "\xEF\xBB\xBF"), "BOM should be stripped")
+	assert.NotContains(t, string(data), "\r", "line endings should be normalized")
+}
+
+func TestUpdateSpecTools_BOMAndCRLF(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "bom and crlf", content: windowsSpec},
+		{name: "crlf only", content: strings.TrimPrefix(windowsSpec, "\xEF\xBB\xBF")},
+		{name: "bom only", content: strings.ReplaceAll(windowsSpec, "\r\n", "\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			path := writeWindowsSpec(t, tt.content)
+
+			require.NoError(t, UpdateSpecTools(path, []string{"new_tool"}))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assertCleanSpec(t, data)
+			assert.Contains(t, string(data), "# Test server\n")
+			assert.Contains(t, string(data), "  - new_tool\n")
+			assert.NotContains(t, string(data), "old_tool")
+		})
+	}
+}
+
+func TestAddWarningComment_BOMAndCRLF(t *testing.T) {
+	t.Parallel()
+
+	path := writeWindowsSpec(t, windowsSpec)
+	require.NoError(t, AddWarningComment(path, "Tool list fetch failed", "Manual verification may be required"))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assertCleanSpec(t, data)
+
+	lines := strings.Split(string(data), "\n")
+	require.GreaterOrEqual(t, len(lines), 4)
+	assert.Equal(t, "# Test server", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "# WARNING: Tool list fetch failed on "))
+	assert.Equal(t, "# Manual verification may be required", lines[2])
+	assert.Equal(t, "image: test/image:latest", lines[3])
+}
+
+func TestAddWarningComment(t *testing.T) {
+	t.Parallel()
+
+	const warning = "# WARNING: Tool list fetch failed on DATE\n# Manual verification may be required\n"
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{
+			name:    "starts with comments",
+			content: "# Test server\n# ---\nimage: test/image:1.0.0\ntools:\n  - search\n",
+			want:    "# Test server\n# ---\n" + warning + "image: test/image:1.0.0\ntools:\n  - search\n",
+		},
+		{
+			name:    "starts with content",
+			content: "image: test/image:1.0.0\n# Tools\ntools:\n  - search\n",
+			want:    warning + "image: test/image:1.0.0\n# Tools\ntools:\n  - search\n",
+		},
+		{
+			name:    "comments separated by a blank line",
+			content: "# Test server\n\nimage: test/image:1.0.0\n",
+			want:    "# Test server\n\n" + warning + "image: test/image:1.0.0\n",
+		},
+		{
+			name:    "indented comment before content",
+			content: "  # Test server\nimage: test/image:1.0.0",
+			want:    "  # Test server\n" + warning + "image: test/image:1.0.0",
+		},
+		{
+			name:    "only comments",
+			content: "# Test server",
+			want:    "# Test server\n" + warning,
+		},
+		{
+			name:    "already contains the warning",
+			content: "# WARNING: Tool list fetch failed on 2025-01-01\n# Manual verification may be required\nimage: test/image:1.0.0\n",
+			want:    "# WARNING: Tool list fetch failed on 2025-01-01\n# Manual verification may be required\nimage: test/image:1.0.0\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			// Adding the same warning twice only adds it once
+			for range 2 {
+				require.NoError(t, AddWarningComment(path, "Tool list fetch failed", "Manual verification may be required"))
+			}
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			want := strings.ReplaceAll(tt.want, "DATE", time.Now().Format("2006-01-02"))
+			assert.Equal(t, want, string(data))
+		})
+	}
+}
+
+func TestRemoveWarningComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "starts with comments", content: "# Test server\n# ---\nimage: test/image:1.0.0\ntools:\n  - search\n"},
+		{name: "starts with content", content: "image: test/image:1.0.0\ntools:\n  - search\n"},
+		{name: "only comments", content: "# Test server\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.content), 0644))
+
+			require.NoError(t, AddWarningComment(path, "Tool list fetch failed", "Manual verification may be required"))
+			require.NoError(t, AddWarningComment(path, "Tool list could not be auto-updated", "Please verify the tools list manually"))
+
+			// Removing one warning leaves the other
+			require.NoError(t, RemoveWarningComment(path, "Tool list fetch failed"))
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "Tool list fetch failed")
+			assert.NotContains(t, string(data), "Manual verification may be required")
+			assert.Contains(t, string(data), "# WARNING: Tool list could not be auto-updated on ")
+			assert.Contains(t, string(data), "# Please verify the tools list manually\n")
+
+			require.NoError(t, RemoveWarningComment(path, "Tool list could not be auto-updated"))
+			data, err = os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.content, string(data))
+		})
+	}
+}
+
+func TestRemoveWarningComment_NoWarning(t *testing.T) {
+	t.Parallel()
+
+	// A file without the warning isn't rewritten, so it keeps its line endings
+	path := writeWindowsSpec(t, windowsSpec)
+	require.NoError(t, RemoveWarningComment(path, "Tool list fetch failed"))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, windowsSpec, string(data))
+
+	// Other comments, including other warnings, are kept
+	content := "# WARNING: Image is deprecated on 2025-01-01\n# Use test/image-v2\nimage: test/image:1.0.0\n"
+	path = filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	require.NoError(t, RemoveWarningComment(path, "Tool list fetch failed"))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data))
+}
+
+func TestUpdateSpecToolsWithDescriptions(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "spec.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`image: test/image:1.0.0
+description: Test server
+transport: stdio
+tools:
+  - old_tool
+`), 0644))
+
+	require.NoError(t, UpdateSpecToolsWithDescriptions(path, []Tool{
+		{Name: "create_issue", Description: "  Create an issue.\nThe issue is assigned to the caller.  "},
+		{Name: "list_issues"},
+		{Name: "search_issues", Description: "Search issues across repositories"},
+	}))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `tools:
+  - create_issue # Create an issue.
+  - list_issues
+  - search_issues # Search issues across repositories
+`)
+
+	// The tools field is still a plain list of names
+	var spec struct {
+		Tools []string `yaml:"tools"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+	assert.Equal(t, []string{"create_issue", "list_issues", "search_issues"}, spec.Tools)
+
+	// Updating the names alone keeps the descriptions of the remaining tools
+	require.NoError(t, UpdateSpecTools(path, []string{"create_issue", "new_tool"}))
+	data, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "tools:\n  - create_issue # Create an issue.\n  - new_tool\n")
+	assert.NotContains(t, string(data), "search_issues")
+}
+
+func TestUpdateSpecField(t *testing.T) {
+	t.Parallel()
+
+	const spec = `# Test server registry entry
+name: test
+# One-line description (REQUIRED)
+description: Old description # keep it short
+status: Active
+tags:
+  - search # the main use
+  - legacy
+metadata:
+  stars: 10
+  last_updated: "2025-01-01T00:00:00Z" # refreshed nightly
+image: test/image:1.0.0 # pinned
+`
+
+	scalar := func(value string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+	}
+	sequence := func(values ...string) *yaml.Node {
+		node := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, value := range values {
+			node.Content = append(node.Content, scalar(value))
+		}
+		return node
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		value   *yaml.Node
+		want    []string
+		wantErr string
+	}{
+		{
+			name:  "scalar",
+			key:   "description",
+			value: scalar("New description"),
+			want:  []string{"# One-line description (REQUIRED)\ndescription: New description # keep it short\n"},
+		},
+		{
+			name:  "scalar with its own comment",
+			key:   "status",
+			value: &yaml.Node{Kind: yaml.ScalarNode, Value: "Deprecated", LineComment: "# replaced by test-v2"},
+			want:  []string{"status: Deprecated # replaced by test-v2\n"},
+		},
+		{
+			name:  "sequence",
+			key:   "tags",
+			value: sequence("search", "web"),
+			want:  []string{"tags:\n  - search # the main use\n  - web\nmetadata:"},
+		},
+		{
+			name:  "nested",
+			key:   "metadata.last_updated",
+			value: &yaml.Node{Kind: yaml.ScalarNode, Value: "2025-06-01T00:00:00Z", Style: yaml.DoubleQuotedStyle},
+			want:  []string{"  stars: 10\n  last_updated: \"2025-06-01T00:00:00Z\" # refreshed nightly\n"},
+		},
+		{
+			name:  "new nested field",
+			key:   "metadata.pulls",
+			value: scalar("42"),
+			want:  []string{"  last_updated: \"2025-01-01T00:00:00Z\" # refreshed nightly\n  pulls: 42\nimage:"},
+		},
+		{
+			name:  "new field with new parents",
+			key:   "tool_discovery.env.MODE",
+			value: scalar("server"),
+			want:  []string{"image: test/image:1.0.0 # pinned\ntool_discovery:\n  env:\n    MODE: server\n"},
+		},
+		{name: "nested under a scalar", key: "status.reason", value: scalar("x"), wantErr: "status: expected mapping node"},
+		{name: "empty path segment", key: "metadata..stars", value: scalar("1"), wantErr: `invalid field path "metadata..stars"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "spec.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(spec), 0644))
+
+			err := UpdateSpecField(path, tt.key, tt.value)
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, string(data), want)
+			}
+
+			// Comments elsewhere in the document are intact
+			assert.True(t, strings.HasPrefix(string(data), "# Test server registry entry\nname: test\n"))
+			assert.Contains(t, string(data), "image: test/image:1.0.0 # pinned\n")
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/distribution/reference"
+)
+
+// dockerHubHosts are the other hostnames images on Docker Hub are referenced by. The
+// reference library already folds index.docker.io into docker.io.
+var dockerHubHosts = []string{"registry-1.docker.io", "registry.hub.docker.com"}
+
+// ImageReference is a parsed container image reference
+type ImageReference struct {
+	// Registry is the registry hostname (and port), e.g. "docker.io" or "ghcr.io"
+	Registry string
+
+	// Repository is the repository path within the registry, e.g. "library/nginx"
+	Repository string
+
+	// Tag is the image tag, empty if none was specified
+	Tag string
+
+	// Digest is the image digest, empty if none was specified
+	Digest string
+}
+
+// Name returns the fully qualified repository name without tag or digest
+func (r *ImageReference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+// String returns the canonical form of the reference
+func (r *ImageReference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// IsDockerHub returns true if the image is hosted on Docker Hub
+func (r *ImageReference) IsDockerHub() bool {
+	return r.Registry == "docker.io"
+}
+
+// ParseImageReference parses an image reference, canonicalizing Docker Hub
+// shorthand such as "nginx" to registry "docker.io" and repository "library/nginx".
+// Docker Hub's other hostnames are canonicalized to docker.io the same way.
+func ParseImageReference(image string) (*ImageReference, error) {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image reference %q: %w", image, err)
+	}
+
+	ref := &ImageReference{
+		Registry:   reference.Domain(named),
+		Repository: reference.Path(named),
+	}
+	for _, host := range dockerHubHosts {
+		if strings.EqualFold(ref.Registry, host) {
+			ref.Registry = "docker.io"
+			if !strings.Contains(ref.Repository, "/") {
+				ref.Repository = "library/" + ref.Repository
+			}
+		}
+	}
+
+	if tagged, ok := named.(reference.Tagged); ok {
+		ref.Tag = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		ref.Digest = digested.Digest().String()
+	}
+
+	return ref, nil
+}
+
+// ImageReference parses the entry's image into its components.
+// It returns an error for remote servers, which have no image.
+func (r *RegistryEntry) ImageReference() (*ImageReference, error) {
+	if !r.IsImage() {
+		return nil, fmt.Errorf("entry is not an image-based server")
+	}
+	return ParseImageReference(r.Image)
+}
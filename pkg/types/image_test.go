@@ -0,0 +1,95 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImageReference(t *testing.T) {
+	t.Parallel()
+
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	tests := []struct {
+		name    string
+		image   string
+		want    ImageReference
+		wantErr bool
+	}{
+		{
+			name:  "docker hub official image shorthand",
+			image: "nginx",
+			want:  ImageReference{Registry: "docker.io", Repository: "library/nginx"},
+		},
+		{
+			name:  "docker hub user image with tag",
+			image: "mcp/fetch:1.0.0",
+			want:  ImageReference{Registry: "docker.io", Repository: "mcp/fetch", Tag: "1.0.0"},
+		},
+		{
+			name:  "explicit docker.io prefix",
+			image: "docker.io/mcp/fetch:latest",
+			want:  ImageReference{Registry: "docker.io", Repository: "mcp/fetch", Tag: "latest"},
+		},
+		{
+			name:  "explicit docker.io official image",
+			image: "docker.io/library/redis:7",
+			want:  ImageReference{Registry: "docker.io", Repository: "library/redis", Tag: "7"},
+		},
+		{
+			name:  "docker hub index host",
+			image: "index.docker.io/library/redis",
+			want:  ImageReference{Registry: "docker.io", Repository: "library/redis"},
+		},
+		{
+			name:  "docker hub registry host with official image",
+			image: "registry-1.docker.io/redis:7",
+			want:  ImageReference{Registry: "docker.io", Repository: "library/redis", Tag: "7"},
+		},
+		{
+			name:  "docker hub registry host with user image",
+			image: "registry.hub.docker.com/mcp/fetch:latest",
+			want:  ImageReference{Registry: "docker.io", Repository: "mcp/fetch", Tag: "latest"},
+		},
+		{
+			name:  "ghcr image with nested path",
+			image: "ghcr.io/stacklok/dockyard/uvx/arxiv-mcp-server:0.2.11",
+			want:  ImageReference{Registry: "ghcr.io", Repository: "stacklok/dockyard/uvx/arxiv-mcp-server", Tag: "0.2.11"},
+		},
+		{
+			name:  "digest only",
+			image: "ghcr.io/github/github-mcp-server@" + digest,
+			want:  ImageReference{Registry: "ghcr.io", Repository: "github/github-mcp-server", Digest: digest},
+		},
+		{
+			name:  "tag and digest",
+			image: "ghcr.io/github/github-mcp-server:v1@" + digest,
+			want:  ImageReference{Registry: "ghcr.io", Repository: "github/github-mcp-server", Tag: "v1", Digest: digest},
+		},
+		{
+			name:  "registry with port",
+			image: "localhost:5000/team/server:dev",
+			want:  ImageReference{Registry: "localhost:5000", Repository: "team/server", Tag: "dev"},
+		},
+		{
+			name:    "invalid reference",
+			image:   "Invalid/UPPER:tag",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ref, err := ParseImageReference(tt.image)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, *ref)
+		})
+	}
+}
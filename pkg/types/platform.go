@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// KnownPlatforms are the OCI platform strings entries may declare in `platforms`
+var KnownPlatforms = []string{
+	"linux/386",
+	"linux/amd64",
+	"linux/arm/v6",
+	"linux/arm/v7",
+	"linux/arm64",
+	"linux/ppc64le",
+	"linux/riscv64",
+	"linux/s390x",
+	"windows/amd64",
+	"windows/arm64",
+}
+
+// FormatPlatform returns the OCI platform string (os/architecture[/variant]) of an image platform
+func FormatPlatform(os, architecture, variant string) string {
+	platform := os + "/" + architecture
+	if variant != "" {
+		platform += "/" + variant
+	}
+	return platform
+}
+
+// ValidatePlatforms checks that every platform is a known OCI platform string and is listed once
+func ValidatePlatforms(platforms []string) error {
+	seen := make(map[string]bool, len(platforms))
+	for _, platform := range platforms {
+		if !slices.Contains(KnownPlatforms, platform) {
+			return fmt.Errorf("unknown platform %q (known platforms: %s)", platform, strings.Join(KnownPlatforms, ", "))
+		}
+		if seen[platform] {
+			return fmt.Errorf("platform %q is listed more than once", platform)
+		}
+		seen[platform] = true
+	}
+	return nil
+}
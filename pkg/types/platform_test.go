@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePlatforms(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		platforms []string
+		wantErr   string
+	}{
+		{name: "none"},
+		{name: "known platforms", platforms: []string{"linux/amd64", "linux/arm64", "linux/arm/v7"}},
+		{name: "unknown platform", platforms: []string{"linux/amd64", "plan9/amd64"}, wantErr: `unknown platform "plan9/amd64"`},
+		{name: "missing architecture", platforms: []string{"linux"}, wantErr: `unknown platform "linux"`},
+		{name: "wrong case", platforms: []string{"Linux/AMD64"}, wantErr: `unknown platform "Linux/AMD64"`},
+		{name: "duplicate", platforms: []string{"linux/amd64", "linux/amd64"}, wantErr: "listed more than once"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := ValidatePlatforms(tt.platforms)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestFormatPlatform(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "linux/amd64", FormatPlatform("linux", "amd64", ""))
+	assert.Equal(t, "linux/arm/v7", FormatPlatform("linux", "arm", "v7"))
+}
@@ -2,10 +2,15 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/stacklok/toolhive/pkg/registry"
+	"gopkg.in/yaml.v3"
 )
 
 // RegistryEntry is a unified type that can represent either an image-based or remote MCP server
@@ -20,6 +25,54 @@ type RegistryEntry struct {
 	// Extended fields for the registry (applies to both types)
 	Examples []Example `yaml:"examples,omitempty"`
 	License  string    `yaml:"license,omitempty"`
+
+	// Homepage is the project's homepage, if different from the repository
+	Homepage string `yaml:"homepage,omitempty"`
+
+	// DocumentationURL links to the server's documentation
+	DocumentationURL string `yaml:"documentation_url,omitempty"`
+
+	// StarsSource is the GitHub or GitLab repository to count stars from, when it
+	// differs from repository_url (e.g. a server that lives in a monorepo)
+	StarsSource string `yaml:"stars_source,omitempty"`
+
+	// PullsSource is the image to count pulls from, when it differs from the
+	// entry's image (e.g. a mirror)
+	PullsSource string `yaml:"pulls_source,omitempty"`
+
+	// Platforms lists the OCI platforms (e.g. linux/amd64) an image server runs on,
+	// for servers that don't support every platform. Empty means no constraint.
+	Platforms []string `yaml:"platforms,omitempty"`
+
+	// Deprecated marks the server as deprecated, with the reason and what replaces it
+	Deprecated *DeprecationInfo `yaml:"deprecated,omitempty"`
+
+	// Enabled marks whether the entry is active. Entries are enabled unless they
+	// set `enabled: false`; use IsEnabled rather than reading the field directly.
+	Enabled *bool `yaml:"enabled,omitempty"`
+
+	// DeclaredTransport is the transport as written in the spec when it was a non-canonical
+	// spelling (e.g. `http`) that was normalized on load. It's empty for canonical transports.
+	DeclaredTransport string `yaml:"-"`
+
+	// RemoteProvenance is the provenance declared by a remote server's spec, in the same
+	// provenance block as image servers. The toolhive remote server metadata has no
+	// provenance, so it's kept here and never included in the built registry.
+	RemoteProvenance *registry.Provenance `yaml:"-"`
+
+	// ToolDiscovery overrides how the server is run to discover its tools, for servers
+	// that need specific args or env to start. It's never included in the built registry.
+	ToolDiscovery *ToolDiscovery `yaml:"tool_discovery,omitempty"`
+
+	// ToolDiscoveryValues maps env var names to the values used when running the
+	// server to discover its tools. They come from the `tool_discovery_value` hint
+	// on env_vars entries and are never included in the built registry.
+	ToolDiscoveryValues map[string]string `yaml:"-"`
+
+	// HeaderEnvVars maps header names to the environment variables their values
+	// are read from during tool discovery. They come from the `from_env` hint on
+	// headers entries; resolved values are never written back to the spec.
+	HeaderEnvVars map[string]string `yaml:"-"`
 }
 
 // GetServerMetadata returns the underlying ServerMetadata interface
@@ -46,6 +99,41 @@ type Example struct {
 	Sample string `yaml:"sample"`
 }
 
+// DeprecationInfo describes why a server is deprecated and what to use instead
+type DeprecationInfo struct {
+	// Reason explains why the server is deprecated. It's required.
+	Reason string `json:"reason" yaml:"reason"`
+
+	// ReplacedBy names the registry entry that replaces the server, if any
+	ReplacedBy string `json:"replaced_by,omitempty" yaml:"replaced_by,omitempty"`
+
+	// Since is when the server was deprecated, as a date or version
+	Since string `json:"since,omitempty" yaml:"since,omitempty"`
+}
+
+// ToolDiscovery overrides the args and env used when running a server to discover its tools
+type ToolDiscovery struct {
+	// Args replace the server's args during discovery. An empty list runs the server
+	// without args; leaving Args unset keeps the server's own args.
+	Args []string `yaml:"args,omitempty"`
+
+	// Env sets environment variables during discovery, taking precedence over env_vars
+	Env map[string]string `yaml:"env,omitempty"`
+}
+
+// MarshalYAML writes an empty Args list as `args: []`, so it stays distinct from unset Args
+func (d *ToolDiscovery) MarshalYAML() (interface{}, error) {
+	type toolDiscovery struct {
+		Args *[]string         `yaml:"args,omitempty"`
+		Env  map[string]string `yaml:"env,omitempty"`
+	}
+	out := toolDiscovery{Env: d.Env}
+	if d.Args != nil {
+		out.Args = &d.Args
+	}
+	return out, nil
+}
+
 // RegistryMetadata contains metadata about the entire registry
 type RegistryMetadata struct {
 	// Version of the registry format
@@ -67,6 +155,42 @@ func (r *RegistryEntry) IsImage() bool {
 	return r.ImageMetadata != nil && r.Image != ""
 }
 
+// IsEnabled returns true unless the entry is explicitly disabled
+func (r *RegistryEntry) IsEnabled() bool {
+	return r.Enabled == nil || *r.Enabled
+}
+
+// ValidateServerType checks that exactly one of the embedded metadata types is populated.
+// The other one must be nil, not merely empty, since a leftover empty struct
+// makes field access through the embedded types ambiguous.
+func (r *RegistryEntry) ValidateServerType() error {
+	switch {
+	case r.ImageMetadata == nil && r.RemoteServerMetadata == nil:
+		return fmt.Errorf("entry must be either an image or remote server")
+	case r.ImageMetadata != nil && r.RemoteServerMetadata != nil:
+		if r.IsImage() && isZeroRemoteMetadata(r.RemoteServerMetadata) {
+			return fmt.Errorf("image entry has empty remote server metadata, which must be nil")
+		}
+		if r.IsRemote() && isZeroImageMetadata(r.ImageMetadata) {
+			return fmt.Errorf("remote entry has empty image metadata, which must be nil")
+		}
+		return fmt.Errorf("entry cannot be both image and remote server")
+	case r.ImageMetadata != nil && !r.IsImage():
+		return fmt.Errorf("entry has image metadata but no image")
+	case r.RemoteServerMetadata != nil && !r.IsRemote():
+		return fmt.Errorf("entry has remote server metadata but no url")
+	}
+	return nil
+}
+
+func isZeroImageMetadata(m *registry.ImageMetadata) bool {
+	return reflect.ValueOf(*m).IsZero()
+}
+
+func isZeroRemoteMetadata(m *registry.RemoteServerMetadata) bool {
+	return reflect.ValueOf(*m).IsZero()
+}
+
 // GetName returns the name of the entry using the ServerMetadata interface
 func (r *RegistryEntry) GetName() string {
 	if metadata := r.GetServerMetadata(); metadata != nil {
@@ -115,6 +239,30 @@ func (r *RegistryEntry) GetTools() []string {
 	return nil
 }
 
+// GetTags returns the tags of the entry using the ServerMetadata interface
+func (r *RegistryEntry) GetTags() []string {
+	if metadata := r.GetServerMetadata(); metadata != nil {
+		return metadata.GetTags()
+	}
+	return nil
+}
+
+// GetEnvVars returns the env vars of the entry using the ServerMetadata interface
+func (r *RegistryEntry) GetEnvVars() []*registry.EnvVar {
+	if metadata := r.GetServerMetadata(); metadata != nil {
+		return metadata.GetEnvVars()
+	}
+	return nil
+}
+
+// GetRepositoryURL returns the repository URL of the entry using the ServerMetadata interface
+func (r *RegistryEntry) GetRepositoryURL() string {
+	if metadata := r.GetServerMetadata(); metadata != nil {
+		return metadata.GetRepositoryURL()
+	}
+	return ""
+}
+
 // SetName sets the name on the appropriate metadata type
 func (r *RegistryEntry) SetName(name string) {
 	if r.ImageMetadata != nil {
@@ -145,6 +293,172 @@ func (r *RegistryEntry) SetDefaults() {
 	}
 }
 
+// EntryDefaults are the fields a category's group.yaml shares with the entries below it
+type EntryDefaults struct {
+	Tags    []string `yaml:"tags,omitempty"`
+	Tier    string   `yaml:"tier,omitempty"`
+	License string   `yaml:"license,omitempty"`
+	Status  string   `yaml:"status,omitempty"`
+}
+
+// ApplyDefaults fills in the fields the entry doesn't set itself from defaults.
+// Tags are taken as a whole, only when the entry has none.
+func (r *RegistryEntry) ApplyDefaults(defaults EntryDefaults) {
+	var base *registry.BaseServerMetadata
+	switch {
+	case r.ImageMetadata != nil:
+		base = &r.ImageMetadata.BaseServerMetadata
+	case r.RemoteServerMetadata != nil:
+		base = &r.RemoteServerMetadata.BaseServerMetadata
+	default:
+		return
+	}
+
+	if len(base.Tags) == 0 && len(defaults.Tags) > 0 {
+		base.Tags = append([]string(nil), defaults.Tags...)
+	}
+	if base.Tier == "" {
+		base.Tier = defaults.Tier
+	}
+	if base.Status == "" {
+		base.Status = defaults.Status
+	}
+	if r.License == "" {
+		r.License = defaults.License
+	}
+}
+
+// contentView is the JSON shape hashed by ContentHash. The embedded metadata is repeated
+// as named fields because encoding/json drops the fields both embedded types declare.
+type contentView struct {
+	*RegistryEntry
+	Image  *registry.ImageMetadata        `json:"image,omitempty"`
+	Remote *registry.RemoteServerMetadata `json:"remote,omitempty"`
+}
+
+// ContentHash returns a stable SHA-256 hash of everything the entry declares, so
+// entries with the same hash are interchangeable for validation
+func (r *RegistryEntry) ContentHash() (string, error) {
+	data, err := json.Marshal(contentView{RegistryEntry: r, Image: r.ImageMetadata, Remote: r.RemoteServerMetadata})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal entry: %w", err)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// envVarHints holds the registry-only hints of an env_vars entry
+type envVarHints struct {
+	Name               string `yaml:"name"`
+	ToolDiscoveryValue string `yaml:"tool_discovery_value,omitempty"`
+}
+
+// headerHints holds the registry-only hints of a headers entry
+type headerHints struct {
+	Name    string `yaml:"name"`
+	FromEnv string `yaml:"from_env,omitempty"`
+}
+
+// extendedFields holds the fields a spec declares beyond the toolhive metadata
+type extendedFields struct {
+	Examples         []Example        `yaml:"examples,omitempty"`
+	License          string           `yaml:"license,omitempty"`
+	Homepage         string           `yaml:"homepage,omitempty"`
+	DocumentationURL string           `yaml:"documentation_url,omitempty"`
+	StarsSource      string           `yaml:"stars_source,omitempty"`
+	PullsSource      string           `yaml:"pulls_source,omitempty"`
+	Deprecated       *DeprecationInfo `yaml:"deprecated,omitempty"`
+	Enabled          *bool            `yaml:"enabled,omitempty"`
+	Platforms        []string         `yaml:"platforms,omitempty"`
+	ToolDiscovery    *ToolDiscovery   `yaml:"tool_discovery,omitempty"`
+	EnvVars          []envVarHints    `yaml:"env_vars,omitempty"`
+	Headers          []headerHints    `yaml:"headers,omitempty"`
+	// Provenance is only read for remote servers; image servers have it in their metadata
+	Provenance *registry.Provenance `yaml:"provenance,omitempty"`
+}
+
+// MarshalYAML implements custom YAML marshaling that emits only the active metadata (image or
+// remote) followed by the extended fields, so a loaded entry serializes back to a spec that
+// loads into an equivalent entry. The tool_discovery_value and from_env hints are written back
+// onto their env_vars and headers entries. Transport aliases are written in their canonical form,
+// and empty optional lists (such as `args: []`) are dropped.
+func (r *RegistryEntry) MarshalYAML() (interface{}, error) {
+	var metadata interface{}
+	switch {
+	case r.ImageMetadata != nil && r.RemoteServerMetadata != nil:
+		return nil, fmt.Errorf("entry cannot be both image and remote server")
+	case r.ImageMetadata != nil:
+		metadata = r.ImageMetadata
+	case r.RemoteServerMetadata != nil:
+		metadata = r.RemoteServerMetadata
+	default:
+		return nil, fmt.Errorf("entry must be either an image or remote server")
+	}
+
+	var node yaml.Node
+	if err := node.Encode(metadata); err != nil {
+		return nil, fmt.Errorf("failed to marshal server metadata: %w", err)
+	}
+
+	fields := extendedFields{
+		Examples:         r.Examples,
+		License:          r.License,
+		Homepage:         r.Homepage,
+		DocumentationURL: r.DocumentationURL,
+		StarsSource:      r.StarsSource,
+		PullsSource:      r.PullsSource,
+		Deprecated:       r.Deprecated,
+		Enabled:          r.Enabled,
+		Platforms:        r.Platforms,
+		ToolDiscovery:    r.ToolDiscovery,
+	}
+	if r.RemoteServerMetadata != nil {
+		fields.Provenance = r.RemoteProvenance
+	}
+
+	var extended yaml.Node
+	if err := extended.Encode(fields); err != nil {
+		return nil, fmt.Errorf("failed to marshal extended fields: %w", err)
+	}
+	node.Content = append(node.Content, extended.Content...)
+
+	addItemHints(&node, "env_vars", "tool_discovery_value", r.ToolDiscoveryValues)
+	addItemHints(&node, "headers", "from_env", r.HeaderEnvVars)
+
+	return &node, nil
+}
+
+// addItemHints adds hintKey to each item of the sequence under key in a mapping node whose
+// name has a hint
+func addItemHints(mapping *yaml.Node, key, hintKey string, hints map[string]string) {
+	if len(hints) == 0 {
+		return
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key || mapping.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, item := range mapping.Content[i+1].Content {
+			if hint := hints[mappingValue(item, "name")]; hint != "" {
+				item.Content = append(item.Content,
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: hintKey},
+					&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: hint})
+			}
+		}
+	}
+}
+
+// mappingValue returns the scalar value of key in a mapping node, or "" if it isn't set
+func mappingValue(mapping *yaml.Node, key string) string {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1].Value
+		}
+	}
+	return ""
+}
+
 // UnmarshalYAML implements custom YAML unmarshaling to determine server type
 func (r *RegistryEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
 	// First unmarshal into a map to check which fields are present
@@ -179,17 +493,65 @@ func (r *RegistryEntry) UnmarshalYAML(unmarshal func(interface{}) error) error {
 		}
 	}
 
-	// Unmarshal extended fields (examples, license) separately
-	type extendedFields struct {
-		Examples []Example `yaml:"examples,omitempty"`
-		License  string    `yaml:"license,omitempty"`
-	}
+	// Unmarshal extended fields (examples, license, links, metadata sources, tool discovery,
+	// env var and header hints) separately
 	var extended extendedFields
 	if err := unmarshal(&extended); err != nil {
 		return err
 	}
 	r.Examples = extended.Examples
 	r.License = extended.License
+	r.Homepage = extended.Homepage
+	r.DocumentationURL = extended.DocumentationURL
+	r.StarsSource = extended.StarsSource
+	r.PullsSource = extended.PullsSource
+	r.Deprecated = extended.Deprecated
+	r.Enabled = extended.Enabled
+	r.Platforms = extended.Platforms
+	r.ToolDiscovery = extended.ToolDiscovery
+	if r.RemoteServerMetadata != nil {
+		r.RemoteProvenance = extended.Provenance
+	}
+
+	for _, envVar := range extended.EnvVars {
+		if envVar.ToolDiscoveryValue != "" {
+			if r.ToolDiscoveryValues == nil {
+				r.ToolDiscoveryValues = make(map[string]string)
+			}
+			r.ToolDiscoveryValues[envVar.Name] = envVar.ToolDiscoveryValue
+		}
+	}
+
+	for _, header := range extended.Headers {
+		if header.FromEnv != "" {
+			if r.HeaderEnvVars == nil {
+				r.HeaderEnvVars = make(map[string]string)
+			}
+			r.HeaderEnvVars[header.Name] = header.FromEnv
+		}
+	}
+
+	r.normalizeTransport()
 
 	return nil
 }
+
+// normalizeTransport rewrites a transport alias or non-canonical casing to its canonical
+// name, recording the declared spelling in DeclaredTransport. Unknown transports are kept
+// so validation can reject them.
+func (r *RegistryEntry) normalizeTransport() {
+	var transport *string
+	switch {
+	case r.ImageMetadata != nil:
+		transport = &r.ImageMetadata.Transport
+	case r.RemoteServerMetadata != nil:
+		transport = &r.RemoteServerMetadata.Transport
+	default:
+		return
+	}
+
+	if canonical, ok := NormalizeTransport(*transport); ok && canonical != *transport {
+		r.DeclaredTransport = *transport
+		*transport = canonical
+	}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// The public hosts of the code forges whose repositories are supported. Repositories on
+// other hosts, such as GitHub Enterprise or self-hosted GitLab instances, are recognized
+// by the shape of their URL.
+const (
+	GitHubHost = "github.com"
+	GitLabHost = "gitlab.com"
+)
+
+// sshRepositoryURLPattern matches SSH-style git URLs such as git@github.com:owner/repo.git,
+// capturing the host and the path after it
+var sshRepositoryURLPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+@([A-Za-z0-9.-]+):(.+)$`)
+
+// SplitRepositoryURL splits a repository URL into its host, without a port, and the path of
+// the repository. Both URLs with a scheme, such as https://github.com/owner/repo, and SSH
+// URLs such as git@gitlab.com:group/project.git are accepted.
+//
+// The path is the owner and repository on GitHub, followed by any path into the repository,
+// or a GitLab project nested in any number of groups, up to the /-/ that starts GitLab's own
+// pages. It has no .git suffix and at least two parts, or ok is false.
+func SplitRepositoryURL(repoURL string) (host, path string, ok bool) {
+	if match := sshRepositoryURLPattern.FindStringSubmatch(repoURL); match != nil {
+		host, path = match[1], match[2]
+	} else {
+		parsed, err := url.Parse(repoURL)
+		if err != nil || parsed.Hostname() == "" {
+			return "", "", false
+		}
+		host, path = parsed.Hostname(), parsed.Path
+	}
+
+	path, _, _ = strings.Cut(path, "/-/")
+	path = strings.TrimSuffix(strings.Trim(path, "/"), ".git")
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || slices.Contains(parts, "") {
+		return "", "", false
+	}
+	return host, path, true
+}
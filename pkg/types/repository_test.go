@@ -0,0 +1,45 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitRepositoryURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		url      string
+		wantHost string
+		wantPath string
+		wantOK   bool
+	}{
+		{name: "github", url: "https://github.com/owner/repo", wantHost: "github.com", wantPath: "owner/repo", wantOK: true},
+		{name: "trailing .git and slash", url: "https://github.com/owner/repo.git/", wantHost: "github.com", wantPath: "owner/repo", wantOK: true},
+		{name: "path into a repository", url: "https://github.com/owner/repo/tree/main/src", wantHost: "github.com",
+			wantPath: "owner/repo/tree/main/src", wantOK: true},
+		{name: "gitlab subgroup", url: "https://gitlab.com/group/sub/project/-/tree/main", wantHost: "gitlab.com",
+			wantPath: "group/sub/project", wantOK: true},
+		{name: "host with port", url: "https://github.example.com:8443/team/server", wantHost: "github.example.com",
+			wantPath: "team/server", wantOK: true},
+		{name: "ssh", url: "git@gitlab.example.com:group/project.git", wantHost: "gitlab.example.com",
+			wantPath: "group/project", wantOK: true},
+		{name: "ssh scheme", url: "ssh://git@github.com/owner/repo.git", wantHost: "github.com", wantPath: "owner/repo", wantOK: true},
+		{name: "owner only", url: "https://github.com/owner"},
+		{name: "empty part", url: "https://github.com/owner//repo"},
+		{name: "no host", url: "owner/repo"},
+		{name: "empty", url: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			host, path, ok := SplitRepositoryURL(tt.url)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantHost, host)
+			assert.Equal(t, tt.wantPath, path)
+		})
+	}
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"bytes"
+	"os"
+)
+
+// utf8BOM is the byte order mark some Windows editors prepend to UTF-8 files
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// NormalizeSpecData strips a leading UTF-8 byte order mark and converts CRLF and CR
+// line endings to LF, so spec files edited on Windows parse and round-trip cleanly
+func NormalizeSpecData(data []byte) []byte {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !bytes.ContainsRune(data, '\r') {
+		return data
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	return bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+}
+
+// ReadSpecFile reads a spec file and normalizes it with NormalizeSpecData
+func ReadSpecFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 - path is provided by the caller
+	if err != nil {
+		return nil, err
+	}
+	return NormalizeSpecData(data), nil
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeSpecData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "unchanged", input: "image: test\ntools:\n  - a\n", want: "image: test\ntools:\n  - a\n"},
+		{name: "bom", input: "\xEF\xBB\xBFimage: test\n", want: "image: test\n"},
+		{name: "crlf", input: "image: test\r\ntools:\r\n  - a\r\n", want: "image: test\ntools:\n  - a\n"},
+		{name: "bom and crlf", input: "\xEF\xBB\xBF# header\r\nimage: test\r\n", want: "# header\nimage: test\n"},
+		{name: "bare cr", input: "image: test\rtools: []\r", want: "image: test\ntools: []\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, string(NormalizeSpecData([]byte(tt.input))))
+		})
+	}
+}
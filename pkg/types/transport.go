@@ -0,0 +1,32 @@
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// Transports are the canonical transport names entries may use
+var Transports = []string{"stdio", "sse", "streamable-http"}
+
+// TransportAliases maps other spellings of a transport, in lowercase, to their canonical name.
+// Any casing of a canonical name or an alias is accepted and normalized.
+var TransportAliases = map[string]string{
+	"http":               "streamable-http",
+	"streamable_http":    "streamable-http",
+	"streamablehttp":     "streamable-http",
+	"streamable":         "streamable-http",
+	"server-sent-events": "sse",
+}
+
+// NormalizeTransport returns the canonical name of a transport and true, or the transport
+// unchanged and false if it isn't a canonical name or alias in any casing
+func NormalizeTransport(transport string) (string, bool) {
+	lower := strings.ToLower(strings.TrimSpace(transport))
+	if slices.Contains(Transports, lower) {
+		return lower, true
+	}
+	if canonical, ok := TransportAliases[lower]; ok {
+		return canonical, true
+	}
+	return transport, false
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeTransport(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		transport string
+		want      string
+		known     bool
+	}{
+		{transport: "stdio", want: "stdio", known: true},
+		{transport: "sse", want: "sse", known: true},
+		{transport: "streamable-http", want: "streamable-http", known: true},
+		{transport: "SSE", want: "sse", known: true},
+		{transport: "Streamable-HTTP", want: "streamable-http", known: true},
+		{transport: " stdio ", want: "stdio", known: true},
+		{transport: "http", want: "streamable-http", known: true},
+		{transport: "HTTP", want: "streamable-http", known: true},
+		{transport: "streamable_http", want: "streamable-http", known: true},
+		{transport: "streamablehttp", want: "streamable-http", known: true},
+		{transport: "streamable", want: "streamable-http", known: true},
+		{transport: "server-sent-events", want: "sse", known: true},
+		{transport: "carrier-pigeon", want: "carrier-pigeon", known: false},
+		{transport: "", want: "", known: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.transport, func(t *testing.T) {
+			t.Parallel()
+			got, known := NormalizeTransport(tt.transport)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.known, known)
+		})
+	}
+}